@@ -7,11 +7,15 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	adminapiresourceapiv1 "github.com/sh-miyoshi/hekate/pkg/apihandler/admin/v1/apiresource"
 	adminauditapiv1 "github.com/sh-miyoshi/hekate/pkg/apihandler/admin/v1/audit"
 	adminclientapiv1 "github.com/sh-miyoshi/hekate/pkg/apihandler/admin/v1/client"
+	adminclientscopeapiv1 "github.com/sh-miyoshi/hekate/pkg/apihandler/admin/v1/clientscope"
 	adminroleapiv1 "github.com/sh-miyoshi/hekate/pkg/apihandler/admin/v1/customrole"
+	admindeviceapiv1 "github.com/sh-miyoshi/hekate/pkg/apihandler/admin/v1/device"
 	adminkeysapiv1 "github.com/sh-miyoshi/hekate/pkg/apihandler/admin/v1/keys"
 	adminprojectapiv1 "github.com/sh-miyoshi/hekate/pkg/apihandler/admin/v1/project"
+	adminratelimitapiv1 "github.com/sh-miyoshi/hekate/pkg/apihandler/admin/v1/ratelimit"
 	adminsessionapiv1 "github.com/sh-miyoshi/hekate/pkg/apihandler/admin/v1/session"
 	adminuserapiv1 "github.com/sh-miyoshi/hekate/pkg/apihandler/admin/v1/user"
 	authnapiv1 "github.com/sh-miyoshi/hekate/pkg/apihandler/auth/v1/authn"
@@ -23,9 +27,13 @@ import (
 	"github.com/sh-miyoshi/hekate/pkg/db"
 	"github.com/sh-miyoshi/hekate/pkg/db/model"
 	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/geoip"
 	"github.com/sh-miyoshi/hekate/pkg/logger"
 	"github.com/sh-miyoshi/hekate/pkg/login"
+	"github.com/sh-miyoshi/hekate/pkg/mail"
+	"github.com/sh-miyoshi/hekate/pkg/ratelimit"
 	defaultrole "github.com/sh-miyoshi/hekate/pkg/role"
+	"github.com/sh-miyoshi/hekate/pkg/sms"
 	"github.com/sh-miyoshi/hekate/pkg/util"
 )
 
@@ -63,12 +71,17 @@ func setAPI(r *mux.Router) {
 
 	// OpenID Connect API
 	r.HandleFunc(basePath+"/project/{projectName}/.well-known/openid-configuration", oidcapiv1.ConfigGetHandler).Methods("GET")
+	r.HandleFunc(basePath+"/project/{projectName}/.well-known/oauth-authorization-server", oidcapiv1.AuthorizationServerMetadataHandler).Methods("GET")
+	// RFC 8414 inserts the well-known path between the host and the path of the issuer
+	r.HandleFunc("/.well-known/oauth-authorization-server"+basePath+"/project/{projectName}", oidcapiv1.AuthorizationServerMetadataHandler).Methods("GET")
 	r.HandleFunc(basePath+"/project/{projectName}/openid-connect/token", oidcapiv1.TokenHandler).Methods("POST")
 	r.HandleFunc(basePath+"/project/{projectName}/openid-connect/certs", oidcapiv1.CertsHandler).Methods("GET")
 	r.HandleFunc(basePath+"/project/{projectName}/openid-connect/auth", oidcapiv1.AuthGETHandler).Methods("GET")
 	r.HandleFunc(basePath+"/project/{projectName}/openid-connect/auth", oidcapiv1.AuthPOSTHandler).Methods("POST")
 	r.HandleFunc(basePath+"/project/{projectName}/openid-connect/userinfo", oidcapiv1.UserInfoHandler).Methods("GET", "POST")
 	r.HandleFunc(basePath+"/project/{projectName}/openid-connect/revoke", oidcapiv1.RevokeHandler).Methods("POST")
+	r.HandleFunc(basePath+"/project/{projectName}/openid-connect/bc-authorize", oidcapiv1.BackchannelAuthHandler).Methods("POST")
+	r.HandleFunc(basePath+"/project/{projectName}/openid-connect/checksession", oidcapiv1.CheckSessionIframeHandler).Methods("GET")
 
 	// OAuth
 	r.HandleFunc(basePath+"/project/{projectName}/oauth/device", oauthapiv1.DeviceRegisterHandler).Methods("POST")
@@ -76,7 +89,20 @@ func setAPI(r *mux.Router) {
 	// Authenticate API
 	r.HandleFunc(basePath+"/project/{projectName}/authn/login", authnapiv1.UserLoginHandler).Methods("POST")
 	r.HandleFunc(basePath+"/project/{projectName}/authn/otpverify", authnapiv1.OTPVerifyHandler).Methods("POST")
+	r.HandleFunc(basePath+"/project/{projectName}/authn/otpenroll", authnapiv1.OTPEnrollHandler).Methods("POST")
+	r.HandleFunc(basePath+"/project/{projectName}/authn/otpenroll/continue", authnapiv1.OTPEnrollContinueHandler).Methods("POST")
+	r.HandleFunc(basePath+"/project/{projectName}/authn/webauthn/options", authnapiv1.WebAuthnOptionsHandler).Methods("POST")
+	r.HandleFunc(basePath+"/project/{projectName}/authn/webauthn", authnapiv1.WebAuthnLoginHandler).Methods("POST")
+	r.HandleFunc(basePath+"/project/{projectName}/authn/mailotp", authnapiv1.MailOTPVerifyHandler).Methods("POST")
+	r.HandleFunc(basePath+"/project/{projectName}/authn/mailotp/resend", authnapiv1.MailOTPResendHandler).Methods("POST")
+	r.HandleFunc(basePath+"/project/{projectName}/authn/smsotp", authnapiv1.SMSOTPVerifyHandler).Methods("POST")
+	r.HandleFunc(basePath+"/project/{projectName}/authn/smsotp/resend", authnapiv1.SMSOTPResendHandler).Methods("POST")
+	r.HandleFunc(basePath+"/project/{projectName}/authn/magiclink", authnapiv1.MagicLinkSendHandler).Methods("POST")
+	r.HandleFunc(basePath+"/project/{projectName}/authn/magiclink/verify", authnapiv1.MagicLinkConfirmHandler).Methods("GET")
+	r.HandleFunc(basePath+"/project/{projectName}/authn/magiclink/verify", authnapiv1.MagicLinkVerifyHandler).Methods("POST")
 	r.HandleFunc(basePath+"/project/{projectName}/authn/consent", authnapiv1.ConsentHandler).Methods("POST")
+	r.HandleFunc(basePath+"/project/{projectName}/authn/changepassword", authnapiv1.ChangePasswordPageHandler).Methods("GET")
+	r.HandleFunc(basePath+"/project/{projectName}/authn/changepassword", authnapiv1.ChangePasswordHandler).Methods("POST")
 
 	//------------------------------
 	// Admin APIs
@@ -104,6 +130,10 @@ func setAPI(r *mux.Router) {
 	r.HandleFunc(basePath+"/project/{projectName}/user/{userID}/role/{roleID}", adminuserapiv1.UserRoleDeleteHandler).Methods("DELETE")
 	r.HandleFunc(basePath+"/project/{projectName}/user/{userID}/reset-password", adminuserapiv1.UserResetPasswordHandler).Methods("POST")
 	r.HandleFunc(basePath+"/project/{projectName}/user/{userID}/unlock", adminuserapiv1.UserUnlockHandler).Methods("POST")
+	r.HandleFunc(basePath+"/project/{projectName}/user/{userID}/webauthn", adminuserapiv1.UserWebAuthnGetListHandler).Methods("GET")
+	r.HandleFunc(basePath+"/project/{projectName}/user/{userID}/webauthn/{credentialID}", adminuserapiv1.UserWebAuthnDeleteHandler).Methods("DELETE")
+	r.HandleFunc(basePath+"/project/{projectName}/user/{userID}/trusted-devices", adminuserapiv1.UserTrustedDeviceGetListHandler).Methods("GET")
+	r.HandleFunc(basePath+"/project/{projectName}/user/{userID}/trusted-devices/{deviceID}", adminuserapiv1.UserTrustedDeviceDeleteHandler).Methods("DELETE")
 
 	// Client API
 	r.HandleFunc(basePath+"/project/{projectName}/client", adminclientapiv1.AllClientGetHandler).Methods("GET")
@@ -111,6 +141,18 @@ func setAPI(r *mux.Router) {
 	r.HandleFunc(basePath+"/project/{projectName}/client/{clientID}", adminclientapiv1.ClientDeleteHandler).Methods("DELETE")
 	r.HandleFunc(basePath+"/project/{projectName}/client/{clientID}", adminclientapiv1.ClientGetHandler).Methods("GET")
 	r.HandleFunc(basePath+"/project/{projectName}/client/{clientID}", adminclientapiv1.ClientUpdateHandler).Methods("PUT")
+	r.HandleFunc(basePath+"/project/{projectName}/client/{clientID}/mapper", adminclientapiv1.ClientMapperGetListHandler).Methods("GET")
+	r.HandleFunc(basePath+"/project/{projectName}/client/{clientID}/mapper", adminclientapiv1.ClientMapperCreateHandler).Methods("POST")
+	r.HandleFunc(basePath+"/project/{projectName}/client/{clientID}/mapper/{mapperID}", adminclientapiv1.ClientMapperGetHandler).Methods("GET")
+	r.HandleFunc(basePath+"/project/{projectName}/client/{clientID}/mapper/{mapperID}", adminclientapiv1.ClientMapperUpdateHandler).Methods("PUT")
+	r.HandleFunc(basePath+"/project/{projectName}/client/{clientID}/mapper/{mapperID}", adminclientapiv1.ClientMapperDeleteHandler).Methods("DELETE")
+
+	// Client Scope API
+	r.HandleFunc(basePath+"/project/{projectName}/scope", adminclientscopeapiv1.AllClientScopeGetHandler).Methods("GET")
+	r.HandleFunc(basePath+"/project/{projectName}/scope", adminclientscopeapiv1.ClientScopeCreateHandler).Methods("POST")
+	r.HandleFunc(basePath+"/project/{projectName}/scope/{scopeName}", adminclientscopeapiv1.ClientScopeDeleteHandler).Methods("DELETE")
+	r.HandleFunc(basePath+"/project/{projectName}/scope/{scopeName}", adminclientscopeapiv1.ClientScopeGetHandler).Methods("GET")
+	r.HandleFunc(basePath+"/project/{projectName}/scope/{scopeName}", adminclientscopeapiv1.ClientScopeUpdateHandler).Methods("PUT")
 
 	// Custom Role API
 	r.HandleFunc(basePath+"/project/{projectName}/role", adminroleapiv1.AllRoleGetHandler).Methods("GET")
@@ -119,13 +161,28 @@ func setAPI(r *mux.Router) {
 	r.HandleFunc(basePath+"/project/{projectName}/role/{roleID}", adminroleapiv1.RoleGetHandler).Methods("GET")
 	r.HandleFunc(basePath+"/project/{projectName}/role/{roleID}", adminroleapiv1.RoleUpdateHandler).Methods("PUT")
 
+	// API Resource API
+	r.HandleFunc(basePath+"/project/{projectName}/resource", adminapiresourceapiv1.AllAPIResourceGetHandler).Methods("GET")
+	r.HandleFunc(basePath+"/project/{projectName}/resource", adminapiresourceapiv1.APIResourceCreateHandler).Methods("POST")
+	r.HandleFunc(basePath+"/project/{projectName}/resource/{resourceID}", adminapiresourceapiv1.APIResourceDeleteHandler).Methods("DELETE")
+	r.HandleFunc(basePath+"/project/{projectName}/resource/{resourceID}", adminapiresourceapiv1.APIResourceGetHandler).Methods("GET")
+	r.HandleFunc(basePath+"/project/{projectName}/resource/{resourceID}", adminapiresourceapiv1.APIResourceUpdateHandler).Methods("PUT")
+
 	// Session API
 	r.HandleFunc(basePath+"/project/{projectName}/session/{sessionID}", adminsessionapiv1.SessionDeleteHandler).Methods("DELETE")
 	r.HandleFunc(basePath+"/project/{projectName}/session/{sessionID}", adminsessionapiv1.SessionGetHandler).Methods("GET")
 
+	// Device API
+	r.HandleFunc(basePath+"/project/{projectName}/device", admindeviceapiv1.AllDeviceGetHandler).Methods("GET")
+	r.HandleFunc(basePath+"/project/{projectName}/device/{userCode}", admindeviceapiv1.DeviceDeleteHandler).Methods("DELETE")
+
 	// Audit API
 	r.HandleFunc(basePath+"/project/{projectName}/audit", adminauditapiv1.AuditGetHandler).Methods("GET")
 
+	// Rate Limit API
+	r.HandleFunc(basePath+"/project/{projectName}/ratelimit/blocks", adminratelimitapiv1.BlockGetListHandler).Methods("GET")
+	r.HandleFunc(basePath+"/project/{projectName}/ratelimit/blocks/{type}/{value}", adminratelimitapiv1.BlockDeleteHandler).Methods("DELETE")
+
 	//------------------------------
 	// User APIs
 	//------------------------------
@@ -136,6 +193,21 @@ func setAPI(r *mux.Router) {
 	r.HandleFunc(basePath+"/project/{projectName}/user/{userID}/otp", userapiv1.OTPGenerateHandler).Methods("POST")
 	r.HandleFunc(basePath+"/project/{projectName}/user/{userID}/otp/verify", userapiv1.OTPVerifyHandler).Methods("POST")
 	r.HandleFunc(basePath+"/project/{projectName}/user/{userID}/otp", userapiv1.OTPDeleteHandler).Methods("DELETE")
+	r.HandleFunc(basePath+"/project/{projectName}/user/{userID}/otp/recovery-codes", userapiv1.OTPRecoveryCodesHandler).Methods("POST")
+	r.HandleFunc(basePath+"/project/{projectName}/user/{userID}/email-otp", userapiv1.EMailOTPSetupHandler).Methods("POST")
+	r.HandleFunc(basePath+"/project/{projectName}/user/{userID}/email-otp/verify", userapiv1.EMailOTPVerifyHandler).Methods("POST")
+	r.HandleFunc(basePath+"/project/{projectName}/user/{userID}/email-otp", userapiv1.EMailOTPDeleteHandler).Methods("DELETE")
+	r.HandleFunc(basePath+"/project/{projectName}/user/{userID}/sms-otp", userapiv1.SMSOTPSetupHandler).Methods("POST")
+	r.HandleFunc(basePath+"/project/{projectName}/user/{userID}/sms-otp/verify", userapiv1.SMSOTPVerifyHandler).Methods("POST")
+	r.HandleFunc(basePath+"/project/{projectName}/user/{userID}/sms-otp", userapiv1.SMSOTPDeleteHandler).Methods("DELETE")
+	r.HandleFunc(basePath+"/project/{projectName}/user/{userID}/webauthn", userapiv1.WebAuthnRegisterHandler).Methods("POST")
+	r.HandleFunc(basePath+"/project/{projectName}/user/{userID}/webauthn/verify", userapiv1.WebAuthnRegisterVerifyHandler).Methods("POST")
+	r.HandleFunc(basePath+"/project/{projectName}/user/{userID}/webauthn", userapiv1.WebAuthnGetListHandler).Methods("GET")
+	r.HandleFunc(basePath+"/project/{projectName}/user/{userID}/webauthn/{credentialID}", userapiv1.WebAuthnDeleteHandler).Methods("DELETE")
+	r.HandleFunc(basePath+"/project/{projectName}/user/{userID}/trusted-devices", userapiv1.TrustedDeviceGetListHandler).Methods("GET")
+	r.HandleFunc(basePath+"/project/{projectName}/user/{userID}/trusted-devices/{deviceID}", userapiv1.TrustedDeviceDeleteHandler).Methods("DELETE")
+	r.HandleFunc(basePath+"/project/{projectName}/user/{userID}/backchannel-auth", userapiv1.BackchannelAuthGetListHandler).Methods("GET")
+	r.HandleFunc(basePath+"/project/{projectName}/user/{userID}/backchannel-auth/{authReqID}", userapiv1.BackchannelAuthDecideHandler).Methods("POST")
 
 	//------------------------------
 	// Other Path
@@ -144,10 +216,15 @@ func setAPI(r *mux.Router) {
 	// Device Login HTML Page
 	r.HandleFunc("/resource/project/{projectName}/devicelogin", oauthapiv1.DeviceLoginPageHandler).Methods("GET")
 	r.HandleFunc("/resource/project/{projectName}/deviceverify", oauthapiv1.DeviceUserCodeVerifyHandler).Methods("POST")
+	r.HandleFunc("/resource/project/{projectName}/deviceqrcode", oauthapiv1.DeviceQRCodeHandler).Methods("GET")
 	r.HandleFunc("/resource/project/{projectName}/devicecomplete", func(w http.ResponseWriter, r *http.Request) {
-		login.WriteDeviceLoginCompletePage(w)
+		projectName := mux.Vars(r)["projectName"]
+		login.WriteDeviceLoginCompletePage(login.NegotiateLocale(r, projectName, nil), w)
 	}).Methods("GET")
 
+	// WebFinger for OpenID Connect Issuer Discovery
+	r.HandleFunc("/.well-known/webfinger", oidcapiv1.WebFingerHandler).Methods("GET")
+
 	// Health Check
 	r.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
 		if err := db.GetInst().Ping(); err != nil {
@@ -170,29 +247,52 @@ func initDB(dbType, connStr, adminName, adminPassword string) *errors.Error {
 		return errors.Append(err, "Failed to init database manager")
 	}
 
+	masterGrantTypes := []model.GrantType{
+		model.GrantTypeAuthorizationCode,
+		model.GrantTypeClientCredentials,
+		model.GrantTypeRefreshToken,
+		model.GrantTypeDevice,
+	}
+
 	// Set Master Project if not exsits
 	err := db.GetInst().ProjectAdd(&model.ProjectInfo{
 		Name:         "master",
 		CreatedAt:    time.Now(),
 		PermitDelete: false,
 		TokenConfig: &model.TokenConfig{
-			AccessTokenLifeSpan:  model.DefaultAccessTokenExpiresInSec,
-			RefreshTokenLifeSpan: model.DefaultRefreshTokenExpiresInSec,
-			SigningAlgorithm:     "RS256",
-		},
-		AllowGrantTypes: []model.GrantType{
-			model.GrantTypeAuthorizationCode,
-			model.GrantTypeClientCredentials,
-			model.GrantTypeRefreshToken,
-			model.GrantTypeDevice,
-			model.GrantTypePassword, // TODO(for debug)
+			AccessTokenLifeSpan:       model.DefaultAccessTokenExpiresInSec,
+			RefreshTokenLifeSpan:      model.DefaultRefreshTokenExpiresInSec,
+			OfflineSessionLifeSpan:    model.DefaultOfflineSessionLifeSpanSec,
+			OfflineSessionIdleTimeout: model.DefaultOfflineSessionIdleTimeoutSec,
+			SigningAlgorithm:          "RS256",
 		},
+		AllowGrantTypes: masterGrantTypes,
 		UserLock: model.UserLock{
 			Enabled:          false,
 			MaxLoginFailure:  model.DefaultMaxLoginFailure,
 			LockDuration:     model.DefaultLockDuration,
 			FailureResetTime: model.DefaultFailureResetTime,
 		},
+		DefaultLocale: model.DefaultLocale,
+		WebAuthnConfig: model.WebAuthnConfig{
+			AttestationConveyance: model.AttestationConveyanceNone,
+		},
+		MailConfig: model.MailConfig{
+			CodeLifeSpan: model.DefaultMailCodeLifeSpan,
+		},
+		SMSConfig: model.SMSConfig{
+			CodeLifeSpan: model.DefaultSMSCodeLifeSpan,
+		},
+		TOTPConfig: model.TOTPConfig{
+			Algorithm: model.TOTPAlgorithmSHA1,
+			Digits:    model.DefaultTOTPDigits,
+			Period:    model.DefaultTOTPPeriod,
+			SkewSteps: model.DefaultTOTPSkewSteps,
+			Issuer:    model.DefaultTOTPIssuer,
+		},
+		MFAPolicy: model.MFAPolicy{
+			Mode: model.MFAModeOptional,
+		},
 	})
 	if err != nil {
 		if errors.Contains(err, model.ErrProjectAlreadyExists) {
@@ -202,6 +302,38 @@ func initDB(dbType, connStr, adminName, adminPassword string) *errors.Error {
 		}
 	} else {
 		logger.Debug("Add master project")
+
+		// only the portal client can use the password grant for debug
+		cli, err := db.GetInst().ClientGet("master", "portal")
+		if err != nil {
+			return errors.Append(err, "Failed to get portal client in master project")
+		}
+		cli.AllowGrantTypes = append(append([]model.GrantType{}, masterGrantTypes...), model.GrantTypePassword)
+		if err := db.GetInst().ClientUpdate("master", cli); err != nil {
+			return errors.Append(err, "Failed to allow password grant to portal client")
+		}
+	}
+
+	// Set default client scopes to the projects which do not have any client scopes
+	prjs, err := db.GetInst().ProjectGetList(nil)
+	if err != nil {
+		return errors.Append(err, "Failed to get project list")
+	}
+	for _, prj := range prjs {
+		scopes, err := db.GetInst().ClientScopeGetList(prj.Name, nil)
+		if err != nil {
+			return errors.Append(err, "Failed to get client scope list")
+		}
+		if len(scopes) > 0 {
+			continue
+		}
+
+		logger.Info("Add default client scopes to project %s", prj.Name)
+		for _, s := range model.DefaultClientScopes(prj.Name, time.Now()) {
+			if err := db.GetInst().ClientScopeAdd(prj.Name, s); err != nil {
+				return errors.Append(err, "Failed to add default client scope %s", s.Name)
+			}
+		}
 	}
 
 	err = db.GetInst().UserAdd("master", &model.UserInfo{
@@ -261,6 +393,35 @@ func initAll() *errors.Error {
 	}
 	logger.Debug("Successfully initialize audit db with type: %s", typ)
 
+	// Initialize Rate Limit
+	typ = cfg.RateLimit.DB.Type
+	connStr = cfg.RateLimit.DB.ConnectionString
+	if typ == "" {
+		typ = cfg.DB.Type
+		connStr = cfg.DB.ConnectionString
+	}
+	if err := ratelimit.Init(cfg.RateLimit, typ, connStr); err != nil {
+		return errors.Append(err, "Failed to initialize rate limit")
+	}
+	logger.Debug("Successfully initialize rate limit with type: %s", typ)
+
+	// Initialize Mail Sender
+	if err := mail.Init(cfg.Mail); err != nil {
+		return errors.Append(err, "Failed to initialize mail sender")
+	}
+	logger.Debug("Successfully initialize mail sender with type: %s", cfg.Mail.Type)
+
+	// Initialize SMS Sender
+	if err := sms.Init(cfg.SMS); err != nil {
+		return errors.Append(err, "Failed to initialize sms sender")
+	}
+	logger.Debug("Successfully initialize sms sender with type: %s", cfg.SMS.Type)
+
+	// Initialize GeoIP
+	if err := geoip.Init(cfg.GeoIPFile); err != nil {
+		return errors.Append(err, "Failed to initialize GeoIP")
+	}
+
 	// Initialize DBGC
 	db.InitGC(cfg.DBGCInterval)
 	logger.Debug("Start database GC per %d [sec]", cfg.DBGCInterval)
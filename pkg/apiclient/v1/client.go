@@ -199,3 +199,156 @@ func (h *Handler) ClientUpdate(projectName, clientID string, req *clientapi.Clie
 	}
 	return fmt.Errorf("Unexpected http response got. Message: %s", httpRes.Status)
 }
+
+// ClientMapperAdd ...
+func (h *Handler) ClientMapperAdd(projectName, clientID string, req *clientapi.ProtocolMapper) (*clientapi.ProtocolMapper, error) {
+	url := fmt.Sprintf("%s/adminapi/v1/project/%s/client/%s/mapper", h.serverAddr, projectName, clientID)
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	httpRes, err := h.request("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer httpRes.Body.Close()
+
+	if httpRes.StatusCode == http.StatusOK {
+		var res clientapi.ProtocolMapper
+		if err := json.NewDecoder(httpRes.Body).Decode(&res); err != nil {
+			return nil, err
+		}
+
+		return &res, nil
+	}
+
+	message := ""
+	var res errors.HTTPResponse
+	if err := json.NewDecoder(httpRes.Body).Decode(&res); err == nil {
+		message = res.Error
+	} else {
+		message = "No messages."
+	}
+
+	switch httpRes.StatusCode {
+	case 400:
+		return nil, fmt.Errorf("Invalid request. Message: %s", message)
+	case 403:
+		return nil, fmt.Errorf("Loggined user did not have permission. Please login with other user")
+	case 404:
+		return nil, fmt.Errorf("Client %s in project %s is not found", clientID, projectName)
+	case 409:
+		return nil, fmt.Errorf("Protocol mapper %s is already exists", req.Name)
+	case 500:
+		return nil, fmt.Errorf("Internal server error occuered. Message: %s", message)
+	}
+	return nil, fmt.Errorf("Unexpected http response got. Message: %s", httpRes.Status)
+}
+
+// ClientMapperDelete ...
+func (h *Handler) ClientMapperDelete(projectName, clientID, mapperID string) error {
+	url := fmt.Sprintf("%s/adminapi/v1/project/%s/client/%s/mapper/%s", h.serverAddr, projectName, clientID, mapperID)
+	httpRes, err := h.request("DELETE", url, nil)
+	if err != nil {
+		return err
+	}
+	defer httpRes.Body.Close()
+
+	if httpRes.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	message := ""
+	var res errors.HTTPResponse
+	if err := json.NewDecoder(httpRes.Body).Decode(&res); err == nil {
+		message = res.Error
+	} else {
+		message = "No messages."
+	}
+
+	switch httpRes.StatusCode {
+	case 403:
+		return fmt.Errorf("Loggined user did not have permission. Please login with other user")
+	case 404:
+		return fmt.Errorf("Protocol mapper %s in client %s is not found", mapperID, clientID)
+	case 500:
+		return fmt.Errorf("Internal server error occuered. Message: %s", message)
+	}
+	return fmt.Errorf("Unexpected http response got. Message: %s", httpRes.Status)
+}
+
+// ClientMapperGetList ...
+func (h *Handler) ClientMapperGetList(projectName, clientID string) ([]*clientapi.ProtocolMapper, error) {
+	url := fmt.Sprintf("%s/adminapi/v1/project/%s/client/%s/mapper", h.serverAddr, projectName, clientID)
+	httpRes, err := h.request("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer httpRes.Body.Close()
+
+	if httpRes.StatusCode == http.StatusOK {
+		var res []*clientapi.ProtocolMapper
+		if err := json.NewDecoder(httpRes.Body).Decode(&res); err != nil {
+			return nil, err
+		}
+
+		return res, nil
+	}
+
+	message := ""
+	var res errors.HTTPResponse
+	if err := json.NewDecoder(httpRes.Body).Decode(&res); err == nil {
+		message = res.Error
+	} else {
+		message = "No messages."
+	}
+
+	switch httpRes.StatusCode {
+	case 403:
+		return nil, fmt.Errorf("Loggined user did not have permission. Please login with other user")
+	case 404:
+		return nil, fmt.Errorf("Client %s in project %s is not found", clientID, projectName)
+	case 500:
+		return nil, fmt.Errorf("Internal server error occuered. Message: %s", message)
+	}
+	return nil, fmt.Errorf("Unexpected http response got. Message: %s", httpRes.Status)
+}
+
+// ClientMapperUpdate ...
+func (h *Handler) ClientMapperUpdate(projectName, clientID, mapperID string, req *clientapi.ProtocolMapper) error {
+	url := fmt.Sprintf("%s/adminapi/v1/project/%s/client/%s/mapper/%s", h.serverAddr, projectName, clientID, mapperID)
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpRes, err := h.request("PUT", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer httpRes.Body.Close()
+
+	if httpRes.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	message := ""
+	var res errors.HTTPResponse
+	if err := json.NewDecoder(httpRes.Body).Decode(&res); err == nil {
+		message = res.Error
+	} else {
+		message = "No messages."
+	}
+
+	switch httpRes.StatusCode {
+	case 400:
+		return fmt.Errorf("Invalid request. Message: %s", message)
+	case 403:
+		return fmt.Errorf("Loggined user did not have permission. Please login with other user")
+	case 404:
+		return fmt.Errorf("Protocol mapper %s in client %s is not found", mapperID, clientID)
+	case 500:
+		return fmt.Errorf("Internal server error occuered. Message: %s", message)
+	}
+	return fmt.Errorf("Unexpected http response got. Message: %s", httpRes.Status)
+}
@@ -0,0 +1,186 @@
+package apiclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	neturl "net/url"
+
+	clientscopeapi "github.com/sh-miyoshi/hekate/pkg/apihandler/admin/v1/clientscope"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/hctl/print"
+)
+
+// ScopeAdd ...
+func (h *Handler) ScopeAdd(projectName string, req *clientscopeapi.ClientScopeCreateRequest) (*clientscopeapi.ClientScopeGetResponse, error) {
+	url := fmt.Sprintf("%s/adminapi/v1/project/%s/scope", h.serverAddr, projectName)
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	httpRes, err := h.request("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer httpRes.Body.Close()
+
+	if httpRes.StatusCode == http.StatusOK {
+		var res clientscopeapi.ClientScopeGetResponse
+		if err := json.NewDecoder(httpRes.Body).Decode(&res); err != nil {
+			return nil, err
+		}
+
+		return &res, nil
+	}
+
+	message := ""
+	var res errors.HTTPResponse
+	if err := json.NewDecoder(httpRes.Body).Decode(&res); err == nil {
+		message = res.Error
+	} else {
+		message = "No messages."
+	}
+
+	switch httpRes.StatusCode {
+	case 400:
+		return nil, fmt.Errorf("Invalid request. Message: %s", message)
+	case 403:
+		return nil, fmt.Errorf("Loggined user did not have permission. Please login with other user")
+	case 404:
+		return nil, fmt.Errorf("Project %s is not found", projectName)
+	case 409:
+		return nil, fmt.Errorf("Scope %s is already exists", req.Name)
+	case 500:
+		return nil, fmt.Errorf("Internal server error occuered. Message: %s", message)
+	}
+	return nil, fmt.Errorf("Unexpected http response got. Message: %s", httpRes.Status)
+}
+
+// ScopeDelete ...
+func (h *Handler) ScopeDelete(projectName string, scopeName string) error {
+	url := fmt.Sprintf("%s/adminapi/v1/project/%s/scope/%s", h.serverAddr, projectName, neturl.PathEscape(scopeName))
+	httpRes, err := h.request("DELETE", url, nil)
+	if err != nil {
+		return err
+	}
+	defer httpRes.Body.Close()
+
+	if httpRes.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	message := ""
+	var res errors.HTTPResponse
+	if err := json.NewDecoder(httpRes.Body).Decode(&res); err == nil {
+		message = res.Error
+	} else {
+		message = "No messages."
+	}
+
+	switch httpRes.StatusCode {
+	case 403:
+		return fmt.Errorf("Loggined user did not have permission. Please login with other user")
+	case 404:
+		return fmt.Errorf("Scope %s in project %s is not found", scopeName, projectName)
+	case 500:
+		return fmt.Errorf("Internal server error occuered. Message: %s", message)
+	}
+	return fmt.Errorf("Unexpected http response got. Message: %s", httpRes.Status)
+}
+
+// ScopeGetList ...
+func (h *Handler) ScopeGetList(projectName string, scopeName string) ([]*clientscopeapi.ClientScopeGetResponse, error) {
+	url := fmt.Sprintf("%s/adminapi/v1/project/%s/scope", h.serverAddr, projectName)
+	httpReq, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Add("Authorization", fmt.Sprintf("bearer %s", h.accessToken))
+
+	if scopeName != "" {
+		httpReq.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		values := neturl.Values{}
+		values.Set("name", scopeName)
+		httpReq.URL.RawQuery = values.Encode()
+	}
+
+	dump, _ := httputil.DumpRequest(httpReq, false)
+	print.Debug("server request dump: %q", dump)
+
+	httpRes, err := h.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpRes.Body.Close()
+
+	dump, _ = httputil.DumpResponse(httpRes, false)
+	print.Debug("server response dump: %q", dump)
+
+	if httpRes.StatusCode == http.StatusOK {
+		var res []*clientscopeapi.ClientScopeGetResponse
+		if err := json.NewDecoder(httpRes.Body).Decode(&res); err != nil {
+			return nil, err
+		}
+
+		return res, nil
+	}
+
+	message := ""
+	var res errors.HTTPResponse
+	if err := json.NewDecoder(httpRes.Body).Decode(&res); err == nil {
+		message = res.Error
+	} else {
+		message = "No messages."
+	}
+
+	switch httpRes.StatusCode {
+	case 403:
+		return nil, fmt.Errorf("Loggined user did not have permission. Please login with other user")
+	case 404:
+		return nil, fmt.Errorf("Project %s is not found", projectName)
+	case 500:
+		return nil, fmt.Errorf("Internal server error occuered. Message: %s", message)
+	}
+	return nil, fmt.Errorf("Unexpected http response got. Message: %s", httpRes.Status)
+}
+
+// ScopeUpdate ...
+func (h *Handler) ScopeUpdate(projectName string, scopeName string, req *clientscopeapi.ClientScopePutRequest) error {
+	url := fmt.Sprintf("%s/adminapi/v1/project/%s/scope/%s", h.serverAddr, projectName, neturl.PathEscape(scopeName))
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpRes, err := h.request("PUT", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer httpRes.Body.Close()
+
+	if httpRes.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	message := ""
+	var res errors.HTTPResponse
+	if err := json.NewDecoder(httpRes.Body).Decode(&res); err == nil {
+		message = res.Error
+	} else {
+		message = "No messages."
+	}
+
+	switch httpRes.StatusCode {
+	case 400:
+		return fmt.Errorf("Invalid request. Message: %s", message)
+	case 403:
+		return fmt.Errorf("Loggined user did not have permission. Please login with other user")
+	case 404:
+		return fmt.Errorf("Scope %s in project %s is not found", scopeName, projectName)
+	case 500:
+		return fmt.Errorf("Internal server error occuered. Message: %s", message)
+	}
+	return fmt.Errorf("Unexpected http response got. Message: %s", httpRes.Status)
+}
@@ -255,8 +255,9 @@ func (h *Handler) UserRoleDelete(projectName string, userName string, roleName s
 	return fmt.Errorf("Unexpected http response got. Message: %s", httpRes.Status)
 }
 
-// UserChangePassword ...
-func (h *Handler) UserChangePassword(projectName string, userName string, newPassword string) error {
+// UserChangePassword resets the password of the user
+// If permanent is false, the user must change the password at next login.
+func (h *Handler) UserChangePassword(projectName string, userName string, newPassword string, permanent bool) error {
 	userID, err := h.getUserID(projectName, userName)
 	if err != nil {
 		return err
@@ -264,7 +265,8 @@ func (h *Handler) UserChangePassword(projectName string, userName string, newPas
 
 	url := fmt.Sprintf("%s/adminapi/v1/project/%s/user/%s/reset-password", h.serverAddr, projectName, userID)
 	body, _ := json.Marshal(&userapi.UserResetPasswordRequest{
-		Password: newPassword,
+		Password:  newPassword,
+		Permanent: permanent,
 	})
 
 	httpRes, err := h.request("POST", url, bytes.NewReader(body))
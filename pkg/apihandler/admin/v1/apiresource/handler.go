@@ -0,0 +1,275 @@
+package apiresourceapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/sh-miyoshi/hekate/pkg/audit"
+	"github.com/sh-miyoshi/hekate/pkg/db"
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	jwthttp "github.com/sh-miyoshi/hekate/pkg/http"
+	"github.com/sh-miyoshi/hekate/pkg/logger"
+	"github.com/sh-miyoshi/hekate/pkg/role"
+)
+
+func convertToResponse(resource *model.APIResource) *APIResourceGetResponse {
+	scopes := resource.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+	roles := resource.RequiredRoles
+	if roles == nil {
+		roles = map[string][]string{}
+	}
+	return &APIResourceGetResponse{
+		ID:                  resource.ID,
+		ProjectName:         resource.ProjectName,
+		CreatedAt:           resource.CreatedAt.Format(time.RFC3339),
+		Identifier:          resource.Identifier,
+		Name:                resource.Name,
+		Scopes:              scopes,
+		RequiredRoles:       roles,
+		AccessTokenLifeSpan: resource.AccessTokenLifeSpan,
+	}
+}
+
+// AllAPIResourceGetHandler ...
+//   require role: read-project
+func AllAPIResourceGetHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+
+	// Authorize API Request
+	if err := jwthttp.Authorize(r, projectName, role.ResProject, role.TypeRead); err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	queries := r.URL.Query()
+	logger.Debug("Query: %v", queries)
+
+	filter := &model.APIResourceFilter{
+		Identifier: queries.Get("identifier"),
+	}
+
+	resources, err := db.GetInst().APIResourceGetList(projectName, filter)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get API resource list"))
+		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	res := []*APIResourceGetResponse{}
+	for _, resource := range resources {
+		res = append(res, convertToResponse(resource))
+	}
+
+	jwthttp.ResponseWrite(w, "AllAPIResourceGetHandler", res)
+}
+
+// APIResourceCreateHandler ...
+//   require role: write-project
+func APIResourceCreateHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+
+	var err *errors.Error
+	defer func() {
+		msg := ""
+		if err != nil {
+			msg = err.Error()
+		}
+		if err = audit.GetInst().Save(projectName, time.Now(), "API_RESOURCE", r.Method, r.URL.String(), msg); err != nil {
+			errors.Print(errors.Append(err, "Failed to save audit event"))
+		}
+	}()
+
+	// Authorize API Request
+	if err := jwthttp.Authorize(r, projectName, role.ResProject, role.TypeWrite); err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	// Parse Request
+	var request APIResourceCreateRequest
+	if e := json.NewDecoder(r.Body).Decode(&request); e != nil {
+		err = errors.Append(errors.ErrInvalidRequest, "Failed to decode API resource create request: %v", e)
+		errors.PrintAsInfo(err)
+		errors.WriteToHTTP(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	// Create API resource entry
+	resource := model.APIResource{
+		ID:                  uuid.New().String(),
+		ProjectName:         projectName,
+		CreatedAt:           time.Now(),
+		Identifier:          request.Identifier,
+		Name:                request.Name,
+		Scopes:              request.Scopes,
+		RequiredRoles:       request.RequiredRoles,
+		AccessTokenLifeSpan: request.AccessTokenLifeSpan,
+	}
+
+	if err = db.GetInst().APIResourceAdd(projectName, &resource); err != nil {
+		if errors.Contains(err, model.ErrAPIResourceAlreadyExists) {
+			errors.PrintAsInfo(errors.Append(err, "API resource %s is already exists", resource.Identifier))
+			errors.WriteToHTTP(w, err, http.StatusConflict, "")
+		} else if errors.Contains(err, model.ErrAPIResourceValidateFailed) {
+			errors.PrintAsInfo(errors.Append(err, "API resource validation failed"))
+			errors.WriteToHTTP(w, err, http.StatusBadRequest, "")
+		} else {
+			errors.Print(errors.Append(err, "Failed to create API resource"))
+			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	jwthttp.ResponseWrite(w, "APIResourceCreateHandler", convertToResponse(&resource))
+}
+
+// APIResourceDeleteHandler ...
+//   require role: write-project
+func APIResourceDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	resourceID := vars["resourceID"]
+
+	var err *errors.Error
+	defer func() {
+		msg := ""
+		if err != nil {
+			msg = err.Error()
+		}
+		if err = audit.GetInst().Save(projectName, time.Now(), "API_RESOURCE", r.Method, r.URL.String(), msg); err != nil {
+			errors.Print(errors.Append(err, "Failed to save audit event"))
+		}
+	}()
+
+	// Authorize API Request
+	if err := jwthttp.Authorize(r, projectName, role.ResProject, role.TypeWrite); err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	if err = db.GetInst().APIResourceDelete(projectName, resourceID); err != nil {
+		if errors.Contains(err, model.ErrNoSuchAPIResource) || errors.Contains(err, model.ErrAPIResourceValidateFailed) {
+			errors.PrintAsInfo(errors.Append(err, "API resource %s is not found", resourceID))
+			errors.WriteToHTTP(w, err, http.StatusNotFound, "")
+		} else {
+			errors.Print(errors.Append(err, "Failed to delete API resource"))
+			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	// Return 204 (No content) for success
+	w.WriteHeader(http.StatusNoContent)
+	logger.Info("APIResourceDeleteHandler method successfully finished")
+}
+
+// APIResourceGetHandler ...
+//   require role: read-project
+func APIResourceGetHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	resourceID := vars["resourceID"]
+
+	// Authorize API Request
+	if err := jwthttp.Authorize(r, projectName, role.ResProject, role.TypeRead); err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	resource, err := db.GetInst().APIResourceGet(projectName, resourceID)
+	if err != nil {
+		if errors.Contains(err, model.ErrNoSuchAPIResource) || errors.Contains(err, model.ErrAPIResourceValidateFailed) {
+			errors.PrintAsInfo(errors.Append(err, "API resource %s is not found", resourceID))
+			errors.WriteToHTTP(w, err, http.StatusNotFound, "")
+		} else {
+			errors.Print(errors.Append(err, "Failed to get API resource"))
+			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	jwthttp.ResponseWrite(w, "APIResourceGetHandler", convertToResponse(resource))
+}
+
+// APIResourceUpdateHandler ...
+//   require role: write-project
+func APIResourceUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	resourceID := vars["resourceID"]
+
+	var err *errors.Error
+	defer func() {
+		msg := ""
+		if err != nil {
+			msg = err.Error()
+		}
+		if err = audit.GetInst().Save(projectName, time.Now(), "API_RESOURCE", r.Method, r.URL.String(), msg); err != nil {
+			errors.Print(errors.Append(err, "Failed to save audit event"))
+		}
+	}()
+
+	// Authorize API Request
+	if err := jwthttp.Authorize(r, projectName, role.ResProject, role.TypeWrite); err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	// Parse Request
+	var request APIResourcePutRequest
+	if e := json.NewDecoder(r.Body).Decode(&request); e != nil {
+		err = errors.Append(errors.ErrInvalidRequest, "Failed to decode API resource update request: %v", e)
+		errors.PrintAsInfo(err)
+		errors.WriteToHTTP(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	// Get Previous API Resource Info
+	resource, err := db.GetInst().APIResourceGet(projectName, resourceID)
+	if err != nil {
+		if errors.Contains(err, model.ErrNoSuchAPIResource) || errors.Contains(err, model.ErrAPIResourceValidateFailed) {
+			errors.PrintAsInfo(errors.Append(err, "API resource %s is not found", resourceID))
+			errors.WriteToHTTP(w, err, http.StatusNotFound, "")
+		} else {
+			errors.Print(errors.Append(err, "Failed to get API resource"))
+			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	// Update Parameters
+	resource.Identifier = request.Identifier
+	resource.Name = request.Name
+	resource.Scopes = request.Scopes
+	resource.RequiredRoles = request.RequiredRoles
+	resource.AccessTokenLifeSpan = request.AccessTokenLifeSpan
+
+	// Update DB
+	if err = db.GetInst().APIResourceUpdate(projectName, resource); err != nil {
+		if errors.Contains(err, model.ErrAPIResourceValidateFailed) || errors.Contains(err, model.ErrAPIResourceAlreadyExists) {
+			errors.PrintAsInfo(errors.Append(err, "Failed to validate request"))
+			errors.WriteToHTTP(w, err, http.StatusBadRequest, "")
+		} else {
+			errors.Print(errors.Append(err, "Failed to update API resource"))
+			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	logger.Info("APIResourceUpdateHandler method successfully finished")
+}
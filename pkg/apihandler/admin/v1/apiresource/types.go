@@ -0,0 +1,31 @@
+package apiresourceapi
+
+// APIResourceCreateRequest ...
+type APIResourceCreateRequest struct {
+	Identifier          string              `json:"identifier"`
+	Name                string              `json:"name"`
+	Scopes              []string            `json:"scopes"`
+	RequiredRoles       map[string][]string `json:"required_roles"`
+	AccessTokenLifeSpan uint                `json:"access_token_life_span"`
+}
+
+// APIResourceGetResponse ...
+type APIResourceGetResponse struct {
+	ID                  string              `json:"id"`
+	ProjectName         string              `json:"project_name"`
+	CreatedAt           string              `json:"created_at"`
+	Identifier          string              `json:"identifier"`
+	Name                string              `json:"name"`
+	Scopes              []string            `json:"scopes"`
+	RequiredRoles       map[string][]string `json:"required_roles"`
+	AccessTokenLifeSpan uint                `json:"access_token_life_span"`
+}
+
+// APIResourcePutRequest ...
+type APIResourcePutRequest struct {
+	Identifier          string              `json:"identifier"`
+	Name                string              `json:"name"`
+	Scopes              []string            `json:"scopes"`
+	RequiredRoles       map[string][]string `json:"required_roles"`
+	AccessTokenLifeSpan uint                `json:"access_token_life_span"`
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/sh-miyoshi/hekate/pkg/audit"
 	"github.com/sh-miyoshi/hekate/pkg/db"
@@ -38,11 +39,28 @@ func AllClientGetHandler(w http.ResponseWriter, r *http.Request) {
 	res := []*ClientGetResponse{}
 	for _, client := range clients {
 		res = append(res, &ClientGetResponse{
-			ID:                  client.ID,
-			Secret:              client.Secret,
-			AccessType:          client.AccessType,
-			CreatedAt:           client.CreatedAt.Format(time.RFC3339),
-			AllowedCallbackURLs: client.AllowedCallbackURLs,
+			ID:                                    client.ID,
+			Secret:                                client.Secret,
+			AccessType:                            client.AccessType,
+			CreatedAt:                             client.CreatedAt.Format(time.RFC3339),
+			AllowedCallbackURLs:                   client.AllowedCallbackURLs,
+			ProtocolMappers:                       NewProtocolMappers(client.ProtocolMappers),
+			DefaultScopes:                         client.DefaultScopes,
+			OptionalScopes:                        client.OptionalScopes,
+			AccessTokenLifeSpan:                   client.AccessTokenLifeSpan,
+			RefreshTokenLifeSpan:                  client.RefreshTokenLifeSpan,
+			IDTokenLifeSpan:                       client.IDTokenLifeSpan,
+			AllowGrantTypes:                       grantTypeStrings(client.AllowGrantTypes),
+			AllowResponseTypes:                    client.AllowResponseTypes,
+			RequirePKCE:                           client.RequirePKCE,
+			JWKS:                                  client.JWKS,
+			IDTokenEncryptedResponseAlg:           client.IDTokenEncryptedResponseAlg,
+			IDTokenEncryptedResponseEnc:           client.IDTokenEncryptedResponseEnc,
+			UserinfoSignedResponseAlg:             client.UserinfoSignedResponseAlg,
+			UserinfoEncryptedResponseAlg:          client.UserinfoEncryptedResponseAlg,
+			UserinfoEncryptedResponseEnc:          client.UserinfoEncryptedResponseEnc,
+			BackchannelTokenDeliveryMode:          client.BackchannelTokenDeliveryMode,
+			BackchannelClientNotificationEndpoint: client.BackchannelClientNotificationEndpoint,
 		})
 	}
 
@@ -82,14 +100,37 @@ func ClientCreateHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	grantTypes, err := parseGrantTypes(request.AllowGrantTypes)
+	if err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to parse grant types"))
+		errors.WriteToHTTP(w, err, http.StatusBadRequest, "")
+		return
+	}
+
 	// Create Client Entry
 	client := model.ClientInfo{
-		ID:                  request.ID,
-		ProjectName:         projectName,
-		Secret:              request.Secret,
-		AccessType:          request.AccessType,
-		CreatedAt:           time.Now(),
-		AllowedCallbackURLs: request.AllowedCallbackURLs,
+		ID:                                    request.ID,
+		ProjectName:                           projectName,
+		Secret:                                request.Secret,
+		AccessType:                            request.AccessType,
+		CreatedAt:                             time.Now(),
+		AllowedCallbackURLs:                   request.AllowedCallbackURLs,
+		DefaultScopes:                         request.DefaultScopes,
+		OptionalScopes:                        request.OptionalScopes,
+		AccessTokenLifeSpan:                   request.AccessTokenLifeSpan,
+		RefreshTokenLifeSpan:                  request.RefreshTokenLifeSpan,
+		IDTokenLifeSpan:                       request.IDTokenLifeSpan,
+		AllowGrantTypes:                       grantTypes,
+		AllowResponseTypes:                    request.AllowResponseTypes,
+		RequirePKCE:                           request.RequirePKCE,
+		JWKS:                                  request.JWKS,
+		IDTokenEncryptedResponseAlg:           request.IDTokenEncryptedResponseAlg,
+		IDTokenEncryptedResponseEnc:           request.IDTokenEncryptedResponseEnc,
+		UserinfoSignedResponseAlg:             request.UserinfoSignedResponseAlg,
+		UserinfoEncryptedResponseAlg:          request.UserinfoEncryptedResponseAlg,
+		UserinfoEncryptedResponseEnc:          request.UserinfoEncryptedResponseEnc,
+		BackchannelTokenDeliveryMode:          request.BackchannelTokenDeliveryMode,
+		BackchannelClientNotificationEndpoint: request.BackchannelClientNotificationEndpoint,
 	}
 
 	if err = db.GetInst().ClientAdd(projectName, &client); err != nil {
@@ -108,11 +149,28 @@ func ClientCreateHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Return Response
 	res := ClientGetResponse{
-		ID:                  client.ID,
-		Secret:              client.Secret,
-		AccessType:          client.AccessType,
-		CreatedAt:           client.CreatedAt.Format(time.RFC3339),
-		AllowedCallbackURLs: client.AllowedCallbackURLs,
+		ID:                                    client.ID,
+		Secret:                                client.Secret,
+		AccessType:                            client.AccessType,
+		CreatedAt:                             client.CreatedAt.Format(time.RFC3339),
+		AllowedCallbackURLs:                   client.AllowedCallbackURLs,
+		ProtocolMappers:                       NewProtocolMappers(client.ProtocolMappers),
+		DefaultScopes:                         client.DefaultScopes,
+		OptionalScopes:                        client.OptionalScopes,
+		AccessTokenLifeSpan:                   client.AccessTokenLifeSpan,
+		RefreshTokenLifeSpan:                  client.RefreshTokenLifeSpan,
+		IDTokenLifeSpan:                       client.IDTokenLifeSpan,
+		AllowGrantTypes:                       grantTypeStrings(client.AllowGrantTypes),
+		AllowResponseTypes:                    client.AllowResponseTypes,
+		RequirePKCE:                           client.RequirePKCE,
+		JWKS:                                  client.JWKS,
+		IDTokenEncryptedResponseAlg:           client.IDTokenEncryptedResponseAlg,
+		IDTokenEncryptedResponseEnc:           client.IDTokenEncryptedResponseEnc,
+		UserinfoSignedResponseAlg:             client.UserinfoSignedResponseAlg,
+		UserinfoEncryptedResponseAlg:          client.UserinfoEncryptedResponseAlg,
+		UserinfoEncryptedResponseEnc:          client.UserinfoEncryptedResponseEnc,
+		BackchannelTokenDeliveryMode:          client.BackchannelTokenDeliveryMode,
+		BackchannelClientNotificationEndpoint: client.BackchannelClientNotificationEndpoint,
 	}
 
 	jwthttp.ResponseWrite(w, "ClientCreateHandler", &res)
@@ -186,11 +244,28 @@ func ClientGetHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	res := ClientGetResponse{
-		ID:                  client.ID,
-		Secret:              client.Secret,
-		AccessType:          client.AccessType,
-		CreatedAt:           client.CreatedAt.Format(time.RFC3339),
-		AllowedCallbackURLs: client.AllowedCallbackURLs,
+		ID:                                    client.ID,
+		Secret:                                client.Secret,
+		AccessType:                            client.AccessType,
+		CreatedAt:                             client.CreatedAt.Format(time.RFC3339),
+		AllowedCallbackURLs:                   client.AllowedCallbackURLs,
+		ProtocolMappers:                       NewProtocolMappers(client.ProtocolMappers),
+		DefaultScopes:                         client.DefaultScopes,
+		OptionalScopes:                        client.OptionalScopes,
+		AccessTokenLifeSpan:                   client.AccessTokenLifeSpan,
+		RefreshTokenLifeSpan:                  client.RefreshTokenLifeSpan,
+		IDTokenLifeSpan:                       client.IDTokenLifeSpan,
+		AllowGrantTypes:                       grantTypeStrings(client.AllowGrantTypes),
+		AllowResponseTypes:                    client.AllowResponseTypes,
+		RequirePKCE:                           client.RequirePKCE,
+		JWKS:                                  client.JWKS,
+		IDTokenEncryptedResponseAlg:           client.IDTokenEncryptedResponseAlg,
+		IDTokenEncryptedResponseEnc:           client.IDTokenEncryptedResponseEnc,
+		UserinfoSignedResponseAlg:             client.UserinfoSignedResponseAlg,
+		UserinfoEncryptedResponseAlg:          client.UserinfoEncryptedResponseAlg,
+		UserinfoEncryptedResponseEnc:          client.UserinfoEncryptedResponseEnc,
+		BackchannelTokenDeliveryMode:          client.BackchannelTokenDeliveryMode,
+		BackchannelClientNotificationEndpoint: client.BackchannelClientNotificationEndpoint,
 	}
 
 	jwthttp.ResponseWrite(w, "ClientGetHandler", &res)
@@ -244,10 +319,33 @@ func ClientUpdateHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	grantTypes, err := parseGrantTypes(request.AllowGrantTypes)
+	if err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to parse grant types"))
+		errors.WriteToHTTP(w, err, http.StatusBadRequest, "")
+		return
+	}
+
 	// Update Parameters
 	client.Secret = request.Secret
 	client.AccessType = request.AccessType
 	client.AllowedCallbackURLs = request.AllowedCallbackURLs
+	client.DefaultScopes = request.DefaultScopes
+	client.OptionalScopes = request.OptionalScopes
+	client.AccessTokenLifeSpan = request.AccessTokenLifeSpan
+	client.RefreshTokenLifeSpan = request.RefreshTokenLifeSpan
+	client.IDTokenLifeSpan = request.IDTokenLifeSpan
+	client.AllowGrantTypes = grantTypes
+	client.AllowResponseTypes = request.AllowResponseTypes
+	client.RequirePKCE = request.RequirePKCE
+	client.JWKS = request.JWKS
+	client.IDTokenEncryptedResponseAlg = request.IDTokenEncryptedResponseAlg
+	client.IDTokenEncryptedResponseEnc = request.IDTokenEncryptedResponseEnc
+	client.UserinfoSignedResponseAlg = request.UserinfoSignedResponseAlg
+	client.UserinfoEncryptedResponseAlg = request.UserinfoEncryptedResponseAlg
+	client.UserinfoEncryptedResponseEnc = request.UserinfoEncryptedResponseEnc
+	client.BackchannelTokenDeliveryMode = request.BackchannelTokenDeliveryMode
+	client.BackchannelClientNotificationEndpoint = request.BackchannelClientNotificationEndpoint
 
 	// Update DB
 	if err = db.GetInst().ClientUpdate(projectName, client); err != nil {
@@ -264,3 +362,344 @@ func ClientUpdateHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 	logger.Info("ClientUpdateHandler method successfully finished")
 }
+
+// ClientMapperGetListHandler ...
+//   require role: read-project
+func ClientMapperGetListHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	clientID := vars["clientID"]
+
+	// Authorize API Request
+	if err := jwthttp.Authorize(r, projectName, role.ResProject, role.TypeRead); err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	client, err := db.GetInst().ClientGet(projectName, clientID)
+	if err != nil {
+		if errors.Contains(err, model.ErrNoSuchClient) || errors.Contains(err, model.ErrClientValidateFailed) {
+			errors.PrintAsInfo(errors.Append(err, "No such client: %s", clientID))
+			errors.WriteToHTTP(w, err, http.StatusNotFound, "")
+		} else {
+			errors.Print(errors.Append(err, "Failed to get client"))
+			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	res := NewProtocolMappers(client.ProtocolMappers)
+	jwthttp.ResponseWrite(w, "ClientMapperGetListHandler", res)
+}
+
+// ClientMapperCreateHandler ...
+//   require role: write-project
+func ClientMapperCreateHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	clientID := vars["clientID"]
+
+	var err *errors.Error
+	defer func() {
+		msg := ""
+		if err != nil {
+			msg = err.Error()
+		}
+		if err = audit.GetInst().Save(projectName, time.Now(), "CLIENT", r.Method, r.URL.String(), msg); err != nil {
+			errors.Print(errors.Append(err, "Failed to save audit event"))
+		}
+	}()
+
+	// Authorize API Request
+	if err = jwthttp.Authorize(r, projectName, role.ResProject, role.TypeWrite); err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	// Parse Request
+	var request ProtocolMapper
+	if e := json.NewDecoder(r.Body).Decode(&request); e != nil {
+		err = errors.Append(errors.ErrInvalidRequest, "Failed to decode protocol mapper create request: %v", e)
+		errors.PrintAsInfo(err)
+		errors.WriteToHTTP(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	var client *model.ClientInfo
+	client, err = db.GetInst().ClientGet(projectName, clientID)
+	if err != nil {
+		if errors.Contains(err, model.ErrNoSuchClient) || errors.Contains(err, model.ErrClientValidateFailed) {
+			errors.PrintAsInfo(errors.Append(err, "No such client: %s", clientID))
+			errors.WriteToHTTP(w, err, http.StatusNotFound, "")
+		} else {
+			errors.Print(errors.Append(err, "Failed to get client"))
+			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	mapper := request.ToModel()
+	mapper.ID = uuid.New().String()
+	client.ProtocolMappers = append(client.ProtocolMappers, mapper)
+
+	if err = db.GetInst().ClientUpdate(projectName, client); err != nil {
+		if errors.Contains(err, model.ErrProtocolMapperAlreadyExists) {
+			errors.PrintAsInfo(errors.Append(err, "Protocol mapper %s is already exists", mapper.Name))
+			errors.WriteToHTTP(w, err, http.StatusConflict, "")
+		} else if errors.Contains(err, model.ErrProtocolMapperValidateFailed) {
+			errors.PrintAsInfo(errors.Append(err, "Bad Request"))
+			errors.WriteToHTTP(w, err, http.StatusBadRequest, "")
+		} else {
+			errors.Print(errors.Append(err, "Failed to add protocol mapper"))
+			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	res := NewProtocolMapper(&mapper)
+	jwthttp.ResponseWrite(w, "ClientMapperCreateHandler", &res)
+}
+
+// ClientMapperGetHandler ...
+//   require role: read-project
+func ClientMapperGetHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	clientID := vars["clientID"]
+	mapperID := vars["mapperID"]
+
+	// Authorize API Request
+	if err := jwthttp.Authorize(r, projectName, role.ResProject, role.TypeRead); err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	client, err := db.GetInst().ClientGet(projectName, clientID)
+	if err != nil {
+		if errors.Contains(err, model.ErrNoSuchClient) || errors.Contains(err, model.ErrClientValidateFailed) {
+			errors.PrintAsInfo(errors.Append(err, "No such client: %s", clientID))
+			errors.WriteToHTTP(w, err, http.StatusNotFound, "")
+		} else {
+			errors.Print(errors.Append(err, "Failed to get client"))
+			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	for _, m := range client.ProtocolMappers {
+		if m.ID == mapperID {
+			res := NewProtocolMapper(&m)
+			jwthttp.ResponseWrite(w, "ClientMapperGetHandler", &res)
+			return
+		}
+	}
+
+	logger.Info("No such protocol mapper: %s", mapperID)
+	errors.WriteToHTTP(w, model.ErrNoSuchProtocolMapper, http.StatusNotFound, "")
+}
+
+// ClientMapperUpdateHandler ...
+//   require role: write-project
+func ClientMapperUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	clientID := vars["clientID"]
+	mapperID := vars["mapperID"]
+
+	var err *errors.Error
+	defer func() {
+		msg := ""
+		if err != nil {
+			msg = err.Error()
+		}
+		if err = audit.GetInst().Save(projectName, time.Now(), "CLIENT", r.Method, r.URL.String(), msg); err != nil {
+			errors.Print(errors.Append(err, "Failed to save audit event"))
+		}
+	}()
+
+	// Authorize API Request
+	if err = jwthttp.Authorize(r, projectName, role.ResProject, role.TypeWrite); err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	// Parse Request
+	var request ProtocolMapper
+	if e := json.NewDecoder(r.Body).Decode(&request); e != nil {
+		err = errors.Append(errors.ErrInvalidRequest, "Failed to decode protocol mapper update request: %v", e)
+		errors.PrintAsInfo(err)
+		errors.WriteToHTTP(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	var client *model.ClientInfo
+	client, err = db.GetInst().ClientGet(projectName, clientID)
+	if err != nil {
+		if errors.Contains(err, model.ErrNoSuchClient) || errors.Contains(err, model.ErrClientValidateFailed) {
+			errors.PrintAsInfo(errors.Append(err, "No such client: %s", clientID))
+			errors.WriteToHTTP(w, err, http.StatusNotFound, "")
+		} else {
+			errors.Print(errors.Append(err, "Failed to get client"))
+			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	found := false
+	for i, m := range client.ProtocolMappers {
+		if m.ID == mapperID {
+			client.ProtocolMappers[i] = request.ToModel()
+			client.ProtocolMappers[i].ID = mapperID
+			found = true
+			break
+		}
+	}
+	if !found {
+		err = model.ErrNoSuchProtocolMapper
+		logger.Info("No such protocol mapper: %s", mapperID)
+		errors.WriteToHTTP(w, err, http.StatusNotFound, "")
+		return
+	}
+
+	if err = db.GetInst().ClientUpdate(projectName, client); err != nil {
+		if errors.Contains(err, model.ErrProtocolMapperAlreadyExists) || errors.Contains(err, model.ErrProtocolMapperValidateFailed) {
+			errors.PrintAsInfo(errors.Append(err, "Bad Request"))
+			errors.WriteToHTTP(w, err, http.StatusBadRequest, "")
+		} else {
+			errors.Print(errors.Append(err, "Failed to update protocol mapper"))
+			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	logger.Info("ClientMapperUpdateHandler method successfully finished")
+}
+
+// ClientMapperDeleteHandler ...
+//   require role: write-project
+func ClientMapperDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	clientID := vars["clientID"]
+	mapperID := vars["mapperID"]
+
+	var err *errors.Error
+	defer func() {
+		msg := ""
+		if err != nil {
+			msg = err.Error()
+		}
+		if err = audit.GetInst().Save(projectName, time.Now(), "CLIENT", r.Method, r.URL.String(), msg); err != nil {
+			errors.Print(errors.Append(err, "Failed to save audit event"))
+		}
+	}()
+
+	// Authorize API Request
+	if err = jwthttp.Authorize(r, projectName, role.ResProject, role.TypeWrite); err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	var client *model.ClientInfo
+	client, err = db.GetInst().ClientGet(projectName, clientID)
+	if err != nil {
+		if errors.Contains(err, model.ErrNoSuchClient) || errors.Contains(err, model.ErrClientValidateFailed) {
+			errors.PrintAsInfo(errors.Append(err, "No such client: %s", clientID))
+			errors.WriteToHTTP(w, err, http.StatusNotFound, "")
+		} else {
+			errors.Print(errors.Append(err, "Failed to get client"))
+			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	mappers := []model.ProtocolMapper{}
+	for _, m := range client.ProtocolMappers {
+		if m.ID != mapperID {
+			mappers = append(mappers, m)
+		}
+	}
+	if len(mappers) == len(client.ProtocolMappers) {
+		err = model.ErrNoSuchProtocolMapper
+		logger.Info("No such protocol mapper: %s", mapperID)
+		errors.WriteToHTTP(w, err, http.StatusNotFound, "")
+		return
+	}
+	client.ProtocolMappers = mappers
+
+	if err = db.GetInst().ClientUpdate(projectName, client); err != nil {
+		errors.Print(errors.Append(err, "Failed to delete protocol mapper"))
+		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	logger.Info("ClientMapperDeleteHandler method successfully finished")
+}
+
+// NewProtocolMapper converts the protocol mapper to the api response
+func NewProtocolMapper(m *model.ProtocolMapper) ProtocolMapper {
+	return ProtocolMapper{
+		ID:            m.ID,
+		Name:          m.Name,
+		Type:          m.Type,
+		ClaimName:     m.ClaimName,
+		UserAttribute: m.UserAttribute,
+		Value:         m.Value,
+		Multivalued:   m.Multivalued,
+		IDToken:       m.IDToken,
+		AccessToken:   m.AccessToken,
+		UserInfo:      m.UserInfo,
+	}
+}
+
+// NewProtocolMappers converts the protocol mapper list to the api response
+func NewProtocolMappers(mappers []model.ProtocolMapper) []ProtocolMapper {
+	res := []ProtocolMapper{}
+	for _, m := range mappers {
+		res = append(res, NewProtocolMapper(&m))
+	}
+	return res
+}
+
+// ToModel converts the api request to the protocol mapper
+func (m *ProtocolMapper) ToModel() model.ProtocolMapper {
+	return model.ProtocolMapper{
+		ID:            m.ID,
+		Name:          m.Name,
+		Type:          m.Type,
+		ClaimName:     m.ClaimName,
+		UserAttribute: m.UserAttribute,
+		Value:         m.Value,
+		Multivalued:   m.Multivalued,
+		IDToken:       m.IDToken,
+		AccessToken:   m.AccessToken,
+		UserInfo:      m.UserInfo,
+	}
+}
+
+func parseGrantTypes(types []string) ([]model.GrantType, *errors.Error) {
+	res := []model.GrantType{}
+	for _, t := range types {
+		v, err := model.GetGrantType(t)
+		if err != nil {
+			return nil, errors.Append(err, "Failed to get grant type %s", t)
+		}
+		res = append(res, v)
+	}
+	return res, nil
+}
+
+func grantTypeStrings(types []model.GrantType) []string {
+	res := []string{}
+	for _, t := range types {
+		res = append(res, string(t))
+	}
+	return res
+}
@@ -2,24 +2,87 @@ package clientapi
 
 // ClientCreateRequest ...
 type ClientCreateRequest struct {
-	ID                  string   `json:"id"`
-	Secret              string   `json:"secret"`
-	AccessType          string   `json:"access_type"`
-	AllowedCallbackURLs []string `json:"allowed_callback_urls"`
+	ID                                    string   `json:"id"`
+	Secret                                string   `json:"secret"`
+	AccessType                            string   `json:"access_type"`
+	AllowedCallbackURLs                   []string `json:"allowed_callback_urls"`
+	DefaultScopes                         []string `json:"default_scopes"`
+	OptionalScopes                        []string `json:"optional_scopes"`
+	AccessTokenLifeSpan                   uint     `json:"access_token_life_span"`
+	RefreshTokenLifeSpan                  uint     `json:"refresh_token_life_span"`
+	IDTokenLifeSpan                       uint     `json:"id_token_life_span"`
+	AllowGrantTypes                       []string `json:"allow_grant_types"`
+	AllowResponseTypes                    []string `json:"allow_response_types"`
+	RequirePKCE                           bool     `json:"require_pkce"`
+	JWKS                                  string   `json:"jwks"`
+	IDTokenEncryptedResponseAlg           string   `json:"id_token_encrypted_response_alg"`
+	IDTokenEncryptedResponseEnc           string   `json:"id_token_encrypted_response_enc"`
+	UserinfoSignedResponseAlg             string   `json:"userinfo_signed_response_alg"`
+	UserinfoEncryptedResponseAlg          string   `json:"userinfo_encrypted_response_alg"`
+	UserinfoEncryptedResponseEnc          string   `json:"userinfo_encrypted_response_enc"`
+	BackchannelTokenDeliveryMode          string   `json:"backchannel_token_delivery_mode"`
+	BackchannelClientNotificationEndpoint string   `json:"backchannel_client_notification_endpoint"`
 }
 
 // ClientGetResponse ...
 type ClientGetResponse struct {
-	ID                  string   `json:"id"`
-	Secret              string   `json:"secret"`
-	AccessType          string   `json:"access_type"`
-	CreatedAt           string   `json:"created_at"`
-	AllowedCallbackURLs []string `json:"allowed_callback_urls"`
+	ID                                    string           `json:"id"`
+	Secret                                string           `json:"secret"`
+	AccessType                            string           `json:"access_type"`
+	CreatedAt                             string           `json:"created_at"`
+	AllowedCallbackURLs                   []string         `json:"allowed_callback_urls"`
+	ProtocolMappers                       []ProtocolMapper `json:"protocol_mappers"`
+	DefaultScopes                         []string         `json:"default_scopes"`
+	OptionalScopes                        []string         `json:"optional_scopes"`
+	AccessTokenLifeSpan                   uint             `json:"access_token_life_span"`
+	RefreshTokenLifeSpan                  uint             `json:"refresh_token_life_span"`
+	IDTokenLifeSpan                       uint             `json:"id_token_life_span"`
+	AllowGrantTypes                       []string         `json:"allow_grant_types"`
+	AllowResponseTypes                    []string         `json:"allow_response_types"`
+	RequirePKCE                           bool             `json:"require_pkce"`
+	JWKS                                  string           `json:"jwks"`
+	IDTokenEncryptedResponseAlg           string           `json:"id_token_encrypted_response_alg"`
+	IDTokenEncryptedResponseEnc           string           `json:"id_token_encrypted_response_enc"`
+	UserinfoSignedResponseAlg             string           `json:"userinfo_signed_response_alg"`
+	UserinfoEncryptedResponseAlg          string           `json:"userinfo_encrypted_response_alg"`
+	UserinfoEncryptedResponseEnc          string           `json:"userinfo_encrypted_response_enc"`
+	BackchannelTokenDeliveryMode          string           `json:"backchannel_token_delivery_mode"`
+	BackchannelClientNotificationEndpoint string           `json:"backchannel_client_notification_endpoint"`
 }
 
 // ClientPutRequest ...
 type ClientPutRequest struct {
-	Secret              string   `json:"secret"`
-	AccessType          string   `json:"access_type"`
-	AllowedCallbackURLs []string `json:"allowed_callback_urls"`
+	Secret                                string   `json:"secret"`
+	AccessType                            string   `json:"access_type"`
+	AllowedCallbackURLs                   []string `json:"allowed_callback_urls"`
+	DefaultScopes                         []string `json:"default_scopes"`
+	OptionalScopes                        []string `json:"optional_scopes"`
+	AccessTokenLifeSpan                   uint     `json:"access_token_life_span"`
+	RefreshTokenLifeSpan                  uint     `json:"refresh_token_life_span"`
+	IDTokenLifeSpan                       uint     `json:"id_token_life_span"`
+	AllowGrantTypes                       []string `json:"allow_grant_types"`
+	AllowResponseTypes                    []string `json:"allow_response_types"`
+	RequirePKCE                           bool     `json:"require_pkce"`
+	JWKS                                  string   `json:"jwks"`
+	IDTokenEncryptedResponseAlg           string   `json:"id_token_encrypted_response_alg"`
+	IDTokenEncryptedResponseEnc           string   `json:"id_token_encrypted_response_enc"`
+	UserinfoSignedResponseAlg             string   `json:"userinfo_signed_response_alg"`
+	UserinfoEncryptedResponseAlg          string   `json:"userinfo_encrypted_response_alg"`
+	UserinfoEncryptedResponseEnc          string   `json:"userinfo_encrypted_response_enc"`
+	BackchannelTokenDeliveryMode          string   `json:"backchannel_token_delivery_mode"`
+	BackchannelClientNotificationEndpoint string   `json:"backchannel_client_notification_endpoint"`
+}
+
+// ProtocolMapper ...
+type ProtocolMapper struct {
+	ID            string `json:"id"` // Ignored in create and update request
+	Name          string `json:"name"`
+	Type          string `json:"type"`
+	ClaimName     string `json:"claim_name"`
+	UserAttribute string `json:"user_attribute"`
+	Value         string `json:"value"`
+	Multivalued   bool   `json:"multivalued"`
+	IDToken       bool   `json:"id_token"`
+	AccessToken   bool   `json:"access_token"`
+	UserInfo      bool   `json:"userinfo"`
 }
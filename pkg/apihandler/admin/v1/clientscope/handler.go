@@ -0,0 +1,280 @@
+package clientscopeapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	clientapi "github.com/sh-miyoshi/hekate/pkg/apihandler/admin/v1/client"
+	"github.com/sh-miyoshi/hekate/pkg/audit"
+	"github.com/sh-miyoshi/hekate/pkg/db"
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	jwthttp "github.com/sh-miyoshi/hekate/pkg/http"
+	"github.com/sh-miyoshi/hekate/pkg/logger"
+	"github.com/sh-miyoshi/hekate/pkg/role"
+)
+
+// AllClientScopeGetHandler ...
+//   require role: read-project
+func AllClientScopeGetHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+
+	// Authorize API Request
+	if err := jwthttp.Authorize(r, projectName, role.ResProject, role.TypeRead); err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	queries := r.URL.Query()
+	logger.Debug("Query: %v", queries)
+
+	filter := &model.ClientScopeFilter{
+		Name: queries.Get("name"),
+	}
+
+	scopes, err := db.GetInst().ClientScopeGetList(projectName, filter)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get client scope list"))
+		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	res := []*ClientScopeGetResponse{}
+	for _, s := range scopes {
+		res = append(res, newClientScopeGetResponse(s))
+	}
+
+	jwthttp.ResponseWrite(w, "AllClientScopeGetHandler", res)
+}
+
+// ClientScopeCreateHandler ...
+//   require role: write-project
+func ClientScopeCreateHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+
+	var err *errors.Error
+	defer func() {
+		msg := ""
+		if err != nil {
+			msg = err.Error()
+		}
+		if err = audit.GetInst().Save(projectName, time.Now(), "CLIENT_SCOPE", r.Method, r.URL.String(), msg); err != nil {
+			errors.Print(errors.Append(err, "Failed to save audit event"))
+		}
+	}()
+
+	// Authorize API Request
+	if err = jwthttp.Authorize(r, projectName, role.ResProject, role.TypeWrite); err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	// Parse Request
+	var request ClientScopeCreateRequest
+	if e := json.NewDecoder(r.Body).Decode(&request); e != nil {
+		err = errors.Append(errors.ErrInvalidRequest, "Failed to decode client scope create request: %v", e)
+		errors.PrintAsInfo(err)
+		errors.WriteToHTTP(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	// Create Client Scope Entry
+	scope := model.ClientScope{
+		Name:            request.Name,
+		ProjectName:     projectName,
+		CreatedAt:       time.Now(),
+		Description:     request.Description,
+		Claims:          request.Claims,
+		ProtocolMappers: toModelMappers(request.ProtocolMappers),
+		RequiredRoles:   request.RequiredRoles,
+	}
+
+	if err = db.GetInst().ClientScopeAdd(projectName, &scope); err != nil {
+		if errors.Contains(err, model.ErrClientScopeAlreadyExists) {
+			errors.PrintAsInfo(errors.Append(err, "Client scope %s is already exists", scope.Name))
+			errors.WriteToHTTP(w, err, http.StatusConflict, "")
+		} else if errors.Contains(err, model.ErrClientScopeValidateFailed) || errors.Contains(err, model.ErrProtocolMapperValidateFailed) {
+			errors.PrintAsInfo(errors.Append(err, "Bad Request"))
+			errors.WriteToHTTP(w, err, http.StatusBadRequest, "")
+		} else {
+			errors.Print(errors.Append(err, "Failed to create client scope"))
+			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	res := newClientScopeGetResponse(&scope)
+	jwthttp.ResponseWrite(w, "ClientScopeCreateHandler", res)
+}
+
+// ClientScopeDeleteHandler ...
+//   require role: write-project
+func ClientScopeDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	scopeName := vars["scopeName"]
+
+	var err *errors.Error
+	defer func() {
+		msg := ""
+		if err != nil {
+			msg = err.Error()
+		}
+		if err = audit.GetInst().Save(projectName, time.Now(), "CLIENT_SCOPE", r.Method, r.URL.String(), msg); err != nil {
+			errors.Print(errors.Append(err, "Failed to save audit event"))
+		}
+	}()
+
+	// Authorize API Request
+	if err = jwthttp.Authorize(r, projectName, role.ResProject, role.TypeWrite); err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	if err = db.GetInst().ClientScopeDelete(projectName, scopeName); err != nil {
+		if errors.Contains(err, model.ErrNoSuchClientScope) || errors.Contains(err, model.ErrClientScopeValidateFailed) {
+			errors.PrintAsInfo(errors.Append(err, "Client scope %s is not found", scopeName))
+			errors.WriteToHTTP(w, err, http.StatusNotFound, "")
+		} else {
+			errors.Print(errors.Append(err, "Failed to delete client scope"))
+			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	// Return 204 (No content) for success
+	w.WriteHeader(http.StatusNoContent)
+	logger.Info("ClientScopeDeleteHandler method successfully finished")
+}
+
+// ClientScopeGetHandler ...
+//   require role: read-project
+func ClientScopeGetHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	scopeName := vars["scopeName"]
+
+	// Authorize API Request
+	if err := jwthttp.Authorize(r, projectName, role.ResProject, role.TypeRead); err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	scope, err := db.GetInst().ClientScopeGet(projectName, scopeName)
+	if err != nil {
+		if errors.Contains(err, model.ErrNoSuchClientScope) || errors.Contains(err, model.ErrClientScopeValidateFailed) {
+			errors.PrintAsInfo(errors.Append(err, "Client scope %s is not found", scopeName))
+			errors.WriteToHTTP(w, err, http.StatusNotFound, "")
+		} else {
+			errors.Print(errors.Append(err, "Failed to get client scope"))
+			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	res := newClientScopeGetResponse(scope)
+	jwthttp.ResponseWrite(w, "ClientScopeGetHandler", res)
+}
+
+// ClientScopeUpdateHandler ...
+//   require role: write-project
+func ClientScopeUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	scopeName := vars["scopeName"]
+
+	var err *errors.Error
+	defer func() {
+		msg := ""
+		if err != nil {
+			msg = err.Error()
+		}
+		if err = audit.GetInst().Save(projectName, time.Now(), "CLIENT_SCOPE", r.Method, r.URL.String(), msg); err != nil {
+			errors.Print(errors.Append(err, "Failed to save audit event"))
+		}
+	}()
+
+	// Authorize API Request
+	if err = jwthttp.Authorize(r, projectName, role.ResProject, role.TypeWrite); err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	// Parse Request
+	var request ClientScopePutRequest
+	if e := json.NewDecoder(r.Body).Decode(&request); e != nil {
+		err = errors.Append(errors.ErrInvalidRequest, "Failed to decode client scope update request: %v", e)
+		errors.PrintAsInfo(err)
+		errors.WriteToHTTP(w, err, http.StatusBadRequest, "")
+		return
+	}
+
+	// Get Previous Client Scope Info
+	var scope *model.ClientScope
+	scope, err = db.GetInst().ClientScopeGet(projectName, scopeName)
+	if err != nil {
+		if errors.Contains(err, model.ErrNoSuchClientScope) || errors.Contains(err, model.ErrClientScopeValidateFailed) {
+			errors.PrintAsInfo(errors.Append(err, "Client scope %s is not found", scopeName))
+			errors.WriteToHTTP(w, err, http.StatusNotFound, "")
+		} else {
+			errors.Print(errors.Append(err, "Failed to update client scope"))
+			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	// Update Parameters
+	scope.Description = request.Description
+	scope.Claims = request.Claims
+	scope.ProtocolMappers = toModelMappers(request.ProtocolMappers)
+	scope.RequiredRoles = request.RequiredRoles
+
+	// Update DB
+	if err = db.GetInst().ClientScopeUpdate(projectName, scope); err != nil {
+		if errors.Contains(err, model.ErrClientScopeValidateFailed) || errors.Contains(err, model.ErrProtocolMapperValidateFailed) {
+			errors.PrintAsInfo(errors.Append(err, "Bad Request"))
+			errors.WriteToHTTP(w, err, http.StatusBadRequest, "")
+		} else {
+			errors.Print(errors.Append(err, "Failed to update client scope"))
+			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	logger.Info("ClientScopeUpdateHandler method successfully finished")
+}
+
+func newClientScopeGetResponse(s *model.ClientScope) *ClientScopeGetResponse {
+	return &ClientScopeGetResponse{
+		Name:            s.Name,
+		ProjectName:     s.ProjectName,
+		CreatedAt:       s.CreatedAt.Format(time.RFC3339),
+		Description:     s.Description,
+		Claims:          s.Claims,
+		ProtocolMappers: clientapi.NewProtocolMappers(s.ProtocolMappers),
+		RequiredRoles:   s.RequiredRoles,
+	}
+}
+
+func toModelMappers(mappers []clientapi.ProtocolMapper) []model.ProtocolMapper {
+	res := []model.ProtocolMapper{}
+	for _, m := range mappers {
+		mapper := m.ToModel()
+		if mapper.ID == "" {
+			mapper.ID = uuid.New().String()
+		}
+		res = append(res, mapper)
+	}
+	return res
+}
@@ -0,0 +1,33 @@
+package clientscopeapi
+
+import (
+	clientapi "github.com/sh-miyoshi/hekate/pkg/apihandler/admin/v1/client"
+)
+
+// ClientScopeCreateRequest ...
+type ClientScopeCreateRequest struct {
+	Name            string                     `json:"name"`
+	Description     string                     `json:"description"`
+	Claims          []string                   `json:"claims"`
+	ProtocolMappers []clientapi.ProtocolMapper `json:"protocol_mappers"`
+	RequiredRoles   []string                   `json:"required_roles"`
+}
+
+// ClientScopeGetResponse ...
+type ClientScopeGetResponse struct {
+	Name            string                     `json:"name"`
+	ProjectName     string                     `json:"project_name"`
+	CreatedAt       string                     `json:"created_at"`
+	Description     string                     `json:"description"`
+	Claims          []string                   `json:"claims"`
+	ProtocolMappers []clientapi.ProtocolMapper `json:"protocol_mappers"`
+	RequiredRoles   []string                   `json:"required_roles"`
+}
+
+// ClientScopePutRequest ...
+type ClientScopePutRequest struct {
+	Description     string                     `json:"description"`
+	Claims          []string                   `json:"claims"`
+	ProtocolMappers []clientapi.ProtocolMapper `json:"protocol_mappers"`
+	RequiredRoles   []string                   `json:"required_roles"`
+}
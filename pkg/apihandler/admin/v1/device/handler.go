@@ -0,0 +1,118 @@
+package deviceapi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sh-miyoshi/hekate/pkg/audit"
+	"github.com/sh-miyoshi/hekate/pkg/db"
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	jwthttp "github.com/sh-miyoshi/hekate/pkg/http"
+	"github.com/sh-miyoshi/hekate/pkg/logger"
+	"github.com/sh-miyoshi/hekate/pkg/oidc"
+	"github.com/sh-miyoshi/hekate/pkg/role"
+)
+
+// AllDeviceGetHandler returns the pending devices of device authorization grant
+//   require role: read-project
+func AllDeviceGetHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+
+	// Authorize API Request
+	if err := jwthttp.Authorize(r, projectName, role.ResProject, role.TypeRead); err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	devices, err := db.GetInst().DeviceGetList(projectName, nil)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get device list"))
+		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	now := time.Now()
+	res := []DeviceGetResponse{}
+	for _, d := range devices {
+		if d.Expired(now) {
+			continue
+		}
+
+		authenticated := false
+		if s, err := db.GetInst().LoginSessionGet(projectName, d.LoginSessionID); err == nil {
+			authenticated = !s.LoginDate.IsZero()
+		}
+
+		res = append(res, DeviceGetResponse{
+			UserCode:      oidc.FormatUserCode(d.UserCode),
+			ClientID:      d.ClientID,
+			CreatedAt:     d.CreatedAt.Format(time.RFC3339),
+			ExpiresIn:     d.ExpiresIn,
+			Authenticated: authenticated,
+		})
+	}
+
+	jwthttp.ResponseWrite(w, "AllDeviceGetHandler", &res)
+}
+
+// DeviceDeleteHandler cancels the pending device authorization
+// The device gets invalid_grant error in the next token request.
+//   require role: write-project
+func DeviceDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	userCode := oidc.NormalizeUserCode(vars["userCode"])
+
+	var err *errors.Error
+	defer func() {
+		msg := ""
+		if err != nil {
+			msg = err.Error()
+		}
+		if err = audit.GetInst().Save(projectName, time.Now(), "DEVICE", r.Method, r.URL.String(), msg); err != nil {
+			errors.Print(errors.Append(err, "Failed to save audit event"))
+		}
+	}()
+
+	// Authorize API Request
+	if err = jwthttp.Authorize(r, projectName, role.ResProject, role.TypeWrite); err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	devices := []*model.Device{}
+	if oidc.ValidUserCodeFormat(userCode) {
+		devices, err = db.GetInst().DeviceGetList(projectName, &model.DeviceFilter{UserCode: userCode})
+		if err != nil {
+			errors.Print(errors.Append(err, "Failed to get device"))
+			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+			return
+		}
+	}
+	if len(devices) == 0 {
+		err = errors.Append(model.ErrNoSuchDevice, "No device for user code %s", userCode)
+		errors.PrintAsInfo(err)
+		errors.WriteToHTTP(w, model.ErrNoSuchDevice, http.StatusNotFound, "")
+		return
+	}
+
+	if err = db.GetInst().DeviceDelete(projectName, devices[0].DeviceCode); err != nil {
+		if errors.Contains(err, model.ErrNoSuchDevice) {
+			errors.PrintAsInfo(errors.Append(err, "Failed to delete device"))
+			errors.WriteToHTTP(w, err, http.StatusNotFound, "")
+		} else {
+			errors.Print(errors.Append(err, "Failed to delete device"))
+			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	// Return 204 (No content) for success
+	w.WriteHeader(http.StatusNoContent)
+	logger.Info("DeviceDeleteHandler method successfully finished")
+}
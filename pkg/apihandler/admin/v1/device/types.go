@@ -0,0 +1,10 @@
+package deviceapi
+
+// DeviceGetResponse ...
+type DeviceGetResponse struct {
+	UserCode      string `json:"user_code"`
+	ClientID      string `json:"client_id"`
+	CreatedAt     string `json:"created_at"`
+	ExpiresIn     int64  `json:"expires_in"`
+	Authenticated bool   `json:"authenticated"` // the user already logged in, and the device does not get the token yet
+}
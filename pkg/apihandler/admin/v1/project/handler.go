@@ -43,9 +43,12 @@ func AllProjectGetHandler(w http.ResponseWriter, r *http.Request) {
 			Name:      prj.Name,
 			CreatedAt: prj.CreatedAt.Format(time.RFC3339),
 			TokenConfig: TokenConfig{
-				AccessTokenLifeSpan:  prj.TokenConfig.AccessTokenLifeSpan,
-				RefreshTokenLifeSpan: prj.TokenConfig.RefreshTokenLifeSpan,
-				SigningAlgorithm:     prj.TokenConfig.SigningAlgorithm,
+				AccessTokenLifeSpan:       prj.TokenConfig.AccessTokenLifeSpan,
+				RefreshTokenLifeSpan:      prj.TokenConfig.RefreshTokenLifeSpan,
+				RefreshTokenGracePeriod:   prj.TokenConfig.RefreshTokenGracePeriod,
+				OfflineSessionLifeSpan:    prj.TokenConfig.OfflineSessionLifeSpan,
+				OfflineSessionIdleTimeout: prj.TokenConfig.OfflineSessionIdleTimeout,
+				SigningAlgorithm:          prj.TokenConfig.SigningAlgorithm,
 			},
 			PasswordPolicy: PasswordPolicy{
 				MinimumLength:       prj.PasswordPolicy.MinimumLength,
@@ -54,6 +57,9 @@ func AllProjectGetHandler(w http.ResponseWriter, r *http.Request) {
 				UseCharacter:        string(prj.PasswordPolicy.UseCharacter),
 				UseDigit:            prj.PasswordPolicy.UseDigit,
 				UseSpecialCharacter: prj.PasswordPolicy.UseSpecialCharacter,
+				MaxAge:              prj.PasswordPolicy.MaxAge,
+				MinAge:              prj.PasswordPolicy.MinAge,
+				HistoryCount:        prj.PasswordPolicy.HistoryCount,
 			},
 			AllowGrantTypes: grantTypes,
 			UserLock: UserLock{
@@ -62,6 +68,18 @@ func AllProjectGetHandler(w http.ResponseWriter, r *http.Request) {
 				LockDuration:     prj.UserLock.LockDuration,
 				FailureResetTime: prj.UserLock.FailureResetTime,
 			},
+			DefaultLocale: prj.DefaultLocale,
+			WebAuthnConfig: WebAuthnConfig{
+				AttestationConveyance: prj.WebAuthnConfig.AttestationConveyance,
+			},
+			MailConfig: newMailConfig(prj.MailConfig),
+			SMSConfig: SMSConfig{
+				OTPEnabled:   prj.SMSConfig.OTPEnabled,
+				CodeLifeSpan: prj.SMSConfig.CodeLifeSpan,
+			},
+			TOTPConfig: newTOTPConfig(prj.TOTPConfig),
+			MFAPolicy:  newMFAPolicy(prj.MFAPolicy),
+			RiskPolicy: newRiskPolicy(prj.RiskPolicy),
 		})
 	}
 	logger.Debug("Project List: %v", res)
@@ -123,9 +141,12 @@ func ProjectCreateHandler(w http.ResponseWriter, r *http.Request) {
 		CreatedAt:    time.Now(),
 		PermitDelete: true,
 		TokenConfig: &model.TokenConfig{
-			AccessTokenLifeSpan:  request.TokenConfig.AccessTokenLifeSpan,
-			RefreshTokenLifeSpan: request.TokenConfig.RefreshTokenLifeSpan,
-			SigningAlgorithm:     request.TokenConfig.SigningAlgorithm,
+			AccessTokenLifeSpan:       request.TokenConfig.AccessTokenLifeSpan,
+			RefreshTokenLifeSpan:      request.TokenConfig.RefreshTokenLifeSpan,
+			RefreshTokenGracePeriod:   request.TokenConfig.RefreshTokenGracePeriod,
+			OfflineSessionLifeSpan:    request.TokenConfig.OfflineSessionLifeSpan,
+			OfflineSessionIdleTimeout: request.TokenConfig.OfflineSessionIdleTimeout,
+			SigningAlgorithm:          request.TokenConfig.SigningAlgorithm,
 		},
 		PasswordPolicy: model.PasswordPolicy{
 			MinimumLength:       request.PasswordPolicy.MinimumLength,
@@ -134,6 +155,9 @@ func ProjectCreateHandler(w http.ResponseWriter, r *http.Request) {
 			UseCharacter:        model.CharacterType(request.PasswordPolicy.UseCharacter),
 			UseDigit:            request.PasswordPolicy.UseDigit,
 			UseSpecialCharacter: request.PasswordPolicy.UseSpecialCharacter,
+			MaxAge:              request.PasswordPolicy.MaxAge,
+			MinAge:              request.PasswordPolicy.MinAge,
+			HistoryCount:        request.PasswordPolicy.HistoryCount,
 		},
 		AllowGrantTypes: grantTypes,
 		UserLock: model.UserLock{
@@ -142,6 +166,28 @@ func ProjectCreateHandler(w http.ResponseWriter, r *http.Request) {
 			LockDuration:     request.UserLock.LockDuration,
 			FailureResetTime: request.UserLock.FailureResetTime,
 		},
+		DefaultLocale: request.DefaultLocale,
+		WebAuthnConfig: model.WebAuthnConfig{
+			AttestationConveyance: request.WebAuthnConfig.AttestationConveyance,
+		},
+		MailConfig: toMailConfig(request.MailConfig),
+		SMSConfig: model.SMSConfig{
+			OTPEnabled:   request.SMSConfig.OTPEnabled,
+			CodeLifeSpan: request.SMSConfig.CodeLifeSpan,
+		},
+		TOTPConfig: toTOTPConfig(request.TOTPConfig),
+		MFAPolicy:  toMFAPolicy(request.MFAPolicy),
+		RiskPolicy: toRiskPolicy(request.RiskPolicy),
+	}
+
+	if project.DefaultLocale == "" {
+		project.DefaultLocale = model.DefaultLocale
+	}
+	if project.WebAuthnConfig.AttestationConveyance == "" {
+		project.WebAuthnConfig.AttestationConveyance = model.AttestationConveyanceNone
+	}
+	if project.SMSConfig.CodeLifeSpan == 0 {
+		project.SMSConfig.CodeLifeSpan = model.DefaultSMSCodeLifeSpan
 	}
 
 	// Create New Project
@@ -164,9 +210,12 @@ func ProjectCreateHandler(w http.ResponseWriter, r *http.Request) {
 		Name:      project.Name,
 		CreatedAt: project.CreatedAt.Format(time.RFC3339),
 		TokenConfig: TokenConfig{
-			AccessTokenLifeSpan:  project.TokenConfig.AccessTokenLifeSpan,
-			RefreshTokenLifeSpan: project.TokenConfig.RefreshTokenLifeSpan,
-			SigningAlgorithm:     project.TokenConfig.SigningAlgorithm,
+			AccessTokenLifeSpan:       project.TokenConfig.AccessTokenLifeSpan,
+			RefreshTokenLifeSpan:      project.TokenConfig.RefreshTokenLifeSpan,
+			RefreshTokenGracePeriod:   project.TokenConfig.RefreshTokenGracePeriod,
+			OfflineSessionLifeSpan:    project.TokenConfig.OfflineSessionLifeSpan,
+			OfflineSessionIdleTimeout: project.TokenConfig.OfflineSessionIdleTimeout,
+			SigningAlgorithm:          project.TokenConfig.SigningAlgorithm,
 		},
 		PasswordPolicy: PasswordPolicy{
 			MinimumLength:       project.PasswordPolicy.MinimumLength,
@@ -175,6 +224,9 @@ func ProjectCreateHandler(w http.ResponseWriter, r *http.Request) {
 			UseCharacter:        string(project.PasswordPolicy.UseCharacter),
 			UseDigit:            project.PasswordPolicy.UseDigit,
 			UseSpecialCharacter: project.PasswordPolicy.UseSpecialCharacter,
+			MaxAge:              project.PasswordPolicy.MaxAge,
+			MinAge:              project.PasswordPolicy.MinAge,
+			HistoryCount:        project.PasswordPolicy.HistoryCount,
 		},
 		AllowGrantTypes: request.AllowGrantTypes,
 		UserLock: UserLock{
@@ -183,6 +235,18 @@ func ProjectCreateHandler(w http.ResponseWriter, r *http.Request) {
 			LockDuration:     project.UserLock.LockDuration,
 			FailureResetTime: project.UserLock.FailureResetTime,
 		},
+		DefaultLocale: project.DefaultLocale,
+		WebAuthnConfig: WebAuthnConfig{
+			AttestationConveyance: project.WebAuthnConfig.AttestationConveyance,
+		},
+		MailConfig: newMailConfig(project.MailConfig),
+		SMSConfig: SMSConfig{
+			OTPEnabled:   project.SMSConfig.OTPEnabled,
+			CodeLifeSpan: project.SMSConfig.CodeLifeSpan,
+		},
+		TOTPConfig: newTOTPConfig(project.TOTPConfig),
+		MFAPolicy:  newMFAPolicy(project.MFAPolicy),
+		RiskPolicy: newRiskPolicy(project.RiskPolicy),
 	}
 
 	jwthttp.ResponseWrite(w, "ProjectCreateHandler", &res)
@@ -259,9 +323,12 @@ func ProjectGetHandler(w http.ResponseWriter, r *http.Request) {
 		Name:      project.Name,
 		CreatedAt: project.CreatedAt.Format(time.RFC3339),
 		TokenConfig: TokenConfig{
-			AccessTokenLifeSpan:  project.TokenConfig.AccessTokenLifeSpan,
-			RefreshTokenLifeSpan: project.TokenConfig.RefreshTokenLifeSpan,
-			SigningAlgorithm:     project.TokenConfig.SigningAlgorithm,
+			AccessTokenLifeSpan:       project.TokenConfig.AccessTokenLifeSpan,
+			RefreshTokenLifeSpan:      project.TokenConfig.RefreshTokenLifeSpan,
+			RefreshTokenGracePeriod:   project.TokenConfig.RefreshTokenGracePeriod,
+			OfflineSessionLifeSpan:    project.TokenConfig.OfflineSessionLifeSpan,
+			OfflineSessionIdleTimeout: project.TokenConfig.OfflineSessionIdleTimeout,
+			SigningAlgorithm:          project.TokenConfig.SigningAlgorithm,
 		},
 		PasswordPolicy: PasswordPolicy{
 			MinimumLength:       project.PasswordPolicy.MinimumLength,
@@ -270,6 +337,9 @@ func ProjectGetHandler(w http.ResponseWriter, r *http.Request) {
 			UseCharacter:        string(project.PasswordPolicy.UseCharacter),
 			UseDigit:            project.PasswordPolicy.UseDigit,
 			UseSpecialCharacter: project.PasswordPolicy.UseSpecialCharacter,
+			MaxAge:              project.PasswordPolicy.MaxAge,
+			MinAge:              project.PasswordPolicy.MinAge,
+			HistoryCount:        project.PasswordPolicy.HistoryCount,
 		},
 		AllowGrantTypes: grantTypes,
 		UserLock: UserLock{
@@ -278,6 +348,18 @@ func ProjectGetHandler(w http.ResponseWriter, r *http.Request) {
 			LockDuration:     project.UserLock.LockDuration,
 			FailureResetTime: project.UserLock.FailureResetTime,
 		},
+		DefaultLocale: project.DefaultLocale,
+		WebAuthnConfig: WebAuthnConfig{
+			AttestationConveyance: project.WebAuthnConfig.AttestationConveyance,
+		},
+		MailConfig: newMailConfig(project.MailConfig),
+		SMSConfig: SMSConfig{
+			OTPEnabled:   project.SMSConfig.OTPEnabled,
+			CodeLifeSpan: project.SMSConfig.CodeLifeSpan,
+		},
+		TOTPConfig: newTOTPConfig(project.TOTPConfig),
+		MFAPolicy:  newMFAPolicy(project.MFAPolicy),
+		RiskPolicy: newRiskPolicy(project.RiskPolicy),
 	}
 
 	jwthttp.ResponseWrite(w, "ProjectGetHandler", &res)
@@ -327,6 +409,9 @@ func ProjectUpdateHandler(w http.ResponseWriter, r *http.Request) {
 	// Update Parameters
 	project.TokenConfig.AccessTokenLifeSpan = request.TokenConfig.AccessTokenLifeSpan
 	project.TokenConfig.RefreshTokenLifeSpan = request.TokenConfig.RefreshTokenLifeSpan
+	project.TokenConfig.RefreshTokenGracePeriod = request.TokenConfig.RefreshTokenGracePeriod
+	project.TokenConfig.OfflineSessionLifeSpan = request.TokenConfig.OfflineSessionLifeSpan
+	project.TokenConfig.OfflineSessionIdleTimeout = request.TokenConfig.OfflineSessionIdleTimeout
 	project.TokenConfig.SigningAlgorithm = request.TokenConfig.SigningAlgorithm
 	project.PasswordPolicy.MinimumLength = request.PasswordPolicy.MinimumLength
 	project.PasswordPolicy.NotUserName = request.PasswordPolicy.NotUserName
@@ -334,6 +419,9 @@ func ProjectUpdateHandler(w http.ResponseWriter, r *http.Request) {
 	project.PasswordPolicy.UseCharacter = model.CharacterType(request.PasswordPolicy.UseCharacter)
 	project.PasswordPolicy.UseDigit = request.PasswordPolicy.UseDigit
 	project.PasswordPolicy.UseSpecialCharacter = request.PasswordPolicy.UseSpecialCharacter
+	project.PasswordPolicy.MaxAge = request.PasswordPolicy.MaxAge
+	project.PasswordPolicy.MinAge = request.PasswordPolicy.MinAge
+	project.PasswordPolicy.HistoryCount = request.PasswordPolicy.HistoryCount
 	project.AllowGrantTypes = []model.GrantType{}
 	for _, t := range request.AllowGrantTypes {
 		v, err := model.GetGrantType(t)
@@ -350,6 +438,25 @@ func ProjectUpdateHandler(w http.ResponseWriter, r *http.Request) {
 		LockDuration:     request.UserLock.LockDuration,
 		FailureResetTime: request.UserLock.FailureResetTime,
 	}
+	project.DefaultLocale = request.DefaultLocale
+	project.WebAuthnConfig.AttestationConveyance = request.WebAuthnConfig.AttestationConveyance
+	project.MailConfig = toMailConfig(request.MailConfig)
+	project.SMSConfig = model.SMSConfig{
+		OTPEnabled:   request.SMSConfig.OTPEnabled,
+		CodeLifeSpan: request.SMSConfig.CodeLifeSpan,
+	}
+	project.TOTPConfig = toTOTPConfig(request.TOTPConfig)
+	project.MFAPolicy = toMFAPolicy(request.MFAPolicy)
+	project.RiskPolicy = toRiskPolicy(request.RiskPolicy)
+	if project.DefaultLocale == "" {
+		project.DefaultLocale = model.DefaultLocale
+	}
+	if project.WebAuthnConfig.AttestationConveyance == "" {
+		project.WebAuthnConfig.AttestationConveyance = model.AttestationConveyanceNone
+	}
+	if project.SMSConfig.CodeLifeSpan == 0 {
+		project.SMSConfig.CodeLifeSpan = model.DefaultSMSCodeLifeSpan
+	}
 
 	// Update DB
 	if err = db.GetInst().ProjectUpdate(project); err != nil {
@@ -366,3 +473,136 @@ func ProjectUpdateHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 	logger.Info("ProjectUpdateHandler method successfully finished")
 }
+
+func toTOTPConfig(req TOTPConfig) model.TOTPConfig {
+	res := model.TOTPConfig{
+		Algorithm: req.Algorithm,
+		Digits:    req.Digits,
+		Period:    req.Period,
+		SkewSteps: req.SkewSteps,
+		Issuer:    req.Issuer,
+	}
+	if res.Algorithm == "" {
+		res.Algorithm = model.TOTPAlgorithmSHA1
+	}
+	if res.Digits == 0 {
+		res.Digits = model.DefaultTOTPDigits
+	}
+	if res.Period == 0 {
+		res.Period = model.DefaultTOTPPeriod
+	}
+	if res.Issuer == "" {
+		res.Issuer = model.DefaultTOTPIssuer
+	}
+	return res
+}
+
+func newTOTPConfig(c model.TOTPConfig) TOTPConfig {
+	return TOTPConfig{
+		Algorithm: c.GetAlgorithm(),
+		Digits:    c.GetDigits(),
+		Period:    c.GetPeriod(),
+		SkewSteps: c.SkewSteps,
+		Issuer:    c.GetIssuer(),
+	}
+}
+
+func toMFAPolicy(req MFAPolicy) model.MFAPolicy {
+	res := model.MFAPolicy{
+		Mode:        req.Mode,
+		SystemRoles: req.SystemRoles,
+		CustomRoles: req.CustomRoles,
+
+		TrustedDeviceLifeSpan: req.TrustedDeviceLifeSpan,
+	}
+	if res.Mode == "" {
+		res.Mode = model.MFAModeOptional
+	}
+	return res
+}
+
+func newMFAPolicy(p model.MFAPolicy) MFAPolicy {
+	res := MFAPolicy{
+		Mode:        p.Mode,
+		SystemRoles: p.SystemRoles,
+		CustomRoles: p.CustomRoles,
+
+		TrustedDeviceLifeSpan: p.TrustedDeviceLifeSpan,
+	}
+	if res.Mode == "" {
+		res.Mode = model.MFAModeOptional
+	}
+	if res.SystemRoles == nil {
+		res.SystemRoles = []string{}
+	}
+	if res.CustomRoles == nil {
+		res.CustomRoles = []string{}
+	}
+	return res
+}
+
+func toRiskPolicy(req RiskPolicy) model.RiskPolicy {
+	res := model.RiskPolicy{}
+	for _, r := range req.Rules {
+		res.Rules = append(res.Rules, model.RiskRule{
+			Type:      r.Type,
+			Action:    r.Action,
+			Threshold: r.Threshold,
+			Window:    r.Window,
+		})
+	}
+	return res
+}
+
+func newRiskPolicy(p model.RiskPolicy) RiskPolicy {
+	res := RiskPolicy{
+		Rules: []RiskRule{},
+	}
+	for _, r := range p.Rules {
+		res.Rules = append(res.Rules, RiskRule{
+			Type:      r.Type,
+			Action:    r.Action,
+			Threshold: r.Threshold,
+			Window:    r.Window,
+		})
+	}
+	return res
+}
+
+func toMailConfig(req MailConfig) model.MailConfig {
+	res := model.MailConfig{
+		EMailOTPEnabled:  req.EMailOTPEnabled,
+		MagicLinkEnabled: req.MagicLinkEnabled,
+		CodeLifeSpan:     req.CodeLifeSpan,
+	}
+	if res.CodeLifeSpan == 0 {
+		res.CodeLifeSpan = model.DefaultMailCodeLifeSpan
+	}
+	for _, t := range req.Templates {
+		res.Templates = append(res.Templates, model.MailTemplate{
+			Type:    t.Type,
+			Locale:  t.Locale,
+			Subject: t.Subject,
+			Body:    t.Body,
+		})
+	}
+	return res
+}
+
+func newMailConfig(c model.MailConfig) MailConfig {
+	res := MailConfig{
+		EMailOTPEnabled:  c.EMailOTPEnabled,
+		MagicLinkEnabled: c.MagicLinkEnabled,
+		CodeLifeSpan:     c.CodeLifeSpan,
+		Templates:        []MailTemplate{},
+	}
+	for _, t := range c.Templates {
+		res.Templates = append(res.Templates, MailTemplate{
+			Type:    t.Type,
+			Locale:  t.Locale,
+			Subject: t.Subject,
+			Body:    t.Body,
+		})
+	}
+	return res
+}
@@ -2,9 +2,12 @@ package projectapi
 
 // TokenConfig ...
 type TokenConfig struct {
-	AccessTokenLifeSpan  uint   `json:"access_token_life_span"`
-	RefreshTokenLifeSpan uint   `json:"refresh_token_life_span"`
-	SigningAlgorithm     string `json:"signing_algorithm"`
+	AccessTokenLifeSpan       uint   `json:"access_token_life_span"`
+	RefreshTokenLifeSpan      uint   `json:"refresh_token_life_span"`
+	RefreshTokenGracePeriod   uint   `json:"refresh_token_grace_period"`
+	OfflineSessionLifeSpan    uint   `json:"offline_session_life_span"`
+	OfflineSessionIdleTimeout uint   `json:"offline_session_idle_timeout"`
+	SigningAlgorithm          string `json:"signing_algorithm"`
 }
 
 // PasswordPolicy ...
@@ -15,6 +18,9 @@ type PasswordPolicy struct {
 	UseCharacter        string   `json:"use_character"`
 	UseDigit            bool     `json:"use_digit"`
 	UseSpecialCharacter bool     `json:"use_special_character"`
+	MaxAge              uint     `json:"max_age"`
+	MinAge              uint     `json:"min_age"`
+	HistoryCount        uint     `json:"history_count"`
 }
 
 // UserLock ...
@@ -25,6 +31,64 @@ type UserLock struct {
 	FailureResetTime uint `json:"failure_reset_time"`
 }
 
+// WebAuthnConfig ...
+type WebAuthnConfig struct {
+	AttestationConveyance string `json:"attestation_conveyance"`
+}
+
+// MailTemplate ...
+type MailTemplate struct {
+	Type    string `json:"type"`
+	Locale  string `json:"locale"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// MailConfig ...
+type MailConfig struct {
+	EMailOTPEnabled  bool           `json:"email_otp_enabled"`
+	MagicLinkEnabled bool           `json:"magic_link_enabled"`
+	CodeLifeSpan     uint           `json:"code_life_span"`
+	Templates        []MailTemplate `json:"templates"`
+}
+
+// SMSConfig ...
+type SMSConfig struct {
+	OTPEnabled   bool `json:"otp_enabled"`
+	CodeLifeSpan uint `json:"code_life_span"`
+}
+
+// TOTPConfig ...
+type TOTPConfig struct {
+	Algorithm string `json:"algorithm"`
+	Digits    uint   `json:"digits"`
+	Period    uint   `json:"period"`
+	SkewSteps uint   `json:"skew_steps"`
+	Issuer    string `json:"issuer"`
+}
+
+// MFAPolicy ...
+type MFAPolicy struct {
+	Mode        string   `json:"mode"`
+	SystemRoles []string `json:"system_roles"`
+	CustomRoles []string `json:"custom_roles"`
+	// TrustedDeviceLifeSpan is the period [sec] a trusted browser skips the second factor, 0 means disabled
+	TrustedDeviceLifeSpan uint `json:"trusted_device_life_span"`
+}
+
+// RiskRule ...
+type RiskRule struct {
+	Type      string `json:"type"`
+	Action    string `json:"action"`
+	Threshold uint   `json:"threshold"`
+	Window    uint   `json:"window"`
+}
+
+// RiskPolicy ...
+type RiskPolicy struct {
+	Rules []RiskRule `json:"rules"`
+}
+
 // ProjectCreateRequest ...
 type ProjectCreateRequest struct {
 	Name            string         `json:"name"`
@@ -32,6 +96,13 @@ type ProjectCreateRequest struct {
 	PasswordPolicy  PasswordPolicy `json:"password_policy"`
 	AllowGrantTypes []string       `json:"allow_grant_types"`
 	UserLock        UserLock       `json:"user_lock"`
+	DefaultLocale   string         `json:"default_locale"`
+	WebAuthnConfig  WebAuthnConfig `json:"webauthn_config"`
+	MailConfig      MailConfig     `json:"mail_config"`
+	SMSConfig       SMSConfig      `json:"sms_config"`
+	TOTPConfig      TOTPConfig     `json:"totp_config"`
+	MFAPolicy       MFAPolicy      `json:"mfa_policy"`
+	RiskPolicy      RiskPolicy     `json:"risk_policy"`
 }
 
 // ProjectGetResponse ...
@@ -42,6 +113,13 @@ type ProjectGetResponse struct {
 	PasswordPolicy  PasswordPolicy `json:"password_policy"`
 	AllowGrantTypes []string       `json:"allow_grant_types"`
 	UserLock        UserLock       `json:"user_lock"`
+	DefaultLocale   string         `json:"default_locale"`
+	WebAuthnConfig  WebAuthnConfig `json:"webauthn_config"`
+	MailConfig      MailConfig     `json:"mail_config"`
+	SMSConfig       SMSConfig      `json:"sms_config"`
+	TOTPConfig      TOTPConfig     `json:"totp_config"`
+	MFAPolicy       MFAPolicy      `json:"mfa_policy"`
+	RiskPolicy      RiskPolicy     `json:"risk_policy"`
 }
 
 // ProjectPutRequest ...
@@ -50,4 +128,11 @@ type ProjectPutRequest struct {
 	PasswordPolicy  PasswordPolicy `json:"password_policy"`
 	AllowGrantTypes []string       `json:"allow_grant_types"`
 	UserLock        UserLock       `json:"user_lock"`
+	DefaultLocale   string         `json:"default_locale"`
+	WebAuthnConfig  WebAuthnConfig `json:"webauthn_config"`
+	MailConfig      MailConfig     `json:"mail_config"`
+	SMSConfig       SMSConfig      `json:"sms_config"`
+	TOTPConfig      TOTPConfig     `json:"totp_config"`
+	MFAPolicy       MFAPolicy      `json:"mfa_policy"`
+	RiskPolicy      RiskPolicy     `json:"risk_policy"`
 }
@@ -0,0 +1,100 @@
+package ratelimitapi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sh-miyoshi/hekate/pkg/audit"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	jwthttp "github.com/sh-miyoshi/hekate/pkg/http"
+	"github.com/sh-miyoshi/hekate/pkg/logger"
+	"github.com/sh-miyoshi/hekate/pkg/ratelimit"
+	"github.com/sh-miyoshi/hekate/pkg/ratelimit/model"
+	"github.com/sh-miyoshi/hekate/pkg/role"
+)
+
+// BlockGetListHandler returns the current blocks by the rate limit
+//   require role: read-project
+func BlockGetListHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+
+	// Authorize API Request
+	if err := jwthttp.Authorize(r, projectName, role.ResProject, role.TypeRead); err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	blocks, err := ratelimit.GetInst().GetBlockList(projectName)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get block list"))
+		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	res := []BlockGetResponse{}
+	for _, b := range blocks {
+		res = append(res, BlockGetResponse{
+			Type:      b.Type,
+			Value:     b.Value,
+			CreatedAt: b.CreatedAt.Format(time.RFC3339),
+			ExpiresAt: b.ExpiresAt.Format(time.RFC3339),
+		})
+	}
+
+	jwthttp.ResponseWrite(w, "BlockGetListHandler", &res)
+}
+
+// BlockDeleteHandler unblocks the IP address, the user or the client before the block expires
+//   require role: write-project
+func BlockDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	key := model.Key{
+		ProjectName: projectName,
+		Type:        vars["type"],
+		Value:       vars["value"],
+	}
+
+	var err *errors.Error
+	defer func() {
+		msg := ""
+		if err != nil {
+			msg = err.Error()
+		}
+		if err = audit.GetInst().Save(projectName, time.Now(), "RATE_LIMIT", r.Method, r.URL.String(), msg); err != nil {
+			errors.Print(errors.Append(err, "Failed to save audit event"))
+		}
+	}()
+
+	// Authorize API Request
+	if err = jwthttp.Authorize(r, projectName, role.ResProject, role.TypeWrite); err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	if key.Type != model.KeyTypeIP && key.Type != model.KeyTypeUser && key.Type != model.KeyTypeClient && key.Type != model.KeyTypeUserCode {
+		err = errors.New("Invalid block type", "Block type %s is not supported", key.Type)
+		errors.PrintAsInfo(err)
+		errors.WriteToHTTP(w, errors.ErrInvalidRequest, 0, "")
+		return
+	}
+
+	if err = ratelimit.GetInst().Unblock(key); err != nil {
+		if errors.Contains(err, model.ErrNoSuchBlock) {
+			errors.PrintAsInfo(errors.Append(err, "Failed to delete block"))
+			errors.WriteToHTTP(w, err, http.StatusNotFound, "")
+		} else {
+			errors.Print(errors.Append(err, "Failed to delete block"))
+			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	// Return 204 (No content) for success
+	w.WriteHeader(http.StatusNoContent)
+	logger.Info("BlockDeleteHandler method successfully finished")
+}
@@ -0,0 +1,9 @@
+package ratelimitapi
+
+// BlockGetResponse ...
+type BlockGetResponse struct {
+	Type      string `json:"type"`  // ip, user or client
+	Value     string `json:"value"` // IP address, user name or client id
+	CreatedAt string `json:"created_at"`
+	ExpiresAt string `json:"expires_at"`
+}
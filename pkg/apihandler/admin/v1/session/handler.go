@@ -82,10 +82,12 @@ func SessionGetHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	res := SessionGetResponse{
-		ID:        s.SessionID,
-		CreatedAt: s.CreatedAt.Format(time.RFC3339),
-		ExpiresIn: s.ExpiresIn,
-		FromIP:    s.FromIP,
+		ID:             s.SessionID,
+		CreatedAt:      s.CreatedAt.Format(time.RFC3339),
+		ExpiresIn:      s.ExpiresIn,
+		FromIP:         s.FromIP,
+		Offline:        s.Offline,
+		LoginSessionID: s.LoginSessionID,
 	}
 
 	jwthttp.ResponseWrite(w, "SessionGetHandler", &res)
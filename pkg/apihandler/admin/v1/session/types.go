@@ -2,8 +2,10 @@ package sessionapi
 
 // SessionGetResponse ...
 type SessionGetResponse struct {
-	ID        string `json:"id"`
-	CreatedAt string `json:"created_at"`
-	ExpiresIn int64  `json:"expires_in"`
-	FromIP    string `json:"from_ip"`
+	ID             string `json:"id"`
+	CreatedAt      string `json:"created_at"`
+	ExpiresIn      int64  `json:"expires_in"`
+	FromIP         string `json:"from_ip"`
+	Offline        bool   `json:"offline"`
+	LoginSessionID string `json:"login_session_id"` // login session which the session was issued from
 }
@@ -81,6 +81,11 @@ func AllUserGetHandler(w http.ResponseWriter, r *http.Request) {
 			SystemRoles: user.SystemRoles,
 			CustomRoles: roles,
 			Locked:      user.LockState.Locked,
+			UpdatedAt:   formatTime(user.UpdatedAt),
+			Attributes:  user.Attributes,
+			OTPInfo:     newOTPInfo(user),
+			Password:    newPasswordInfo(user),
+			Profile:     newProfile(user),
 		}
 		sessions, err := db.GetInst().SessionGetList(projectName, &model.SessionFilter{UserID: user.ID})
 		if err != nil {
@@ -90,7 +95,11 @@ func AllUserGetHandler(w http.ResponseWriter, r *http.Request) {
 		}
 
 		for _, s := range sessions {
-			tmp.Sessions = append(tmp.Sessions, s.SessionID)
+			if s.Offline {
+				tmp.OfflineSessions = append(tmp.OfflineSessions, s.SessionID)
+			} else {
+				tmp.Sessions = append(tmp.Sessions, s.SessionID)
+			}
 		}
 
 		res = append(res, tmp)
@@ -156,7 +165,11 @@ func UserCreateHandler(w http.ResponseWriter, r *http.Request) {
 		PasswordHash: util.CreateHash(request.Password),
 		SystemRoles:  request.SystemRoles,
 		CustomRoles:  request.CustomRoles,
+		Attributes:   request.Attributes,
 	}
+	user.PasswordUpdatedAt = user.CreatedAt
+	user.PasswordChangeRequired = request.PasswordChangeRequired
+	setProfile(&user, request.Profile)
 
 	if err = db.GetInst().UserAdd(projectName, &user); err != nil {
 		if errors.Contains(err, model.ErrUserValidateFailed) {
@@ -195,6 +208,11 @@ func UserCreateHandler(w http.ResponseWriter, r *http.Request) {
 		SystemRoles: user.SystemRoles,
 		CustomRoles: roles,
 		Locked:      user.LockState.Locked,
+		UpdatedAt:   formatTime(user.UpdatedAt),
+		Attributes:  user.Attributes,
+		OTPInfo:     newOTPInfo(&user),
+		Password:    newPasswordInfo(&user),
+		Profile:     newProfile(&user),
 	}
 
 	jwthttp.ResponseWrite(w, "UserGetAllUserGetHandlerHandler", &res)
@@ -290,6 +308,11 @@ func UserGetHandler(w http.ResponseWriter, r *http.Request) {
 		SystemRoles: user.SystemRoles,
 		CustomRoles: roles,
 		Locked:      user.LockState.Locked,
+		UpdatedAt:   formatTime(user.UpdatedAt),
+		Attributes:  user.Attributes,
+		OTPInfo:     newOTPInfo(user),
+		Password:    newPasswordInfo(user),
+		Profile:     newProfile(user),
 	}
 
 	sessions, err := db.GetInst().SessionGetList(projectName, &model.SessionFilter{UserID: user.ID})
@@ -300,7 +323,11 @@ func UserGetHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	for _, s := range sessions {
-		res.Sessions = append(res.Sessions, s.SessionID)
+		if s.Offline {
+			res.OfflineSessions = append(res.OfflineSessions, s.SessionID)
+		} else {
+			res.Sessions = append(res.Sessions, s.SessionID)
+		}
 	}
 
 	jwthttp.ResponseWrite(w, "UserGetHandler", &res)
@@ -359,9 +386,23 @@ func UserUpdateHandler(w http.ResponseWriter, r *http.Request) {
 	// Update Parameters
 	// name, roles
 	user.Name = request.Name
+	if user.EMail != request.EMail {
+		// the email OTP must be set up again to confirm the new address
+		user.EMailOTPInfo = model.EMailOTPInfo{}
+	}
 	user.EMail = request.EMail
 	user.SystemRoles = request.SystemRoles
 	user.CustomRoles = request.CustomRoles
+	user.Attributes = request.Attributes
+	if request.PasswordChangeRequired != nil {
+		user.PasswordChangeRequired = *request.PasswordChangeRequired
+	}
+	phoneNumber := user.PhoneNumber
+	setProfile(user, request.Profile)
+	if user.PhoneNumber != phoneNumber {
+		// the SMS OTP must be set up again to confirm the new number
+		user.SMSOTPInfo = model.SMSOTPInfo{}
+	}
 
 	// Update DB
 	if err = db.GetInst().UserUpdate(projectName, user); err != nil {
@@ -523,7 +564,8 @@ func UserResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = db.GetInst().UserChangePassword(projectName, userID, req.Password); err != nil {
+	// the password reset by the administrator is temporary by default
+	if err = db.GetInst().UserChangePassword(projectName, userID, req.Password, !req.Permanent); err != nil {
 		if errors.Contains(err, model.ErrNoSuchUser) {
 			logger.Info("No such user: %s", userID)
 			errors.WriteToHTTP(w, err, http.StatusNotFound, "")
@@ -535,7 +577,7 @@ func UserResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
 				errors.PrintAsInfo(errors.Append(err, "Invalid password was specified"))
 				errors.WriteToHTTP(w, err, http.StatusBadRequest, "")
 			}
-		} else if errors.Contains(err, secret.ErrPasswordPolicyFailed) {
+		} else if errors.Contains(err, secret.ErrPasswordPolicyFailed) || errors.Contains(err, secret.ErrPasswordReused) {
 			errors.PrintAsInfo(errors.Append(err, "Invalid password was specified"))
 			errors.WriteToHTTP(w, err, http.StatusBadRequest, "")
 		} else {
@@ -600,3 +642,222 @@ func UserUnlockHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 	logger.Info("UserUnlockHandler method successfully finished")
 }
+
+// UserWebAuthnGetListHandler ...
+//   require role: read-project
+func UserWebAuthnGetListHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	userID := vars["userID"]
+
+	// Authorize API Request
+	if err := jwthttp.Authorize(r, projectName, role.ResProject, role.TypeRead); err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	user, err := db.GetInst().UserGet(projectName, userID)
+	if err != nil {
+		if errors.Contains(err, model.ErrNoSuchUser) {
+			errors.PrintAsInfo(errors.Append(err, "User %s is not found", userID))
+			errors.WriteToHTTP(w, err, http.StatusNotFound, "")
+		} else if errors.Contains(err, model.ErrUserValidateFailed) {
+			errors.PrintAsInfo(errors.Append(err, "Invalid user ID format"))
+			errors.WriteToHTTP(w, err, http.StatusBadRequest, "")
+		} else {
+			errors.Print(errors.Append(err, "Failed to get user"))
+			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	res := []WebAuthnCredential{}
+	for _, c := range user.WebAuthnInfo.Credentials {
+		res = append(res, WebAuthnCredential{
+			ID:                c.ID,
+			Name:              c.Name,
+			Transports:        c.Transports,
+			AAGUID:            c.AAGUID,
+			AttestationFormat: c.AttestationFormat,
+			CreatedAt:         formatTime(c.CreatedAt),
+			LastUsedAt:        formatTime(c.LastUsedAt),
+		})
+	}
+
+	jwthttp.ResponseWrite(w, "UserWebAuthnGetListHandler", &res)
+}
+
+// UserWebAuthnDeleteHandler ...
+//   require role: write-project
+func UserWebAuthnDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	userID := vars["userID"]
+	credentialID := vars["credentialID"]
+
+	var err *errors.Error
+	defer func() {
+		msg := ""
+		if err != nil {
+			msg = err.Error()
+		}
+		if err = audit.GetInst().Save(projectName, time.Now(), "USER", r.Method, r.URL.String(), msg); err != nil {
+			errors.Print(errors.Append(err, "Failed to save audit event"))
+		}
+	}()
+
+	// Authorize API Request
+	if err = jwthttp.Authorize(r, projectName, role.ResProject, role.TypeWrite); err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	if err = db.GetInst().WebAuthnCredentialDelete(projectName, userID, credentialID); err != nil {
+		if errors.Contains(err, model.ErrNoSuchUser) || errors.Contains(err, model.ErrNoSuchWebAuthnCredential) {
+			errors.PrintAsInfo(errors.Append(err, "WebAuthn credential %s of user %s is not found", credentialID, userID))
+			errors.WriteToHTTP(w, err, http.StatusNotFound, "")
+		} else {
+			errors.Print(errors.Append(err, "Failed to delete WebAuthn credential"))
+			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	logger.Info("UserWebAuthnDeleteHandler method successfully finished")
+}
+
+// UserTrustedDeviceGetListHandler ...
+//   require role: read-project
+func UserTrustedDeviceGetListHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	userID := vars["userID"]
+
+	// Authorize API Request
+	if err := jwthttp.Authorize(r, projectName, role.ResProject, role.TypeRead); err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	user, err := db.GetInst().UserGet(projectName, userID)
+	if err != nil {
+		if errors.Contains(err, model.ErrNoSuchUser) {
+			errors.PrintAsInfo(errors.Append(err, "User %s is not found", userID))
+			errors.WriteToHTTP(w, err, http.StatusNotFound, "")
+		} else if errors.Contains(err, model.ErrUserValidateFailed) {
+			errors.PrintAsInfo(errors.Append(err, "Invalid user ID format"))
+			errors.WriteToHTTP(w, err, http.StatusBadRequest, "")
+		} else {
+			errors.Print(errors.Append(err, "Failed to get user"))
+			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	res := []TrustedDevice{}
+	now := time.Now()
+	for _, d := range user.TrustedDevices {
+		if d.ExpiresAt.After(now) {
+			res = append(res, TrustedDevice{
+				ID:         d.ID,
+				UserAgent:  d.UserAgent,
+				CreatedAt:  formatTime(d.CreatedAt),
+				ExpiresAt:  formatTime(d.ExpiresAt),
+				LastUsedAt: formatTime(d.LastUsedAt),
+			})
+		}
+	}
+
+	jwthttp.ResponseWrite(w, "UserTrustedDeviceGetListHandler", &res)
+}
+
+// UserTrustedDeviceDeleteHandler ...
+//   require role: write-project
+func UserTrustedDeviceDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	userID := vars["userID"]
+	deviceID := vars["deviceID"]
+
+	var err *errors.Error
+	defer func() {
+		msg := ""
+		if err != nil {
+			msg = err.Error()
+		}
+		if err = audit.GetInst().Save(projectName, time.Now(), "USER", r.Method, r.URL.String(), msg); err != nil {
+			errors.Print(errors.Append(err, "Failed to save audit event"))
+		}
+	}()
+
+	// Authorize API Request
+	if err = jwthttp.Authorize(r, projectName, role.ResProject, role.TypeWrite); err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	if err = db.GetInst().TrustedDeviceDelete(projectName, userID, deviceID); err != nil {
+		if errors.Contains(err, model.ErrNoSuchUser) || errors.Contains(err, model.ErrNoSuchTrustedDevice) {
+			errors.PrintAsInfo(errors.Append(err, "Trusted device %s of user %s is not found", deviceID, userID))
+			errors.WriteToHTTP(w, err, http.StatusNotFound, "")
+		} else {
+			errors.Print(errors.Append(err, "Failed to delete trusted device"))
+			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	logger.Info("UserTrustedDeviceDeleteHandler method successfully finished")
+}
+
+func newOTPInfo(user *model.UserInfo) OTPInfo {
+	return OTPInfo{
+		Enabled:                user.OTPInfo.Enabled,
+		RecoveryCodesRemaining: len(user.OTPInfo.RecoveryCodes),
+	}
+}
+
+func newPasswordInfo(user *model.UserInfo) PasswordInfo {
+	return PasswordInfo{
+		ChangeRequired: user.PasswordChangeRequired,
+		UpdatedAt:      formatTime(user.PasswordUpdatedAt),
+	}
+}
+
+func newProfile(user *model.UserInfo) Profile {
+	return Profile{
+		GivenName:           user.GivenName,
+		FamilyName:          user.FamilyName,
+		Locale:              user.Locale,
+		Zoneinfo:            user.Zoneinfo,
+		PhoneNumber:         user.PhoneNumber,
+		PhoneNumberVerified: user.PhoneNumberVerified,
+		Address:             Address(user.Address),
+		EMailVerified:       user.EMailVerified,
+	}
+}
+
+func setProfile(user *model.UserInfo, profile Profile) {
+	user.GivenName = profile.GivenName
+	user.FamilyName = profile.FamilyName
+	user.Locale = profile.Locale
+	user.Zoneinfo = profile.Zoneinfo
+	user.PhoneNumber = profile.PhoneNumber
+	user.PhoneNumberVerified = profile.PhoneNumberVerified
+	user.Address = model.Address(profile.Address)
+	user.EMailVerified = profile.EMailVerified
+	user.UpdatedAt = time.Now()
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -6,37 +6,107 @@ type CustomRole struct {
 	Name string `json:"name"`
 }
 
+// Address ...
+type Address struct {
+	Formatted     string `json:"formatted"`
+	StreetAddress string `json:"street_address"`
+	Locality      string `json:"locality"`
+	Region        string `json:"region"`
+	PostalCode    string `json:"postal_code"`
+	Country       string `json:"country"`
+}
+
+// OTPInfo ...
+type OTPInfo struct {
+	Enabled                bool `json:"enabled"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+// PasswordInfo ...
+type PasswordInfo struct {
+	ChangeRequired bool   `json:"change_required"` // the user must change the password at next login
+	UpdatedAt      string `json:"updated_at"`
+}
+
+// Profile is a set of standard OpenID Connect profile claims
+type Profile struct {
+	GivenName           string  `json:"given_name"`
+	FamilyName          string  `json:"family_name"`
+	Locale              string  `json:"locale"`
+	Zoneinfo            string  `json:"zoneinfo"`
+	PhoneNumber         string  `json:"phone_number"`
+	PhoneNumberVerified bool    `json:"phone_number_verified"`
+	Address             Address `json:"address"`
+	EMailVerified       bool    `json:"email_verified"`
+}
+
 // UserCreateRequest ...
 type UserCreateRequest struct {
-	Name        string   `json:"name"`
-	EMail       string   `json:"email"`
-	Password    string   `json:"password"`
-	SystemRoles []string `json:"system_roles"`
-	CustomRoles []string `json:"custom_roles"`
+	Name        string              `json:"name"`
+	EMail       string              `json:"email"`
+	Password    string              `json:"password"`
+	SystemRoles []string            `json:"system_roles"`
+	CustomRoles []string            `json:"custom_roles"`
+	Attributes  map[string][]string `json:"attributes"`
+	// PasswordChangeRequired means that the user must change the password at next login
+	PasswordChangeRequired bool `json:"password_change_required"`
+	Profile
 }
 
 // UserGetResponse ...
 type UserGetResponse struct {
-	ID          string       `json:"id"`
-	Name        string       `json:"name"`
-	EMail       string       `json:"email"`
-	CreatedAt   string       `json:"created_at"`
-	SystemRoles []string     `json:"system_roles"`
-	CustomRoles []CustomRole `json:"custom_roles"`
-	Sessions    []string     `json:"sessions"` // Array of session IDs
-	Locked      bool         `json:"locked"`
-	// TODO OTP Info
+	ID              string              `json:"id"`
+	Name            string              `json:"name"`
+	EMail           string              `json:"email"`
+	CreatedAt       string              `json:"created_at"`
+	SystemRoles     []string            `json:"system_roles"`
+	CustomRoles     []CustomRole        `json:"custom_roles"`
+	Sessions        []string            `json:"sessions"`         // Array of session IDs
+	OfflineSessions []string            `json:"offline_sessions"` // Array of offline session IDs
+	Locked          bool                `json:"locked"`
+	UpdatedAt       string              `json:"updated_at"`
+	Attributes      map[string][]string `json:"attributes"`
+	OTPInfo         OTPInfo             `json:"otp_info"`
+	Password        PasswordInfo        `json:"password"`
+	Profile
 }
 
 // UserPutRequest ...
 type UserPutRequest struct {
-	Name        string   `json:"name"`
-	EMail       string   `json:"email"`
-	SystemRoles []string `json:"system_roles"`
-	CustomRoles []string `json:"custom_roles"`
+	Name        string              `json:"name"`
+	EMail       string              `json:"email"`
+	SystemRoles []string            `json:"system_roles"`
+	CustomRoles []string            `json:"custom_roles"`
+	Attributes  map[string][]string `json:"attributes"`
+	// PasswordChangeRequired means that the user must change the password at next login
+	// The current value is kept if it is not set.
+	PasswordChangeRequired *bool `json:"password_change_required"`
+	Profile
 }
 
 // UserResetPasswordRequest ...
 type UserResetPasswordRequest struct {
 	Password string `json:"password"`
+	// Permanent means that the user does not need to change the reset password at next login
+	Permanent bool `json:"permanent"`
+}
+
+// WebAuthnCredential ...
+type WebAuthnCredential struct {
+	ID                string   `json:"id"`
+	Name              string   `json:"name"`
+	Transports        []string `json:"transports"`
+	AAGUID            string   `json:"aaguid"`
+	AttestationFormat string   `json:"attestation_format"`
+	CreatedAt         string   `json:"created_at"`
+	LastUsedAt        string   `json:"last_used_at"`
+}
+
+// TrustedDevice ...
+type TrustedDevice struct {
+	ID         string `json:"id"`
+	UserAgent  string `json:"user_agent"`
+	CreatedAt  string `json:"created_at"`
+	ExpiresAt  string `json:"expires_at"`
+	LastUsedAt string `json:"last_used_at"`
 }
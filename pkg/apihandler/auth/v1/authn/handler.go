@@ -1,6 +1,7 @@
 package authn
 
 import (
+	"encoding/json"
 	"net/http"
 	"time"
 
@@ -10,12 +11,17 @@ import (
 	"github.com/sh-miyoshi/hekate/pkg/db"
 	"github.com/sh-miyoshi/hekate/pkg/db/model"
 	"github.com/sh-miyoshi/hekate/pkg/errors"
+	jwthttp "github.com/sh-miyoshi/hekate/pkg/http"
 	"github.com/sh-miyoshi/hekate/pkg/logger"
 	"github.com/sh-miyoshi/hekate/pkg/login"
 	"github.com/sh-miyoshi/hekate/pkg/oidc"
 	"github.com/sh-miyoshi/hekate/pkg/oidc/token"
 	"github.com/sh-miyoshi/hekate/pkg/otp"
+	"github.com/sh-miyoshi/hekate/pkg/ratelimit"
+	ratelimitmodel "github.com/sh-miyoshi/hekate/pkg/ratelimit/model"
+	"github.com/sh-miyoshi/hekate/pkg/secret"
 	"github.com/sh-miyoshi/hekate/pkg/sso"
+	"github.com/sh-miyoshi/hekate/pkg/webauthn"
 	"github.com/stretchr/stew/slice"
 )
 
@@ -72,10 +78,16 @@ func UserLoginHandler(w http.ResponseWriter, r *http.Request) {
 	// Verify user
 	uname := r.Form.Get("username")
 	passwd := r.Form.Get("password")
+	limitKeys := []ratelimitmodel.Key{ratelimit.IPKey(projectName, r), ratelimit.UserKey(projectName, uname)}
+	if err = ratelimit.GetInst().Check(limitKeys...); err != nil {
+		writeRateLimitError(w, r, projectName, s, state, err)
+		return
+	}
 	usr, err := login.UserVerifyByPassword(projectName, uname, passwd)
 	if err != nil {
 		if errors.Contains(err, login.ErrAuthFailed) || errors.Contains(err, login.ErrUserLocked) {
 			errors.PrintAsInfo(errors.Append(err, "Failed to authenticate user %s", uname))
+			ratelimit.GetInst().Failed(limitKeys...)
 
 			lsID, err := renewSession(projectName, s, state)
 			if err != nil {
@@ -84,7 +96,7 @@ func UserLoginHandler(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 
-			login.WriteUserLoginPage(projectName, lsID, "invalid user name or password", state, w)
+			login.WriteUserLoginPage(projectName, lsID, login.MsgInvalidUserOrPassword, state, login.NegotiateLocale(r, projectName, s.UILocales), w)
 			err = nil // do not delete session in defer function
 		} else {
 			errors.Print(errors.Append(err, "Failed to verify user"))
@@ -93,8 +105,24 @@ func UserLoginHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ratelimit.GetInst().Succeeded(ratelimit.UserKey(projectName, uname))
+
+	if err = checkLoginRisk(r, projectName, usr, s); err != nil {
+		writeLoginRiskError(w, r, projectName, s, state, err)
+		return
+	}
+
 	s.UserID = usr.ID
 	s.LoginDate = time.Now()
+	s.AuthMethods = []string{model.AuthMethodPassword}
+
+	// Decide scopes granted to the user
+	s.Scopes, err = oidc.GrantScopeNames(projectName, s.ClientID, s.UserID, s.Scopes)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to grant scopes"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
 
 	if err = db.GetInst().LoginSessionUpdate(projectName, s); err != nil {
 		errors.Print(errors.Append(err, "Failed to update login session"))
@@ -109,15 +137,26 @@ func UserLoginHandler(w http.ResponseWriter, r *http.Request) {
 	// 2. If required content, return consent page
 	// 3. login session finished, redirect to callback URL
 
-	// OTP Verify Page
-	if usr.OTPInfo.Enabled {
-		login.WriteOTPVerifyPage(projectName, sessionID, state, w)
+	// MFA Verify Page
+	prj, err := db.GetInst().ProjectGet(projectName)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get project"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+	written, err := writeSecondFactorPage(w, r, prj, usr, s, state, login.NegotiateLocale(r, projectName, s.UILocales))
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to write second factor page"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+	if written {
 		return
 	}
 
 	// Consent Page
-	if ok := slice.Contains(s.Prompt, "consent"); ok {
-		login.WriteConsentPage(projectName, sessionID, state, w)
+	if slice.Contains(s.Prompt, "consent") || oidc.RequireConsent(s.Scopes) {
+		login.WriteConsentPage(projectName, sessionID, state, s.Scopes, login.NegotiateLocale(r, projectName, s.UILocales), w)
 		return
 	}
 
@@ -180,10 +219,23 @@ func OTPVerifyHandler(w http.ResponseWriter, r *http.Request) {
 		errors.WriteToHTTP(w, err, 0, state)
 		return
 	}
+	prj, err := db.GetInst().ProjectGet(projectName)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get project"))
+		err = errors.ErrServerError
+		errors.WriteToHTTP(w, err, 0, state)
+		return
+	}
 
-	if err := otp.Verify(time.Now(), user, userCode); err != nil {
-		if errors.Contains(err, otp.ErrVerifyFailed) {
+	limitKeys := []ratelimitmodel.Key{ratelimit.IPKey(projectName, r), ratelimit.UserKey(projectName, user.Name)}
+	if err = ratelimit.GetInst().Check(limitKeys...); err != nil {
+		writeRateLimitError(w, r, projectName, s, state, err)
+		return
+	}
+	if err := verifyOTPOrRecoveryCode(r, prj, user, userCode); err != nil {
+		if errors.Contains(err, otp.ErrVerifyFailed) || errors.Contains(err, model.ErrNoSuchRecoveryCode) {
 			errors.PrintAsInfo(err)
+			ratelimit.GetInst().Failed(limitKeys...)
 
 			lsID, err := renewSession(projectName, s, state)
 			if err != nil {
@@ -192,7 +244,7 @@ func OTPVerifyHandler(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 			// write OTP verify page again
-			login.WriteOTPVerifyPage(projectName, lsID, state, w)
+			login.WriteOTPVerifyPage(projectName, lsID, state, login.NegotiateLocale(r, projectName, s.UILocales), prj.MFAPolicy.TrustedDeviceLifeSpan > 0, w)
 			return
 		}
 		errors.Print(err)
@@ -200,13 +252,31 @@ func OTPVerifyHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ratelimit.GetInst().Succeeded(ratelimit.UserKey(projectName, user.Name))
+
+	s.AuthMethods = append(s.AuthMethods, model.AuthMethodOTP)
+	if err = db.GetInst().LoginSessionUpdate(projectName, s); err != nil {
+		errors.Print(errors.Append(err, "Failed to update login session"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+
+	if r.Form.Get("trust_device") == "true" && prj.MFAPolicy.TrustedDeviceLifeSpan > 0 {
+		if err = sso.SetTrustedDeviceCookie(w, prj, user.ID, token.GetFullIssuer(r), r.UserAgent()); err != nil {
+			errors.Print(errors.Append(err, "Failed to trust device"))
+			errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+			return
+		}
+		logger.Info("User %s trusts the device", user.ID)
+	}
+
 	// Next Steps.
 	// 1. If required content, return consent page
 	// 2. login session finished, redirect to callback URL
 
 	// Consent Page
-	if ok := slice.Contains(s.Prompt, "consent"); ok {
-		login.WriteConsentPage(projectName, sessionID, state, w)
+	if slice.Contains(s.Prompt, "consent") || oidc.RequireConsent(s.Scopes) {
+		login.WriteConsentPage(projectName, sessionID, state, s.Scopes, login.NegotiateLocale(r, projectName, s.UILocales), w)
 		return
 	}
 
@@ -264,6 +334,13 @@ func ConsentHandler(w http.ResponseWriter, r *http.Request) {
 
 	switch sel {
 	case "yes":
+		s.Consented = true
+		if err = db.GetInst().LoginSessionUpdate(projectName, s); err != nil {
+			errors.Print(errors.Append(err, "Failed to update login session"))
+			errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+			return
+		}
+
 		req, err := redirectToCallback(w, r, projectName, s)
 		if err != nil {
 			if !errors.Contains(err, errSessionEnd) {
@@ -283,22 +360,349 @@ func ConsentHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// WebAuthnOptionsHandler returns options of navigator.credentials.get for the login session
+// If the user is not identified yet, the options are for the passwordless login by the discoverable credential.
+func WebAuthnOptionsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	sessionID := r.FormValue("login_session_id")
+
+	s, err := login.VerifySession(projectName, sessionID)
+	if err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to verify user login session"))
+		if errors.Contains(err, errors.ErrSessionExpired) {
+			errors.WriteToHTTP(w, errors.ErrSessionExpired, 0, "")
+		} else {
+			errors.WriteToHTTP(w, errors.ErrInvalidRequest, 0, "")
+		}
+		return
+	}
+
+	var usr *model.UserInfo
+	uv := webauthn.UserVerificationRequired
+	if s.UserID != "" {
+		usr, err = db.GetInst().UserGet(projectName, s.UserID)
+		if err != nil {
+			errors.Print(errors.Append(err, "Failed to get login user"))
+			errors.WriteToHTTP(w, errors.ErrServerError, 0, "")
+			return
+		}
+		// the user is already verified by the password
+		uv = webauthn.UserVerificationPreferred
+	}
+
+	opts := webauthn.NewRequestOptions(usr, webauthn.RPID(r), uv)
+	s.WebAuthnChallenge = opts.Challenge
+	if err = db.GetInst().LoginSessionUpdate(projectName, s); err != nil {
+		errors.Print(errors.Append(err, "Failed to update login session"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, "")
+		return
+	}
+
+	jwthttp.ResponseWrite(w, "WebAuthnOptionsHandler", opts)
+}
+
+// WebAuthnLoginHandler verifies the assertion of the authenticator
+// It is used as the second factor after the password, or as the passwordless login from the login page.
+func WebAuthnLoginHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+
+	// Get data form Form
+	if err := r.ParseForm(); err != nil {
+		logger.Info("Failed to parse form: %v", err)
+		errors.WriteToHTTP(w, errors.ErrInvalidRequest, 0, "")
+		return
+	}
+
+	state := r.Form.Get("state")
+	sessionID := r.Form.Get("login_session_id")
+
+	var err *errors.Error
+	defer func() {
+		msg := ""
+		if err != nil {
+			msg = err.Error()
+			// delete session if login failed
+			db.GetInst().LoginSessionDelete(projectName, sessionID)
+		}
+
+		if err = audit.GetInst().Save(projectName, time.Now(), "USER_LOGIN", r.Method, r.URL.String(), msg); err != nil {
+			errors.Print(errors.Append(err, "Failed to save audit event"))
+		}
+	}()
+
+	s, err := login.VerifySession(projectName, sessionID)
+	if err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to verify user login session"))
+		if errors.Contains(err, errors.ErrSessionExpired) {
+			err = errors.ErrSessionExpired
+		} else {
+			err = errors.ErrInvalidRequest
+		}
+		errors.WriteToHTTP(w, err, 0, state)
+		return
+	}
+
+	// the challenge can be used only once
+	challenge := s.WebAuthnChallenge
+	s.WebAuthnChallenge = ""
+	if err = db.GetInst().LoginSessionUpdate(projectName, s); err != nil {
+		errors.Print(errors.Append(err, "Failed to update login session"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+
+	passwordless := s.UserID == ""
+	locale := login.NegotiateLocale(r, projectName, s.UILocales)
+	writeRetryPage := func() {
+		if passwordless {
+			login.WriteUserLoginPage(projectName, sessionID, login.MsgWebAuthnFailed, state, locale, w)
+		} else {
+			login.WriteWebAuthnPage(projectName, sessionID, login.MsgWebAuthnFailed, state, locale, w)
+		}
+	}
+
+	var resp webauthn.AssertionResponse
+	if e := json.Unmarshal([]byte(r.Form.Get("credential")), &resp); e != nil {
+		logger.Info("Failed to parse WebAuthn credential: %v", e)
+		writeRetryPage()
+		return
+	}
+
+	var usr *model.UserInfo
+	if passwordless {
+		usr, err = webauthn.FindUser(projectName, &resp)
+		if err == nil {
+			err = login.CheckUserLocked(projectName, usr)
+		}
+	} else {
+		usr, err = db.GetInst().UserGet(projectName, s.UserID)
+	}
+	if err != nil {
+		if errors.Contains(err, webauthn.ErrVerifyFailed) || errors.Contains(err, login.ErrUserLocked) {
+			errors.PrintAsInfo(errors.Append(err, "Failed to find WebAuthn user"))
+			err = nil // do not delete session in defer function
+			writeRetryPage()
+			return
+		}
+		errors.Print(errors.Append(err, "Failed to get login user"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+
+	rpID := webauthn.RPID(r)
+	verified, err := webauthn.Verify(projectName, usr, challenge, &resp, token.GetExpectIssuer(r), rpID, passwordless)
+	if err != nil {
+		if errors.Contains(err, webauthn.ErrVerifyFailed) {
+			errors.PrintAsInfo(errors.Append(err, "Failed to verify WebAuthn assertion of user %s", usr.ID))
+			err = nil // do not delete session in defer function
+			writeRetryPage()
+			return
+		}
+		errors.Print(errors.Append(err, "Failed to verify WebAuthn assertion"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+
+	if passwordless {
+		if err = checkLoginRisk(r, projectName, usr, s); err != nil {
+			writeLoginRiskError(w, r, projectName, s, state, err)
+			return
+		}
+
+		s.UserID = usr.ID
+		s.LoginDate = time.Now()
+		s.AuthMethods = []string{}
+
+		// Decide scopes granted to the user
+		s.Scopes, err = oidc.GrantScopeNames(projectName, s.ClientID, s.UserID, s.Scopes)
+		if err != nil {
+			errors.Print(errors.Append(err, "Failed to grant scopes"))
+			errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+			return
+		}
+	}
+	s.AuthMethods = append(s.AuthMethods, model.AuthMethodWebAuthn)
+	if verified {
+		s.AuthMethods = append(s.AuthMethods, model.AuthMethodMFA)
+	}
+	if err = db.GetInst().LoginSessionUpdate(projectName, s); err != nil {
+		errors.Print(errors.Append(err, "Failed to update login session"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+
+	logger.Debug("Successfully verify user login by WebAuthn")
+
+	// the passwordless login requires another factor if the user verification is not done by the authenticator
+	if passwordless {
+		var prj *model.ProjectInfo
+		prj, err = db.GetInst().ProjectGet(projectName)
+		if err != nil {
+			errors.Print(errors.Append(err, "Failed to get project"))
+			errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+			return
+		}
+		if mfaRequired(prj, usr, s) || stepUpRequired(s) {
+			var written bool
+			written, err = writeSecondFactorPage(w, r, prj, usr, s, state, locale)
+			if err != nil {
+				errors.Print(errors.Append(err, "Failed to write second factor page"))
+				errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+				return
+			}
+			if written {
+				return
+			}
+		}
+	}
+
+	// Consent Page
+	if slice.Contains(s.Prompt, "consent") || oidc.RequireConsent(s.Scopes) {
+		login.WriteConsentPage(projectName, sessionID, state, s.Scopes, locale, w)
+		return
+	}
+
+	// Login Success
+	req, err := redirectToCallback(w, r, projectName, s)
+	if err != nil {
+		if !errors.Contains(err, errSessionEnd) {
+			errors.Print(err)
+			errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+			return
+		}
+	}
+	http.Redirect(w, req, req.URL.String(), http.StatusFound)
+}
+
+// writeSecondFactorPage writes the verify page of the second factor if it is needed
+// The factor which is already used in the login session is skipped, e.g. the email OTP after the magic link.
+// The second factor is also verified if the client requests the higher acr than the login, even if the MFA policy is off.
+// If the MFA policy requires the second factor but the user has no usable one, it writes the OTP enrollment page.
+func writeSecondFactorPage(w http.ResponseWriter, r *http.Request, prj *model.ProjectInfo, usr *model.UserInfo, s *model.LoginSession, state, locale string) (bool, *errors.Error) {
+	stepUp := stepUpRequired(s)
+	if !stepUp && (login.MFASatisfied(s.AuthMethods) || (!s.RiskMFARequired && !login.SecondFactorEnabled(prj, usr))) {
+		return false, nil
+	}
+
+	// the second factor is skipped in the device trusted by the user, except for the risky login and the step-up
+	if prj.MFAPolicy.TrustedDeviceLifeSpan > 0 && !s.RiskMFARequired && !stepUp {
+		err := sso.VerifyTrustedDevice(r, prj.Name, usr.ID, token.GetFullIssuer(r))
+		if err == nil {
+			s.AuthMethods = append(s.AuthMethods, model.AuthMethodTrustedDevice)
+			if err := db.GetInst().LoginSessionUpdate(prj.Name, s); err != nil {
+				return false, errors.Append(err, "Failed to update login session")
+			}
+			return false, nil
+		}
+		logger.Debug("The device is not trusted: %v", err)
+	}
+
+	switch secondFactor(prj, usr, s) {
+	case model.AuthMethodWebAuthn:
+		login.WriteWebAuthnPage(prj.Name, s.SessionID, "", state, locale, w)
+		return true, nil
+	case model.AuthMethodOTP:
+		login.WriteOTPVerifyPage(prj.Name, s.SessionID, state, locale, prj.MFAPolicy.TrustedDeviceLifeSpan > 0, w)
+		return true, nil
+	case model.AuthMethodEMail:
+		return true, sendMailOTP(w, prj, usr, s, state, locale)
+	case model.AuthMethodSMS:
+		return true, sendSMSOTP(w, prj, usr, s, state, locale)
+	}
+
+	if mfaRequired(prj, usr, s) {
+		return true, writeOTPEnrollPage(w, prj, usr, s, "", state, locale)
+	}
+	return false, nil
+}
+
+// stepUpRequired returns true if the login does not satisfy the acr values requested by the client
+func stepUpRequired(s *model.LoginSession) bool {
+	acrValues := append([]string{}, s.ACRValues...)
+	if claims, err := token.ParseClaimsRequest(s.Claims); err == nil {
+		acrValues = append(acrValues, claims.EssentialACRValues()...)
+	}
+	return !token.ACRSatisfied(token.ACRLevel(s.AuthMethods), acrValues)
+}
+
+// secondFactor returns the authentication method which the user can use as the second factor
+// It returns an empty string if the user has no usable factor.
+func secondFactor(prj *model.ProjectInfo, usr *model.UserInfo, s *model.LoginSession) string {
+	usable := func(method string) bool {
+		return !slice.Contains(s.AuthMethods, method)
+	}
+
+	if usable(model.AuthMethodWebAuthn) && len(usr.WebAuthnInfo.Credentials) > 0 {
+		return model.AuthMethodWebAuthn
+	}
+	if usable(model.AuthMethodOTP) && usr.OTPInfo.Enabled {
+		return model.AuthMethodOTP
+	}
+	if usable(model.AuthMethodEMail) && usr.EMailOTPInfo.Enabled && prj.MailConfig.EMailOTPEnabled && login.MailAvailable() {
+		return model.AuthMethodEMail
+	}
+	if usable(model.AuthMethodSMS) && usr.SMSOTPInfo.Enabled && prj.SMSConfig.OTPEnabled && login.SMSAvailable() {
+		return model.AuthMethodSMS
+	}
+	return ""
+}
+
 func redirectToCallback(w http.ResponseWriter, r *http.Request, projectName string, session *model.LoginSession) (*http.Request, *errors.Error) {
+	// the code and tokens are not issued until the user satisfies the MFA policy
+	prj, err := db.GetInst().ProjectGet(projectName)
+	if err != nil {
+		return nil, errors.Append(err, "Failed to get project")
+	}
+	usr, err := db.GetInst().UserGet(projectName, session.UserID)
+	if err != nil {
+		return nil, errors.Append(err, "Failed to get login user")
+	}
+	if err := login.CheckMFA(prj, usr, session.AuthMethods); err != nil {
+		return nil, err
+	}
+	if session.RiskMFARequired && !login.MFASatisfied(session.AuthMethods) {
+		return nil, errors.Append(login.ErrMFARequired, "Login of user %s requires MFA by the risk policy", usr.ID)
+	}
+
 	state := r.Form.Get("state")
+
+	// the user must change the temporary or expired password before finishing the login in any authentication method
+	if session.PasswordChangeRequired || secret.PasswordChangeRequired(usr, prj.PasswordPolicy, time.Now()) {
+		session.PasswordChangeRequired = true
+		if err := db.GetInst().LoginSessionUpdate(projectName, session); err != nil {
+			return nil, errors.Append(err, "Failed to update login session")
+		}
+		req, e := http.NewRequest("GET", login.ChangePasswordURL(projectName, session.SessionID, state), nil)
+		if e != nil {
+			return nil, errors.New("Internal server error", "Failed to create change password request: %v", e)
+		}
+		return req, nil
+	}
+
 	issuer := token.GetFullIssuer(r)
 
-	req, err := oidc.CreateLoggedInResponse(session, state, issuer)
+	// new login always starts a new SSO session, so the browser state is also changed
+	browserState := sso.NewBrowserState()
+	req, err := oidc.CreateLoggedInResponse(session, state, issuer, browserState)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := sso.SetSSOSessionToCookie(w, projectName, session.UserID, issuer, browserState); err != nil {
+		return nil, errors.Append(err, "Failed to set cookie")
+	}
+	login.RecordLogin(projectName, session.UserID, r)
+
 	if ok := slice.Contains(session.ResponseType, "code"); !ok && len(session.ResponseType) > 0 {
 		// delete session
 		return req, errSessionEnd
 	}
 
-	if err := sso.SetSSOSessionToCookie(w, projectName, session.UserID, issuer); err != nil {
-		return nil, errors.Append(err, "Failed to set cookie")
+	// save the authorization code and the granted scopes for the token request
+	if err := db.GetInst().LoginSessionUpdate(projectName, session); err != nil {
+		return nil, errors.Append(err, "Failed to update login session")
 	}
 
 	return req, nil
@@ -320,3 +724,27 @@ func renewSession(projectName string, oldSession *model.LoginSession, state stri
 	}
 	return sid, nil
 }
+
+// verifyOTPOrRecoveryCode verifies the TOTP code, or consumes the recovery code if the user enters it
+func verifyOTPOrRecoveryCode(r *http.Request, prj *model.ProjectInfo, user *model.UserInfo, code string) *errors.Error {
+	step, err := otp.Verify(time.Now(), &prj.TOTPConfig, user, code)
+	if err == nil {
+		return otp.Use(prj.Name, user.ID, step)
+	}
+	if !errors.Contains(err, otp.ErrVerifyFailed) || !otp.IsRecoveryCode(code) {
+		return err
+	}
+
+	remain, err := db.GetInst().OTPRecoveryCodeUse(prj.Name, user.ID, otp.HashRecoveryCode(code))
+	msg := ""
+	if err != nil {
+		msg = err.Error()
+	} else {
+		logger.Info("User %s used an OTP recovery code, %d codes remain", user.ID, remain)
+	}
+	if e := audit.GetInst().Save(prj.Name, time.Now(), "OTP_RECOVERY_CODE", r.Method, r.URL.String(), msg); e != nil {
+		errors.Print(errors.Append(e, "Failed to save audit event"))
+	}
+
+	return err
+}
@@ -0,0 +1,113 @@
+package authn
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sh-miyoshi/hekate/pkg/db"
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/oidc/token"
+	"github.com/sh-miyoshi/hekate/pkg/sso"
+	"github.com/stretchr/stew/slice"
+)
+
+func initTestDB(t *testing.T) {
+	if db.GetInst() != nil {
+		return
+	}
+	if err := db.InitDBManager("memory", ""); err != nil {
+		t.Fatalf("Failed to init db: %v", err)
+	}
+}
+
+func TestWriteSecondFactorPage(t *testing.T) {
+	initTestDB(t)
+
+	now := time.Now()
+	addProject := func(name string, policy model.MFAPolicy) *model.ProjectInfo {
+		prj := &model.ProjectInfo{
+			Name:      name,
+			CreatedAt: now,
+			TokenConfig: &model.TokenConfig{
+				AccessTokenLifeSpan:  1,
+				RefreshTokenLifeSpan: 1,
+				SigningAlgorithm:     "RS256",
+			},
+			MFAPolicy: policy,
+		}
+		if err := db.GetInst().ProjectAdd(prj); err != nil {
+			t.Fatalf("Failed to add project: %v", err)
+		}
+		return prj
+	}
+	addUser := func(prj *model.ProjectInfo) *model.UserInfo {
+		usr := &model.UserInfo{
+			ID:          uuid.New().String(),
+			ProjectName: prj.Name,
+			Name:        "user1",
+			CreatedAt:   now,
+			OTPInfo: model.OTPInfo{
+				ID:         uuid.New().String(),
+				PrivateKey: "JBSWY3DPEHPK3PXP",
+				Enabled:    true,
+			},
+		}
+		if err := db.GetInst().UserAdd(prj.Name, usr); err != nil {
+			t.Fatalf("Failed to add user: %v", err)
+		}
+		return usr
+	}
+	addSession := func(prj *model.ProjectInfo, usr *model.UserInfo, acrValues []string) *model.LoginSession {
+		s := &model.LoginSession{
+			SessionID:   uuid.New().String(),
+			ProjectName: prj.Name,
+			ExpiresDate: now.Add(time.Minute),
+			UserID:      usr.ID,
+			AuthMethods: []string{model.AuthMethodPassword},
+			ACRValues:   acrValues,
+		}
+		if err := db.GetInst().LoginSessionAdd(prj.Name, s); err != nil {
+			t.Fatalf("Failed to add login session: %v", err)
+		}
+		return s
+	}
+
+	// Test acr_values forces the second factor even if the MFA policy is off
+	prj := addProject("step-up-project", model.MFAPolicy{Mode: model.MFAModeOff})
+	usr := addUser(prj)
+	r := httptest.NewRequest("POST", "http://localhost/authapi/v1/project/"+prj.Name+"/authn/login", nil)
+
+	written, err := writeSecondFactorPage(httptest.NewRecorder(), r, prj, usr, addSession(prj, usr, nil), "", "en")
+	if err != nil || written {
+		t.Errorf("The second factor is verified in the MFA off project without acr_values: written %v, err %v", written, err)
+	}
+	written, err = writeSecondFactorPage(httptest.NewRecorder(), r, prj, usr, addSession(prj, usr, []string{token.ACRMultiFactor}), "", "en")
+	if err != nil || !written {
+		t.Errorf("The second factor is not verified for acr_values=2: written %v, err %v", written, err)
+	}
+
+	// Test the trusted device does not skip the second factor for the step-up
+	prj = addProject("trusted-device-project", model.MFAPolicy{Mode: model.MFAModeOptional, TrustedDeviceLifeSpan: 3600})
+	usr = addUser(prj)
+	r = httptest.NewRequest("POST", "http://localhost/authapi/v1/project/"+prj.Name+"/authn/login", nil)
+	rec := httptest.NewRecorder()
+	if err := sso.SetTrustedDeviceCookie(rec, prj, usr.ID, token.GetFullIssuer(r), "test-agent"); err != nil {
+		t.Fatalf("Failed to set trusted device cookie: %v", err)
+	}
+	for _, c := range rec.Result().Cookies() {
+		r.AddCookie(c)
+	}
+
+	s := addSession(prj, usr, nil)
+	written, err = writeSecondFactorPage(httptest.NewRecorder(), r, prj, usr, s, "", "en")
+	if err != nil || written || !slice.Contains(s.AuthMethods, model.AuthMethodTrustedDevice) {
+		t.Errorf("The second factor is not skipped in the trusted device: written %v, amr %v, err %v", written, s.AuthMethods, err)
+	}
+	s = addSession(prj, usr, []string{token.ACRMultiFactor})
+	written, err = writeSecondFactorPage(httptest.NewRecorder(), r, prj, usr, s, "", "en")
+	if err != nil || !written || slice.Contains(s.AuthMethods, model.AuthMethodTrustedDevice) {
+		t.Errorf("The trusted device skips the second factor for acr_values=2: written %v, amr %v, err %v", written, s.AuthMethods, err)
+	}
+}
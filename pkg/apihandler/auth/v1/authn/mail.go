@@ -0,0 +1,423 @@
+package authn
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sh-miyoshi/hekate/pkg/audit"
+	"github.com/sh-miyoshi/hekate/pkg/config"
+	"github.com/sh-miyoshi/hekate/pkg/db"
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/logger"
+	"github.com/sh-miyoshi/hekate/pkg/login"
+	"github.com/sh-miyoshi/hekate/pkg/oidc"
+	"github.com/stretchr/stew/slice"
+)
+
+// sendMailOTP sends the one-time code to the user, and writes the verify page
+func sendMailOTP(w http.ResponseWriter, prj *model.ProjectInfo, usr *model.UserInfo, s *model.LoginSession, state, locale string) *errors.Error {
+	errMsg := ""
+	if err := login.SendMailCode(prj, usr, &s.MailCode, model.CodePurposeOTP, "", locale); err != nil {
+		if !errors.Contains(err, login.ErrCodeSendLimited) {
+			return errors.Append(err, "Failed to send email OTP")
+		}
+		errors.PrintAsInfo(errors.Append(err, "Failed to send email OTP to user %s", usr.ID))
+		errMsg = login.MsgCodeSendLimited
+	}
+
+	if err := db.GetInst().LoginSessionUpdate(prj.Name, s); err != nil {
+		return errors.Append(err, "Failed to update login session")
+	}
+
+	login.WriteMailOTPVerifyPage(prj.Name, s.SessionID, errMsg, state, locale, w)
+	return nil
+}
+
+// MailOTPVerifyHandler verifies the one-time code sent by email
+func MailOTPVerifyHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+
+	// Get data form Form
+	if err := r.ParseForm(); err != nil {
+		logger.Info("Failed to parse form: %v", err)
+		errors.WriteToHTTP(w, errors.ErrInvalidRequest, 0, "")
+		return
+	}
+
+	state := r.Form.Get("state")
+	sessionID := r.Form.Get("login_session_id")
+
+	var err *errors.Error
+	defer func() {
+		if err != nil {
+			// delete session if login failed
+			db.GetInst().LoginSessionDelete(projectName, sessionID)
+		}
+	}()
+
+	s, err := login.VerifySession(projectName, sessionID)
+	if err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to verify user login session"))
+		if errors.Contains(err, errors.ErrSessionExpired) {
+			err = errors.ErrSessionExpired
+		} else {
+			err = errors.ErrInvalidRequest
+		}
+		errors.WriteToHTTP(w, err, 0, state)
+		return
+	}
+
+	if s.UserID == "" || s.MailCode.UserID != s.UserID {
+		err = errors.ErrInvalidRequest
+		errors.PrintAsInfo(errors.Append(err, "Email OTP is not sent to the login user"))
+		errors.WriteToHTTP(w, err, 0, state)
+		return
+	}
+
+	locale := login.NegotiateLocale(r, projectName, s.UILocales)
+	verifyErr := login.VerifyCode(&s.MailCode, model.CodePurposeOTP, r.Form.Get("code"))
+	// save the result because the code is used only once and the failure is counted
+	if err = db.GetInst().LoginSessionUpdate(projectName, s); err != nil {
+		errors.Print(errors.Append(err, "Failed to update login session"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+	if verifyErr != nil {
+		errors.PrintAsInfo(errors.Append(verifyErr, "Failed to verify email OTP of user %s", s.UserID))
+		login.WriteMailOTPVerifyPage(projectName, sessionID, login.MsgCodeInvalid, state, locale, w)
+		return
+	}
+
+	s.AuthMethods = append(s.AuthMethods, model.AuthMethodEMail)
+	if err = db.GetInst().LoginSessionUpdate(projectName, s); err != nil {
+		errors.Print(errors.Append(err, "Failed to update login session"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+
+	// Consent Page
+	if slice.Contains(s.Prompt, "consent") || oidc.RequireConsent(s.Scopes) {
+		login.WriteConsentPage(projectName, sessionID, state, s.Scopes, locale, w)
+		return
+	}
+
+	// Login Success
+	req, err := redirectToCallback(w, r, projectName, s)
+	if err != nil {
+		if !errors.Contains(err, errSessionEnd) {
+			errors.Print(err)
+			errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+			return
+		}
+	}
+	http.Redirect(w, req, req.URL.String(), http.StatusFound)
+}
+
+// MailOTPResendHandler sends the one-time code again
+func MailOTPResendHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+
+	// Get data form Form
+	if err := r.ParseForm(); err != nil {
+		logger.Info("Failed to parse form: %v", err)
+		errors.WriteToHTTP(w, errors.ErrInvalidRequest, 0, "")
+		return
+	}
+
+	state := r.Form.Get("state")
+	sessionID := r.Form.Get("login_session_id")
+
+	s, err := login.VerifySession(projectName, sessionID)
+	if err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to verify user login session"))
+		if errors.Contains(err, errors.ErrSessionExpired) {
+			errors.WriteToHTTP(w, errors.ErrSessionExpired, 0, state)
+		} else {
+			errors.WriteToHTTP(w, errors.ErrInvalidRequest, 0, state)
+		}
+		return
+	}
+
+	if s.UserID == "" || s.MailCode.UserID != s.UserID {
+		errors.PrintAsInfo(errors.Append(errors.ErrInvalidRequest, "Email OTP is not sent to the login user"))
+		errors.WriteToHTTP(w, errors.ErrInvalidRequest, 0, state)
+		return
+	}
+
+	prj, err := db.GetInst().ProjectGet(projectName)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get project"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+	usr, err := db.GetInst().UserGet(projectName, s.UserID)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get login user"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+
+	if err := sendMailOTP(w, prj, usr, s, state, login.NegotiateLocale(r, projectName, s.UILocales)); err != nil {
+		errors.Print(err)
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+}
+
+// MagicLinkSendHandler sends the login link to the user by email
+// It always returns the same page whether the user exists or not to prevent the user enumeration.
+func MagicLinkSendHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+
+	// Get data form Form
+	if err := r.ParseForm(); err != nil {
+		logger.Info("Failed to parse form: %v", err)
+		errors.WriteToHTTP(w, errors.ErrInvalidRequest, 0, "")
+		return
+	}
+
+	state := r.Form.Get("state")
+	sessionID := r.Form.Get("login_session_id")
+
+	s, err := login.VerifySession(projectName, sessionID)
+	if err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to verify user login session"))
+		if errors.Contains(err, errors.ErrSessionExpired) {
+			errors.WriteToHTTP(w, errors.ErrSessionExpired, 0, state)
+		} else {
+			errors.WriteToHTTP(w, errors.ErrInvalidRequest, 0, state)
+		}
+		return
+	}
+
+	prj, err := db.GetInst().ProjectGet(projectName)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get project"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+	if !prj.MailConfig.MagicLinkEnabled || !login.MagicLinkAvailable() {
+		errors.PrintAsInfo(errors.Append(errors.ErrInvalidRequest, "Magic link login is not enabled in project %s", projectName))
+		errors.WriteToHTTP(w, errors.ErrInvalidRequest, 0, state)
+		return
+	}
+
+	locale := login.NegotiateLocale(r, projectName, s.UILocales)
+	usr, err := findMagicLinkUser(projectName, r.Form.Get("username"))
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to find magic link user"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+	if usr == nil {
+		logger.Info("Magic link is requested for unknown user")
+		login.WriteMagicLinkPage(projectName, sessionID, "", state, locale, w)
+		return
+	}
+
+	q := url.Values{}
+	q.Set("login_session_id", sessionID)
+	if state != "" {
+		q.Set("state", state)
+	}
+	link := config.GetExternalServerAddr() + "/authapi/v1/project/" + projectName + "/authn/magiclink/verify?" + q.Encode()
+	if err := login.SendMailCode(prj, usr, &s.MailCode, model.CodePurposeMagicLink, link, locale); err != nil {
+		if errors.Contains(err, login.ErrCodeSendLimited) || errors.Contains(err, login.ErrNoMailAddress) {
+			errors.PrintAsInfo(errors.Append(err, "Failed to send magic link to user %s", usr.ID))
+			login.WriteMagicLinkPage(projectName, sessionID, "", state, locale, w)
+			return
+		}
+		errors.Print(errors.Append(err, "Failed to send magic link"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+
+	if err := db.GetInst().LoginSessionUpdate(projectName, s); err != nil {
+		errors.Print(errors.Append(err, "Failed to update login session"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+
+	login.WriteMagicLinkPage(projectName, sessionID, "", state, locale, w)
+}
+
+// MagicLinkConfirmHandler returns the page to confirm the login by the link
+func MagicLinkConfirmHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+
+	queries := r.URL.Query()
+	state := queries.Get("state")
+	sessionID := queries.Get("login_session_id")
+
+	s, err := login.VerifySession(projectName, sessionID)
+	if err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to verify user login session"))
+		if errors.Contains(err, errors.ErrSessionExpired) {
+			errors.WriteToHTTP(w, errors.ErrSessionExpired, 0, state)
+		} else {
+			errors.WriteToHTTP(w, errors.ErrInvalidRequest, 0, state)
+		}
+		return
+	}
+
+	token := queries.Get("token")
+	if token == "" {
+		errors.WriteToHTTP(w, errors.ErrInvalidRequest, 0, state)
+		return
+	}
+
+	login.WriteMagicLinkPage(projectName, sessionID, token, state, login.NegotiateLocale(r, projectName, s.UILocales), w)
+}
+
+// MagicLinkVerifyHandler verifies the token of the login link, and finishes the login
+func MagicLinkVerifyHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+
+	// Get data form Form
+	if err := r.ParseForm(); err != nil {
+		logger.Info("Failed to parse form: %v", err)
+		errors.WriteToHTTP(w, errors.ErrInvalidRequest, 0, "")
+		return
+	}
+
+	state := r.Form.Get("state")
+	sessionID := r.Form.Get("login_session_id")
+
+	var err *errors.Error
+	defer func() {
+		msg := ""
+		if err != nil {
+			msg = err.Error()
+			// delete session if login failed
+			db.GetInst().LoginSessionDelete(projectName, sessionID)
+		}
+
+		if err = audit.GetInst().Save(projectName, time.Now(), "USER_LOGIN", r.Method, r.URL.String(), msg); err != nil {
+			errors.Print(errors.Append(err, "Failed to save audit event"))
+		}
+	}()
+
+	s, err := login.VerifySession(projectName, sessionID)
+	if err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to verify user login session"))
+		if errors.Contains(err, errors.ErrSessionExpired) {
+			err = errors.ErrSessionExpired
+		} else {
+			err = errors.ErrInvalidRequest
+		}
+		errors.WriteToHTTP(w, err, 0, state)
+		return
+	}
+
+	// the link is used only once, so the session is deleted if it is wrong
+	userID := s.MailCode.UserID
+	if err = login.VerifyCode(&s.MailCode, model.CodePurposeMagicLink, r.Form.Get("token")); err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to verify magic link"))
+		errors.WriteToHTTP(w, errors.ErrInvalidRequest, 0, state)
+		return
+	}
+
+	usr, err := db.GetInst().UserGet(projectName, userID)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get magic link user"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+	if err = login.CheckUserLocked(projectName, usr); err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Magic link user %s is locked", usr.ID))
+		errors.WriteToHTTP(w, errors.ErrInvalidRequest, 0, state)
+		return
+	}
+
+	if err = checkLoginRisk(r, projectName, usr, s); err != nil {
+		writeLoginRiskError(w, r, projectName, s, state, err)
+		return
+	}
+
+	s.UserID = usr.ID
+	s.LoginDate = time.Now()
+	s.AuthMethods = []string{model.AuthMethodEMail}
+
+	// Decide scopes granted to the user
+	s.Scopes, err = oidc.GrantScopeNames(projectName, s.ClientID, s.UserID, s.Scopes)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to grant scopes"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+
+	if err = db.GetInst().LoginSessionUpdate(projectName, s); err != nil {
+		errors.Print(errors.Append(err, "Failed to update login session"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+
+	logger.Debug("Successfully verify user login by magic link")
+
+	// the link replaces the password, so the second factor is still required
+	locale := login.NegotiateLocale(r, projectName, s.UILocales)
+	prj, err := db.GetInst().ProjectGet(projectName)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get project"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+	written, err := writeSecondFactorPage(w, r, prj, usr, s, state, locale)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to write second factor page"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+	if written {
+		return
+	}
+
+	// Consent Page
+	if slice.Contains(s.Prompt, "consent") || oidc.RequireConsent(s.Scopes) {
+		login.WriteConsentPage(projectName, sessionID, state, s.Scopes, locale, w)
+		return
+	}
+
+	// Login Success
+	req, err := redirectToCallback(w, r, projectName, s)
+	if err != nil {
+		if !errors.Contains(err, errSessionEnd) {
+			errors.Print(err)
+			errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+			return
+		}
+	}
+	http.Redirect(w, req, req.URL.String(), http.StatusFound)
+}
+
+// findMagicLinkUser returns the user who has the name or the email address
+// It returns nil if the user is not found.
+func findMagicLinkUser(projectName, name string) (*model.UserInfo, *errors.Error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	users, err := db.GetInst().UserGetList(projectName, &model.UserFilter{Name: name})
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		users, err = db.GetInst().UserGetList(projectName, &model.UserFilter{EMail: name})
+		if err != nil {
+			return nil, err
+		}
+	}
+	// email address may not be unique
+	if len(users) != 1 {
+		return nil, nil
+	}
+	return users[0], nil
+}
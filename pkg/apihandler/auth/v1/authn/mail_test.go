@@ -0,0 +1,101 @@
+package authn
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/sh-miyoshi/hekate/pkg/config"
+	"github.com/sh-miyoshi/hekate/pkg/db"
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/mail"
+)
+
+func TestMagicLinkSendHandler(t *testing.T) {
+	dir, e := ioutil.TempDir("", "hekate-mail")
+	if e != nil {
+		t.Fatalf("Failed to create temp dir: %v", e)
+	}
+	defer os.RemoveAll(dir)
+	mailFile := filepath.Join(dir, "mail.log")
+
+	initTestDB(t)
+	if err := mail.Init(config.MailConfig{Type: "file", FilePath: mailFile, From: "noreply@example.com"}); err != nil {
+		t.Fatalf("Failed to init mail: %v", err)
+	}
+
+	now := time.Now()
+	prj := &model.ProjectInfo{
+		Name:      "test-project",
+		CreatedAt: now,
+		TokenConfig: &model.TokenConfig{
+			AccessTokenLifeSpan:  1,
+			RefreshTokenLifeSpan: 1,
+			SigningAlgorithm:     "RS256",
+		},
+		MailConfig: model.MailConfig{
+			MagicLinkEnabled: true,
+			CodeLifeSpan:     model.DefaultMailCodeLifeSpan,
+		},
+	}
+	if err := db.GetInst().ProjectAdd(prj); err != nil {
+		t.Fatalf("Failed to add project: %v", err)
+	}
+	usr := &model.UserInfo{
+		ID:          uuid.New().String(),
+		ProjectName: prj.Name,
+		Name:        "user1",
+		EMail:       "user1@example.com",
+		CreatedAt:   now,
+	}
+	if err := db.GetInst().UserAdd(prj.Name, usr); err != nil {
+		t.Fatalf("Failed to add user: %v", err)
+	}
+
+	send := func(sessionID string) {
+		body := strings.NewReader("login_session_id=" + sessionID + "&username=user1")
+		// the attacker sends the request to the server with the forged Host header
+		r := httptest.NewRequest("POST", "http://evil.example/authapi/v1/project/test-project/authn/magiclink", body)
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r = mux.SetURLVars(r, map[string]string{"projectName": prj.Name})
+		MagicLinkSendHandler(httptest.NewRecorder(), r)
+	}
+	newSession := func() string {
+		s := &model.LoginSession{
+			SessionID:   uuid.New().String(),
+			ProjectName: prj.Name,
+			ExpiresDate: now.Add(time.Minute),
+		}
+		if err := db.GetInst().LoginSessionAdd(prj.Name, s); err != nil {
+			t.Fatalf("Failed to add login session: %v", err)
+		}
+		return s.SessionID
+	}
+
+	// the magic link is not sent without the configured server address
+	os.Unsetenv("HEKATE_SERVER_ADDR")
+	send(newSession())
+	if _, e := os.Stat(mailFile); !os.IsNotExist(e) {
+		t.Errorf("Magic link is sent without HEKATE_SERVER_ADDR")
+	}
+
+	os.Setenv("HEKATE_SERVER_ADDR", "https://auth.example.com")
+	defer os.Unsetenv("HEKATE_SERVER_ADDR")
+	send(newSession())
+	buf, e := ioutil.ReadFile(mailFile)
+	if e != nil {
+		t.Fatalf("Failed to read sent mail: %v", e)
+	}
+	if !strings.Contains(string(buf), "https://auth.example.com/authapi/v1/project/test-project/authn/magiclink/verify?") {
+		t.Errorf("Magic link is not created from HEKATE_SERVER_ADDR: %s", string(buf))
+	}
+	if strings.Contains(string(buf), "evil.example") {
+		t.Errorf("Magic link is created from the Host header: %s", string(buf))
+	}
+}
@@ -0,0 +1,214 @@
+package authn
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sh-miyoshi/hekate/pkg/audit"
+	"github.com/sh-miyoshi/hekate/pkg/db"
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/logger"
+	"github.com/sh-miyoshi/hekate/pkg/login"
+	"github.com/sh-miyoshi/hekate/pkg/oidc"
+	"github.com/sh-miyoshi/hekate/pkg/otp"
+	"github.com/stretchr/stew/slice"
+)
+
+// writeOTPEnrollPage writes the page to register the authenticator app
+// The key which is registered but not verified yet is reused, so reloading the page does not change the QR code.
+func writeOTPEnrollPage(w http.ResponseWriter, prj *model.ProjectInfo, usr *model.UserInfo, s *model.LoginSession, errMsg, state, locale string) *errors.Error {
+	if usr.OTPInfo.PrivateKey == "" {
+		if _, err := otp.Register(prj, usr.ID, usr.Name); err != nil {
+			return errors.Append(err, "Failed to register OTP")
+		}
+		var err *errors.Error
+		usr, err = db.GetInst().UserGet(prj.Name, usr.ID)
+		if err != nil {
+			return errors.Append(err, "Failed to get registered user")
+		}
+	}
+
+	qrCode, err := otp.QRCode(prj, usr.Name, &usr.OTPInfo)
+	if err != nil {
+		return errors.Append(err, "Failed to create QR code")
+	}
+
+	login.WriteOTPEnrollPage(prj.Name, s.SessionID, qrCode, usr.OTPInfo.PrivateKey, nil, errMsg, state, locale, w)
+	return nil
+}
+
+// OTPEnrollHandler verifies the first code of the authenticator app registered at the login
+// It is allowed only if the MFA policy requires the second factor and the user has no usable one.
+func OTPEnrollHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+
+	// Get data form Form
+	if err := r.ParseForm(); err != nil {
+		logger.Info("Failed to parse form: %v", err)
+		errors.WriteToHTTP(w, errors.ErrInvalidRequest, 0, "")
+		return
+	}
+
+	state := r.Form.Get("state")
+	sessionID := r.Form.Get("login_session_id")
+
+	var err *errors.Error
+	defer func() {
+		if err != nil {
+			// delete session if login failed
+			db.GetInst().LoginSessionDelete(projectName, sessionID)
+		}
+	}()
+
+	s, err := login.VerifySession(projectName, sessionID)
+	if err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to verify user login session"))
+		if errors.Contains(err, errors.ErrSessionExpired) {
+			err = errors.ErrSessionExpired
+		} else {
+			err = errors.ErrInvalidRequest
+		}
+		errors.WriteToHTTP(w, err, 0, state)
+		return
+	}
+
+	prj, usr, err := getEnrollTarget(projectName, s)
+	if err != nil {
+		if errors.Contains(err, errors.ErrInvalidRequest) {
+			errors.PrintAsInfo(err)
+			errors.WriteToHTTP(w, errors.ErrInvalidRequest, 0, state)
+		} else {
+			errors.Print(err)
+			errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		}
+		return
+	}
+
+	// verify with a copy so that the user is not enabled if the code is wrong
+	tmp := *usr
+	tmp.OTPInfo.Enabled = true
+	locale := login.NegotiateLocale(r, projectName, s.UILocales)
+
+	step, err := otp.Verify(time.Now(), &prj.TOTPConfig, &tmp, r.Form.Get("code"))
+	if err != nil {
+		if errors.Contains(err, otp.ErrVerifyFailed) {
+			errors.PrintAsInfo(err)
+			if err = writeOTPEnrollPage(w, prj, usr, s, login.MsgCodeInvalid, state, locale); err != nil {
+				errors.Print(errors.Append(err, "Failed to write OTP enroll page"))
+				errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+			}
+			return
+		}
+		errors.Print(err)
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+
+	codes, hashes := otp.GenerateRecoveryCodes()
+	usr.OTPInfo.Enabled = true
+	usr.OTPInfo.LastUsedStep = step
+	usr.OTPInfo.RecoveryCodes = hashes
+	if err = db.GetInst().UserUpdate(projectName, usr); err != nil {
+		errors.Print(errors.Append(err, "Failed to enable OTP"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+	logger.Info("User %s enrolled OTP at the login", usr.ID)
+	if e := audit.GetInst().Save(projectName, time.Now(), "OTP_ENROLL", r.Method, r.URL.String(), ""); e != nil {
+		errors.Print(errors.Append(e, "Failed to save audit event"))
+	}
+
+	s.AuthMethods = append(s.AuthMethods, model.AuthMethodOTP)
+	if err = db.GetInst().LoginSessionUpdate(projectName, s); err != nil {
+		errors.Print(errors.Append(err, "Failed to update login session"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+
+	// show the recovery codes only once
+	login.WriteOTPEnrollPage(projectName, sessionID, "", "", codes, "", state, locale, w)
+}
+
+// OTPEnrollContinueHandler continues the login after the recovery codes are shown
+func OTPEnrollContinueHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+
+	// Get data form Form
+	if err := r.ParseForm(); err != nil {
+		logger.Info("Failed to parse form: %v", err)
+		errors.WriteToHTTP(w, errors.ErrInvalidRequest, 0, "")
+		return
+	}
+
+	state := r.Form.Get("state")
+	sessionID := r.Form.Get("login_session_id")
+
+	var err *errors.Error
+	defer func() {
+		if err != nil {
+			// delete session if login failed
+			db.GetInst().LoginSessionDelete(projectName, sessionID)
+		}
+	}()
+
+	s, err := login.VerifySession(projectName, sessionID)
+	if err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to verify user login session"))
+		if errors.Contains(err, errors.ErrSessionExpired) {
+			err = errors.ErrSessionExpired
+		} else {
+			err = errors.ErrInvalidRequest
+		}
+		errors.WriteToHTTP(w, err, 0, state)
+		return
+	}
+
+	if s.UserID == "" || !login.MFASatisfied(s.AuthMethods) {
+		err = errors.ErrInvalidRequest
+		logger.Info("Login session %s has not finished the OTP enrollment", sessionID)
+		errors.WriteToHTTP(w, err, 0, state)
+		return
+	}
+
+	// Consent Page
+	if slice.Contains(s.Prompt, "consent") || oidc.RequireConsent(s.Scopes) {
+		login.WriteConsentPage(projectName, sessionID, state, s.Scopes, login.NegotiateLocale(r, projectName, s.UILocales), w)
+		return
+	}
+
+	// Login Success
+	req, err := redirectToCallback(w, r, projectName, s)
+	if err != nil {
+		if !errors.Contains(err, errSessionEnd) {
+			errors.Print(err)
+			errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+			return
+		}
+	}
+	http.Redirect(w, req, req.URL.String(), http.StatusFound)
+}
+
+// getEnrollTarget returns the project and the user of the login session which must enroll OTP
+func getEnrollTarget(projectName string, s *model.LoginSession) (*model.ProjectInfo, *model.UserInfo, *errors.Error) {
+	if s.UserID == "" {
+		return nil, nil, errors.Append(errors.ErrInvalidRequest, "Login session %s has no user", s.SessionID)
+	}
+
+	prj, err := db.GetInst().ProjectGet(projectName)
+	if err != nil {
+		return nil, nil, errors.Append(err, "Failed to get project")
+	}
+	usr, err := db.GetInst().UserGet(projectName, s.UserID)
+	if err != nil {
+		return nil, nil, errors.Append(err, "Failed to get login user")
+	}
+
+	if login.MFASatisfied(s.AuthMethods) || !mfaRequired(prj, usr, s) || secondFactor(prj, usr, s) != "" {
+		return nil, nil, errors.Append(errors.ErrInvalidRequest, "User %s does not need to enroll OTP", usr.ID)
+	}
+	return prj, usr, nil
+}
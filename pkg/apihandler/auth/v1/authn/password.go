@@ -0,0 +1,122 @@
+package authn
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sh-miyoshi/hekate/pkg/audit"
+	"github.com/sh-miyoshi/hekate/pkg/db"
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/logger"
+	"github.com/sh-miyoshi/hekate/pkg/login"
+	"github.com/sh-miyoshi/hekate/pkg/secret"
+)
+
+// ChangePasswordPageHandler returns the page to change the password before finishing the login
+func ChangePasswordPageHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+
+	queries := r.URL.Query()
+	state := queries.Get("state")
+	sessionID := queries.Get("login_session_id")
+
+	s, err := verifyChangePasswordSession(projectName, sessionID)
+	if err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to verify user login session"))
+		if errors.Contains(err, errors.ErrSessionExpired) {
+			errors.WriteToHTTP(w, errors.ErrSessionExpired, 0, state)
+		} else {
+			errors.WriteToHTTP(w, errors.ErrInvalidRequest, 0, state)
+		}
+		return
+	}
+
+	login.WriteChangePasswordPage(projectName, sessionID, "", state, login.NegotiateLocale(r, projectName, s.UILocales), w)
+}
+
+// ChangePasswordHandler changes the password of the login user, and finishes the login
+func ChangePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+
+	// Get data form Form
+	if err := r.ParseForm(); err != nil {
+		logger.Info("Failed to parse form: %v", err)
+		errors.WriteToHTTP(w, errors.ErrInvalidRequest, 0, "")
+		return
+	}
+
+	state := r.Form.Get("state")
+	sessionID := r.Form.Get("login_session_id")
+
+	s, err := verifyChangePasswordSession(projectName, sessionID)
+	if err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to verify user login session"))
+		if errors.Contains(err, errors.ErrSessionExpired) {
+			errors.WriteToHTTP(w, errors.ErrSessionExpired, 0, state)
+		} else {
+			errors.WriteToHTTP(w, errors.ErrInvalidRequest, 0, state)
+		}
+		return
+	}
+	locale := login.NegotiateLocale(r, projectName, s.UILocales)
+
+	// the user can retry in the same session if the new password is not accepted
+	password := r.Form.Get("password")
+	if password != r.Form.Get("password_confirm") {
+		login.WriteChangePasswordPage(projectName, sessionID, login.MsgPasswordMismatch, state, locale, w)
+		return
+	}
+
+	err = db.GetInst().UserChangePassword(projectName, s.UserID, password, false)
+	if err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to change password of user %s", s.UserID))
+		if errors.Contains(err, secret.ErrPasswordPolicyFailed) {
+			login.WriteChangePasswordPage(projectName, sessionID, login.MsgPasswordPolicyFailed, state, locale, w)
+		} else if errors.Contains(err, secret.ErrPasswordReused) {
+			login.WriteChangePasswordPage(projectName, sessionID, login.MsgPasswordReused, state, locale, w)
+		} else {
+			errors.Print(errors.Append(err, "Failed to change user password"))
+			errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		}
+		return
+	}
+
+	if err := audit.GetInst().Save(projectName, time.Now(), "USER", r.Method, r.URL.String(), ""); err != nil {
+		errors.Print(errors.Append(err, "Failed to save audit event"))
+	}
+
+	s.PasswordChangeRequired = false
+	if err = db.GetInst().LoginSessionUpdate(projectName, s); err != nil {
+		errors.Print(errors.Append(err, "Failed to update login session"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+
+	// Login session finished, redirect to callback URL
+	req, err := redirectToCallback(w, r, projectName, s)
+	if err != nil {
+		if !errors.Contains(err, errSessionEnd) {
+			errors.Print(err)
+			errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+			return
+		}
+		db.GetInst().LoginSessionDelete(projectName, sessionID)
+	}
+
+	http.Redirect(w, req, req.URL.String(), http.StatusFound)
+}
+
+func verifyChangePasswordSession(projectName, sessionID string) (*model.LoginSession, *errors.Error) {
+	s, err := login.VerifySession(projectName, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if s.UserID == "" || !s.PasswordChangeRequired {
+		return nil, errors.New("Invalid request", "Login session %s does not require password change", sessionID)
+	}
+	return s, nil
+}
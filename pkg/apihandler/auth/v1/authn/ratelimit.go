@@ -0,0 +1,28 @@
+package authn
+
+import (
+	"net/http"
+
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/login"
+)
+
+// writeRateLimitError writes the response of the request which is blocked by the rate limit
+// The user can retry from the login page with a new login session after the block expires.
+func writeRateLimitError(w http.ResponseWriter, r *http.Request, projectName string, s *model.LoginSession, state string, err *errors.Error) {
+	if !errors.Contains(err, errors.ErrTooManyRequests) {
+		errors.Print(errors.Append(err, "Failed to check rate limit"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+
+	errors.PrintAsInfo(err)
+	lsID, err := renewSession(projectName, s, state)
+	if err != nil {
+		errors.Print(err)
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+	login.WriteUserLoginPage(projectName, lsID, login.MsgTooManyAttempts, state, login.NegotiateLocale(r, projectName, s.UILocales), w)
+}
@@ -0,0 +1,51 @@
+package authn
+
+import (
+	"net/http"
+
+	"github.com/sh-miyoshi/hekate/pkg/db"
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/login"
+)
+
+// checkLoginRisk evaluates the risk of the login after the first factor identifies the user
+// The second factor is required in the login session if the matched rule requires it.
+// It returns login.ErrLoginBlocked if the matched rule blocks the login.
+func checkLoginRisk(r *http.Request, projectName string, usr *model.UserInfo, s *model.LoginSession) *errors.Error {
+	prj, err := db.GetInst().ProjectGet(projectName)
+	if err != nil {
+		return errors.Append(err, "Failed to get project")
+	}
+
+	decision, err := login.CheckLoginRisk(prj, usr, r)
+	if err != nil {
+		return err
+	}
+	s.RiskMFARequired = decision.Action == model.RiskActionMFA
+	return nil
+}
+
+// writeLoginRiskError writes the response of the login which failed in the risk evaluation
+// The blocked user can retry from the login page with a new login session.
+func writeLoginRiskError(w http.ResponseWriter, r *http.Request, projectName string, s *model.LoginSession, state string, err *errors.Error) {
+	if !errors.Contains(err, login.ErrLoginBlocked) {
+		errors.Print(errors.Append(err, "Failed to evaluate login risk"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+
+	errors.PrintAsInfo(err)
+	lsID, err := renewSession(projectName, s, state)
+	if err != nil {
+		errors.Print(err)
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+	login.WriteUserLoginPage(projectName, lsID, login.MsgLoginBlocked, state, login.NegotiateLocale(r, projectName, s.UILocales), w)
+}
+
+// mfaRequired returns true if the login must use the second factor by the MFA policy or the risk evaluation
+func mfaRequired(prj *model.ProjectInfo, usr *model.UserInfo, s *model.LoginSession) bool {
+	return s.RiskMFARequired || login.MFARequired(prj, usr)
+}
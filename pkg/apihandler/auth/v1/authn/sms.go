@@ -0,0 +1,162 @@
+package authn
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sh-miyoshi/hekate/pkg/db"
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/logger"
+	"github.com/sh-miyoshi/hekate/pkg/login"
+	"github.com/sh-miyoshi/hekate/pkg/oidc"
+	"github.com/stretchr/stew/slice"
+)
+
+// sendSMSOTP sends the one-time code to the user's phone, and writes the verify page
+func sendSMSOTP(w http.ResponseWriter, prj *model.ProjectInfo, usr *model.UserInfo, s *model.LoginSession, state, locale string) *errors.Error {
+	errMsg := ""
+	if err := login.SendSMSCode(prj, usr, &s.SMSCode, model.CodePurposeOTP, locale); err != nil {
+		if !errors.Contains(err, login.ErrCodeSendLimited) {
+			return errors.Append(err, "Failed to send SMS OTP")
+		}
+		errors.PrintAsInfo(errors.Append(err, "Failed to send SMS OTP to user %s", usr.ID))
+		errMsg = login.MsgCodeSendLimited
+	}
+
+	if err := db.GetInst().LoginSessionUpdate(prj.Name, s); err != nil {
+		return errors.Append(err, "Failed to update login session")
+	}
+
+	login.WriteSMSOTPVerifyPage(prj.Name, s.SessionID, errMsg, state, locale, w)
+	return nil
+}
+
+// SMSOTPVerifyHandler verifies the one-time code sent by SMS
+func SMSOTPVerifyHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+
+	// Get data form Form
+	if err := r.ParseForm(); err != nil {
+		logger.Info("Failed to parse form: %v", err)
+		errors.WriteToHTTP(w, errors.ErrInvalidRequest, 0, "")
+		return
+	}
+
+	state := r.Form.Get("state")
+	sessionID := r.Form.Get("login_session_id")
+
+	var err *errors.Error
+	defer func() {
+		if err != nil {
+			// delete session if login failed
+			db.GetInst().LoginSessionDelete(projectName, sessionID)
+		}
+	}()
+
+	s, err := login.VerifySession(projectName, sessionID)
+	if err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to verify user login session"))
+		if errors.Contains(err, errors.ErrSessionExpired) {
+			err = errors.ErrSessionExpired
+		} else {
+			err = errors.ErrInvalidRequest
+		}
+		errors.WriteToHTTP(w, err, 0, state)
+		return
+	}
+
+	if s.UserID == "" || s.SMSCode.UserID != s.UserID {
+		err = errors.ErrInvalidRequest
+		errors.PrintAsInfo(errors.Append(err, "SMS OTP is not sent to the login user"))
+		errors.WriteToHTTP(w, err, 0, state)
+		return
+	}
+
+	locale := login.NegotiateLocale(r, projectName, s.UILocales)
+	verifyErr := login.VerifyCode(&s.SMSCode, model.CodePurposeOTP, r.Form.Get("code"))
+	if verifyErr == nil {
+		s.AuthMethods = append(s.AuthMethods, model.AuthMethodSMS)
+	}
+	// save the result because the code is used only once and the failure is counted
+	if err = db.GetInst().LoginSessionUpdate(projectName, s); err != nil {
+		errors.Print(errors.Append(err, "Failed to update login session"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+	if verifyErr != nil {
+		errors.PrintAsInfo(errors.Append(verifyErr, "Failed to verify SMS OTP of user %s", s.UserID))
+		login.WriteSMSOTPVerifyPage(projectName, sessionID, login.MsgCodeInvalid, state, locale, w)
+		return
+	}
+
+	// Consent Page
+	if slice.Contains(s.Prompt, "consent") || oidc.RequireConsent(s.Scopes) {
+		login.WriteConsentPage(projectName, sessionID, state, s.Scopes, locale, w)
+		return
+	}
+
+	// Login Success
+	req, err := redirectToCallback(w, r, projectName, s)
+	if err != nil {
+		if !errors.Contains(err, errSessionEnd) {
+			errors.Print(err)
+			errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+			return
+		}
+	}
+	http.Redirect(w, req, req.URL.String(), http.StatusFound)
+}
+
+// SMSOTPResendHandler sends the one-time code by SMS again
+func SMSOTPResendHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+
+	// Get data form Form
+	if err := r.ParseForm(); err != nil {
+		logger.Info("Failed to parse form: %v", err)
+		errors.WriteToHTTP(w, errors.ErrInvalidRequest, 0, "")
+		return
+	}
+
+	state := r.Form.Get("state")
+	sessionID := r.Form.Get("login_session_id")
+
+	s, err := login.VerifySession(projectName, sessionID)
+	if err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to verify user login session"))
+		if errors.Contains(err, errors.ErrSessionExpired) {
+			errors.WriteToHTTP(w, errors.ErrSessionExpired, 0, state)
+		} else {
+			errors.WriteToHTTP(w, errors.ErrInvalidRequest, 0, state)
+		}
+		return
+	}
+
+	if s.UserID == "" || s.SMSCode.UserID != s.UserID {
+		errors.PrintAsInfo(errors.Append(errors.ErrInvalidRequest, "SMS OTP is not sent to the login user"))
+		errors.WriteToHTTP(w, errors.ErrInvalidRequest, 0, state)
+		return
+	}
+
+	prj, err := db.GetInst().ProjectGet(projectName)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get project"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+	usr, err := db.GetInst().UserGet(projectName, s.UserID)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get login user"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+
+	if err := sendSMSOTP(w, prj, usr, s, state, login.NegotiateLocale(r, projectName, s.UILocales)); err != nil {
+		errors.Print(err)
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+}
@@ -2,6 +2,7 @@ package oauth
 
 import (
 	"net/http"
+	neturl "net/url"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,13 +16,14 @@ import (
 	"github.com/sh-miyoshi/hekate/pkg/logger"
 	"github.com/sh-miyoshi/hekate/pkg/login"
 	"github.com/sh-miyoshi/hekate/pkg/oidc"
-	"github.com/sh-miyoshi/hekate/pkg/util"
-	"github.com/stretchr/stew/slice"
+	"github.com/sh-miyoshi/hekate/pkg/ratelimit"
+	qrcode "github.com/skip2/go-qrcode"
 )
 
 const (
-	userCodeLength      = 8
+	userCodeMaxRetry    = 5
 	tokenReqIntervalSec = 5
+	qrCodeSize          = 256
 )
 
 // DeviceRegisterHandler ...
@@ -75,9 +77,14 @@ func DeviceRegisterHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	scope := r.Form.Get("scope")
-	if !slice.Contains(config.Get().SupportedScope, scope) {
-		errors.PrintAsInfo(errors.New("Invalid scope", "Invalid scope request: %s", scope))
-		errors.WriteToHTTP(w, errors.ErrRequestNotSupported, 0, "")
+	if err = oidc.ValidateScope(projectName, clientID, scope); err != nil {
+		if err.StatusCode() == 0 {
+			errors.Print(errors.Append(err, "Failed to validate scope"))
+			errors.WriteToHTTP(w, errors.ErrServerError, 0, "")
+		} else {
+			errors.PrintAsInfo(errors.Append(err, "Invalid scope request: %s", scope))
+			errors.WriteToHTTP(w, err, 0, "")
+		}
 		return
 	}
 
@@ -96,8 +103,14 @@ func DeviceRegisterHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	userCode, err := newUserCode(projectName)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to generate user code"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, "")
+		return
+	}
+
 	deviceCode := uuid.New().String()
-	userCode := util.RandomString(userCodeLength, util.CharTypeUpper)
 	expires := config.Get().LoginSessionExpiresIn
 
 	ent := &model.Device{
@@ -107,6 +120,8 @@ func DeviceRegisterHandler(w http.ResponseWriter, r *http.Request) {
 		CreatedAt:      time.Now(),
 		ExpiresIn:      int64(expires),
 		LoginSessionID: lsID,
+		ClientID:       clientID,
+		Interval:       tokenReqIntervalSec,
 	}
 
 	if err := db.GetInst().DeviceAdd(projectName, ent); err != nil {
@@ -115,13 +130,15 @@ func DeviceRegisterHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	displayCode := oidc.FormatUserCode(userCode)
 	url = config.GetServerAddr(r) + "/resource/project/" + projectName + "/devicelogin"
 	res := DeviceAuthorizationResponse{
-		DeviceCode:      deviceCode,
-		UserCode:        userCode,
-		VerificationURI: url,
-		ExpiresIn:       int(expires),
-		Interval:        tokenReqIntervalSec,
+		DeviceCode:              deviceCode,
+		UserCode:                displayCode,
+		VerificationURI:         url,
+		VerificationURIComplete: url + "?user_code=" + neturl.QueryEscape(displayCode),
+		ExpiresIn:               int(expires),
+		Interval:                tokenReqIntervalSec,
 	}
 
 	logger.Debug("Device Authorization Response: %v", res)
@@ -137,7 +154,13 @@ func DeviceLoginPageHandler(w http.ResponseWriter, r *http.Request) {
 	queries := r.URL.Query()
 	err := queries.Get("error")
 
-	login.WriteDeviceLoginPage(projectName, err, w)
+	// user code is set if the user comes from verification_uri_complete
+	userCode := ""
+	if c := oidc.NormalizeUserCode(queries.Get("user_code")); c != "" {
+		userCode = oidc.FormatUserCode(c)
+	}
+
+	login.WriteDeviceLoginPage(projectName, userCode, err, login.NegotiateLocale(r, projectName, nil), w)
 }
 
 // DeviceUserCodeVerifyHandler ...
@@ -161,19 +184,80 @@ func DeviceUserCodeVerifyHandler(w http.ResponseWriter, r *http.Request) {
 		errors.WriteToHTTP(w, errors.ErrInvalidRequestObject, 0, "")
 		return
 	}
-	userCode := r.Form.Get("code")
-	devices, err := db.GetInst().DeviceGetList(projectName, &model.DeviceFilter{UserCode: userCode})
-	if err != nil {
-		errors.Print(errors.Append(err, "Failed to get device"))
-		errors.WriteToHTTP(w, errors.ErrServerError, 0, "")
+	locale := login.NegotiateLocale(r, projectName, nil)
+
+	// The user code has low entropy, so the guess is limited per client address by the strict rule.
+	// The failures are also counted for the address across the login and the token request.
+	//   ref. https://tools.ietf.org/html/rfc8628#section-5.1
+	if err = ratelimit.GetInst().Check(ratelimit.UserCodeKey(projectName, r), ratelimit.IPKey(projectName, r)); err != nil {
+		if !errors.Contains(err, errors.ErrTooManyRequests) {
+			errors.Print(errors.Append(err, "Failed to check rate limit"))
+			errors.WriteToHTTP(w, errors.ErrServerError, 0, "")
+			return
+		}
+		errors.PrintAsInfo(err)
+		login.WriteDeviceLoginPage(projectName, "", login.MsgDeviceVerifyLocked, locale, w)
 		return
 	}
-	if len(devices) == 0 {
+
+	userCode := oidc.NormalizeUserCode(r.Form.Get("code"))
+	devices := []*model.Device{}
+	if oidc.ValidUserCodeFormat(userCode) {
+		devices, err = db.GetInst().DeviceGetList(projectName, &model.DeviceFilter{UserCode: userCode})
+		if err != nil {
+			errors.Print(errors.Append(err, "Failed to get device"))
+			errors.WriteToHTTP(w, errors.ErrServerError, 0, "")
+			return
+		}
+	}
+	if len(devices) == 0 || devices[0].Expired(time.Now()) {
 		logger.Info("No valid device for user code: %s", userCode)
-		login.WriteDeviceLoginPage(projectName, "The code is invalid", w)
+		ratelimit.GetInst().Failed(ratelimit.UserCodeKey(projectName, r), ratelimit.IPKey(projectName, r))
+		login.WriteDeviceLoginPage(projectName, "", login.MsgInvalidDeviceCode, locale, w)
 		return
 	}
 
 	// ok to verify user code, next is user authentication
-	login.WriteUserLoginPage(projectName, devices[0].LoginSessionID, "", "", w)
+	login.WriteUserLoginPage(projectName, devices[0].LoginSessionID, "", "", locale, w)
+}
+
+// DeviceQRCodeHandler returns QR code image of verification_uri_complete
+// The device can show it instead of the user code, and the user reads it with smartphone.
+func DeviceQRCodeHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+
+	userCode := oidc.NormalizeUserCode(r.URL.Query().Get("user_code"))
+	if !oidc.ValidUserCodeFormat(userCode) {
+		logger.Info("Invalid user code format: %s", userCode)
+		errors.WriteToHTTP(w, errors.ErrInvalidRequest, 0, "")
+		return
+	}
+
+	url := config.GetServerAddr(r) + "/resource/project/" + projectName + "/devicelogin?user_code=" + neturl.QueryEscape(oidc.FormatUserCode(userCode))
+	png, e := qrcode.Encode(url, qrcode.Medium, qrCodeSize)
+	if e != nil {
+		errors.Print(errors.New("QR code failed", "Failed to encode QR code: %v", e))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, "")
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(png)
+}
+
+// newUserCode returns a user code which is not used by other devices in the project
+func newUserCode(projectName string) (string, *errors.Error) {
+	for i := 0; i < userCodeMaxRetry; i++ {
+		code := oidc.GenerateUserCode()
+		devices, err := db.GetInst().DeviceGetList(projectName, &model.DeviceFilter{UserCode: code})
+		if err != nil {
+			return "", errors.Append(err, "Failed to get device list")
+		}
+		if len(devices) == 0 {
+			return code, nil
+		}
+	}
+	return "", errors.New("Internal Error", "Failed to generate unique user code in %d times", userCodeMaxRetry)
 }
@@ -0,0 +1,97 @@
+package oidc
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/sh-miyoshi/hekate/pkg/sso"
+)
+
+// checkSessionIframe is a page which is loaded by the RP in a hidden iframe
+// The RP posts "client_id session_state" to it, and it returns "changed", "unchanged" or "error".
+// It calculates the session_state again with the browser state in the cookie, so it works without server access.
+//   ref. https://openid.net/specs/openid-connect-session-1_0.html#OPiframe
+const checkSessionIframe = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>check session</title>
+</head>
+<body>
+<script>
+(function () {
+  var cookieName = "{{COOKIE_NAME}}";
+
+  function getBrowserState() {
+    var cookies = document.cookie.split(";");
+    for (var i = 0; i < cookies.length; i++) {
+      var c = cookies[i].trim();
+      if (c.indexOf(cookieName + "=") === 0) {
+        return decodeURIComponent(c.substring(cookieName.length + 1));
+      }
+    }
+    return "";
+  }
+
+  function sha256(text) {
+    var data = new TextEncoder().encode(text);
+    return window.crypto.subtle.digest("SHA-256", data).then(function (buf) {
+      var bytes = new Uint8Array(buf);
+      var res = "";
+      for (var i = 0; i < bytes.length; i++) {
+        res += ("0" + bytes[i].toString(16)).slice(-2);
+      }
+      return res;
+    });
+  }
+
+  window.addEventListener("message", function (e) {
+    if (typeof e.data !== "string") {
+      return;
+    }
+
+    var reply = function (msg) {
+      e.source.postMessage(msg, e.origin);
+    };
+
+    var params = e.data.split(" ");
+    if (params.length !== 2) {
+      reply("error");
+      return;
+    }
+    var clientID = params[0];
+    var sessionState = params[1];
+    var i = sessionState.lastIndexOf(".");
+    if (clientID === "" || i < 0) {
+      reply("error");
+      return;
+    }
+    var salt = sessionState.substring(i + 1);
+
+    var browserState = getBrowserState();
+    if (browserState === "") {
+      reply("changed");
+      return;
+    }
+
+    sha256([clientID, e.origin, browserState, salt].join(" ")).then(function (hash) {
+      reply(hash + "." + salt === sessionState ? "unchanged" : "changed");
+    }, function () {
+      reply("error");
+    });
+  }, false);
+})();
+</script>
+</body>
+</html>
+`
+
+// CheckSessionIframeHandler method return the OP iframe of OpenID Connect Session Management
+func CheckSessionIframeHandler(w http.ResponseWriter, r *http.Request) {
+	page := strings.Replace(checkSessionIframe, "{{COOKIE_NAME}}", sso.BrowserStateCookieName, 1)
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(page))
+}
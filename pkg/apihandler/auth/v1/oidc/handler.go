@@ -1,10 +1,14 @@
 package oidc
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/sh-miyoshi/hekate/pkg/audit"
 	"github.com/sh-miyoshi/hekate/pkg/config"
@@ -16,64 +20,96 @@ import (
 	"github.com/sh-miyoshi/hekate/pkg/login"
 	"github.com/sh-miyoshi/hekate/pkg/oidc"
 	"github.com/sh-miyoshi/hekate/pkg/oidc/authn"
+	"github.com/sh-miyoshi/hekate/pkg/oidc/ciba"
 	"github.com/sh-miyoshi/hekate/pkg/oidc/token"
 	"github.com/sh-miyoshi/hekate/pkg/sso"
 	"github.com/stretchr/stew/slice"
 )
 
+const (
+	backchannelPollIntervalSec = 5
+	bindingMessageMaxLength    = 64
+)
+
 // ConfigGetHandler method return a configuration of OpenID Connect
 func ConfigGetHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	projectName := vars["projectName"]
 
-	issuer := token.GetFullIssuer(r)
-	logger.Debug("Issuer: %s", issuer)
+	res, err := serverMetadata(r, projectName)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get server metadata"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, "")
+		return
+	}
 
-	prj, err := db.GetInst().ProjectGet(projectName)
+	jwthttp.ResponseWrite(w, "ConfigGetHandler", res)
+}
+
+// AuthorizationServerMetadataHandler method return a metadata of OAuth 2.0 Authorization Server
+//   ref. https://tools.ietf.org/html/rfc8414
+func AuthorizationServerMetadataHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+
+	res, err := serverMetadata(r, projectName)
 	if err != nil {
-		errors.Print(errors.Append(err, "Failed to get project info"))
+		errors.Print(errors.Append(err, "Failed to get server metadata"))
 		errors.WriteToHTTP(w, errors.ErrServerError, 0, "")
 		return
 	}
-	grantTypes := []string{}
-	for _, t := range prj.AllowGrantTypes {
-		grantTypes = append(grantTypes, string(t))
+
+	jwthttp.ResponseWrite(w, "AuthorizationServerMetadataHandler", res)
+}
+
+// WebFingerHandler method return the issuer of the project which the resource belongs to
+//   ref. https://openid.net/specs/openid-connect-discovery-1_0.html#IssuerDiscovery
+func WebFingerHandler(w http.ResponseWriter, r *http.Request) {
+	resource := r.URL.Query().Get("resource")
+	rels := r.URL.Query()["rel"]
+
+	projectName, err := oidc.WebFingerProject(resource, &config.Get().WebFinger)
+	if err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to resolve resource %s", resource))
+		if errors.Contains(err, oidc.ErrNoSuchWebFingerResource) {
+			errors.WriteToHTTP(w, err, http.StatusNotFound, "")
+		} else {
+			errors.WriteToHTTP(w, errors.ErrInvalidRequest, 0, "")
+		}
+		return
 	}
 
-	cfg := config.Get()
-	res := Config{
-		Issuer:                 issuer,
-		AuthorizationEndpoint:  issuer + "/openid-connect/auth",
-		TokenEndpoint:          issuer + "/openid-connect/token",
-		UserinfoEndpoint:       issuer + "/openid-connect/userinfo",
-		JwksURI:                issuer + "/openid-connect/certs",
-		ScopesSupported:        cfg.SupportedScope,
-		ResponseTypesSupported: cfg.SupportedResponseType,
-		SubjectTypesSupported:  []string{"public"},
-		IDTokenSigningAlgValuesSupported: []string{
-			"RS256",
-		},
-		ClaimsSupported: []string{
-			"iss",
-			"aud",
-			"sub",
-			"exp",
-			"jti",
-			"iat",
-			"nbf",
-		},
-		ResponseModesSupported: []string{
-			"query",
-			"fragment",
-		},
-		GrantTypesSupported: grantTypes,
-		TokenEndpointAuthMethodsSupported: []string{
-			"client_secret_basic",
-			"client_secret_post",
-		},
+	if _, err := db.GetInst().ProjectGet(projectName); err != nil {
+		if errors.Contains(err, model.ErrNoSuchProject) || errors.Contains(err, model.ErrProjectValidateFailed) {
+			errors.PrintAsInfo(errors.Append(err, "No such project %s for resource %s", projectName, resource))
+			errors.WriteToHTTP(w, errors.ErrProjectNotFound, 0, "")
+		} else {
+			errors.Print(errors.Append(err, "Failed to get project"))
+			errors.WriteToHTTP(w, errors.ErrServerError, 0, "")
+		}
+		return
+	}
+
+	res := WebFingerResponse{
+		Subject: resource,
+		Links:   []WebFingerLink{},
+	}
+	// rel parameter filters the link relations in the response
+	if len(rels) == 0 || slice.Contains(rels, oidc.IssuerRelation) {
+		res.Links = append(res.Links, WebFingerLink{
+			Rel:  oidc.IssuerRelation,
+			Href: token.GetProjectIssuer(r, projectName),
+		})
 	}
 
-	jwthttp.ResponseWrite(w, "ConfigGetHandler", &res)
+	w.Header().Add("Content-Type", "application/jrd+json")
+	w.Header().Add("Access-Control-Allow-Origin", "*")
+	if err := json.NewEncoder(w).Encode(&res); err != nil {
+		logger.Error("Failed to encode a response for WebFingerHandler: %+v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	logger.Info("WebFingerHandler method successfully finished")
 }
 
 // TokenHandler ...
@@ -135,6 +171,14 @@ func TokenHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// existence of client is already checked in oidc.ClientAuth
+	cli, err := db.GetInst().ClientGet(projectName, clientID)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get client"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, state)
+		return
+	}
+
 	var tkn *oidc.TokenResponse
 
 	if r.Form.Get("redirect_uri") != "" {
@@ -159,11 +203,16 @@ func TokenHandler(w http.ResponseWriter, r *http.Request) {
 		errors.WriteToHTTP(w, errors.ErrInvalidGrant, 0, state)
 		return
 	}
-	if ok := slice.Contains(project.AllowGrantTypes, gt); !ok {
+	if ok := slice.Contains(project.AllowGrantTypes, gt); !ok && len(cli.AllowGrantTypes) == 0 {
 		logger.Info("Grant Type %s is not in allowed list %v", gtStr, project.AllowGrantTypes)
 		errors.WriteToHTTP(w, errors.ErrUnsupportedGrantType, 0, state)
 		return
 	}
+	if ok := slice.Contains(cli.GrantTypes(project.AllowGrantTypes), gt); !ok {
+		logger.Info("Grant Type %s is not in allowed list %v of client %s", gtStr, cli.AllowGrantTypes, clientID)
+		errors.WriteToHTTP(w, errors.ErrUnauthorizedClient, 0, state)
+		return
+	}
 
 	switch gt {
 	case model.GrantTypeClientCredentials:
@@ -172,6 +221,18 @@ func TokenHandler(w http.ResponseWriter, r *http.Request) {
 		uname := r.Form.Get("username")
 		passwd := r.Form.Get("password")
 		tkn, err = authn.ReqAuthByPassword(project, uname, passwd, r)
+
+		if err != nil && errors.Contains(err, errors.ErrInvalidGrant) {
+			// the description tells the client why the grant is rejected, e.g. multi-factor authentication is required
+			errors.PrintAsInfo(errors.Append(err, "Failed to authenticate by password"))
+			errors.WriteToHTTP(w, err, 0, state)
+			return
+		}
+		if err != nil && errors.Contains(err, errors.ErrTooManyRequests) {
+			errors.PrintAsInfo(errors.Append(err, "Failed to authenticate by password"))
+			errors.WriteToHTTP(w, errors.ErrTooManyRequests, 0, state)
+			return
+		}
 	case model.GrantTypeRefreshToken:
 		refreshToken := r.Form.Get("refresh_token")
 		tkn, err = authn.ReqAuthByRefreshToken(project, clientID, refreshToken, r)
@@ -181,13 +242,63 @@ func TokenHandler(w http.ResponseWriter, r *http.Request) {
 			errors.WriteToHTTP(w, errors.ErrInvalidGrant, 0, state)
 			return
 		}
+		if err != nil && errors.Contains(err, errors.ErrInvalidGrant) {
+			errors.PrintAsInfo(errors.Append(err, "Failed to refresh token"))
+			errors.WriteToHTTP(w, errors.ErrInvalidGrant, 0, state)
+			return
+		}
 	case model.GrantTypeAuthorizationCode:
 		code := r.Form.Get("code")
 		codeVerifier := r.Form.Get("code_verifier")
 		tkn, err = authn.ReqAuthByCode(project, clientID, code, codeVerifier, r)
+
+		if err != nil && errors.Contains(err, errors.ErrInvalidGrant) {
+			errors.PrintAsInfo(errors.Append(err, "Failed to exchange code"))
+			errors.WriteToHTTP(w, errors.ErrInvalidGrant, 0, state)
+			return
+		}
 	case model.GrantTypeDevice:
 		deviceCode := r.Form.Get("device_code")
 		tkn, err = authn.ReqAuthByDeviceCode(project, clientID, deviceCode, r)
+
+		deviceErrs := []*errors.Error{
+			errors.ErrInvalidRequest,
+			errors.ErrInvalidGrant,
+			errors.ErrAuthorizationPending,
+			errors.ErrSlowDown,
+			errors.ErrExpiredToken,
+		}
+		for _, e := range deviceErrs {
+			if err != nil && errors.Contains(err, e) {
+				errors.PrintAsInfo(errors.Append(err, "Failed to get token by device_code"))
+				errors.WriteToHTTP(w, e, 0, state)
+				return
+			}
+		}
+	case model.GrantTypeCIBA:
+		authReqID := r.Form.Get("auth_req_id")
+		tkn, err = authn.ReqAuthByCIBA(project, clientID, authReqID, r)
+
+		cibaErrs := []*errors.Error{
+			errors.ErrInvalidGrant,
+			errors.ErrAuthorizationPending,
+			errors.ErrSlowDown,
+			errors.ErrExpiredToken,
+			errors.ErrAccessDenied,
+		}
+		for _, e := range cibaErrs {
+			if err != nil && errors.Contains(err, e) {
+				errors.PrintAsInfo(errors.Append(err, "Failed to get token by auth_req_id"))
+				errors.WriteToHTTP(w, e, 0, state)
+				return
+			}
+		}
+	}
+
+	if err != nil && errors.Contains(err, errors.ErrInvalidTarget) {
+		errors.PrintAsInfo(errors.Append(err, "Failed to verify resource"))
+		errors.WriteToHTTP(w, errors.ErrInvalidTarget, 0, state)
+		return
 	}
 
 	if err != nil {
@@ -208,6 +319,7 @@ func TokenHandler(w http.ResponseWriter, r *http.Request) {
 		RefreshToken:     tkn.RefreshToken,
 		RefreshExpiresIn: tkn.RefreshExpiresIn,
 		IDToken:          tkn.IDToken,
+		Scope:            tkn.Scope,
 	}
 
 	w.Header().Add("Cache-Control", "no-store")
@@ -287,14 +399,39 @@ func UserInfoHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res := &UserInfo{
-		Subject:  claims.Subject,
-		UserName: user.Name,
-		EMail:    user.EMail,
+	res, err := token.GetUserInfo(user, claims)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get userinfo claims"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, "")
+		return
 	}
 
 	w.Header().Add("Cache-Control", "no-store")
 	w.Header().Add("Pragma", "no-cache")
+
+	if claims.ClientID != "" {
+		cli, err := db.GetInst().ClientGet(projectName, claims.ClientID)
+		if err != nil {
+			errors.Print(errors.Append(err, "Failed to get client"))
+			errors.WriteToHTTP(w, errors.ErrServerError, 0, "")
+			return
+		}
+
+		if token.UserInfoJWTRequired(cli) {
+			tkn, err := token.GenerateUserInfoResponse(projectName, token.GetFullIssuer(r), res, cli)
+			if err != nil {
+				errors.Print(errors.Append(err, "Failed to generate userinfo response"))
+				errors.WriteToHTTP(w, errors.ErrServerError, 0, "")
+				return
+			}
+
+			w.Header().Add("Content-Type", "application/jwt")
+			w.Write([]byte(tkn))
+			logger.Info("UserInfoHandler method successfully finished")
+			return
+		}
+	}
+
 	jwthttp.ResponseWrite(w, "UserInfoHandler", res)
 }
 
@@ -344,6 +481,201 @@ func RevokeHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// BackchannelAuthHandler method starts Client Initiated Backchannel Authentication
+//   ref. https://openid.net/specs/openid-client-initiated-backchannel-authentication-core-1_0.html#rfc.section.7
+func BackchannelAuthHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+
+	var err *errors.Error
+	defer func() {
+		msg := ""
+		if err != nil {
+			msg = err.Error()
+		}
+		if err = audit.GetInst().Save(projectName, time.Now(), "CIBA", r.Method, r.URL.String(), msg); err != nil {
+			errors.Print(errors.Append(err, "Failed to save audit event"))
+		}
+	}()
+
+	if err := r.ParseForm(); err != nil {
+		logger.Info("Failed to parse form: %v", err)
+		errors.WriteToHTTP(w, errors.ErrInvalidRequestObject, 0, "")
+		return
+	}
+
+	logger.Debug("Form: %v", r.Form)
+
+	clientID := r.Form.Get("client_id")
+	clientSecret := r.Form.Get("client_secret")
+
+	if clientID == "" {
+		// maybe basic authentication
+		i, s, ok := r.BasicAuth()
+		if !ok {
+			logger.Info("Failed to get client ID from request, Request header: %v", r.Header)
+			errors.WriteToHTTP(w, errors.ErrInvalidClient, 0, "")
+			return
+		}
+		clientID = i
+		clientSecret = s
+	}
+
+	if err = oidc.ClientAuth(projectName, clientID, clientSecret); err != nil {
+		if errors.Contains(err, errors.ErrInvalidClient) {
+			errors.PrintAsInfo(errors.Append(err, "Failed to authenticate client %s", clientID))
+			errors.WriteToHTTP(w, errors.ErrInvalidClient, 0, "")
+		} else {
+			errors.Print(errors.Append(err, "Failed to authenticate client"))
+			errors.WriteToHTTP(w, errors.ErrServerError, 0, "")
+		}
+		return
+	}
+
+	project, err := db.GetInst().ProjectGet(projectName)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get project info"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, "")
+		return
+	}
+	cli, err := db.GetInst().ClientGet(projectName, clientID)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get client"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, "")
+		return
+	}
+	if !slice.Contains(cli.GrantTypes(project.AllowGrantTypes), model.GrantTypeCIBA) {
+		logger.Info("Client %s is not allowed to use CIBA grant", clientID)
+		errors.WriteToHTTP(w, errors.ErrUnauthorizedClient, 0, "")
+		return
+	}
+
+	scope := r.Form.Get("scope")
+	if !slice.Contains(strings.Split(scope, " "), "openid") {
+		logger.Info("Backchannel auth request does not have openid scope: %s", scope)
+		errors.WriteToHTTP(w, errors.ErrInvalidScope, 0, "")
+		return
+	}
+	if err = oidc.ValidateScope(projectName, clientID, scope); err != nil {
+		if err.StatusCode() == 0 {
+			errors.Print(errors.Append(err, "Failed to validate scope"))
+			errors.WriteToHTTP(w, errors.ErrServerError, 0, "")
+		} else {
+			errors.PrintAsInfo(errors.Append(err, "Invalid scope request: %s", scope))
+			errors.WriteToHTTP(w, err, 0, "")
+		}
+		return
+	}
+
+	notificationToken := r.Form.Get("client_notification_token")
+	if cli.BackchannelPingMode() && notificationToken == "" {
+		logger.Info("client_notification_token is required in ping mode")
+		errors.WriteToHTTP(w, errors.ErrInvalidRequest, 0, "")
+		return
+	}
+
+	bindingMessage := r.Form.Get("binding_message")
+	if len(bindingMessage) > bindingMessageMaxLength {
+		logger.Info("binding_message is too long: %d", len(bindingMessage))
+		errors.WriteToHTTP(w, errors.ErrInvalidBindingMessage, 0, "")
+		return
+	}
+
+	expires := int64(config.Get().LoginSessionExpiresIn)
+	if v := r.Form.Get("requested_expiry"); v != "" {
+		req, e := strconv.ParseInt(v, 10, 64)
+		if e != nil || req <= 0 {
+			logger.Info("Invalid requested_expiry: %s", v)
+			errors.WriteToHTTP(w, errors.ErrInvalidRequest, 0, "")
+			return
+		}
+		if req < expires {
+			expires = req
+		}
+	}
+
+	// identify the user by exactly one hint
+	//   login_hint_token is not supported
+	loginHint := r.Form.Get("login_hint")
+	idTokenHint := r.Form.Get("id_token_hint")
+	if (loginHint == "") == (idTokenHint == "") || r.Form.Get("login_hint_token") != "" {
+		logger.Info("Backchannel auth request requires exactly one of login_hint or id_token_hint")
+		errors.WriteToHTTP(w, errors.ErrInvalidRequest, 0, "")
+		return
+	}
+
+	userID := ""
+	if idTokenHint != "" {
+		var claims token.IDTokenClaims
+		if err = token.ValidateIDToken(&claims, idTokenHint, projectName, token.GetExpectIssuer(r)); err != nil {
+			errors.PrintAsInfo(errors.Append(err, "Failed to validate id_token_hint"))
+			errors.WriteToHTTP(w, errors.ErrInvalidRequest, 0, "")
+			return
+		}
+		userID = claims.Subject
+		if _, err = db.GetInst().UserGet(projectName, userID); err != nil {
+			if errors.Contains(err, model.ErrNoSuchUser) || errors.Contains(err, model.ErrUserValidateFailed) {
+				errors.PrintAsInfo(errors.Append(err, "No such user %s in id_token_hint", userID))
+				errors.WriteToHTTP(w, errors.ErrUnknownUserID, 0, "")
+			} else {
+				errors.Print(errors.Append(err, "Failed to get user"))
+				errors.WriteToHTTP(w, errors.ErrServerError, 0, "")
+			}
+			return
+		}
+	} else {
+		users, err := db.GetInst().UserGetList(projectName, &model.UserFilter{Name: loginHint})
+		if err != nil && !errors.Contains(err, model.ErrUserValidateFailed) {
+			errors.Print(errors.Append(err, "Failed to get user list"))
+			errors.WriteToHTTP(w, errors.ErrServerError, 0, "")
+			return
+		}
+		if len(users) == 0 {
+			logger.Info("No such user %s in login_hint", loginHint)
+			errors.WriteToHTTP(w, errors.ErrUnknownUserID, 0, "")
+			return
+		}
+		userID = users[0].ID
+	}
+
+	ent := &model.BackchannelAuth{
+		AuthReqID:               uuid.New().String(),
+		ProjectName:             projectName,
+		ClientID:                clientID,
+		UserID:                  userID,
+		Scopes:                  strings.Split(scope, " "),
+		BindingMessage:          bindingMessage,
+		ExpiresIn:               expires,
+		CreatedAt:               time.Now(),
+		Status:                  model.BackchannelAuthStatusPending,
+		Interval:                backchannelPollIntervalSec,
+		ClientNotificationToken: notificationToken,
+	}
+
+	if err = db.GetInst().BackchannelAuthAdd(projectName, ent); err != nil {
+		errors.Print(errors.Append(err, "Failed to add backchannel auth request"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, "")
+		return
+	}
+
+	if err = ciba.NotifyUser(ent); err != nil {
+		errors.Print(errors.Append(err, "Failed to notify user of backchannel auth request"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, "")
+		return
+	}
+
+	res := BackchannelAuthResponse{
+		AuthReqID: ent.AuthReqID,
+		ExpiresIn: ent.ExpiresIn,
+	}
+	if !cli.BackchannelPingMode() {
+		res.Interval = ent.Interval
+	}
+
+	w.Header().Add("Cache-Control", "no-store")
+	jwthttp.ResponseWrite(w, "BackchannelAuthHandler", &res)
+}
+
 func authHandler(w http.ResponseWriter, r *http.Request, projectName string, req url.Values) {
 	var err *errors.Error
 	defer func() {
@@ -385,6 +717,45 @@ func authHandler(w http.ResponseWriter, r *http.Request, projectName string, req
 		return
 	}
 
+	if err = oidc.ValidateClientRequest(projectName, authReq); err != nil {
+		if err.StatusCode() == 0 {
+			errors.Print(errors.Append(err, "Failed to validate request by client settings"))
+			errors.WriteToHTTP(w, errors.ErrServerError, 0, authReq.State)
+		} else {
+			errors.PrintAsInfo(errors.Append(err, "Failed to validate request by client settings"))
+			errors.RedirectWithOAuthError(w, err, r.Method, authReq.RedirectURI, authReq.State)
+		}
+		return
+	}
+
+	if err = oidc.ValidateScope(projectName, authReq.ClientID, authReq.Scope); err != nil {
+		if err.StatusCode() == 0 {
+			errors.Print(errors.Append(err, "Failed to validate scope"))
+			errors.WriteToHTTP(w, errors.ErrServerError, 0, authReq.State)
+		} else {
+			errors.PrintAsInfo(errors.Append(err, "Failed to validate scope %s", authReq.Scope))
+			errors.RedirectWithOAuthError(w, err, r.Method, authReq.RedirectURI, authReq.State)
+		}
+		return
+	}
+
+	if _, err = oidc.GetResources(projectName, authReq.Resources); err != nil {
+		if err.StatusCode() == 0 {
+			errors.Print(errors.Append(err, "Failed to validate resource"))
+			errors.WriteToHTTP(w, errors.ErrServerError, 0, authReq.State)
+		} else {
+			errors.PrintAsInfo(errors.Append(err, "Failed to validate resource %v", authReq.Resources))
+			errors.RedirectWithOAuthError(w, err, r.Method, authReq.RedirectURI, authReq.State)
+		}
+		return
+	}
+
+	// remember the locale selected by ui_locales for the following login pages
+	locale := login.NegotiateLocale(r, projectName, authReq.UILocales)
+	if len(authReq.UILocales) > 0 {
+		login.SetLocaleCookie(w, locale)
+	}
+
 	// if prompt contains login or select_account or consent
 	//   create login_session and return login page
 	// else
@@ -406,12 +777,13 @@ func authHandler(w http.ResponseWriter, r *http.Request, projectName string, req
 			return
 		}
 
-		login.WriteUserLoginPage(projectName, lsID, "", authReq.State, w)
+		login.WriteUserLoginPage(projectName, lsID, "", authReq.State, locale, w)
 		return
 	}
 
 	// get user id from id_token_hint or cookie
 	userID := ""
+	fromCookie := false
 	if authReq.IDTokenHint != "" {
 		var claims token.IDTokenClaims
 		if err = token.ValidateIDToken(&claims, authReq.IDTokenHint, projectName, tokenIssuer); err != nil {
@@ -421,16 +793,17 @@ func authHandler(w http.ResponseWriter, r *http.Request, projectName string, req
 		}
 		userID = claims.Subject
 	} else {
-		cookie, err := r.Cookie("HEKATE_LOGIN_SESSION")
+		cookie, err := sso.GetSSOSessionCookie(r)
 		if err != nil {
 			logger.Debug("Failed to get user id from cookie: %v", err)
 		} else {
 			userID, err = sso.GetLoginUserIDFromSSOSessionCookie(cookie, projectName)
+			fromCookie = true
 		}
 	}
 
 	if userID != "" {
-		req, err := sso.Handle(r.Method, projectName, userID, tokenIssuer, authReq)
+		req, err := sso.Handle(r.Method, projectName, userID, tokenIssuer, sso.GetBrowserState(r), authReq)
 		if err == nil {
 			http.Redirect(w, req, req.URL.String(), http.StatusFound)
 			return
@@ -440,6 +813,11 @@ func authHandler(w http.ResponseWriter, r *http.Request, projectName string, req
 			errors.RedirectWithOAuthError(w, errors.ErrServerError, r.Method, authReq.RedirectURI, authReq.State)
 			return
 		}
+
+		if fromCookie {
+			// the SSO session was already ended, so the RPs detect the change by the browser state
+			sso.DeleteSSOSessionCookie(w, token.GetFullIssuer(r))
+		}
 	}
 
 	if slice.Contains(authReq.Prompt, "none") {
@@ -457,5 +835,81 @@ func authHandler(w http.ResponseWriter, r *http.Request, projectName string, req
 	}
 
 	// Return login page
-	login.WriteUserLoginPage(projectName, lsID, "", authReq.State, w)
+	login.WriteUserLoginPage(projectName, lsID, "", authReq.State, locale, w)
+}
+
+// serverMetadata returns the metadata of the project
+// It is the single source of both OpenID Connect Discovery and OAuth 2.0 Authorization Server Metadata.
+func serverMetadata(r *http.Request, projectName string) (*Config, *errors.Error) {
+	issuer := token.GetFullIssuer(r)
+	logger.Debug("Issuer: %s", issuer)
+
+	prj, err := db.GetInst().ProjectGet(projectName)
+	if err != nil {
+		return nil, errors.Append(err, "Failed to get project info")
+	}
+	grantTypes := []string{}
+	for _, t := range prj.AllowGrantTypes {
+		grantTypes = append(grantTypes, string(t))
+	}
+
+	scopes, err := oidc.SupportedScopes(projectName, "")
+	if err != nil {
+		return nil, errors.Append(err, "Failed to get client scopes")
+	}
+
+	cfg := config.Get()
+	res := &Config{
+		Issuer:                            issuer,
+		AuthorizationEndpoint:             issuer + "/openid-connect/auth",
+		TokenEndpoint:                     issuer + "/openid-connect/token",
+		UserinfoEndpoint:                  issuer + "/openid-connect/userinfo",
+		JwksURI:                           issuer + "/openid-connect/certs",
+		RevocationEndpoint:                issuer + "/openid-connect/revoke",
+		BackchannelAuthenticationEndpoint: issuer + "/openid-connect/bc-authorize",
+		DeviceAuthorizationEndpoint:       issuer + "/oauth/device",
+		CheckSessionIframe:                issuer + "/openid-connect/checksession",
+		ScopesSupported:                   scopes,
+		ResponseTypesSupported:            cfg.SupportedResponseType,
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported: []string{
+			"RS256",
+		},
+		ClaimsSupported: append([]string{
+			"iss",
+			"aud",
+			"sub",
+			"exp",
+			"jti",
+			"iat",
+			"nbf",
+			"auth_time",
+			"nonce",
+			"acr",
+			"amr",
+			"azp",
+		}, token.SupportedUserClaims()...),
+		ACRValuesSupported:       token.SupportedACRValues(),
+		UILocalesSupported:       login.SupportedLocales(),
+		ClaimsParameterSupported: true,
+		ResponseModesSupported: []string{
+			"query",
+			"fragment",
+		},
+		GrantTypesSupported:                    grantTypes,
+		CodeChallengeMethodsSupported:          oidc.CodeChallengeMethods,
+		BackchannelTokenDeliveryModesSupported: model.BackchannelTokenDeliveryModes,
+		IDTokenEncryptionAlgValuesSupported:    model.JWEAlgorithms,
+		IDTokenEncryptionEncValuesSupported:    model.JWEEncryptions,
+		UserinfoSigningAlgValuesSupported:      model.UserinfoSigningAlgorithms,
+		UserinfoEncryptionAlgValuesSupported:   model.JWEAlgorithms,
+		UserinfoEncryptionEncValuesSupported:   model.JWEEncryptions,
+		TokenEndpointAuthMethodsSupported: []string{
+			"client_secret_basic",
+			"client_secret_post",
+		},
+	}
+	res.RevocationEndpointAuthMethodsSupported = res.TokenEndpointAuthMethodsSupported
+
+	return res, nil
 }
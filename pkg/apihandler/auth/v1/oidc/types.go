@@ -2,19 +2,35 @@ package oidc
 
 // Config ...
 type Config struct {
-	Issuer                            string   `json:"issuer"`
-	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
-	TokenEndpoint                     string   `json:"token_endpoint"`
-	UserinfoEndpoint                  string   `json:"userinfo_endpoint"`
-	JwksURI                           string   `json:"jwks_uri"`
-	ScopesSupported                   []string `json:"scopes_supported"`
-	ResponseTypesSupported            []string `json:"response_types_supported"`
-	SubjectTypesSupported             []string `json:"subject_types_supported"`
-	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
-	ClaimsSupported                   []string `json:"claims_supported"`
-	ResponseModesSupported            []string `json:"response_modes_supported"`
-	GrantTypesSupported               []string `json:"grant_types_supported"`
-	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	Issuer                                 string   `json:"issuer"`
+	AuthorizationEndpoint                  string   `json:"authorization_endpoint"`
+	TokenEndpoint                          string   `json:"token_endpoint"`
+	UserinfoEndpoint                       string   `json:"userinfo_endpoint"`
+	JwksURI                                string   `json:"jwks_uri"`
+	RevocationEndpoint                     string   `json:"revocation_endpoint"`
+	DeviceAuthorizationEndpoint            string   `json:"device_authorization_endpoint"`
+	CheckSessionIframe                     string   `json:"check_session_iframe"`
+	ScopesSupported                        []string `json:"scopes_supported"`
+	ResponseTypesSupported                 []string `json:"response_types_supported"`
+	SubjectTypesSupported                  []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported       []string `json:"id_token_signing_alg_values_supported"`
+	ClaimsSupported                        []string `json:"claims_supported"`
+	ClaimsParameterSupported               bool     `json:"claims_parameter_supported"`
+	ACRValuesSupported                     []string `json:"acr_values_supported"`
+	UILocalesSupported                     []string `json:"ui_locales_supported"`
+	ResponseModesSupported                 []string `json:"response_modes_supported"`
+	GrantTypesSupported                    []string `json:"grant_types_supported"`
+	TokenEndpointAuthMethodsSupported      []string `json:"token_endpoint_auth_methods_supported"`
+	RevocationEndpointAuthMethodsSupported []string `json:"revocation_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported          []string `json:"code_challenge_methods_supported"`
+	BackchannelAuthenticationEndpoint      string   `json:"backchannel_authentication_endpoint"`
+	BackchannelTokenDeliveryModesSupported []string `json:"backchannel_token_delivery_modes_supported"`
+	BackchannelUserCodeParameterSupported  bool     `json:"backchannel_user_code_parameter_supported"`
+	IDTokenEncryptionAlgValuesSupported    []string `json:"id_token_encryption_alg_values_supported"`
+	IDTokenEncryptionEncValuesSupported    []string `json:"id_token_encryption_enc_values_supported"`
+	UserinfoSigningAlgValuesSupported      []string `json:"userinfo_signing_alg_values_supported"`
+	UserinfoEncryptionAlgValuesSupported   []string `json:"userinfo_encryption_alg_values_supported"`
+	UserinfoEncryptionEncValuesSupported   []string `json:"userinfo_encryption_enc_values_supported"`
 }
 
 // TokenResponse ...
@@ -25,13 +41,14 @@ type TokenResponse struct {
 	RefreshToken     string `json:"refresh_token"`
 	RefreshExpiresIn uint   `json:"refresh_expires_in"`
 	IDToken          string `json:"id_token"`
+	Scope            string `json:"scope,omitempty"`
 }
 
-// UserInfo ...
-type UserInfo struct {
-	Subject  string `json:"sub"`
-	UserName string `json:"preferred_username"`
-	EMail    string `json:"email"`
+// BackchannelAuthResponse ...
+type BackchannelAuthResponse struct {
+	AuthReqID string `json:"auth_req_id"`
+	ExpiresIn int64  `json:"expires_in"`
+	Interval  int64  `json:"interval,omitempty"`
 }
 
 // ErrorResponse ...
@@ -40,3 +57,15 @@ type ErrorResponse struct {
 	Description string `json:"error_description"`
 	State       string `json:"state"`
 }
+
+// WebFingerLink ...
+type WebFingerLink struct {
+	Rel  string `json:"rel"`
+	Href string `json:"href"`
+}
+
+// WebFingerResponse ...
+type WebFingerResponse struct {
+	Subject string          `json:"subject"`
+	Links   []WebFingerLink `json:"links"`
+}
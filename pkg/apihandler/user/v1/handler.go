@@ -6,13 +6,18 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/sh-miyoshi/hekate/pkg/audit"
 	"github.com/sh-miyoshi/hekate/pkg/db"
 	"github.com/sh-miyoshi/hekate/pkg/db/model"
 	"github.com/sh-miyoshi/hekate/pkg/errors"
 	jwthttp "github.com/sh-miyoshi/hekate/pkg/http"
 	"github.com/sh-miyoshi/hekate/pkg/logger"
+	"github.com/sh-miyoshi/hekate/pkg/login"
+	"github.com/sh-miyoshi/hekate/pkg/oidc/ciba"
 	"github.com/sh-miyoshi/hekate/pkg/otp"
 	"github.com/sh-miyoshi/hekate/pkg/secret"
+	"github.com/sh-miyoshi/hekate/pkg/sms"
+	"github.com/sh-miyoshi/hekate/pkg/webauthn"
 )
 
 // GetHandler ...
@@ -41,6 +46,13 @@ func GetHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	sessions, err := db.GetInst().SessionGetList(projectName, &model.SessionFilter{UserID: user.ID})
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get session list"))
+		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
 	// Return Response
 	res := &GetResponse{
 		ID:        user.ID,
@@ -48,9 +60,22 @@ func GetHandler(w http.ResponseWriter, r *http.Request) {
 		EMail:     user.EMail,
 		CreatedAt: user.CreatedAt.Format(time.RFC3339),
 		OPTInfo: OTPInfo{
-			ID:      user.OTPInfo.ID,
-			Enabled: user.OTPInfo.Enabled,
+			ID:                     user.OTPInfo.ID,
+			Enabled:                user.OTPInfo.Enabled,
+			RecoveryCodesRemaining: len(user.OTPInfo.RecoveryCodes),
 		},
+		EMailOTPEnabled: user.EMailOTPInfo.Enabled,
+		PhoneNumber:     user.PhoneNumber,
+		SMSOTPEnabled:   user.SMSOTPInfo.Enabled,
+		Sessions:        []string{},
+		OfflineSessions: []string{},
+	}
+	for _, s := range sessions {
+		if s.Offline {
+			res.OfflineSessions = append(res.OfflineSessions, s.SessionID)
+		} else {
+			res.Sessions = append(res.Sessions, s.SessionID)
+		}
 	}
 	jwthttp.ResponseWrite(w, "GetHandler", res)
 }
@@ -77,7 +102,21 @@ func ChangePasswordHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = db.GetInst().UserChangePassword(projectName, userID, req.Password); err != nil {
+	if err = checkPasswordMinAge(projectName, userID); err != nil {
+		if errors.Contains(err, secret.ErrPasswordChangeTooEarly) {
+			errors.PrintAsInfo(errors.Append(err, "Password was changed recently"))
+			errors.WriteToHTTP(w, err, http.StatusBadRequest, "")
+		} else if errors.Contains(err, model.ErrNoSuchUser) || errors.Contains(err, model.ErrUserValidateFailed) {
+			logger.Info("No such user: %s", userID)
+			errors.WriteToHTTP(w, err, http.StatusNotFound, "")
+		} else {
+			errors.Print(errors.Append(err, "Failed to check password min age"))
+			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	if err = db.GetInst().UserChangePassword(projectName, userID, req.Password, false); err != nil {
 		if errors.Contains(err, model.ErrNoSuchUser) {
 			logger.Info("No such user: %s", userID)
 			errors.WriteToHTTP(w, err, http.StatusNotFound, "")
@@ -89,7 +128,7 @@ func ChangePasswordHandler(w http.ResponseWriter, r *http.Request) {
 				errors.PrintAsInfo(errors.Append(err, "Invalid password was specified"))
 				errors.WriteToHTTP(w, err, http.StatusBadRequest, "")
 			}
-		} else if errors.Contains(err, secret.ErrPasswordPolicyFailed) {
+		} else if errors.Contains(err, secret.ErrPasswordPolicyFailed) || errors.Contains(err, secret.ErrPasswordReused) {
 			errors.PrintAsInfo(errors.Append(err, "Invalid password was specified"))
 			errors.WriteToHTTP(w, err, http.StatusBadRequest, "")
 		} else {
@@ -146,7 +185,14 @@ func OTPGenerateHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	qrcode, err := otp.Register(projectName, userID, claims.UserName)
+	prj, err := db.GetInst().ProjectGet(projectName)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get project"))
+		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	qrcode, err := otp.Register(prj, userID, claims.UserName)
 	if err != nil {
 		errors.Print(errors.Append(err, "Failed to register OTP"))
 		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
@@ -183,6 +229,13 @@ func OTPVerifyHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	prj, err := db.GetInst().ProjectGet(projectName)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get project"))
+		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
 	user, err := db.GetInst().UserGet(projectName, userID)
 	if err != nil {
 		errors.Print(err)
@@ -190,30 +243,86 @@ func OTPVerifyHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !user.OTPInfo.Enabled {
-		user.OTPInfo.Enabled = true
-		logger.Debug("After enabled OTP: %+v", user.OTPInfo)
-		if err := db.GetInst().UserUpdate(projectName, user); err != nil {
+	// the first verification finishes the registration of the authenticator
+	// verify with a copy so that the user is not enabled if the code is wrong
+	enroll := !user.OTPInfo.Enabled
+	tmp := *user
+	tmp.OTPInfo.Enabled = true
+
+	step, err := otp.Verify(time.Now(), &prj.TOTPConfig, &tmp, req.UserCode)
+	if err == nil && !enroll {
+		err = otp.Use(projectName, userID, step)
+	}
+	if err != nil {
+		if errors.Contains(err, otp.ErrVerifyFailed) {
+			errors.PrintAsInfo(err)
+			errors.WriteToHTTP(w, err, http.StatusBadRequest, "")
+		} else {
 			errors.Print(err)
 			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
-			return
 		}
+		return
 	}
 
-	if err := otp.Verify(time.Now(), user, req.UserCode); err != nil {
-		if errors.Contains(err, otp.ErrVerifyFailed) {
+	if !enroll {
+		// Return 204 (No content) for success
+		w.WriteHeader(http.StatusNoContent)
+		logger.Info("OTPVerifyHandler method successfully finished")
+		return
+	}
+
+	codes, hashes := otp.GenerateRecoveryCodes()
+	user.OTPInfo.Enabled = true
+	user.OTPInfo.LastUsedStep = step
+	user.OTPInfo.RecoveryCodes = hashes
+	logger.Debug("After enabled OTP: %+v", user.OTPInfo)
+	if err := db.GetInst().UserUpdate(projectName, user); err != nil {
+		errors.Print(err)
+		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	// Return recovery codes only once
+	res := &OTPRecoveryCodesResponse{
+		RecoveryCodes: codes,
+	}
+	jwthttp.ResponseWrite(w, "OTPVerifyHandler", res)
+}
+
+// OTPRecoveryCodesHandler issues new recovery codes, and the old codes are invalidated
+func OTPRecoveryCodesHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	userID := vars["userID"]
+
+	// Authorize API Request
+	claims, err := jwthttp.ValidateAPIToken(r)
+	if err != nil || claims.Subject != userID {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	codes, hashes := otp.GenerateRecoveryCodes()
+	if err := db.GetInst().OTPRecoveryCodesSet(projectName, userID, hashes); err != nil {
+		if errors.Contains(err, model.ErrUserOTPNotEnabled) {
 			errors.PrintAsInfo(err)
 			errors.WriteToHTTP(w, err, http.StatusBadRequest, "")
 		} else {
-			errors.Print(err)
+			errors.Print(errors.Append(err, "Failed to set OTP recovery codes"))
 			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
 		}
 		return
 	}
 
-	// Return 204 (No content) for success
-	w.WriteHeader(http.StatusNoContent)
-	logger.Info("OTPVerifyHandler method successfully finished")
+	if err := audit.GetInst().Save(projectName, time.Now(), "OTP_RECOVERY_CODE", r.Method, r.URL.String(), ""); err != nil {
+		errors.Print(errors.Append(err, "Failed to save audit event"))
+	}
+
+	res := &OTPRecoveryCodesResponse{
+		RecoveryCodes: codes,
+	}
+	jwthttp.ResponseWrite(w, "OTPRecoveryCodesHandler", res)
 }
 
 // OTPDeleteHandler ...
@@ -241,6 +350,9 @@ func OTPDeleteHandler(w http.ResponseWriter, r *http.Request) {
 	user.OTPInfo.Enabled = false
 	user.OTPInfo.ID = ""
 	user.OTPInfo.PrivateKey = ""
+	user.OTPInfo.RecoveryCodes = nil
+	// trusted devices were registered by the removed second factor
+	user.TrustedDevices = nil
 
 	if err := db.GetInst().UserUpdate(projectName, user); err != nil {
 		errors.Print(err)
@@ -252,3 +364,646 @@ func OTPDeleteHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 	logger.Info("OTPDeleteHandler method successfully finished")
 }
+
+// EMailOTPSetupHandler sends the code to the user's email address to enable the email OTP
+func EMailOTPSetupHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	userID := vars["userID"]
+
+	// Authorize API Request
+	claims, err := jwthttp.ValidateAPIToken(r)
+	if err != nil || claims.Subject != userID {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	prj, err := db.GetInst().ProjectGet(projectName)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get project"))
+		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		return
+	}
+	if !prj.MailConfig.EMailOTPEnabled || !login.MailAvailable() {
+		err := errors.Append(errors.ErrInvalidRequest, "Email OTP is not enabled in project %s", projectName)
+		errors.PrintAsInfo(err)
+		errors.WriteToHTTP(w, err, 0, "")
+		return
+	}
+
+	user, err := db.GetInst().UserGet(projectName, userID)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get user"))
+		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	if err := login.SendMailCode(prj, user, &user.EMailOTPInfo.SetupCode, model.CodePurposeSetup, "", login.NegotiateLocale(r, projectName, []string{user.Locale})); err != nil {
+		if errors.Contains(err, login.ErrNoMailAddress) {
+			errors.PrintAsInfo(err)
+			errors.WriteToHTTP(w, err, http.StatusBadRequest, "")
+		} else if errors.Contains(err, login.ErrCodeSendLimited) {
+			errors.PrintAsInfo(err)
+			errors.WriteToHTTP(w, err, http.StatusTooManyRequests, "")
+		} else {
+			errors.Print(errors.Append(err, "Failed to send email OTP setup code"))
+			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	if err := db.GetInst().UserUpdate(projectName, user); err != nil {
+		errors.Print(errors.Append(err, "Failed to update user"))
+		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	// Return 204 (No content) for success
+	w.WriteHeader(http.StatusNoContent)
+	logger.Info("EMailOTPSetupHandler method successfully finished")
+}
+
+// EMailOTPVerifyHandler verifies the setup code, and enables the email OTP
+func EMailOTPVerifyHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	userID := vars["userID"]
+
+	// Authorize API Request
+	claims, err := jwthttp.ValidateAPIToken(r)
+	if err != nil || claims.Subject != userID {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	var req EMailOTPVerifyRequest
+	if e := json.NewDecoder(r.Body).Decode(&req); e != nil {
+		err := errors.Append(errors.ErrInvalidRequest, "Failed to decode email otp verify request: %v", e)
+		errors.PrintAsInfo(err)
+		errors.WriteToHTTP(w, err, 0, "")
+		return
+	}
+
+	user, err := db.GetInst().UserGet(projectName, userID)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get user"))
+		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	verifyErr := login.VerifyCode(&user.EMailOTPInfo.SetupCode, model.CodePurposeSetup, req.UserCode)
+	if verifyErr == nil {
+		// the user proved the ownership of the address
+		user.EMailOTPInfo.Enabled = true
+		user.EMailVerified = true
+	}
+	// save the failure count even if the code is wrong
+	if err := db.GetInst().UserUpdate(projectName, user); err != nil {
+		errors.Print(errors.Append(err, "Failed to update user"))
+		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		return
+	}
+	if verifyErr != nil {
+		errors.PrintAsInfo(verifyErr)
+		errors.WriteToHTTP(w, verifyErr, http.StatusBadRequest, "")
+		return
+	}
+
+	// Return 204 (No content) for success
+	w.WriteHeader(http.StatusNoContent)
+	logger.Info("EMailOTPVerifyHandler method successfully finished")
+}
+
+// EMailOTPDeleteHandler ...
+func EMailOTPDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	userID := vars["userID"]
+
+	// Authorize API Request
+	claims, err := jwthttp.ValidateAPIToken(r)
+	if err != nil || claims.Subject != userID {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	user, err := db.GetInst().UserGet(projectName, userID)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get user"))
+		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	user.EMailOTPInfo = model.EMailOTPInfo{}
+	if err := db.GetInst().UserUpdate(projectName, user); err != nil {
+		errors.Print(errors.Append(err, "Failed to update user"))
+		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	// Return 204 (No content) for success
+	w.WriteHeader(http.StatusNoContent)
+	logger.Info("EMailOTPDeleteHandler method successfully finished")
+}
+
+// SMSOTPSetupHandler registers the phone number, and sends the code to it to enable the SMS OTP
+func SMSOTPSetupHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	userID := vars["userID"]
+
+	// Authorize API Request
+	claims, err := jwthttp.ValidateAPIToken(r)
+	if err != nil || claims.Subject != userID {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	var req SMSOTPSetupRequest
+	if e := json.NewDecoder(r.Body).Decode(&req); e != nil {
+		err := errors.Append(errors.ErrInvalidRequest, "Failed to decode sms otp setup request: %v", e)
+		errors.PrintAsInfo(err)
+		errors.WriteToHTTP(w, err, 0, "")
+		return
+	}
+	if !sms.ValidPhoneNumber(req.PhoneNumber) {
+		err := errors.Append(errors.ErrInvalidRequest, "Phone number %s is not E.164 format", req.PhoneNumber)
+		errors.PrintAsInfo(err)
+		errors.WriteToHTTP(w, err, 0, "")
+		return
+	}
+
+	prj, err := db.GetInst().ProjectGet(projectName)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get project"))
+		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		return
+	}
+	if !prj.SMSConfig.OTPEnabled || !login.SMSAvailable() {
+		err := errors.Append(errors.ErrInvalidRequest, "SMS OTP is not enabled in project %s", projectName)
+		errors.PrintAsInfo(err)
+		errors.WriteToHTTP(w, err, 0, "")
+		return
+	}
+
+	user, err := db.GetInst().UserGet(projectName, userID)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get user"))
+		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	if user.PhoneNumber != req.PhoneNumber {
+		// the new number is not verified yet
+		user.PhoneNumber = req.PhoneNumber
+		user.PhoneNumberVerified = false
+		user.SMSOTPInfo = model.SMSOTPInfo{}
+	}
+
+	sendErr := login.SendSMSCode(prj, user, &user.SMSOTPInfo.SetupCode, model.CodePurposeSetup, login.NegotiateLocale(r, projectName, []string{user.Locale}))
+	if sendErr != nil && !errors.Contains(sendErr, login.ErrCodeSendLimited) {
+		errors.Print(errors.Append(sendErr, "Failed to send SMS OTP setup code"))
+		errors.WriteToHTTP(w, sendErr, http.StatusInternalServerError, "")
+		return
+	}
+
+	// save the phone number even if the code is not sent
+	if err := db.GetInst().UserUpdate(projectName, user); err != nil {
+		errors.Print(errors.Append(err, "Failed to update user"))
+		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		return
+	}
+	if sendErr != nil {
+		errors.PrintAsInfo(sendErr)
+		errors.WriteToHTTP(w, sendErr, http.StatusTooManyRequests, "")
+		return
+	}
+
+	// Return 204 (No content) for success
+	w.WriteHeader(http.StatusNoContent)
+	logger.Info("SMSOTPSetupHandler method successfully finished")
+}
+
+// SMSOTPVerifyHandler verifies the setup code, and enables the SMS OTP
+func SMSOTPVerifyHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	userID := vars["userID"]
+
+	// Authorize API Request
+	claims, err := jwthttp.ValidateAPIToken(r)
+	if err != nil || claims.Subject != userID {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	var req SMSOTPVerifyRequest
+	if e := json.NewDecoder(r.Body).Decode(&req); e != nil {
+		err := errors.Append(errors.ErrInvalidRequest, "Failed to decode sms otp verify request: %v", e)
+		errors.PrintAsInfo(err)
+		errors.WriteToHTTP(w, err, 0, "")
+		return
+	}
+
+	user, err := db.GetInst().UserGet(projectName, userID)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get user"))
+		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	verifyErr := login.VerifyCode(&user.SMSOTPInfo.SetupCode, model.CodePurposeSetup, req.UserCode)
+	if verifyErr == nil {
+		// the user proved the ownership of the phone
+		user.SMSOTPInfo.Enabled = true
+		user.PhoneNumberVerified = true
+	}
+	// save the failure count even if the code is wrong
+	if err := db.GetInst().UserUpdate(projectName, user); err != nil {
+		errors.Print(errors.Append(err, "Failed to update user"))
+		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		return
+	}
+	if verifyErr != nil {
+		errors.PrintAsInfo(verifyErr)
+		errors.WriteToHTTP(w, verifyErr, http.StatusBadRequest, "")
+		return
+	}
+
+	// Return 204 (No content) for success
+	w.WriteHeader(http.StatusNoContent)
+	logger.Info("SMSOTPVerifyHandler method successfully finished")
+}
+
+// SMSOTPDeleteHandler disables the SMS OTP
+// The phone number is kept because it is also a profile of the user.
+func SMSOTPDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	userID := vars["userID"]
+
+	// Authorize API Request
+	claims, err := jwthttp.ValidateAPIToken(r)
+	if err != nil || claims.Subject != userID {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	user, err := db.GetInst().UserGet(projectName, userID)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get user"))
+		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	user.SMSOTPInfo = model.SMSOTPInfo{}
+	if err := db.GetInst().UserUpdate(projectName, user); err != nil {
+		errors.Print(errors.Append(err, "Failed to update user"))
+		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	// Return 204 (No content) for success
+	w.WriteHeader(http.StatusNoContent)
+	logger.Info("SMSOTPDeleteHandler method successfully finished")
+}
+
+// WebAuthnRegisterHandler returns options of navigator.credentials.create to register a new credential
+func WebAuthnRegisterHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	userID := vars["userID"]
+
+	// Authorize API Request
+	claims, err := jwthttp.ValidateAPIToken(r)
+	if err != nil || claims.Subject != userID {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	prj, err := db.GetInst().ProjectGet(projectName)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get project"))
+		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	user, err := db.GetInst().UserGet(projectName, userID)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get user"))
+		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	res, err := webauthn.BeginRegistration(prj, user, webauthn.RPID(r))
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to start WebAuthn registration"))
+		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	jwthttp.ResponseWrite(w, "WebAuthnRegisterHandler", res)
+}
+
+// WebAuthnRegisterVerifyHandler verifies the response of navigator.credentials.create, and registers the credential
+func WebAuthnRegisterVerifyHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	userID := vars["userID"]
+
+	// Authorize API Request
+	claims, err := jwthttp.ValidateAPIToken(r)
+	if err != nil || claims.Subject != userID {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	var req WebAuthnRegisterVerifyRequest
+	if e := json.NewDecoder(r.Body).Decode(&req); e != nil {
+		err := errors.Append(errors.ErrInvalidRequest, "Failed to decode WebAuthn register verify request: %v", e)
+		errors.PrintAsInfo(err)
+		errors.WriteToHTTP(w, err, 0, "")
+		return
+	}
+
+	prj, err := db.GetInst().ProjectGet(projectName)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get project"))
+		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	user, err := db.GetInst().UserGet(projectName, userID)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get user"))
+		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	rpID := webauthn.RPID(r)
+	cred, err := webauthn.FinishRegistration(prj, user, req.Name, &req.Credential, webauthn.ExpectedOrigin(r, rpID), rpID)
+	if err != nil {
+		if errors.Contains(err, webauthn.ErrVerifyFailed) || errors.Contains(err, webauthn.ErrRegistrationNotStarted) ||
+			errors.Contains(err, model.ErrWebAuthnCredentialValidateFailed) {
+			errors.PrintAsInfo(errors.Append(err, "Failed to verify WebAuthn registration"))
+			errors.WriteToHTTP(w, err, http.StatusBadRequest, "")
+		} else if errors.Contains(err, model.ErrWebAuthnCredentialAlreadyExists) {
+			errors.PrintAsInfo(errors.Append(err, "WebAuthn credential is already registered"))
+			errors.WriteToHTTP(w, err, http.StatusConflict, "")
+		} else {
+			errors.Print(errors.Append(err, "Failed to register WebAuthn credential"))
+			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	res := newWebAuthnCredential(cred)
+	jwthttp.ResponseWrite(w, "WebAuthnRegisterVerifyHandler", &res)
+}
+
+// WebAuthnGetListHandler ...
+func WebAuthnGetListHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	userID := vars["userID"]
+
+	// Authorize API Request
+	claims, err := jwthttp.ValidateAPIToken(r)
+	if err != nil || claims.Subject != userID {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	user, err := db.GetInst().UserGet(projectName, userID)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get user"))
+		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	res := []WebAuthnCredential{}
+	for i := range user.WebAuthnInfo.Credentials {
+		res = append(res, newWebAuthnCredential(&user.WebAuthnInfo.Credentials[i]))
+	}
+	jwthttp.ResponseWrite(w, "WebAuthnGetListHandler", &res)
+}
+
+// WebAuthnDeleteHandler ...
+func WebAuthnDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	userID := vars["userID"]
+	credentialID := vars["credentialID"]
+
+	// Authorize API Request
+	claims, err := jwthttp.ValidateAPIToken(r)
+	if err != nil || claims.Subject != userID {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	if err := db.GetInst().WebAuthnCredentialDelete(projectName, userID, credentialID); err != nil {
+		if errors.Contains(err, model.ErrNoSuchWebAuthnCredential) {
+			errors.PrintAsInfo(errors.Append(err, "WebAuthn credential %s is not found", credentialID))
+			errors.WriteToHTTP(w, err, http.StatusNotFound, "")
+		} else {
+			errors.Print(errors.Append(err, "Failed to delete WebAuthn credential"))
+			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	// Return 204 (No content) for success
+	w.WriteHeader(http.StatusNoContent)
+	logger.Info("WebAuthnDeleteHandler method successfully finished")
+}
+
+// TrustedDeviceGetListHandler returns the browsers which skip the second factor
+func TrustedDeviceGetListHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	userID := vars["userID"]
+
+	// Authorize API Request
+	claims, err := jwthttp.ValidateAPIToken(r)
+	if err != nil || claims.Subject != userID {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	user, err := db.GetInst().UserGet(projectName, userID)
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get user"))
+		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	res := []TrustedDevice{}
+	now := time.Now()
+	for i := range user.TrustedDevices {
+		if user.TrustedDevices[i].ExpiresAt.After(now) {
+			res = append(res, newTrustedDevice(&user.TrustedDevices[i]))
+		}
+	}
+	jwthttp.ResponseWrite(w, "TrustedDeviceGetListHandler", &res)
+}
+
+// TrustedDeviceDeleteHandler revokes the trusted device
+func TrustedDeviceDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	userID := vars["userID"]
+	deviceID := vars["deviceID"]
+
+	// Authorize API Request
+	claims, err := jwthttp.ValidateAPIToken(r)
+	if err != nil || claims.Subject != userID {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	if err := db.GetInst().TrustedDeviceDelete(projectName, userID, deviceID); err != nil {
+		if errors.Contains(err, model.ErrNoSuchTrustedDevice) {
+			errors.PrintAsInfo(errors.Append(err, "Trusted device %s is not found", deviceID))
+			errors.WriteToHTTP(w, err, http.StatusNotFound, "")
+		} else {
+			errors.Print(errors.Append(err, "Failed to delete trusted device"))
+			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	// Return 204 (No content) for success
+	w.WriteHeader(http.StatusNoContent)
+	logger.Info("TrustedDeviceDeleteHandler method successfully finished")
+}
+
+// BackchannelAuthGetListHandler returns pending backchannel authentication requests for the user
+func BackchannelAuthGetListHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	userID := vars["userID"]
+
+	// Authorize API Request
+	claims, err := jwthttp.ValidateAPIToken(r)
+	if err != nil || claims.Subject != userID {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	reqs, err := db.GetInst().BackchannelAuthGetList(projectName, &model.BackchannelAuthFilter{UserID: userID})
+	if err != nil {
+		errors.Print(errors.Append(err, "Failed to get backchannel auth requests"))
+		errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		return
+	}
+
+	now := time.Now()
+	res := []*BackchannelAuthRequest{}
+	for _, req := range reqs {
+		if req.Status != model.BackchannelAuthStatusPending || req.Expired(now) {
+			continue
+		}
+		res = append(res, &BackchannelAuthRequest{
+			AuthReqID:      req.AuthReqID,
+			ClientID:       req.ClientID,
+			Scopes:         req.Scopes,
+			BindingMessage: req.BindingMessage,
+			ExpiresAt:      req.CreatedAt.Add(time.Second * time.Duration(req.ExpiresIn)).Format(time.RFC3339),
+		})
+	}
+
+	jwthttp.ResponseWrite(w, "BackchannelAuthGetListHandler", res)
+}
+
+// BackchannelAuthDecideHandler approves or denies the backchannel authentication request
+func BackchannelAuthDecideHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectName := vars["projectName"]
+	userID := vars["userID"]
+	authReqID := vars["authReqID"]
+
+	// Authorize API Request
+	claims, err := jwthttp.ValidateAPIToken(r)
+	if err != nil || claims.Subject != userID {
+		errors.PrintAsInfo(errors.Append(err, "Failed to authorize header"))
+		errors.WriteToHTTP(w, errors.ErrUnpermitted, 0, "")
+		return
+	}
+
+	var req BackchannelAuthDecideRequest
+	if e := json.NewDecoder(r.Body).Decode(&req); e != nil {
+		err := errors.Append(errors.ErrInvalidRequest, "Failed to decode backchannel auth decide request: %v", e)
+		errors.PrintAsInfo(err)
+		errors.WriteToHTTP(w, err, 0, "")
+		return
+	}
+
+	// the user is authenticated by the methods used to log in to the app on the authentication device
+	if err := ciba.Decide(projectName, userID, authReqID, req.Approve, claims.AMR); err != nil {
+		if errors.Contains(err, model.ErrNoSuchBackchannelAuth) || errors.Contains(err, model.ErrBackchannelAuthValidateFailed) {
+			errors.PrintAsInfo(errors.Append(err, "No such backchannel auth request %s", authReqID))
+			errors.WriteToHTTP(w, err, http.StatusNotFound, "")
+		} else if errors.Contains(err, ciba.ErrRequestNotPending) {
+			errors.PrintAsInfo(errors.Append(err, "Backchannel auth request %s is already decided", authReqID))
+			errors.WriteToHTTP(w, err, http.StatusBadRequest, "")
+		} else {
+			errors.Print(errors.Append(err, "Failed to decide backchannel auth request"))
+			errors.WriteToHTTP(w, err, http.StatusInternalServerError, "")
+		}
+		return
+	}
+
+	// Return 204 (No content) for success
+	w.WriteHeader(http.StatusNoContent)
+	logger.Info("BackchannelAuthDecideHandler method successfully finished")
+}
+
+// checkPasswordMinAge returns secret.ErrPasswordChangeTooEarly if the user changed the password recently
+// The min age prevents the user from cycling through the password history back to the old password.
+func checkPasswordMinAge(projectName string, userID string) *errors.Error {
+	prj, err := db.GetInst().ProjectGet(projectName)
+	if err != nil {
+		return errors.Append(err, "Failed to get project")
+	}
+	user, err := db.GetInst().UserGet(projectName, userID)
+	if err != nil {
+		return errors.Append(err, "Failed to get user")
+	}
+	return secret.CheckPasswordMinAge(user, prj.PasswordPolicy, time.Now())
+}
+
+func newTrustedDevice(d *model.TrustedDevice) TrustedDevice {
+	return TrustedDevice{
+		ID:         d.ID,
+		UserAgent:  d.UserAgent,
+		CreatedAt:  d.CreatedAt.Format(time.RFC3339),
+		ExpiresAt:  d.ExpiresAt.Format(time.RFC3339),
+		LastUsedAt: d.LastUsedAt.Format(time.RFC3339),
+	}
+}
+
+func newWebAuthnCredential(c *model.WebAuthnCredential) WebAuthnCredential {
+	return WebAuthnCredential{
+		ID:                c.ID,
+		Name:              c.Name,
+		Transports:        c.Transports,
+		AAGUID:            c.AAGUID,
+		AttestationFormat: c.AttestationFormat,
+		CreatedAt:         c.CreatedAt.Format(time.RFC3339),
+		LastUsedAt:        c.LastUsedAt.Format(time.RFC3339),
+	}
+}
@@ -1,18 +1,28 @@
 package userv1
 
+import (
+	"github.com/sh-miyoshi/hekate/pkg/webauthn"
+)
+
 // OTPInfo ...
 type OTPInfo struct {
-	ID      string `json:"id"`
-	Enabled bool   `json:"enabled"`
+	ID                     string `json:"id"`
+	Enabled                bool   `json:"enabled"`
+	RecoveryCodesRemaining int    `json:"recovery_codes_remaining"`
 }
 
 // GetResponse ...
 type GetResponse struct {
-	ID        string  `json:"id"`
-	Name      string  `json:"name"`
-	EMail     string  `json:"email"`
-	CreatedAt string  `json:"created_at"`
-	OPTInfo   OTPInfo `json:"otp_info"`
+	ID              string   `json:"id"`
+	Name            string   `json:"name"`
+	EMail           string   `json:"email"`
+	CreatedAt       string   `json:"created_at"`
+	OPTInfo         OTPInfo  `json:"otp_info"`
+	EMailOTPEnabled bool     `json:"email_otp_enabled"`
+	PhoneNumber     string   `json:"phone_number"`
+	SMSOTPEnabled   bool     `json:"sms_otp_enabled"`
+	Sessions        []string `json:"sessions"`         // Array of session IDs
+	OfflineSessions []string `json:"offline_sessions"` // Array of offline session IDs
 }
 
 // ChangePasswordRequest ...
@@ -29,3 +39,63 @@ type OTPGenerateResponse struct {
 type OTPVerifyRequest struct {
 	UserCode string `json:"user_code"`
 }
+
+// EMailOTPVerifyRequest ...
+type EMailOTPVerifyRequest struct {
+	UserCode string `json:"user_code"`
+}
+
+// SMSOTPSetupRequest ...
+type SMSOTPSetupRequest struct {
+	PhoneNumber string `json:"phone_number"` // E.164 format, e.g. +819012345678
+}
+
+// SMSOTPVerifyRequest ...
+type SMSOTPVerifyRequest struct {
+	UserCode string `json:"user_code"`
+}
+
+// OTPRecoveryCodesResponse ...
+type OTPRecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"` // Shown only once
+}
+
+// BackchannelAuthRequest ...
+type BackchannelAuthRequest struct {
+	AuthReqID      string   `json:"auth_req_id"`
+	ClientID       string   `json:"client_id"`
+	Scopes         []string `json:"scopes"`
+	BindingMessage string   `json:"binding_message"`
+	ExpiresAt      string   `json:"expires_at"`
+}
+
+// BackchannelAuthDecideRequest ...
+type BackchannelAuthDecideRequest struct {
+	Approve bool `json:"approve"`
+}
+
+// WebAuthnRegisterVerifyRequest ...
+type WebAuthnRegisterVerifyRequest struct {
+	Name       string                        `json:"name"`
+	Credential webauthn.RegistrationResponse `json:"credential"` // JSON form of PublicKeyCredential
+}
+
+// WebAuthnCredential ...
+type WebAuthnCredential struct {
+	ID                string   `json:"id"`
+	Name              string   `json:"name"`
+	Transports        []string `json:"transports"`
+	AAGUID            string   `json:"aaguid"`
+	AttestationFormat string   `json:"attestation_format"`
+	CreatedAt         string   `json:"created_at"`
+	LastUsedAt        string   `json:"last_used_at"`
+}
+
+// TrustedDevice ...
+type TrustedDevice struct {
+	ID         string `json:"id"`
+	UserAgent  string `json:"user_agent"`
+	CreatedAt  string `json:"created_at"`
+	ExpiresAt  string `json:"expires_at"`
+	LastUsedAt string `json:"last_used_at"`
+}
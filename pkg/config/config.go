@@ -3,6 +3,7 @@ package config
 import (
 	"flag"
 	"net/http"
+	"net/url"
 	"os"
 	"path"
 	"regexp"
@@ -13,8 +14,35 @@ import (
 	"gopkg.in/yaml.v2"
 )
 
+// maxRateLimitWindow is a max window and block duration of the rate limit [sec]
+const maxRateLimitWindow = 24 * 60 * 60
+
 var inst = GlobalConfig{}
 
+func (r *RateLimitRule) setDefault(maxFailures uint) {
+	if r.MaxFailures == 0 {
+		r.MaxFailures = maxFailures
+	}
+	if r.Window == 0 {
+		r.Window = 10 * 60
+	}
+	if r.BlockDuration == 0 {
+		r.BlockDuration = 15 * 60
+	}
+}
+
+func (c *RateLimitConfig) setDefault() {
+	c.IP.setDefault(50)
+	c.User.setDefault(20)
+	c.Client.setDefault(200)
+	if c.DelayAfter == 0 {
+		c.DelayAfter = 3
+	}
+	if c.MaxDelay == 0 {
+		c.MaxDelay = 4
+	}
+}
+
 // Validate ...
 func (c *GlobalConfig) Validate() *errors.Error {
 	if c.Port == 0 || c.Port > 65535 {
@@ -41,6 +69,43 @@ func (c *GlobalConfig) Validate() *errors.Error {
 		return errors.New("Invalid config", "interval of db gc is 0")
 	}
 
+	for typ, r := range map[string]RateLimitRule{"ip": c.RateLimit.IP, "user": c.RateLimit.User, "client": c.RateLimit.Client} {
+		if r.Window > maxRateLimitWindow || r.BlockDuration > maxRateLimitWindow {
+			return errors.New("Invalid config", "window and block duration of %s rate limit must be less than or equal to %d", typ, maxRateLimitWindow)
+		}
+	}
+
+	for _, m := range c.WebFinger.Mappings {
+		if m.Domain == "" || m.Project == "" {
+			return errors.New("Invalid config", "webfinger mapping requires both domain and project: %v", m)
+		}
+	}
+
+	switch c.Mail.Type {
+	case "":
+	case "smtp":
+		if c.Mail.SMTP.Host == "" || c.Mail.SMTP.Port == 0 || c.Mail.SMTP.Port > 65535 {
+			return errors.New("Invalid config", "smtp host and port are required to send mail: %s:%d", c.Mail.SMTP.Host, c.Mail.SMTP.Port)
+		}
+		if c.Mail.From == "" {
+			return errors.New("Invalid config", "from address of mail is empty")
+		}
+	case "file":
+	default:
+		return errors.New("Invalid config", "mail type %s is not supported", c.Mail.Type)
+	}
+
+	switch c.SMS.Type {
+	case "", "log":
+	case "webhook":
+		u, err := url.Parse(c.SMS.Webhook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("Invalid config", "sms webhook url %s is invalid", c.SMS.Webhook.URL)
+		}
+	default:
+		return errors.New("Invalid config", "sms type %s is not supported", c.SMS.Type)
+	}
+
 	finfo, err := os.Stat(c.UserLoginResourceDir)
 	if err != nil {
 		return errors.New("Invalid config", "Failed to get login resource info: %v", err)
@@ -57,6 +122,11 @@ func (c *GlobalConfig) setLoginResource() *errors.Error {
 	// .
 	// ├── consent.html    : consent page
 	// ├── otp_verify.html : OTP verify page
+	// ├── webauthn.html   : WebAuthn verify page
+	// ├── magiclink.html  : page to notify that the login link is sent, and to confirm the login by the link
+	// ├── sms_verify.html : SMS OTP verify page
+	// ├── otp_enroll.html : OTP enrollment page which is shown when MFA is required
+	// ├── change_password.html : page to change the expired or temporary password
 	// ├── index.html      : login page
 	// └── static          : directory of static assets
 
@@ -82,6 +152,26 @@ func (c *GlobalConfig) setLoginResource() *errors.Error {
 	if _, err := os.Stat(c.LoginResource.DeviceLoginCompletePage); err != nil {
 		return errors.New(pubMsg, "Failed to get device login complete page: %v", err)
 	}
+	c.LoginResource.WebAuthnPage = path.Join(dir, "webauthn.html")
+	if _, err := os.Stat(c.LoginResource.WebAuthnPage); err != nil {
+		return errors.New(pubMsg, "Failed to get WebAuthn verify page: %v", err)
+	}
+	c.LoginResource.MagicLinkPage = path.Join(dir, "magiclink.html")
+	if _, err := os.Stat(c.LoginResource.MagicLinkPage); err != nil {
+		return errors.New(pubMsg, "Failed to get magic link page: %v", err)
+	}
+	c.LoginResource.SMSVerifyPage = path.Join(dir, "sms_verify.html")
+	if _, err := os.Stat(c.LoginResource.SMSVerifyPage); err != nil {
+		return errors.New(pubMsg, "Failed to get SMS verify page: %v", err)
+	}
+	c.LoginResource.OTPEnrollPage = path.Join(dir, "otp_enroll.html")
+	if _, err := os.Stat(c.LoginResource.OTPEnrollPage); err != nil {
+		return errors.New(pubMsg, "Failed to get OTP enroll page: %v", err)
+	}
+	c.LoginResource.ChangePasswordPage = path.Join(dir, "change_password.html")
+	if _, err := os.Stat(c.LoginResource.ChangePasswordPage); err != nil {
+		return errors.New(pubMsg, "Failed to get change password page: %v", err)
+	}
 	// static directory is option, so does not require check
 
 	return nil
@@ -134,6 +224,16 @@ func InitConfig(osArgs []string) *errors.Error {
 	if err := setEnvUint("HEKATE_DBGC_INTERVAL", &inst.DBGCInterval); err != nil {
 		return errors.New("Invalid os env", "Failed to get db gc interval: %v", err)
 	}
+	setEnvVar("HEKATE_MAIL_TYPE", &inst.Mail.Type)
+	setEnvVar("HEKATE_MAIL_FROM", &inst.Mail.From)
+	setEnvVar("HEKATE_MAIL_SMTP_HOST", &inst.Mail.SMTP.Host)
+	if err := setEnvInt("HEKATE_MAIL_SMTP_PORT", &inst.Mail.SMTP.Port); err != nil {
+		return errors.New("Invalid os env", "Failed to get smtp port number: %v", err)
+	}
+	setEnvVar("HEKATE_MAIL_SMTP_USERNAME", &inst.Mail.SMTP.Username)
+	setEnvVar("HEKATE_MAIL_SMTP_PASSWORD", &inst.Mail.SMTP.Password)
+	setEnvVar("HEKATE_SMS_TYPE", &inst.SMS.Type)
+	setEnvVar("HEKATE_SMS_WEBHOOK_URL", &inst.SMS.Webhook.URL)
 
 	// Set by command line args
 
@@ -158,6 +258,8 @@ func InitConfig(osArgs []string) *errors.Error {
 	flag.Uint64Var(&inst.SSOExpiresIn, "sso-expires", inst.SSOExpiresIn, "expires time of single sign on [sec]")
 	flag.StringVar(&inst.UserLoginResourceDir, "login-res", inst.UserLoginResourceDir, "directory path for user login")
 	flag.Uint64Var(&inst.DBGCInterval, "dbgc-interval", inst.DBGCInterval, "interval time of garbage collector for expired sessions [sec]")
+	flag.StringVar(&inst.Mail.Type, "mail-type", inst.Mail.Type, "type of mail sender (smtp or file)")
+	flag.StringVar(&inst.SMS.Type, "sms-type", inst.SMS.Type, "type of sms sender (webhook or log)")
 	flag.Parse()
 
 	// Set supported type
@@ -171,9 +273,10 @@ func InitConfig(osArgs []string) *errors.Error {
 		"code id_token token",
 		// TODO(support type "none")
 	}
-	inst.SupportedScope = []string{"openid"}
 	inst.LoginStaticResourceURL = "/resource/login"
 
+	inst.RateLimit.setDefault()
+
 	// Validate config
 	if err := inst.Validate(); err != nil {
 		return errors.Append(err, "Failed to validate config")
@@ -193,8 +296,8 @@ func Get() *GlobalConfig {
 
 // GetServerAddr ...
 func GetServerAddr(r *http.Request) string {
-	if os.Getenv("HEKATE_SERVER_ADDR") != "" {
-		return os.Getenv("HEKATE_SERVER_ADDR")
+	if addr := GetExternalServerAddr(); addr != "" {
+		return addr
 	}
 
 	// get from request
@@ -205,6 +308,13 @@ func GetServerAddr(r *http.Request) string {
 	return scheme + "://" + r.Host
 }
 
+// GetExternalServerAddr returns the server address set by HEKATE_SERVER_ADDR, or empty if not set
+// The link sent out of the request such as the login link by email must be created from it
+// because the Host header of the request can be forged by the client.
+func GetExternalServerAddr() string {
+	return os.Getenv("HEKATE_SERVER_ADDR")
+}
+
 func setEnvVar(key string, target *string) {
 	val := os.Getenv(key)
 	if len(val) > 0 {
@@ -100,6 +100,11 @@ func TestCheckLoginResDirStruct(t *testing.T) {
 	indexFile := filepath.Join(dir, "index.html")
 	deviceFile := filepath.Join(dir, "devicelogin.html")
 	deviceCompFile := filepath.Join(dir, "devicelogin_complete.html")
+	webauthnFile := filepath.Join(dir, "webauthn.html")
+	magicLinkFile := filepath.Join(dir, "magiclink.html")
+	smsVerifyFile := filepath.Join(dir, "sms_verify.html")
+	otpEnrollFile := filepath.Join(dir, "otp_enroll.html")
+	changePasswordFile := filepath.Join(dir, "change_password.html")
 	data := []byte("data")
 
 	// Test no consent page
@@ -111,6 +116,11 @@ func TestCheckLoginResDirStruct(t *testing.T) {
 	ioutil.WriteFile(indexFile, data, 0644)
 	ioutil.WriteFile(deviceFile, data, 0644)
 	ioutil.WriteFile(deviceCompFile, data, 0644)
+	ioutil.WriteFile(webauthnFile, data, 0644)
+	ioutil.WriteFile(magicLinkFile, data, 0644)
+	ioutil.WriteFile(smsVerifyFile, data, 0644)
+	ioutil.WriteFile(otpEnrollFile, data, 0644)
+	ioutil.WriteFile(changePasswordFile, data, 0644)
 	if err := c.setLoginResource(); err == nil {
 		t.Errorf("CheckLoginResDirStruct returns nil, but expect is no consent page")
 	}
@@ -123,12 +133,22 @@ func TestCheckLoginResDirStruct(t *testing.T) {
 	os.Remove(indexFile)
 	os.Remove(deviceFile)
 	os.Remove(deviceCompFile)
+	os.Remove(webauthnFile)
+	os.Remove(magicLinkFile)
+	os.Remove(smsVerifyFile)
+	os.Remove(otpEnrollFile)
+	os.Remove(changePasswordFile)
 
 	// Test no OTP verify page
 	ioutil.WriteFile(consentFile, data, 0644)
 	ioutil.WriteFile(indexFile, data, 0644)
 	ioutil.WriteFile(deviceFile, data, 0644)
 	ioutil.WriteFile(deviceCompFile, data, 0644)
+	ioutil.WriteFile(webauthnFile, data, 0644)
+	ioutil.WriteFile(magicLinkFile, data, 0644)
+	ioutil.WriteFile(smsVerifyFile, data, 0644)
+	ioutil.WriteFile(otpEnrollFile, data, 0644)
+	ioutil.WriteFile(changePasswordFile, data, 0644)
 	if err := c.setLoginResource(); err == nil {
 		t.Errorf("CheckLoginResDirStruct returns nil, but expect is no OTP verify page")
 	}
@@ -136,12 +156,22 @@ func TestCheckLoginResDirStruct(t *testing.T) {
 	os.Remove(indexFile)
 	os.Remove(deviceFile)
 	os.Remove(deviceCompFile)
+	os.Remove(webauthnFile)
+	os.Remove(magicLinkFile)
+	os.Remove(smsVerifyFile)
+	os.Remove(otpEnrollFile)
+	os.Remove(changePasswordFile)
 
 	// Test no login page
 	ioutil.WriteFile(consentFile, data, 0644)
 	ioutil.WriteFile(otpVerifyFile, data, 0644)
 	ioutil.WriteFile(deviceFile, data, 0644)
 	ioutil.WriteFile(deviceCompFile, data, 0644)
+	ioutil.WriteFile(webauthnFile, data, 0644)
+	ioutil.WriteFile(magicLinkFile, data, 0644)
+	ioutil.WriteFile(smsVerifyFile, data, 0644)
+	ioutil.WriteFile(otpEnrollFile, data, 0644)
+	ioutil.WriteFile(changePasswordFile, data, 0644)
 	if err := c.setLoginResource(); err == nil {
 		t.Errorf("CheckLoginResDirStruct returns nil, but expect is no login page")
 	}
@@ -149,12 +179,22 @@ func TestCheckLoginResDirStruct(t *testing.T) {
 	os.Remove(otpVerifyFile)
 	os.Remove(deviceFile)
 	os.Remove(deviceCompFile)
+	os.Remove(webauthnFile)
+	os.Remove(magicLinkFile)
+	os.Remove(smsVerifyFile)
+	os.Remove(otpEnrollFile)
+	os.Remove(changePasswordFile)
 
 	// Test no device login page
 	ioutil.WriteFile(consentFile, data, 0644)
 	ioutil.WriteFile(otpVerifyFile, data, 0644)
 	ioutil.WriteFile(indexFile, data, 0644)
 	ioutil.WriteFile(deviceCompFile, data, 0644)
+	ioutil.WriteFile(webauthnFile, data, 0644)
+	ioutil.WriteFile(magicLinkFile, data, 0644)
+	ioutil.WriteFile(smsVerifyFile, data, 0644)
+	ioutil.WriteFile(otpEnrollFile, data, 0644)
+	ioutil.WriteFile(changePasswordFile, data, 0644)
 	if err := c.setLoginResource(); err == nil {
 		t.Errorf("CheckLoginResDirStruct returns nil, but expect is no device login page")
 	}
@@ -162,12 +202,22 @@ func TestCheckLoginResDirStruct(t *testing.T) {
 	os.Remove(otpVerifyFile)
 	os.Remove(indexFile)
 	os.Remove(deviceCompFile)
+	os.Remove(webauthnFile)
+	os.Remove(magicLinkFile)
+	os.Remove(smsVerifyFile)
+	os.Remove(otpEnrollFile)
+	os.Remove(changePasswordFile)
 
 	// Test no device login complete page
 	ioutil.WriteFile(consentFile, data, 0644)
 	ioutil.WriteFile(otpVerifyFile, data, 0644)
 	ioutil.WriteFile(indexFile, data, 0644)
 	ioutil.WriteFile(deviceFile, data, 0644)
+	ioutil.WriteFile(webauthnFile, data, 0644)
+	ioutil.WriteFile(magicLinkFile, data, 0644)
+	ioutil.WriteFile(smsVerifyFile, data, 0644)
+	ioutil.WriteFile(otpEnrollFile, data, 0644)
+	ioutil.WriteFile(changePasswordFile, data, 0644)
 	if err := c.setLoginResource(); err == nil {
 		t.Errorf("CheckLoginResDirStruct returns nil, but expect is no device login complete page")
 	}
@@ -175,6 +225,126 @@ func TestCheckLoginResDirStruct(t *testing.T) {
 	os.Remove(otpVerifyFile)
 	os.Remove(indexFile)
 	os.Remove(deviceFile)
+	os.Remove(webauthnFile)
+	os.Remove(magicLinkFile)
+	os.Remove(smsVerifyFile)
+	os.Remove(otpEnrollFile)
+	os.Remove(changePasswordFile)
+
+	// Test no WebAuthn verify page
+	ioutil.WriteFile(consentFile, data, 0644)
+	ioutil.WriteFile(otpVerifyFile, data, 0644)
+	ioutil.WriteFile(indexFile, data, 0644)
+	ioutil.WriteFile(deviceFile, data, 0644)
+	ioutil.WriteFile(deviceCompFile, data, 0644)
+	ioutil.WriteFile(magicLinkFile, data, 0644)
+	ioutil.WriteFile(smsVerifyFile, data, 0644)
+	ioutil.WriteFile(otpEnrollFile, data, 0644)
+	ioutil.WriteFile(changePasswordFile, data, 0644)
+	if err := c.setLoginResource(); err == nil {
+		t.Errorf("CheckLoginResDirStruct returns nil, but expect is no WebAuthn verify page")
+	}
+	os.Remove(consentFile)
+	os.Remove(otpVerifyFile)
+	os.Remove(indexFile)
+	os.Remove(deviceFile)
+	os.Remove(deviceCompFile)
+	os.Remove(magicLinkFile)
+	os.Remove(smsVerifyFile)
+	os.Remove(otpEnrollFile)
+	os.Remove(changePasswordFile)
+
+	// Test no magic link page
+	ioutil.WriteFile(consentFile, data, 0644)
+	ioutil.WriteFile(otpVerifyFile, data, 0644)
+	ioutil.WriteFile(indexFile, data, 0644)
+	ioutil.WriteFile(deviceFile, data, 0644)
+	ioutil.WriteFile(deviceCompFile, data, 0644)
+	ioutil.WriteFile(webauthnFile, data, 0644)
+	ioutil.WriteFile(smsVerifyFile, data, 0644)
+	ioutil.WriteFile(otpEnrollFile, data, 0644)
+	ioutil.WriteFile(changePasswordFile, data, 0644)
+	if err := c.setLoginResource(); err == nil {
+		t.Errorf("CheckLoginResDirStruct returns nil, but expect is no magic link page")
+	}
+	os.Remove(consentFile)
+	os.Remove(otpVerifyFile)
+	os.Remove(indexFile)
+	os.Remove(deviceFile)
+	os.Remove(deviceCompFile)
+	os.Remove(webauthnFile)
+	os.Remove(smsVerifyFile)
+	os.Remove(otpEnrollFile)
+	os.Remove(changePasswordFile)
+
+	// Test no SMS verify page
+	ioutil.WriteFile(consentFile, data, 0644)
+	ioutil.WriteFile(otpVerifyFile, data, 0644)
+	ioutil.WriteFile(indexFile, data, 0644)
+	ioutil.WriteFile(deviceFile, data, 0644)
+	ioutil.WriteFile(deviceCompFile, data, 0644)
+	ioutil.WriteFile(webauthnFile, data, 0644)
+	ioutil.WriteFile(magicLinkFile, data, 0644)
+	ioutil.WriteFile(otpEnrollFile, data, 0644)
+	ioutil.WriteFile(changePasswordFile, data, 0644)
+	if err := c.setLoginResource(); err == nil {
+		t.Errorf("CheckLoginResDirStruct returns nil, but expect is no SMS verify page")
+	}
+	os.Remove(consentFile)
+	os.Remove(otpVerifyFile)
+	os.Remove(indexFile)
+	os.Remove(deviceFile)
+	os.Remove(deviceCompFile)
+	os.Remove(webauthnFile)
+	os.Remove(magicLinkFile)
+	os.Remove(otpEnrollFile)
+	os.Remove(changePasswordFile)
+
+	// Test no OTP enroll page
+	ioutil.WriteFile(consentFile, data, 0644)
+	ioutil.WriteFile(otpVerifyFile, data, 0644)
+	ioutil.WriteFile(indexFile, data, 0644)
+	ioutil.WriteFile(deviceFile, data, 0644)
+	ioutil.WriteFile(deviceCompFile, data, 0644)
+	ioutil.WriteFile(webauthnFile, data, 0644)
+	ioutil.WriteFile(magicLinkFile, data, 0644)
+	ioutil.WriteFile(smsVerifyFile, data, 0644)
+	ioutil.WriteFile(changePasswordFile, data, 0644)
+	if err := c.setLoginResource(); err == nil {
+		t.Errorf("CheckLoginResDirStruct returns nil, but expect is no OTP enroll page")
+	}
+	os.Remove(consentFile)
+	os.Remove(otpVerifyFile)
+	os.Remove(indexFile)
+	os.Remove(deviceFile)
+	os.Remove(deviceCompFile)
+	os.Remove(webauthnFile)
+	os.Remove(magicLinkFile)
+	os.Remove(smsVerifyFile)
+	os.Remove(changePasswordFile)
+
+	// Test no change password page
+	ioutil.WriteFile(consentFile, data, 0644)
+	ioutil.WriteFile(otpVerifyFile, data, 0644)
+	ioutil.WriteFile(indexFile, data, 0644)
+	ioutil.WriteFile(deviceFile, data, 0644)
+	ioutil.WriteFile(deviceCompFile, data, 0644)
+	ioutil.WriteFile(webauthnFile, data, 0644)
+	ioutil.WriteFile(magicLinkFile, data, 0644)
+	ioutil.WriteFile(smsVerifyFile, data, 0644)
+	ioutil.WriteFile(otpEnrollFile, data, 0644)
+	if err := c.setLoginResource(); err == nil {
+		t.Errorf("CheckLoginResDirStruct returns nil, but expect is no change password page")
+	}
+	os.Remove(consentFile)
+	os.Remove(otpVerifyFile)
+	os.Remove(indexFile)
+	os.Remove(deviceFile)
+	os.Remove(deviceCompFile)
+	os.Remove(webauthnFile)
+	os.Remove(magicLinkFile)
+	os.Remove(smsVerifyFile)
+	os.Remove(otpEnrollFile)
 
 	// Test ok
 	ioutil.WriteFile(consentFile, data, 0644)
@@ -182,6 +352,11 @@ func TestCheckLoginResDirStruct(t *testing.T) {
 	ioutil.WriteFile(indexFile, data, 0644)
 	ioutil.WriteFile(deviceFile, data, 0644)
 	ioutil.WriteFile(deviceCompFile, data, 0644)
+	ioutil.WriteFile(webauthnFile, data, 0644)
+	ioutil.WriteFile(magicLinkFile, data, 0644)
+	ioutil.WriteFile(smsVerifyFile, data, 0644)
+	ioutil.WriteFile(otpEnrollFile, data, 0644)
+	ioutil.WriteFile(changePasswordFile, data, 0644)
 	if err := c.setLoginResource(); err != nil {
 		t.Errorf("CheckLoginResDirStruct returns error %v, but expect is nil", err)
 	}
@@ -190,6 +365,11 @@ func TestCheckLoginResDirStruct(t *testing.T) {
 	os.Remove(indexFile)
 	os.Remove(deviceFile)
 	os.Remove(deviceCompFile)
+	os.Remove(webauthnFile)
+	os.Remove(magicLinkFile)
+	os.Remove(smsVerifyFile)
+	os.Remove(otpEnrollFile)
+	os.Remove(changePasswordFile)
 }
 
 func TestGetServerAddr(t *testing.T) {
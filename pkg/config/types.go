@@ -20,26 +20,105 @@ type LoginResource struct {
 	ConsentPage             string
 	DeviceLoginPage         string
 	DeviceLoginCompletePage string
+	WebAuthnPage            string
+	MagicLinkPage           string
+	SMSVerifyPage           string
+	OTPEnrollPage           string
+	ChangePasswordPage      string
+}
+
+// WebFingerMapping is a rule to resolve the domain of WebFinger resource to the project
+type WebFingerMapping struct {
+	Domain  string `yaml:"domain"`
+	Project string `yaml:"project"`
+}
+
+// WebFingerConfig ...
+type WebFingerConfig struct {
+	// DefaultProject is used when the resource does not match any mapping
+	DefaultProject string             `yaml:"default_project"`
+	Mappings       []WebFingerMapping `yaml:"mappings"`
+}
+
+// SMTPConfig ...
+type SMTPConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// UseTLS is true if the server requires the implicit TLS connection such as port 465
+	// Otherwise STARTTLS is used when the server supports it.
+	UseTLS bool `yaml:"use_tls"`
+}
+
+// MailConfig ...
+type MailConfig struct {
+	// Type is one of "smtp", "file" or empty to disable sending mail
+	Type string     `yaml:"type"`
+	From string     `yaml:"from"`
+	SMTP SMTPConfig `yaml:"smtp"`
+	// FilePath is an output file of the "file" sender, messages are written to the log if empty
+	FilePath string `yaml:"file_path"`
+}
+
+// SMSWebhookConfig ...
+type SMSWebhookConfig struct {
+	// URL receives a POST request with the JSON body {"to": phone number, "body": message}
+	URL string `yaml:"url"`
+	// Headers are added to the request, e.g. Authorization of the SMS gateway
+	Headers map[string]string `yaml:"headers"`
+}
+
+// SMSConfig ...
+type SMSConfig struct {
+	// Type is one of "webhook", "log" or empty to disable sending SMS
+	Type    string           `yaml:"type"`
+	Webhook SMSWebhookConfig `yaml:"webhook"`
+}
+
+// RateLimitRule is a limit of the failures per key
+// The key is blocked for BlockDuration [sec] when it fails MaxFailures times within Window [sec].
+type RateLimitRule struct {
+	MaxFailures   uint `yaml:"max_failures"`
+	Window        uint `yaml:"window"`
+	BlockDuration uint `yaml:"block_duration"`
+}
+
+// RateLimitConfig is a config of the brute-force protection for the login and the token request
+type RateLimitConfig struct {
+	// DB is a store of the failures, the main db is used if empty
+	DB     DBInfo        `yaml:"db"`
+	IP     RateLimitRule `yaml:"ip"`
+	User   RateLimitRule `yaml:"user"`
+	Client RateLimitRule `yaml:"client"`
+	// The response is delayed progressively after DelayAfter failures up to MaxDelay [sec]
+	DelayAfter uint `yaml:"delay_after"`
+	MaxDelay   uint `yaml:"max_delay"`
 }
 
 // GlobalConfig ...
 type GlobalConfig struct {
-	AdminName             string      `yaml:"admin_name"`
-	AdminPassword         string      `yaml:"admin_password"`
-	Port                  int         `yaml:"server_port"`
-	BindAddr              string      `yaml:"server_bind_address"`
-	HTTPSConfig           HTTPSConfig `yaml:"https"`
-	LogFile               string      `yaml:"logfile"`
-	ModeDebug             bool        `yaml:"debug_mode"`
-	DB                    DBInfo      `yaml:"db"`
-	AuditDB               DBInfo      `yaml:"audit_db"`
-	LoginSessionExpiresIn uint64      `yaml:"login_session_expires_in"`
-	SSOExpiresIn          uint64      `yaml:"sso_expires_in"`
-	UserLoginResourceDir  string      `yaml:"user_login_page_res"`
-	DBGCInterval          uint64      `yaml:"dbgc_interval"`
+	AdminName             string          `yaml:"admin_name"`
+	AdminPassword         string          `yaml:"admin_password"`
+	Port                  int             `yaml:"server_port"`
+	BindAddr              string          `yaml:"server_bind_address"`
+	HTTPSConfig           HTTPSConfig     `yaml:"https"`
+	LogFile               string          `yaml:"logfile"`
+	ModeDebug             bool            `yaml:"debug_mode"`
+	DB                    DBInfo          `yaml:"db"`
+	AuditDB               DBInfo          `yaml:"audit_db"`
+	LoginSessionExpiresIn uint64          `yaml:"login_session_expires_in"`
+	SSOExpiresIn          uint64          `yaml:"sso_expires_in"`
+	UserLoginResourceDir  string          `yaml:"user_login_page_res"`
+	DBGCInterval          uint64          `yaml:"dbgc_interval"`
+	WebFinger             WebFingerConfig `yaml:"webfinger"`
+	Mail                  MailConfig      `yaml:"mail"`
+	SMS                   SMSConfig       `yaml:"sms"`
+	// GeoIPFile is a CSV file to resolve the country of the login in the risk evaluation
+	GeoIPFile string          `yaml:"geoip_file"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
 
 	SupportedResponseType  []string
-	SupportedScope         []string
 	LoginResource          LoginResource
 	LoginStaticResourceURL string
 }
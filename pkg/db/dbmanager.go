@@ -13,19 +13,23 @@ import (
 	"github.com/sh-miyoshi/hekate/pkg/role"
 	"github.com/sh-miyoshi/hekate/pkg/secret"
 	"github.com/sh-miyoshi/hekate/pkg/util"
+	"github.com/stretchr/stew/slice"
 )
 
 // Manager ...
 type Manager struct {
-	project      model.ProjectInfoHandler
-	user         model.UserInfoHandler
-	session      model.SessionHandler
-	client       model.ClientInfoHandler
-	customRole   model.CustomRoleHandler
-	loginSession model.LoginSessionHandler
-	transaction  model.TransactionManager
-	ping         model.PingHandler
-	device       model.DeviceHandler
+	project         model.ProjectInfoHandler
+	user            model.UserInfoHandler
+	session         model.SessionHandler
+	client          model.ClientInfoHandler
+	customRole      model.CustomRoleHandler
+	loginSession    model.LoginSessionHandler
+	transaction     model.TransactionManager
+	ping            model.PingHandler
+	device          model.DeviceHandler
+	clientScope     model.ClientScopeHandler
+	backchannelAuth model.BackchannelAuthHandler
+	apiResource     model.APIResourceHandler
 
 	portalAddr string
 }
@@ -42,15 +46,18 @@ func InitDBManager(dbType string, connStr string) *errors.Error {
 	case "memory":
 		logger.Info("Initialize with local memory DB")
 		inst = &Manager{
-			project:      memory.NewProjectHandler(),
-			user:         memory.NewUserHandler(),
-			session:      memory.NewSessionHandler(),
-			client:       memory.NewClientHandler(),
-			customRole:   memory.NewCustomRoleHandler(),
-			loginSession: memory.NewLoginSessionHandler(),
-			transaction:  memory.NewTransactionManager(),
-			ping:         memory.NewPingHandler(),
-			device:       memory.NewDeviceHandler(),
+			project:         memory.NewProjectHandler(),
+			user:            memory.NewUserHandler(),
+			session:         memory.NewSessionHandler(),
+			client:          memory.NewClientHandler(),
+			customRole:      memory.NewCustomRoleHandler(),
+			loginSession:    memory.NewLoginSessionHandler(),
+			transaction:     memory.NewTransactionManager(),
+			ping:            memory.NewPingHandler(),
+			device:          memory.NewDeviceHandler(),
+			clientScope:     memory.NewClientScopeHandler(),
+			backchannelAuth: memory.NewBackchannelAuthHandler(),
+			apiResource:     memory.NewAPIResourceHandler(),
 		}
 	case "mongo":
 		logger.Info("Initialize with mongo DB")
@@ -87,17 +94,32 @@ func InitDBManager(dbType string, connStr string) *errors.Error {
 		if err != nil {
 			return errors.Append(err, "Failed to create device handler")
 		}
+		clientScopeHandler, err := mongo.NewClientScopeHandler(dbClient)
+		if err != nil {
+			return errors.Append(err, "Failed to create client scope handler")
+		}
+		backchannelAuthHandler, err := mongo.NewBackchannelAuthHandler(dbClient)
+		if err != nil {
+			return errors.Append(err, "Failed to create backchannel auth handler")
+		}
+		apiResourceHandler, err := mongo.NewAPIResourceHandler(dbClient)
+		if err != nil {
+			return errors.Append(err, "Failed to create API resource handler")
+		}
 
 		inst = &Manager{
-			project:      prjHandler,
-			user:         userHandler,
-			session:      sessionHandler,
-			client:       clientHandler,
-			customRole:   customRoleHandler,
-			loginSession: loginSessionHandler,
-			transaction:  mongo.NewTransactionManager(dbClient),
-			ping:         mongo.NewPingHandler(dbClient),
-			device:       deviceHandler,
+			project:         prjHandler,
+			user:            userHandler,
+			session:         sessionHandler,
+			client:          clientHandler,
+			customRole:      customRoleHandler,
+			loginSession:    loginSessionHandler,
+			transaction:     mongo.NewTransactionManager(dbClient),
+			ping:            mongo.NewPingHandler(dbClient),
+			device:          deviceHandler,
+			clientScope:     clientScopeHandler,
+			backchannelAuth: backchannelAuthHandler,
+			apiResource:     apiResourceHandler,
 		}
 	default:
 		return errors.New("Internal server error", "Database Type %s is not implemented yet", dbType)
@@ -147,6 +169,10 @@ func (m *Manager) ProjectAdd(ent *model.ProjectInfo) *errors.Error {
 			return model.ErrProjectAlreadyExists
 		}
 
+		if err := m.validateMFAPolicy(ent); err != nil {
+			return err
+		}
+
 		if err := m.project.Add(ent); err != nil {
 			return errors.Append(err, "Failed to add project")
 		}
@@ -167,6 +193,13 @@ func (m *Manager) ProjectAdd(ent *model.ProjectInfo) *errors.Error {
 			return errors.Append(err, "Failed to add client for portal login")
 		}
 
+		// add default client scopes
+		for _, s := range model.DefaultClientScopes(ent.Name, ent.CreatedAt) {
+			if err := m.clientScope.Add(ent.Name, s); err != nil {
+				return errors.Append(err, "Failed to add default client scope %s", s.Name)
+			}
+		}
+
 		return nil
 	})
 }
@@ -208,6 +241,10 @@ func (m *Manager) ProjectDelete(name string) *errors.Error {
 			return errors.Append(err, "Failed to delete client data")
 		}
 
+		if err := m.clientScope.DeleteAll(name); err != nil {
+			return errors.Append(err, "Failed to delete client scope data")
+		}
+
 		if err := m.user.DeleteAll(name); err != nil {
 			return errors.Append(err, "Failed to delete user data")
 		}
@@ -216,6 +253,14 @@ func (m *Manager) ProjectDelete(name string) *errors.Error {
 			return errors.Append(err, "Failed to delete device data")
 		}
 
+		if err := m.backchannelAuth.DeleteAll(name); err != nil {
+			return errors.Append(err, "Failed to delete backchannel auth data")
+		}
+
+		if err := m.apiResource.DeleteAll(name); err != nil {
+			return errors.Append(err, "Failed to delete API resource data")
+		}
+
 		if err := m.project.Delete(name); err != nil {
 			return errors.Append(err, "Failed to delete project")
 		}
@@ -255,6 +300,9 @@ func (m *Manager) ProjectUpdate(ent *model.ProjectInfo) *errors.Error {
 	}
 
 	return m.transaction.Transaction(func() *errors.Error {
+		if err := m.validateMFAPolicy(ent); err != nil {
+			return err
+		}
 		if err := m.project.Update(ent); err != nil {
 			return errors.Append(err, "Failed to update project")
 		}
@@ -525,8 +573,9 @@ func (m *Manager) UserDeleteRole(projectName string, userID string, roleID strin
 	})
 }
 
-// UserChangePassword ...
-func (m *Manager) UserChangePassword(projectName string, userID string, password string) *errors.Error {
+// UserChangePassword changes the password of the user
+// If changeRequired is true, the user must change the password again at next login.
+func (m *Manager) UserChangePassword(projectName string, userID string, password string, changeRequired bool) *errors.Error {
 	if !model.ValidateUserID(userID) {
 		return errors.Append(model.ErrUserValidateFailed, "invalid user id format")
 	}
@@ -553,8 +602,16 @@ func (m *Manager) UserChangePassword(projectName string, userID string, password
 		if err := secret.CheckPassword(usr.Name, password, prj.PasswordPolicy); err != nil {
 			return errors.Append(err, "Failed to check password")
 		}
+		if err := secret.CheckPasswordHistory(password, usr, prj.PasswordPolicy); err != nil {
+			return errors.Append(err, "Failed to check password history")
+		}
 
+		usr.PasswordHistory = secret.NextPasswordHistory(usr)
 		usr.PasswordHash = util.CreateHash(password)
+		usr.PasswordUpdatedAt = time.Now()
+		usr.PasswordChangeRequired = changeRequired
+		// the devices trusted with the old password are not trusted anymore
+		usr.TrustedDevices = nil
 
 		if err := m.user.Update(projectName, usr); err != nil {
 			return errors.Append(err, "Failed to update user password")
@@ -571,8 +628,19 @@ func (m *Manager) UserLogout(projectName string, userID string) *errors.Error {
 	}
 
 	return m.transaction.Transaction(func() *errors.Error {
-		if err := m.session.Delete(projectName, &model.SessionFilter{UserID: userID}); err != nil {
-			return errors.Append(err, "Delete user session failed")
+		sessions, err := m.session.GetList(projectName, &model.SessionFilter{UserID: userID})
+		if err != nil {
+			return errors.Append(err, "Get user session list failed")
+		}
+
+		// offline sessions are alive even after logout
+		for _, s := range sessions {
+			if s.Offline {
+				continue
+			}
+			if err := m.session.Delete(projectName, &model.SessionFilter{SessionID: s.SessionID}); err != nil {
+				return errors.Append(err, "Delete user session failed")
+			}
 		}
 
 		return nil
@@ -629,6 +697,14 @@ func (m *Manager) LoginSessionGetByCode(projectName string, code string) (*model
 	return m.loginSession.GetByCode(projectName, code)
 }
 
+// LoginSessionRedeemCode marks the authorization code of the login session redeemed
+// It returns model.ErrLoginSessionCodeRedeemed if the code is already redeemed.
+func (m *Manager) LoginSessionRedeemCode(projectName string, sessionID string) *errors.Error {
+	return m.transaction.Transaction(func() *errors.Error {
+		return m.loginSession.RedeemCode(projectName, sessionID)
+	})
+}
+
 // SessionAdd ...
 func (m *Manager) SessionAdd(projectName string, ent *model.Session) *errors.Error {
 	if err := ent.Validate(); err != nil {
@@ -660,6 +736,12 @@ func (m *Manager) SessionGetList(projectName string, filter *model.SessionFilter
 		if filter.UserID != "" && !model.ValidateUserID(filter.UserID) {
 			return nil, model.ErrSessionValidateFailed
 		}
+		if filter.FamilyID != "" && !model.ValidateSessionID(filter.FamilyID) {
+			return nil, model.ErrSessionValidateFailed
+		}
+		if filter.LoginSessionID != "" && !model.ValidateSessionID(filter.LoginSessionID) {
+			return nil, model.ErrSessionValidateFailed
+		}
 	}
 
 	return m.session.GetList(projectName, filter)
@@ -700,6 +782,34 @@ func (m *Manager) SessionDelete(projectName string, sessionID string) *errors.Er
 	})
 }
 
+// SessionFamilyDelete revokes all sessions in the family
+func (m *Manager) SessionFamilyDelete(projectName string, familyID string) *errors.Error {
+	if !model.ValidateSessionID(familyID) {
+		return errors.Append(model.ErrSessionValidateFailed, "invalid family id format")
+	}
+
+	return m.transaction.Transaction(func() *errors.Error {
+		if err := m.session.Delete(projectName, &model.SessionFilter{FamilyID: familyID}); err != nil {
+			return errors.Append(err, "Failed to revoke session family")
+		}
+		return nil
+	})
+}
+
+// SessionLineageDelete revokes all sessions issued from the login session
+func (m *Manager) SessionLineageDelete(projectName string, loginSessionID string) *errors.Error {
+	if !model.ValidateSessionID(loginSessionID) {
+		return errors.Append(model.ErrSessionValidateFailed, "invalid login session id format")
+	}
+
+	return m.transaction.Transaction(func() *errors.Error {
+		if err := m.session.Delete(projectName, &model.SessionFilter{LoginSessionID: loginSessionID}); err != nil {
+			return errors.Append(err, "Failed to revoke sessions issued from the login session")
+		}
+		return nil
+	})
+}
+
 // ClientAdd ...
 func (m *Manager) ClientAdd(projectName string, ent *model.ClientInfo) *errors.Error {
 	if err := ent.Validate(); err != nil {
@@ -723,6 +833,10 @@ func (m *Manager) ClientAdd(projectName string, ent *model.ClientInfo) *errors.E
 			return model.ErrClientAlreadyExists
 		}
 
+		if err := m.checkClientScopes(projectName, ent); err != nil {
+			return err
+		}
+
 		if err := m.client.Add(projectName, ent); err != nil {
 			return errors.Append(err, "Failed to add client")
 		}
@@ -793,6 +907,10 @@ func (m *Manager) ClientUpdate(projectName string, ent *model.ClientInfo) *error
 			return model.ErrNoSuchClient
 		}
 
+		if err := m.checkClientScopes(projectName, ent); err != nil {
+			return err
+		}
+
 		if err := m.client.Update(projectName, ent); err != nil {
 			return errors.Append(err, "Failed to update client")
 		}
@@ -800,6 +918,69 @@ func (m *Manager) ClientUpdate(projectName string, ent *model.ClientInfo) *error
 	})
 }
 
+// checkClientScopes checks the scopes of the client are client scopes or permissions of API resources
+func (m *Manager) checkClientScopes(projectName string, ent *model.ClientInfo) *errors.Error {
+	valid, err := m.scopeNames(projectName)
+	if err != nil {
+		return err
+	}
+	for _, name := range append(append([]string{}, ent.DefaultScopes...), ent.OptionalScopes...) {
+		if !slice.Contains(valid, name) {
+			return errors.Append(model.ErrClientValidateFailed, "No such client scope %s", name)
+		}
+	}
+	return nil
+}
+
+// scopeNames returns the names of the client scopes and the permissions of the API resources in the project
+func (m *Manager) scopeNames(projectName string) ([]string, *errors.Error) {
+	scopes, err := m.clientScope.GetList(projectName, nil)
+	if err != nil {
+		return nil, errors.Append(err, "Failed to get client scope list")
+	}
+	resources, err := m.apiResource.GetList(projectName, nil)
+	if err != nil {
+		return nil, errors.Append(err, "Failed to get API resource list")
+	}
+
+	res := []string{}
+	for _, s := range scopes {
+		res = append(res, s.Name)
+	}
+	for _, r := range resources {
+		res = append(res, r.Scopes...)
+	}
+	return res, nil
+}
+
+// removeUnknownClientScopes deletes the permissions which are removed from the API resources from all clients
+func (m *Manager) removeUnknownClientScopes(projectName string) *errors.Error {
+	valid, err := m.scopeNames(projectName)
+	if err != nil {
+		return err
+	}
+	clis, err := m.client.GetList(projectName, nil)
+	if err != nil {
+		return errors.Append(err, "Failed to get client list")
+	}
+	for _, c := range clis {
+		changed := false
+		for _, name := range append(append([]string{}, c.DefaultScopes...), c.OptionalScopes...) {
+			if !slice.Contains(valid, name) {
+				c.DefaultScopes = removeString(c.DefaultScopes, name)
+				c.OptionalScopes = removeString(c.OptionalScopes, name)
+				changed = true
+			}
+		}
+		if changed {
+			if err := m.client.Update(projectName, c); err != nil {
+				return errors.Append(err, "Failed to delete unknown scopes from client %s", c.ID)
+			}
+		}
+	}
+	return nil
+}
+
 // CustomRoleAdd ...
 func (m *Manager) CustomRoleAdd(projectName string, ent *model.CustomRole) *errors.Error {
 	if err := ent.Validate(); err != nil {
@@ -853,6 +1034,45 @@ func (m *Manager) CustomRoleDelete(projectName string, customRoleID string) *err
 			return errors.Append(err, "Failed to delete custom role from user")
 		}
 
+		// Delete custom role from required roles of all client scope
+		scopes, err := m.clientScope.GetList(projectName, nil)
+		if err != nil {
+			return errors.Append(err, "Failed to get client scope list")
+		}
+		for _, s := range scopes {
+			if !slice.Contains(s.RequiredRoles, customRoleID) {
+				continue
+			}
+			roles := []string{}
+			for _, r := range s.RequiredRoles {
+				if r != customRoleID {
+					roles = append(roles, r)
+				}
+			}
+			s.RequiredRoles = roles
+			if err := m.clientScope.Update(projectName, s); err != nil {
+				return errors.Append(err, "Failed to delete custom role from client scope")
+			}
+		}
+
+		// Delete custom role from MFA policy of the project
+		prjs, err := m.project.GetList(&model.ProjectFilter{Name: projectName})
+		if err != nil {
+			return errors.Append(err, "Failed to get project")
+		}
+		if len(prjs) > 0 && slice.Contains(prjs[0].MFAPolicy.CustomRoles, customRoleID) {
+			roles := []string{}
+			for _, r := range prjs[0].MFAPolicy.CustomRoles {
+				if r != customRoleID {
+					roles = append(roles, r)
+				}
+			}
+			prjs[0].MFAPolicy.CustomRoles = roles
+			if err := m.project.Update(prjs[0]); err != nil {
+				return errors.Append(err, "Failed to delete custom role from MFA policy")
+			}
+		}
+
 		if err := m.customRole.Delete(projectName, customRoleID); err != nil {
 			return errors.Append(err, "Failed to delete customRole")
 		}
@@ -919,6 +1139,261 @@ func (m *Manager) CustomRoleUpdate(projectName string, ent *model.CustomRole) *e
 	})
 }
 
+// APIResourceAdd ...
+func (m *Manager) APIResourceAdd(projectName string, ent *model.APIResource) *errors.Error {
+	if err := ent.Validate(); err != nil {
+		return errors.Append(err, "Failed to validate entry")
+	}
+
+	return m.transaction.Transaction(func() *errors.Error {
+		prjs, err := m.project.GetList(&model.ProjectFilter{Name: projectName})
+		if err != nil {
+			return errors.Append(err, "Failed to get current project")
+		}
+		if len(prjs) == 0 {
+			return model.ErrNoSuchProject
+		}
+
+		// identifier must be unique in project
+		resources, err := m.apiResource.GetList(projectName, &model.APIResourceFilter{Identifier: ent.Identifier})
+		if err != nil {
+			return errors.Append(err, "Failed to get current API resource list")
+		}
+		if len(resources) != 0 {
+			return model.ErrAPIResourceAlreadyExists
+		}
+
+		if err := m.apiResource.Add(projectName, ent); err != nil {
+			return errors.Append(err, "Failed to add API resource")
+		}
+		return nil
+	})
+}
+
+// APIResourceDelete ...
+func (m *Manager) APIResourceDelete(projectName string, resourceID string) *errors.Error {
+	if !model.ValidateAPIResourceID(resourceID) {
+		return model.ErrAPIResourceValidateFailed
+	}
+
+	return m.transaction.Transaction(func() *errors.Error {
+		resources, err := m.apiResource.GetList(projectName, &model.APIResourceFilter{ID: resourceID})
+		if err != nil {
+			return errors.Append(err, "Failed to get current API resource list")
+		}
+		if len(resources) == 0 {
+			return model.ErrNoSuchAPIResource
+		}
+
+		if err := m.apiResource.Delete(projectName, resourceID); err != nil {
+			return errors.Append(err, "Failed to delete API resource")
+		}
+		return m.removeUnknownClientScopes(projectName)
+	})
+}
+
+// APIResourceGetList ...
+func (m *Manager) APIResourceGetList(projectName string, filter *model.APIResourceFilter) ([]*model.APIResource, *errors.Error) {
+	if filter != nil {
+		if filter.ID != "" && !model.ValidateAPIResourceID(filter.ID) {
+			return nil, errors.Append(model.ErrAPIResourceValidateFailed, "Invalid API resource id format")
+		}
+		if filter.Identifier != "" && !model.ValidateAPIResourceIdentifier(filter.Identifier) {
+			return nil, errors.Append(model.ErrAPIResourceValidateFailed, "Invalid API resource identifier format")
+		}
+	}
+	return m.apiResource.GetList(projectName, filter)
+}
+
+// APIResourceGet ...
+func (m *Manager) APIResourceGet(projectName string, resourceID string) (*model.APIResource, *errors.Error) {
+	resources, err := m.APIResourceGetList(projectName, &model.APIResourceFilter{ID: resourceID})
+	if err != nil {
+		return nil, err
+	}
+	if len(resources) == 0 {
+		return nil, errors.Append(model.ErrNoSuchAPIResource, "Failed to get API resource")
+	}
+
+	return resources[0], nil
+}
+
+// APIResourceUpdate ...
+func (m *Manager) APIResourceUpdate(projectName string, ent *model.APIResource) *errors.Error {
+	if err := ent.Validate(); err != nil {
+		return errors.Append(err, "Failed to validate entry")
+	}
+
+	return m.transaction.Transaction(func() *errors.Error {
+		r, err := m.apiResource.GetList(projectName, &model.APIResourceFilter{ID: ent.ID})
+		if err != nil {
+			return errors.Append(err, "Failed to get current API resource list by ID")
+		}
+		if len(r) == 0 {
+			return model.ErrNoSuchAPIResource
+		}
+
+		r, err = m.apiResource.GetList(projectName, &model.APIResourceFilter{Identifier: ent.Identifier})
+		if err != nil {
+			return errors.Append(err, "Failed to get current API resource list by identifier")
+		}
+
+		// check identifier uniquness in project
+		if len(r) > 0 && r[0].ID != ent.ID {
+			return model.ErrAPIResourceAlreadyExists
+		}
+
+		if err := m.apiResource.Update(projectName, ent); err != nil {
+			return errors.Append(err, "Failed to update API resource")
+		}
+		return m.removeUnknownClientScopes(projectName)
+	})
+}
+
+// ClientScopeAdd ...
+func (m *Manager) ClientScopeAdd(projectName string, ent *model.ClientScope) *errors.Error {
+	if err := ent.Validate(); err != nil {
+		return errors.Append(err, "Failed to validate entry")
+	}
+
+	return m.transaction.Transaction(func() *errors.Error {
+		prjs, err := m.project.GetList(&model.ProjectFilter{Name: projectName})
+		if err != nil {
+			return errors.Append(err, "Failed to get current project")
+		}
+		if len(prjs) == 0 {
+			return model.ErrNoSuchProject
+		}
+
+		scopes, err := m.clientScope.GetList(projectName, &model.ClientScopeFilter{Name: ent.Name})
+		if err != nil {
+			return errors.Append(err, "Failed to get current client scope list")
+		}
+		if len(scopes) != 0 {
+			return model.ErrClientScopeAlreadyExists
+		}
+
+		if err := m.checkRequiredRoles(projectName, ent); err != nil {
+			return err
+		}
+
+		if err := m.clientScope.Add(projectName, ent); err != nil {
+			return errors.Append(err, "Failed to add client scope")
+		}
+		return nil
+	})
+}
+
+// ClientScopeDelete ...
+func (m *Manager) ClientScopeDelete(projectName string, name string) *errors.Error {
+	if !model.ValidateClientScopeName(name) {
+		return errors.Append(model.ErrClientScopeValidateFailed, "invalid client scope name format")
+	}
+
+	return m.transaction.Transaction(func() *errors.Error {
+		scopes, err := m.clientScope.GetList(projectName, &model.ClientScopeFilter{Name: name})
+		if err != nil {
+			return errors.Append(err, "Failed to get current client scope list")
+		}
+		if len(scopes) == 0 {
+			return model.ErrNoSuchClientScope
+		}
+
+		// Delete client scope from all client
+		clis, err := m.client.GetList(projectName, nil)
+		if err != nil {
+			return errors.Append(err, "Failed to get client list")
+		}
+		for _, c := range clis {
+			if !slice.Contains(c.DefaultScopes, name) && !slice.Contains(c.OptionalScopes, name) {
+				continue
+			}
+			c.DefaultScopes = removeString(c.DefaultScopes, name)
+			c.OptionalScopes = removeString(c.OptionalScopes, name)
+			if err := m.client.Update(projectName, c); err != nil {
+				return errors.Append(err, "Failed to delete client scope from client %s", c.ID)
+			}
+		}
+
+		if err := m.clientScope.Delete(projectName, name); err != nil {
+			return errors.Append(err, "Failed to delete client scope")
+		}
+		return nil
+	})
+}
+
+// ClientScopeGetList ...
+func (m *Manager) ClientScopeGetList(projectName string, filter *model.ClientScopeFilter) ([]*model.ClientScope, *errors.Error) {
+	if filter != nil {
+		if filter.Name != "" && !model.ValidateClientScopeName(filter.Name) {
+			return nil, errors.Append(model.ErrClientScopeValidateFailed, "Invalid client scope name format")
+		}
+	}
+	return m.clientScope.GetList(projectName, filter)
+}
+
+// ClientScopeGet ...
+func (m *Manager) ClientScopeGet(projectName string, name string) (*model.ClientScope, *errors.Error) {
+	scopes, err := m.ClientScopeGetList(projectName, &model.ClientScopeFilter{Name: name})
+	if err != nil {
+		return nil, err
+	}
+	if len(scopes) == 0 {
+		return nil, errors.Append(model.ErrNoSuchClientScope, "Failed to get client scope")
+	}
+
+	return scopes[0], nil
+}
+
+// ClientScopeUpdate ...
+func (m *Manager) ClientScopeUpdate(projectName string, ent *model.ClientScope) *errors.Error {
+	if err := ent.Validate(); err != nil {
+		return errors.Append(err, "Failed to validate entry")
+	}
+
+	return m.transaction.Transaction(func() *errors.Error {
+		scopes, err := m.clientScope.GetList(projectName, &model.ClientScopeFilter{Name: ent.Name})
+		if err != nil {
+			return errors.Append(err, "Failed to get current client scope list")
+		}
+		if len(scopes) == 0 {
+			return model.ErrNoSuchClientScope
+		}
+
+		if err := m.checkRequiredRoles(projectName, ent); err != nil {
+			return err
+		}
+
+		if err := m.clientScope.Update(projectName, ent); err != nil {
+			return errors.Append(err, "Failed to update client scope")
+		}
+		return nil
+	})
+}
+
+func (m *Manager) checkRequiredRoles(projectName string, ent *model.ClientScope) *errors.Error {
+	for _, r := range ent.RequiredRoles {
+		roles, err := m.customRole.GetList(projectName, &model.CustomRoleFilter{ID: r})
+		if err != nil {
+			return errors.Append(err, "Custom role get error")
+		}
+		if len(roles) == 0 {
+			return errors.Append(model.ErrClientScopeValidateFailed, "No such custom role %s", r)
+		}
+	}
+	return nil
+}
+
+func removeString(list []string, target string) []string {
+	res := []string{}
+	for _, v := range list {
+		if v != target {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
 // DeviceAdd ...
 func (m *Manager) DeviceAdd(projectName string, ent *model.Device) *errors.Error {
 	if err := ent.Validate(); err != nil {
@@ -933,6 +1408,20 @@ func (m *Manager) DeviceAdd(projectName string, ent *model.Device) *errors.Error
 	})
 }
 
+// DeviceUpdate ...
+func (m *Manager) DeviceUpdate(projectName string, ent *model.Device) *errors.Error {
+	if err := ent.Validate(); err != nil {
+		return errors.Append(err, "Failed to validate entry")
+	}
+
+	return m.transaction.Transaction(func() *errors.Error {
+		if err := m.device.Update(projectName, ent); err != nil {
+			return errors.Append(err, "Failed to update device")
+		}
+		return nil
+	})
+}
+
 // DeviceDelete ...
 func (m *Manager) DeviceDelete(projectName string, deviceCode string) *errors.Error {
 	// TODO validate deviceCode
@@ -963,6 +1452,68 @@ func (m *Manager) DeviceGetList(projectName string, filter *model.DeviceFilter)
 	return m.device.GetList(projectName, filter)
 }
 
+// BackchannelAuthAdd ...
+func (m *Manager) BackchannelAuthAdd(projectName string, ent *model.BackchannelAuth) *errors.Error {
+	if err := ent.Validate(); err != nil {
+		return errors.Append(err, "Failed to validate entry")
+	}
+
+	return m.transaction.Transaction(func() *errors.Error {
+		if err := m.backchannelAuth.Add(projectName, ent); err != nil {
+			return errors.Append(err, "Failed to add backchannel auth request")
+		}
+		return nil
+	})
+}
+
+// BackchannelAuthGet ...
+func (m *Manager) BackchannelAuthGet(projectName string, authReqID string) (*model.BackchannelAuth, *errors.Error) {
+	if authReqID == "" {
+		return nil, errors.Append(model.ErrBackchannelAuthValidateFailed, "auth_req_id is empty")
+	}
+
+	reqs, err := m.backchannelAuth.GetList(projectName, &model.BackchannelAuthFilter{AuthReqID: authReqID})
+	if err != nil {
+		return nil, err
+	}
+	if len(reqs) == 0 {
+		return nil, model.ErrNoSuchBackchannelAuth
+	}
+	return reqs[0], nil
+}
+
+// BackchannelAuthGetList ...
+func (m *Manager) BackchannelAuthGetList(projectName string, filter *model.BackchannelAuthFilter) ([]*model.BackchannelAuth, *errors.Error) {
+	if filter != nil && filter.UserID != "" && !model.ValidateUserID(filter.UserID) {
+		return nil, errors.Append(model.ErrBackchannelAuthValidateFailed, "Invalid user ID format")
+	}
+	return m.backchannelAuth.GetList(projectName, filter)
+}
+
+// BackchannelAuthUpdate ...
+func (m *Manager) BackchannelAuthUpdate(projectName string, ent *model.BackchannelAuth) *errors.Error {
+	if err := ent.Validate(); err != nil {
+		return errors.Append(err, "Failed to validate entry")
+	}
+
+	return m.transaction.Transaction(func() *errors.Error {
+		if err := m.backchannelAuth.Update(projectName, ent); err != nil {
+			return errors.Append(err, "Failed to update backchannel auth request")
+		}
+		return nil
+	})
+}
+
+// BackchannelAuthDelete ...
+func (m *Manager) BackchannelAuthDelete(projectName string, authReqID string) *errors.Error {
+	return m.transaction.Transaction(func() *errors.Error {
+		if err := m.backchannelAuth.Delete(projectName, authReqID); err != nil {
+			return errors.Append(err, "Failed to delete backchannel auth request")
+		}
+		return nil
+	})
+}
+
 // OTPAdd ...
 func (m *Manager) OTPAdd(projectName string, userID string, ent *model.OTPInfo) *errors.Error {
 	// otp add is used in internal only, so validation is not required
@@ -989,6 +1540,308 @@ func (m *Manager) OTPAdd(projectName string, userID string, ent *model.OTPInfo)
 	})
 }
 
+// OTPStepUse records the time step of the accepted TOTP code
+// It returns model.ErrOTPCodeAlreadyUsed if the code of the step or later is already used.
+func (m *Manager) OTPStepUse(projectName string, userID string, step int64) *errors.Error {
+	return m.transaction.Transaction(func() *errors.Error {
+		usr, err := m.getUser(projectName, userID)
+		if err != nil {
+			return errors.Append(err, "Failed to get user of OTP step use")
+		}
+
+		if step <= usr.OTPInfo.LastUsedStep {
+			return model.ErrOTPCodeAlreadyUsed
+		}
+
+		usr.OTPInfo.LastUsedStep = step
+		if err := m.user.Update(projectName, usr); err != nil {
+			return errors.Append(err, "Failed to update user")
+		}
+		return nil
+	})
+}
+
+// OTPRecoveryCodesSet replaces the recovery codes of the user
+func (m *Manager) OTPRecoveryCodesSet(projectName string, userID string, hashes []string) *errors.Error {
+	return m.transaction.Transaction(func() *errors.Error {
+		usr, err := m.getUser(projectName, userID)
+		if err != nil {
+			return errors.Append(err, "Failed to get user of OTP recovery codes set")
+		}
+		if !usr.OTPInfo.Enabled {
+			return model.ErrUserOTPNotEnabled
+		}
+
+		usr.OTPInfo.RecoveryCodes = hashes
+		if err := m.user.Update(projectName, usr); err != nil {
+			return errors.Append(err, "Failed to update user")
+		}
+		return nil
+	})
+}
+
+// OTPRecoveryCodeUse removes the recovery code from the user, and returns the number of remaining codes
+// It returns model.ErrNoSuchRecoveryCode if the code is not found, e.g. already used.
+func (m *Manager) OTPRecoveryCodeUse(projectName string, userID string, hash string) (int, *errors.Error) {
+	remaining := 0
+	err := m.transaction.Transaction(func() *errors.Error {
+		usr, err := m.getUser(projectName, userID)
+		if err != nil {
+			return errors.Append(err, "Failed to get user of OTP recovery code use")
+		}
+		if !usr.OTPInfo.Enabled {
+			return model.ErrUserOTPNotEnabled
+		}
+
+		codes := []string{}
+		found := false
+		for _, c := range usr.OTPInfo.RecoveryCodes {
+			if !found && c == hash {
+				found = true
+				continue
+			}
+			codes = append(codes, c)
+		}
+		if !found {
+			return model.ErrNoSuchRecoveryCode
+		}
+
+		usr.OTPInfo.RecoveryCodes = codes
+		if err := m.user.Update(projectName, usr); err != nil {
+			return errors.Append(err, "Failed to update user")
+		}
+		remaining = len(codes)
+		return nil
+	})
+	return remaining, err
+}
+
+// WebAuthnRegistrationStart saves the challenge of the registration ceremony to the user
+func (m *Manager) WebAuthnRegistrationStart(projectName string, userID string, challenge string, expiresAt time.Time) *errors.Error {
+	return m.transaction.Transaction(func() *errors.Error {
+		usr, err := m.getUser(projectName, userID)
+		if err != nil {
+			return errors.Append(err, "Failed to get user of WebAuthn registration")
+		}
+
+		usr.WebAuthnInfo.RegistrationChallenge = challenge
+		usr.WebAuthnInfo.RegistrationExpiresAt = expiresAt
+		if err := m.user.Update(projectName, usr); err != nil {
+			return errors.Append(err, "Failed to update user")
+		}
+		return nil
+	})
+}
+
+// WebAuthnCredentialAdd adds the registered credential to the user, and finishes the registration ceremony
+func (m *Manager) WebAuthnCredentialAdd(projectName string, userID string, ent *model.WebAuthnCredential) *errors.Error {
+	if err := ent.Validate(); err != nil {
+		return errors.Append(err, "Failed to validate entry")
+	}
+
+	return m.transaction.Transaction(func() *errors.Error {
+		users, err := m.user.GetList(projectName, nil)
+		if err != nil {
+			return errors.Append(err, "Failed to get user list of WebAuthn credential add")
+		}
+
+		var usr *model.UserInfo
+		for _, u := range users {
+			// the credential id must be unique in the relying party
+			if u.GetWebAuthnCredential(ent.ID) != nil {
+				return model.ErrWebAuthnCredentialAlreadyExists
+			}
+			if u.ID == userID {
+				usr = u
+			}
+		}
+		if usr == nil {
+			return model.ErrNoSuchUser
+		}
+
+		usr.WebAuthnInfo.Credentials = append(usr.WebAuthnInfo.Credentials, *ent)
+		usr.WebAuthnInfo.RegistrationChallenge = ""
+		usr.WebAuthnInfo.RegistrationExpiresAt = time.Time{}
+		if err := m.user.Update(projectName, usr); err != nil {
+			return errors.Append(err, "Failed to update user")
+		}
+		return nil
+	})
+}
+
+// WebAuthnCredentialUpdate updates the signature counter and the last used time of the credential
+func (m *Manager) WebAuthnCredentialUpdate(projectName string, userID string, ent *model.WebAuthnCredential) *errors.Error {
+	if err := ent.Validate(); err != nil {
+		return errors.Append(err, "Failed to validate entry")
+	}
+
+	return m.transaction.Transaction(func() *errors.Error {
+		usr, err := m.getUser(projectName, userID)
+		if err != nil {
+			return errors.Append(err, "Failed to get user of WebAuthn credential update")
+		}
+
+		c := usr.GetWebAuthnCredential(ent.ID)
+		if c == nil {
+			return model.ErrNoSuchWebAuthnCredential
+		}
+		c.SignCount = ent.SignCount
+		c.LastUsedAt = ent.LastUsedAt
+		if err := m.user.Update(projectName, usr); err != nil {
+			return errors.Append(err, "Failed to update user")
+		}
+		return nil
+	})
+}
+
+// WebAuthnCredentialDelete ...
+func (m *Manager) WebAuthnCredentialDelete(projectName string, userID string, credentialID string) *errors.Error {
+	return m.transaction.Transaction(func() *errors.Error {
+		usr, err := m.getUser(projectName, userID)
+		if err != nil {
+			return errors.Append(err, "Failed to get user of WebAuthn credential delete")
+		}
+
+		creds := []model.WebAuthnCredential{}
+		for _, c := range usr.WebAuthnInfo.Credentials {
+			if c.ID != credentialID {
+				creds = append(creds, c)
+			}
+		}
+		if len(creds) == len(usr.WebAuthnInfo.Credentials) {
+			return model.ErrNoSuchWebAuthnCredential
+		}
+
+		usr.WebAuthnInfo.Credentials = creds
+		if err := m.user.Update(projectName, usr); err != nil {
+			return errors.Append(err, "Failed to update user")
+		}
+		return nil
+	})
+}
+
+// TrustedDeviceAdd adds the device which skips the second factor, and removes the expired devices
+func (m *Manager) TrustedDeviceAdd(projectName string, userID string, ent *model.TrustedDevice) *errors.Error {
+	return m.transaction.Transaction(func() *errors.Error {
+		usr, err := m.getUser(projectName, userID)
+		if err != nil {
+			return errors.Append(err, "Failed to get user of trusted device add")
+		}
+
+		devices := []model.TrustedDevice{}
+		for _, d := range usr.TrustedDevices {
+			if ent.CreatedAt.Before(d.ExpiresAt) {
+				devices = append(devices, d)
+			}
+		}
+
+		usr.TrustedDevices = append(devices, *ent)
+		if err := m.user.Update(projectName, usr); err != nil {
+			return errors.Append(err, "Failed to update user")
+		}
+		return nil
+	})
+}
+
+// TrustedDeviceUse updates the last used time of the trusted device
+// It returns model.ErrNoSuchTrustedDevice if the device is not found or expired.
+func (m *Manager) TrustedDeviceUse(projectName string, userID string, deviceID string, now time.Time) *errors.Error {
+	return m.transaction.Transaction(func() *errors.Error {
+		usr, err := m.getUser(projectName, userID)
+		if err != nil {
+			return errors.Append(err, "Failed to get user of trusted device use")
+		}
+
+		d := usr.GetTrustedDevice(deviceID, now)
+		if d == nil {
+			return model.ErrNoSuchTrustedDevice
+		}
+		d.LastUsedAt = now
+		if err := m.user.Update(projectName, usr); err != nil {
+			return errors.Append(err, "Failed to update user")
+		}
+		return nil
+	})
+}
+
+// TrustedDeviceDelete revokes the trusted device
+func (m *Manager) TrustedDeviceDelete(projectName string, userID string, deviceID string) *errors.Error {
+	return m.transaction.Transaction(func() *errors.Error {
+		usr, err := m.getUser(projectName, userID)
+		if err != nil {
+			return errors.Append(err, "Failed to get user of trusted device delete")
+		}
+
+		devices := []model.TrustedDevice{}
+		for _, d := range usr.TrustedDevices {
+			if d.ID != deviceID {
+				devices = append(devices, d)
+			}
+		}
+		if len(devices) == len(usr.TrustedDevices) {
+			return model.ErrNoSuchTrustedDevice
+		}
+
+		usr.TrustedDevices = devices
+		if err := m.user.Update(projectName, usr); err != nil {
+			return errors.Append(err, "Failed to update user")
+		}
+		return nil
+	})
+}
+
+// LoginHistoryAdd records the successful login of the user, and removes the old records over model.MaxLoginHistory
+func (m *Manager) LoginHistoryAdd(projectName string, userID string, ent *model.LoginRecord) *errors.Error {
+	return m.transaction.Transaction(func() *errors.Error {
+		usr, err := m.getUser(projectName, userID)
+		if err != nil {
+			return errors.Append(err, "Failed to get user of login history add")
+		}
+
+		history := append(usr.LoginHistory, *ent)
+		if len(history) > model.MaxLoginHistory {
+			history = history[len(history)-model.MaxLoginHistory:]
+		}
+
+		usr.LoginHistory = history
+		if err := m.user.Update(projectName, usr); err != nil {
+			return errors.Append(err, "Failed to update user")
+		}
+		return nil
+	})
+}
+
+// validateMFAPolicy checks that the roles in the MFA policy exist
+func (m *Manager) validateMFAPolicy(ent *model.ProjectInfo) *errors.Error {
+	for _, r := range ent.MFAPolicy.SystemRoles {
+		if _, _, ok := role.GetInst().Parse(r); !ok {
+			return errors.Append(model.ErrProjectValidateFailed, "Invalid system role %s in MFA policy", r)
+		}
+	}
+
+	for _, r := range ent.MFAPolicy.CustomRoles {
+		roles, err := m.customRole.GetList(ent.Name, &model.CustomRoleFilter{ID: r})
+		if err != nil {
+			return errors.Append(err, "Custom role get error")
+		}
+		if len(roles) == 0 {
+			return errors.Append(model.ErrProjectValidateFailed, "No such custom role %s in MFA policy", r)
+		}
+	}
+	return nil
+}
+
+func (m *Manager) getUser(projectName string, userID string) (*model.UserInfo, *errors.Error) {
+	users, err := m.user.GetList(projectName, &model.UserFilter{ID: userID})
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, model.ErrNoSuchUser
+	}
+	return users[0], nil
+}
+
 // DeleteExpiredSessions ...
 func (m *Manager) DeleteExpiredSessions() *errors.Error {
 	now := time.Now()
@@ -1006,6 +1859,10 @@ func (m *Manager) DeleteExpiredSessions() *errors.Error {
 			return errors.Append(err, "Failed to cleanup devices")
 		}
 
+		if err := m.backchannelAuth.Cleanup(now); err != nil {
+			return errors.Append(err, "Failed to cleanup backchannel auth requests")
+		}
+
 		return nil
 	})
 }
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"sync"
 	"testing"
 	"time"
 
@@ -13,6 +14,7 @@ func TestProjectAdd(t *testing.T) {
 	mgr := &Manager{
 		client:      memory.NewClientHandler(),
 		project:     memory.NewProjectHandler(),
+		clientScope: memory.NewClientScopeHandler(),
 		transaction: memory.NewTransactionManager(),
 	}
 
@@ -37,9 +39,118 @@ func TestProjectAdd(t *testing.T) {
 		t.Errorf("Failed to register portal client, expect 1 client, but got %d", len(clis))
 	}
 
+	// Check default client scopes exist
+	scopes, _ := mgr.clientScope.GetList(prjInfo.Name, nil)
+	if len(scopes) != len(model.DefaultClientScopes(prjInfo.Name, time.Now())) {
+		t.Errorf("Failed to register default client scopes, got %d scopes", len(scopes))
+	}
+
 	// Test Duplicate Project Name
 	err := mgr.ProjectAdd(prjInfo)
 	if !errors.Contains(err, model.ErrProjectAlreadyExists) {
 		t.Errorf("Expect error is %v, but got %v", model.ErrProjectAlreadyExists, err)
 	}
 }
+
+func TestLoginSessionRedeemCode(t *testing.T) {
+	mgr := &Manager{
+		loginSession: memory.NewLoginSessionHandler(),
+		transaction:  memory.NewTransactionManager(),
+	}
+
+	projectName := "test-project"
+	sessionID := "session-id"
+	mgr.loginSession.Add(projectName, &model.LoginSession{ProjectName: projectName, SessionID: sessionID})
+
+	// only one of the concurrent redemptions succeeds
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := mgr.LoginSessionRedeemCode(projectName, sessionID)
+			if err != nil && !errors.Contains(err, model.ErrLoginSessionCodeRedeemed) {
+				t.Errorf("Expect error is %v, but got %v", model.ErrLoginSessionCodeRedeemed, err)
+			}
+			if err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if succeeded != 1 {
+		t.Errorf("Expect 1 redemption succeeded, but got %d", succeeded)
+	}
+}
+
+func TestClientScopesOfAPIResource(t *testing.T) {
+	mgr := &Manager{
+		client:      memory.NewClientHandler(),
+		project:     memory.NewProjectHandler(),
+		clientScope: memory.NewClientScopeHandler(),
+		apiResource: memory.NewAPIResourceHandler(),
+		transaction: memory.NewTransactionManager(),
+	}
+
+	prjInfo := &model.ProjectInfo{
+		Name:      "test-project",
+		CreatedAt: time.Now(),
+		TokenConfig: &model.TokenConfig{
+			AccessTokenLifeSpan:  1,
+			RefreshTokenLifeSpan: 1,
+			SigningAlgorithm:     "RS256",
+		},
+	}
+	if err := mgr.ProjectAdd(prjInfo); err != nil {
+		t.Fatalf("Failed to add project: %v", err)
+	}
+
+	resource := &model.APIResource{
+		ID:          "b8c1e0a6-3f1d-4d8e-9f55-1c7f2b1f0a01",
+		ProjectName: prjInfo.Name,
+		CreatedAt:   time.Now(),
+		Identifier:  "https://api.example.com",
+		Scopes:      []string{"read", "write"},
+	}
+	if err := mgr.APIResourceAdd(prjInfo.Name, resource); err != nil {
+		t.Fatalf("Failed to add API resource: %v", err)
+	}
+
+	cli := &model.ClientInfo{
+		ID:             "client1",
+		ProjectName:    prjInfo.Name,
+		AccessType:     "public",
+		CreatedAt:      time.Now(),
+		OptionalScopes: []string{"openid", "read", "write"},
+	}
+	if err := mgr.ClientAdd(prjInfo.Name, cli); err != nil {
+		t.Fatalf("Failed to add client with permissions of API resource: %v", err)
+	}
+
+	// Test unknown scope
+	cli2 := &model.ClientInfo{
+		ID:             "client2",
+		ProjectName:    prjInfo.Name,
+		AccessType:     "public",
+		CreatedAt:      time.Now(),
+		OptionalScopes: []string{"delete"},
+	}
+	if err := mgr.ClientAdd(prjInfo.Name, cli2); !errors.Contains(err, model.ErrClientValidateFailed) {
+		t.Errorf("Expect error is %v, but got %v", model.ErrClientValidateFailed, err)
+	}
+
+	// Test the removed permission is also removed from the client
+	resource.Scopes = []string{"read"}
+	if err := mgr.APIResourceUpdate(prjInfo.Name, resource); err != nil {
+		t.Fatalf("Failed to update API resource: %v", err)
+	}
+	clis, _ := mgr.client.GetList(prjInfo.Name, &model.ClientFilter{ID: cli.ID})
+	if len(clis) != 1 || len(clis[0].OptionalScopes) != 2 || clis[0].OptionalScopes[1] != "read" {
+		t.Errorf("Failed to remove permission from client, got %v", clis)
+	}
+}
@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+)
+
+// APIResourceHandler implement db.APIResourceHandler
+type APIResourceHandler struct {
+	// resourceList[resourceID] = APIResource
+	resourceList map[string]*model.APIResource
+}
+
+// NewAPIResourceHandler ...
+func NewAPIResourceHandler() *APIResourceHandler {
+	res := &APIResourceHandler{
+		resourceList: make(map[string]*model.APIResource),
+	}
+	return res
+}
+
+// Add ...
+func (h *APIResourceHandler) Add(projectName string, ent *model.APIResource) *errors.Error {
+	h.resourceList[ent.ID] = ent
+	return nil
+}
+
+// Delete ...
+func (h *APIResourceHandler) Delete(projectName string, resourceID string) *errors.Error {
+	if res, exists := h.resourceList[resourceID]; exists && res.ProjectName == projectName {
+		delete(h.resourceList, resourceID)
+		return nil
+	}
+	return model.ErrNoSuchAPIResource
+}
+
+// GetList ...
+func (h *APIResourceHandler) GetList(projectName string, filter *model.APIResourceFilter) ([]*model.APIResource, *errors.Error) {
+	res := []*model.APIResource{}
+
+	for _, r := range h.resourceList {
+		if r.ProjectName == projectName {
+			res = append(res, r)
+		}
+	}
+
+	if filter != nil {
+		res = matchFilterAPIResourceList(res, projectName, filter)
+	}
+
+	return res, nil
+}
+
+// Update ...
+func (h *APIResourceHandler) Update(projectName string, ent *model.APIResource) *errors.Error {
+	if res, exists := h.resourceList[ent.ID]; !exists || res.ProjectName != projectName {
+		return model.ErrNoSuchAPIResource
+	}
+
+	h.resourceList[ent.ID] = ent
+
+	return nil
+}
+
+// DeleteAll ...
+func (h *APIResourceHandler) DeleteAll(projectName string) *errors.Error {
+	for _, r := range h.resourceList {
+		if r.ProjectName == projectName {
+			delete(h.resourceList, r.ID)
+		}
+	}
+	return nil
+}
+
+// matchFilterAPIResourceList returns a list which matches the filter rules
+func matchFilterAPIResourceList(data []*model.APIResource, projectName string, filter *model.APIResourceFilter) []*model.APIResource {
+	if filter == nil {
+		return data
+	}
+	res := []*model.APIResource{}
+
+	for _, r := range data {
+		if projectName == r.ProjectName {
+			if filter.ID != "" && r.ID != filter.ID {
+				// missmatch id
+				continue
+			}
+
+			if filter.Identifier != "" && r.Identifier != filter.Identifier {
+				// missmatch identifier
+				continue
+			}
+		}
+
+		res = append(res, r)
+	}
+
+	return res
+}
@@ -0,0 +1,123 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+)
+
+// BackchannelAuthHandler implement db.BackchannelAuthHandler
+type BackchannelAuthHandler struct {
+	requests []*model.BackchannelAuth
+}
+
+// NewBackchannelAuthHandler ...
+func NewBackchannelAuthHandler() *BackchannelAuthHandler {
+	return &BackchannelAuthHandler{}
+}
+
+// Add ...
+func (h *BackchannelAuthHandler) Add(projectName string, ent *model.BackchannelAuth) *errors.Error {
+	h.requests = append(h.requests, ent)
+	return nil
+}
+
+// Update ...
+func (h *BackchannelAuthHandler) Update(projectName string, ent *model.BackchannelAuth) *errors.Error {
+	for i, r := range h.requests {
+		if r.ProjectName == projectName && r.AuthReqID == ent.AuthReqID {
+			h.requests[i] = ent
+			return nil
+		}
+	}
+	return model.ErrNoSuchBackchannelAuth
+}
+
+// DeleteAll ...
+func (h *BackchannelAuthHandler) DeleteAll(projectName string) *errors.Error {
+	newList := []*model.BackchannelAuth{}
+	for _, r := range h.requests {
+		if r.ProjectName != projectName {
+			newList = append(newList, r)
+		}
+	}
+
+	h.requests = newList
+	return nil
+}
+
+// Cleanup ...
+func (h *BackchannelAuthHandler) Cleanup(now time.Time) *errors.Error {
+	newList := []*model.BackchannelAuth{}
+	for _, r := range h.requests {
+		if !r.Expired(now) {
+			newList = append(newList, r)
+		}
+	}
+
+	h.requests = newList
+	return nil
+}
+
+// GetList ...
+func (h *BackchannelAuthHandler) GetList(projectName string, filter *model.BackchannelAuthFilter) ([]*model.BackchannelAuth, *errors.Error) {
+	res := []*model.BackchannelAuth{}
+
+	for _, r := range h.requests {
+		if r.ProjectName == projectName {
+			res = append(res, r)
+		}
+	}
+
+	if filter != nil {
+		res = matchFilterBackchannelAuthList(res, projectName, filter)
+	}
+
+	return res, nil
+}
+
+// Delete ...
+func (h *BackchannelAuthHandler) Delete(projectName string, authReqID string) *errors.Error {
+	newList := []*model.BackchannelAuth{}
+	found := false
+	for _, r := range h.requests {
+		if r.ProjectName == projectName && r.AuthReqID == authReqID {
+			found = true
+		} else {
+			newList = append(newList, r)
+		}
+	}
+
+	if found {
+		h.requests = newList
+		return nil
+	}
+	return model.ErrNoSuchBackchannelAuth
+}
+
+// matchFilterBackchannelAuthList returns a list which matches the filter rules
+func matchFilterBackchannelAuthList(data []*model.BackchannelAuth, projectName string, filter *model.BackchannelAuthFilter) []*model.BackchannelAuth {
+	if filter == nil {
+		return data
+	}
+	res := []*model.BackchannelAuth{}
+
+	for _, r := range data {
+		if projectName == r.ProjectName {
+			if filter.AuthReqID != "" && r.AuthReqID != filter.AuthReqID {
+				// missmatch auth request id
+				continue
+			}
+
+			if filter.UserID != "" && r.UserID != filter.UserID {
+				// missmatch user id
+				continue
+			}
+		}
+
+		res = append(res, r)
+	}
+
+	return res
+}
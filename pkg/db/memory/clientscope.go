@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+)
+
+// ClientScopeHandler implement db.ClientScopeHandler
+type ClientScopeHandler struct {
+	scopeList []*model.ClientScope
+}
+
+// NewClientScopeHandler ...
+func NewClientScopeHandler() *ClientScopeHandler {
+	res := &ClientScopeHandler{}
+	return res
+}
+
+// Add ...
+func (h *ClientScopeHandler) Add(projectName string, ent *model.ClientScope) *errors.Error {
+	h.scopeList = append(h.scopeList, ent)
+	return nil
+}
+
+// Delete ...
+func (h *ClientScopeHandler) Delete(projectName string, name string) *errors.Error {
+	newList := []*model.ClientScope{}
+	found := false
+	for _, s := range h.scopeList {
+		if s.ProjectName == projectName && s.Name == name {
+			found = true
+		} else {
+			newList = append(newList, s)
+		}
+	}
+
+	if found {
+		h.scopeList = newList
+		return nil
+	}
+	return errors.New("Internal Error", "No such client scope %s", name)
+}
+
+// GetList ...
+func (h *ClientScopeHandler) GetList(projectName string, filter *model.ClientScopeFilter) ([]*model.ClientScope, *errors.Error) {
+	res := []*model.ClientScope{}
+
+	for _, s := range h.scopeList {
+		if s.ProjectName != projectName {
+			continue
+		}
+		if filter != nil && filter.Name != "" && s.Name != filter.Name {
+			continue
+		}
+		res = append(res, s)
+	}
+
+	return res, nil
+}
+
+// Update ...
+func (h *ClientScopeHandler) Update(projectName string, ent *model.ClientScope) *errors.Error {
+	for i, s := range h.scopeList {
+		if s.ProjectName == projectName && s.Name == ent.Name {
+			h.scopeList[i] = ent
+			return nil
+		}
+	}
+
+	return errors.New("Internal Error", "No such client scope %s", ent.Name)
+}
+
+// DeleteAll ...
+func (h *ClientScopeHandler) DeleteAll(projectName string) *errors.Error {
+	newList := []*model.ClientScope{}
+	for _, s := range h.scopeList {
+		if s.ProjectName != projectName {
+			newList = append(newList, s)
+		}
+	}
+	h.scopeList = newList
+	return nil
+}
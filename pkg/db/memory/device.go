@@ -23,6 +23,17 @@ func (h *DeviceHandler) Add(projectName string, ent *model.Device) *errors.Error
 	return nil
 }
 
+// Update ...
+func (h *DeviceHandler) Update(projectName string, ent *model.Device) *errors.Error {
+	for i, d := range h.devices {
+		if d.ProjectName == projectName && d.DeviceCode == ent.DeviceCode {
+			h.devices[i] = ent
+			return nil
+		}
+	}
+	return model.ErrNoSuchDevice
+}
+
 // DeleteAll ...
 func (h *DeviceHandler) DeleteAll(projectName string) *errors.Error {
 	newList := []*model.Device{}
@@ -40,8 +51,7 @@ func (h *DeviceHandler) DeleteAll(projectName string) *errors.Error {
 func (h *DeviceHandler) Cleanup(now time.Time) *errors.Error {
 	newList := []*model.Device{}
 	for _, s := range h.devices {
-		expire := s.CreatedAt.Add(time.Second * time.Duration(s.ExpiresIn))
-		if now.Before(expire) {
+		if !s.Expired(now) {
 			newList = append(newList, s)
 		}
 	}
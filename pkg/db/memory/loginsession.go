@@ -64,6 +64,21 @@ func (h *LoginSessionHandler) Get(projectName string, id string) (*model.LoginSe
 	return nil, model.ErrNoSuchLoginSession
 }
 
+// RedeemCode ...
+func (h *LoginSessionHandler) RedeemCode(projectName string, sessionID string) *errors.Error {
+	for _, s := range h.sessionList {
+		if s.ProjectName == projectName && s.SessionID == sessionID {
+			if s.CodeRedeemed {
+				return model.ErrLoginSessionCodeRedeemed
+			}
+			s.CodeRedeemed = true
+			return nil
+		}
+	}
+
+	return model.ErrNoSuchLoginSession
+}
+
 // DeleteAll ...
 func (h *LoginSessionHandler) DeleteAll(projectName string) *errors.Error {
 	newList := []*model.LoginSession{}
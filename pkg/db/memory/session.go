@@ -92,6 +92,14 @@ func matchFilterSessionList(data []*model.Session, projectName string, filter *m
 				// missmatch user id
 				continue
 			}
+			if filter.FamilyID != "" && s.FamilyID != filter.FamilyID {
+				// missmatch family id
+				continue
+			}
+			if filter.LoginSessionID != "" && s.LoginSessionID != filter.LoginSessionID {
+				// missmatch login session id
+				continue
+			}
 		}
 		res = append(res, s)
 	}
@@ -115,6 +123,14 @@ func missMatchFilterSessionList(data []*model.Session, projectName string, filte
 				// matched to user id
 				continue
 			}
+			if filter.FamilyID != "" && s.FamilyID == filter.FamilyID {
+				// matched to family id
+				continue
+			}
+			if filter.LoginSessionID != "" && s.LoginSessionID == filter.LoginSessionID {
+				// matched to login session id
+				continue
+			}
 		}
 		res = append(res, s)
 	}
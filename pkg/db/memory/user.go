@@ -181,6 +181,10 @@ func matchFilterUserList(data []*model.UserInfo, projectName string, filter *mod
 				// missmatch id
 				continue
 			}
+			if filter.EMail != "" && user.EMail != filter.EMail {
+				// missmatch email
+				continue
+			}
 		}
 		res = append(res, user)
 	}
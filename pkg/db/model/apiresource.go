@@ -0,0 +1,95 @@
+package model
+
+import (
+	"time"
+
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/stretchr/stew/slice"
+)
+
+// APIResource is a protected resource which a client requests the access token for
+//   ref. https://tools.ietf.org/html/rfc8707
+type APIResource struct {
+	ID          string
+	ProjectName string
+	CreatedAt   time.Time
+
+	// Identifier is an absolute URI of the resource, and is set to aud claim of the access token
+	Identifier string
+	Name       string
+	// Scopes is a list of permissions of the resource
+	// The client can request them only if they are in the default or optional scopes of the client.
+	Scopes []string
+	// RequiredRoles is a map from the permission to the list of custom role IDs.
+	// If the permission has roles, it is granted only to the user who has at least one of them.
+	RequiredRoles map[string][]string
+	// AccessTokenLifeSpan overwrites the life span of the access token for the resource if it is not 0
+	AccessTokenLifeSpan uint
+}
+
+// APIResourceFilter ...
+type APIResourceFilter struct {
+	ID         string
+	Identifier string
+}
+
+var (
+	// ErrNoSuchAPIResource ...
+	ErrNoSuchAPIResource = errors.New("No such API resource", "No such API resource")
+
+	// ErrAPIResourceAlreadyExists ...
+	ErrAPIResourceAlreadyExists = errors.New("API resource already exists", "API resource already exists")
+
+	// ErrAPIResourceValidateFailed ...
+	ErrAPIResourceValidateFailed = errors.New("API resource validation failed", "API resource validation failed")
+)
+
+// APIResourceHandler ...
+type APIResourceHandler interface {
+	Add(projectName string, ent *APIResource) *errors.Error
+	Delete(projectName string, resourceID string) *errors.Error
+	GetList(projectName string, filter *APIResourceFilter) ([]*APIResource, *errors.Error)
+	Update(projectName string, ent *APIResource) *errors.Error
+	DeleteAll(projectName string) *errors.Error
+}
+
+// Validate ...
+func (r *APIResource) Validate() *errors.Error {
+	if !ValidateAPIResourceID(r.ID) {
+		return errors.Append(ErrAPIResourceValidateFailed, "Invalid API Resource ID format")
+	}
+
+	if !ValidateProjectName(r.ProjectName) {
+		return errors.Append(ErrAPIResourceValidateFailed, "Invalid Project Name format")
+	}
+
+	if !ValidateAPIResourceIdentifier(r.Identifier) {
+		return errors.Append(ErrAPIResourceValidateFailed, "Invalid API Resource Identifier %s", r.Identifier)
+	}
+
+	if len(r.Name) >= 64 {
+		return errors.Append(ErrAPIResourceValidateFailed, "API Resource Name is too long")
+	}
+
+	for _, s := range r.Scopes {
+		if !ValidateClientScopeName(s) {
+			return errors.Append(ErrAPIResourceValidateFailed, "Invalid scope %s", s)
+		}
+	}
+
+	for s := range r.RequiredRoles {
+		if !slice.Contains(r.Scopes, s) {
+			return errors.Append(ErrAPIResourceValidateFailed, "Required roles are set to unknown scope %s", s)
+		}
+	}
+
+	return nil
+}
+
+// LifeSpan returns the life span of the access token for the resource
+func (r *APIResource) LifeSpan(defaultLifeSpan uint) uint {
+	if r.AccessTokenLifeSpan != 0 {
+		return r.AccessTokenLifeSpan
+	}
+	return defaultLifeSpan
+}
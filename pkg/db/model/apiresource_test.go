@@ -0,0 +1,28 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestValidateAPIResourceIdentifier(t *testing.T) {
+	tt := []struct {
+		identifier string
+		expect     bool
+	}{
+		{"https://api.example.com", true},
+		{"https://api.example.com/orders", true},
+		{"urn:example:orders", true},
+		{"api.example.com", false},
+		{"/orders", false},
+		{"https://api.example.com/orders#v1", false},
+		{"https://api.example.com/#", false},
+		{"", false},
+	}
+
+	for _, tc := range tt {
+		res := ValidateAPIResourceIdentifier(tc.identifier)
+		if res != tc.expect {
+			t.Errorf("ValidateAPIResourceIdentifier(%s) returns %v, but expect %v", tc.identifier, res, tc.expect)
+		}
+	}
+}
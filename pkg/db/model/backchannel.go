@@ -0,0 +1,102 @@
+package model
+
+import (
+	"time"
+
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+)
+
+// BackchannelAuthStatus is a status of the backchannel authentication request
+type BackchannelAuthStatus string
+
+const (
+	// BackchannelAuthStatusPending ...
+	BackchannelAuthStatusPending = BackchannelAuthStatus("pending")
+	// BackchannelAuthStatusApproved ...
+	BackchannelAuthStatusApproved = BackchannelAuthStatus("approved")
+	// BackchannelAuthStatusDenied ...
+	BackchannelAuthStatusDenied = BackchannelAuthStatus("denied")
+)
+
+// BackchannelAuth is a pending request of Client Initiated Backchannel Authentication (CIBA)
+type BackchannelAuth struct {
+	AuthReqID      string
+	ProjectName    string
+	ClientID       string
+	UserID         string
+	Scopes         []string
+	BindingMessage string
+	ExpiresIn      int64
+	CreatedAt      time.Time
+	Status         BackchannelAuthStatus
+
+	// Interval is the minimum poll interval in seconds, and LastPolledAt is the time of the last token request
+	Interval     int64
+	LastPolledAt time.Time
+
+	// ClientNotificationToken is a bearer token to notify the client in ping mode
+	ClientNotificationToken string
+
+	// AuthDate and AuthMethods are set when the user approves the request
+	AuthDate    time.Time
+	AuthMethods []string
+}
+
+// BackchannelAuthFilter ...
+type BackchannelAuthFilter struct {
+	AuthReqID string
+	UserID    string
+}
+
+// BackchannelAuthHandler ...
+type BackchannelAuthHandler interface {
+	Add(projectName string, ent *BackchannelAuth) *errors.Error
+	Update(projectName string, ent *BackchannelAuth) *errors.Error
+	DeleteAll(projectName string) *errors.Error
+	Cleanup(now time.Time) *errors.Error
+	GetList(projectName string, filter *BackchannelAuthFilter) ([]*BackchannelAuth, *errors.Error)
+	Delete(projectName string, authReqID string) *errors.Error
+}
+
+var (
+	// ErrBackchannelAuthValidateFailed ...
+	ErrBackchannelAuthValidateFailed = errors.New("Backchannel auth validation failed", "Backchannel auth validation failed")
+	// ErrNoSuchBackchannelAuth ...
+	ErrNoSuchBackchannelAuth = errors.New("No such backchannel auth request", "No such backchannel auth request")
+)
+
+// Validate ...
+func (b *BackchannelAuth) Validate() *errors.Error {
+	if b.AuthReqID == "" {
+		return errors.Append(ErrBackchannelAuthValidateFailed, "AuthReqID is empty")
+	}
+
+	if !ValidateProjectName(b.ProjectName) {
+		return errors.Append(ErrBackchannelAuthValidateFailed, "Invalid Project Name format")
+	}
+
+	if !ValidateClientID(b.ClientID) {
+		return errors.Append(ErrBackchannelAuthValidateFailed, "Invalid Client ID format")
+	}
+
+	if !ValidateUserID(b.UserID) {
+		return errors.Append(ErrBackchannelAuthValidateFailed, "Invalid User ID format")
+	}
+
+	if b.ExpiresIn <= 0 {
+		return errors.Append(ErrBackchannelAuthValidateFailed, "expires time must be positive number, but got %d", b.ExpiresIn)
+	}
+
+	switch b.Status {
+	case BackchannelAuthStatusPending, BackchannelAuthStatusApproved, BackchannelAuthStatusDenied:
+	default:
+		return errors.Append(ErrBackchannelAuthValidateFailed, "Invalid status %s", b.Status)
+	}
+
+	return nil
+}
+
+// Expired returns true if the request is already expired
+func (b *BackchannelAuth) Expired(now time.Time) bool {
+	return !now.Before(b.CreatedAt.Add(time.Second * time.Duration(b.ExpiresIn)))
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackchannelAuthExpired(t *testing.T) {
+	now := time.Now()
+	tt := []struct {
+		createdAt time.Time
+		expiresIn int64
+		expect    bool
+	}{
+		{now, 60, false},
+		{now.Add(-30 * time.Second), 60, false},
+		{now.Add(-60 * time.Second), 60, true},
+		{now.Add(-120 * time.Second), 60, true},
+	}
+
+	for _, tc := range tt {
+		b := BackchannelAuth{
+			CreatedAt: tc.createdAt,
+			ExpiresIn: tc.expiresIn,
+		}
+		res := b.Expired(now)
+		if res != tc.expect {
+			t.Errorf("Expired of request created at %v with expires %d expects %v, but got %v", tc.createdAt, tc.expiresIn, tc.expect, res)
+		}
+	}
+}
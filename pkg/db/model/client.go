@@ -1,10 +1,14 @@
 package model
 
 import (
+	"encoding/json"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/asaskevich/govalidator"
 	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/stretchr/stew/slice"
 )
 
 // ClientFilter ...
@@ -20,8 +24,92 @@ type ClientInfo struct {
 	AccessType          string
 	CreatedAt           time.Time
 	AllowedCallbackURLs []string
+	ProtocolMappers     []ProtocolMapper
+
+	// DefaultScopes are always granted to the client
+	DefaultScopes []string
+	// OptionalScopes are granted only when the client requests them
+	OptionalScopes []string
+
+	// The following settings override the project settings.
+	// Zero or empty value means that the project setting is used.
+	AccessTokenLifeSpan  uint
+	RefreshTokenLifeSpan uint
+	IDTokenLifeSpan      uint
+	AllowGrantTypes      []GrantType
+	// AllowResponseTypes is a list of response types such as "code" or "code id_token"
+	AllowResponseTypes []string
+	// RequirePKCE rejects authorization code requests without code_challenge
+	RequirePKCE bool
+
+	// JWKS is a JSON Web Key Set of the client public keys used to encrypt tokens
+	JWKS string
+	// The algorithms of the responses for the client
+	//   ref. https://openid.net/specs/openid-connect-registration-1_0.html#ClientMetadata
+	IDTokenEncryptedResponseAlg  string
+	IDTokenEncryptedResponseEnc  string
+	UserinfoSignedResponseAlg    string
+	UserinfoEncryptedResponseAlg string
+	UserinfoEncryptedResponseEnc string
+
+	// BackchannelTokenDeliveryMode is a token delivery mode of CIBA, "poll" or "ping"
+	// Empty value means "poll".
+	//   ref. https://openid.net/specs/openid-client-initiated-backchannel-authentication-core-1_0.html#rfc.section.4
+	BackchannelTokenDeliveryMode string
+	// BackchannelClientNotificationEndpoint is notified when the user completes authentication in ping mode
+	BackchannelClientNotificationEndpoint string
+}
+
+// TokenLifeSpans ...
+type TokenLifeSpans struct {
+	AccessToken  uint
+	RefreshToken uint
+	IDToken      uint
 }
 
+var (
+	// JWEAlgorithms is a list of supported key management algorithms for encrypted responses
+	JWEAlgorithms = []string{
+		"RSA1_5",
+		"RSA-OAEP",
+		"RSA-OAEP-256",
+		"A128KW",
+		"A192KW",
+		"A256KW",
+		"dir",
+	}
+
+	// JWEEncryptions is a list of supported content encryption algorithms for encrypted responses
+	JWEEncryptions = []string{
+		"A128CBC-HS256",
+		"A192CBC-HS384",
+		"A256CBC-HS512",
+		"A128GCM",
+		"A192GCM",
+		"A256GCM",
+	}
+
+	// UserinfoSigningAlgorithms is a list of supported algorithms for signed userinfo responses
+	UserinfoSigningAlgorithms = []string{
+		"RS256",
+	}
+)
+
+const (
+	// BackchannelTokenDeliveryModePoll ...
+	BackchannelTokenDeliveryModePoll = "poll"
+	// BackchannelTokenDeliveryModePing ...
+	BackchannelTokenDeliveryModePing = "ping"
+)
+
+var (
+	// BackchannelTokenDeliveryModes is a list of supported token delivery modes of CIBA
+	BackchannelTokenDeliveryModes = []string{
+		BackchannelTokenDeliveryModePoll,
+		BackchannelTokenDeliveryModePing,
+	}
+)
+
 var (
 	// ErrClientAlreadyExists ...
 	ErrClientAlreadyExists = errors.New("Client already exists", "Client already exists")
@@ -42,6 +130,69 @@ type ClientInfoHandler interface {
 	DeleteAll(projectName string) *errors.Error
 }
 
+// AllowedScopes returns a list of scope names the client can request.
+// If the client does not set any scopes, all scopes in the project are allowed, so nil is returned.
+func (c *ClientInfo) AllowedScopes() []string {
+	if len(c.DefaultScopes) == 0 && len(c.OptionalScopes) == 0 {
+		return nil
+	}
+	return append(append([]string{}, c.DefaultScopes...), c.OptionalScopes...)
+}
+
+// LifeSpans returns token life spans of the client.
+// The ID token uses the access token life span if neither the client nor the project sets it.
+func (c *ClientInfo) LifeSpans(cfg *TokenConfig) TokenLifeSpans {
+	res := TokenLifeSpans{
+		AccessToken:  cfg.AccessTokenLifeSpan,
+		RefreshToken: cfg.RefreshTokenLifeSpan,
+	}
+	if c.AccessTokenLifeSpan != 0 {
+		res.AccessToken = c.AccessTokenLifeSpan
+	}
+	if c.RefreshTokenLifeSpan != 0 {
+		res.RefreshToken = c.RefreshTokenLifeSpan
+	}
+	res.IDToken = res.AccessToken
+	if c.IDTokenLifeSpan != 0 {
+		res.IDToken = c.IDTokenLifeSpan
+	}
+	return res
+}
+
+// BackchannelPingMode returns true if the client receives the notification of CIBA
+func (c *ClientInfo) BackchannelPingMode() bool {
+	return c.BackchannelTokenDeliveryMode == BackchannelTokenDeliveryModePing
+}
+
+// GrantTypes returns grant types which the client can use
+func (c *ClientInfo) GrantTypes(projectGrantTypes []GrantType) []GrantType {
+	if len(c.AllowGrantTypes) == 0 {
+		return projectGrantTypes
+	}
+	return c.AllowGrantTypes
+}
+
+// ResponseTypeAllowed returns true if the client can request the response type
+func (c *ClientInfo) ResponseTypeAllowed(types []string) bool {
+	if len(c.AllowResponseTypes) == 0 {
+		return true
+	}
+
+	req := normalizeResponseType(types)
+	for _, t := range c.AllowResponseTypes {
+		if normalizeResponseType(strings.Split(t, " ")) == req {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeResponseType(types []string) string {
+	res := append([]string{}, types...)
+	sort.Strings(res)
+	return strings.Join(res, " ")
+}
+
 // Validate ...
 func (c *ClientInfo) Validate() *errors.Error {
 	if !ValidateClientID(c.ID) {
@@ -66,5 +217,78 @@ func (c *ClientInfo) Validate() *errors.Error {
 		}
 	}
 
+	if err := ValidateProtocolMappers(c.ProtocolMappers); err != nil {
+		return errors.Append(err, "Invalid protocol mapper")
+	}
+
+	for _, s := range append(append([]string{}, c.DefaultScopes...), c.OptionalScopes...) {
+		if !ValidateClientScopeName(s) {
+			return errors.Append(ErrClientValidateFailed, "Invalid scope name %s", s)
+		}
+	}
+
+	for _, t := range c.AllowGrantTypes {
+		if _, err := GetGrantType(string(t)); err != nil {
+			return errors.Append(ErrClientValidateFailed, "Invalid grant type %s", t)
+		}
+	}
+
+	for _, t := range c.AllowResponseTypes {
+		if !ValidateResponseType(t) {
+			return errors.Append(ErrClientValidateFailed, "Invalid response type %s", t)
+		}
+	}
+
+	if c.JWKS != "" && !json.Valid([]byte(c.JWKS)) {
+		return errors.Append(ErrClientValidateFailed, "Invalid JWKS format")
+	}
+
+	if err := c.validateEncryption(c.IDTokenEncryptedResponseAlg, c.IDTokenEncryptedResponseEnc); err != nil {
+		return errors.Append(err, "Invalid id token encryption")
+	}
+
+	if c.UserinfoSignedResponseAlg != "" && !slice.Contains(UserinfoSigningAlgorithms, c.UserinfoSignedResponseAlg) {
+		return errors.Append(ErrClientValidateFailed, "Userinfo signing algorithm %s is not supported", c.UserinfoSignedResponseAlg)
+	}
+
+	if err := c.validateEncryption(c.UserinfoEncryptedResponseAlg, c.UserinfoEncryptedResponseEnc); err != nil {
+		return errors.Append(err, "Invalid userinfo encryption")
+	}
+
+	if c.BackchannelTokenDeliveryMode != "" && !slice.Contains(BackchannelTokenDeliveryModes, c.BackchannelTokenDeliveryMode) {
+		return errors.Append(ErrClientValidateFailed, "Backchannel token delivery mode %s is not supported", c.BackchannelTokenDeliveryMode)
+	}
+	if c.BackchannelTokenDeliveryMode == BackchannelTokenDeliveryModePing && !govalidator.IsRequestURL(c.BackchannelClientNotificationEndpoint) {
+		return errors.Append(ErrClientValidateFailed, "Ping mode requires valid client notification endpoint")
+	}
+
+	return nil
+}
+
+// validateEncryption checks the pair of the key management algorithm and the content encryption.
+// RSA algorithms require the client public key, and the others require the client secret.
+func (c *ClientInfo) validateEncryption(alg, enc string) *errors.Error {
+	if alg == "" {
+		if enc != "" {
+			return errors.Append(ErrClientValidateFailed, "Encryption algorithm is required when enc is set")
+		}
+		return nil
+	}
+
+	if !slice.Contains(JWEAlgorithms, alg) {
+		return errors.Append(ErrClientValidateFailed, "Encryption algorithm %s is not supported", alg)
+	}
+	if enc != "" && !slice.Contains(JWEEncryptions, enc) {
+		return errors.Append(ErrClientValidateFailed, "Content encryption %s is not supported", enc)
+	}
+
+	if strings.HasPrefix(alg, "RSA") {
+		if c.JWKS == "" {
+			return errors.Append(ErrClientValidateFailed, "JWKS is required for algorithm %s", alg)
+		}
+	} else if c.AccessType != "confidential" {
+		return errors.Append(ErrClientValidateFailed, "Algorithm %s requires the client secret", alg)
+	}
+
 	return nil
 }
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestResponseTypeAllowed(t *testing.T) {
+	tt := []struct {
+		allowed []string
+		types   []string
+		expect  bool
+	}{
+		{[]string{}, []string{"token"}, true},
+		{[]string{"code"}, []string{"code"}, true},
+		{[]string{"code"}, []string{"token"}, false},
+		{[]string{"code id_token"}, []string{"id_token", "code"}, true},
+		{[]string{"code id_token"}, []string{"code"}, false},
+	}
+
+	for _, tc := range tt {
+		c := ClientInfo{
+			AllowResponseTypes: tc.allowed,
+		}
+		res := c.ResponseTypeAllowed(tc.types)
+		if res != tc.expect {
+			t.Errorf("ResponseTypeAllowed %v with allowed %v expects %v, but got %v", tc.types, tc.allowed, tc.expect, res)
+		}
+	}
+}
+
+func TestValidateResponseType(t *testing.T) {
+	tt := []struct {
+		typ    string
+		expect bool
+	}{
+		{"code", true},
+		{"code id_token token", true},
+		{"", false},
+		{"code code", false},
+		{"none", false},
+	}
+
+	for _, tc := range tt {
+		res := ValidateResponseType(tc.typ)
+		if res != tc.expect {
+			t.Errorf("ValidateResponseType(%s) expects %v, but got %v", tc.typ, tc.expect, res)
+		}
+	}
+}
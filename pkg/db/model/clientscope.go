@@ -0,0 +1,151 @@
+package model
+
+import (
+	"time"
+
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/stretchr/stew/slice"
+)
+
+// ClientScope is a named scope which a client can request in the project
+type ClientScope struct {
+	Name        string
+	ProjectName string
+	CreatedAt   time.Time
+
+	// Description is shown in the consent page
+	Description string
+	// Claims is a list of standard user claims released by the scope
+	Claims []string
+	// ProtocolMappers add custom claims when the scope is granted
+	ProtocolMappers []ProtocolMapper
+	// RequiredRoles is a list of custom role IDs.
+	// If it is not empty, the scope is granted only to the user who has at least one of them.
+	RequiredRoles []string
+}
+
+// ClientScopeFilter ...
+type ClientScopeFilter struct {
+	Name string
+}
+
+var (
+	// ErrNoSuchClientScope ...
+	ErrNoSuchClientScope = errors.New("No such client scope", "No such client scope")
+
+	// ErrClientScopeAlreadyExists ...
+	ErrClientScopeAlreadyExists = errors.New("Client scope already exists", "Client scope already exists")
+
+	// ErrClientScopeValidateFailed ...
+	ErrClientScopeValidateFailed = errors.New("Client scope validation failed", "Client scope validation failed")
+)
+
+var (
+	// StandardUserClaims is a list of user claims which can be released by client scopes
+	//   ref. https://openid.net/specs/openid-connect-core-1_0.html#StandardClaims
+	StandardUserClaims = []string{
+		"name",
+		"family_name",
+		"given_name",
+		"preferred_username",
+		"locale",
+		"zoneinfo",
+		"updated_at",
+		"email",
+		"email_verified",
+		"address",
+		"phone_number",
+		"phone_number_verified",
+	}
+)
+
+// ClientScopeHandler ...
+type ClientScopeHandler interface {
+	Add(projectName string, ent *ClientScope) *errors.Error
+	Delete(projectName string, name string) *errors.Error
+	GetList(projectName string, filter *ClientScopeFilter) ([]*ClientScope, *errors.Error)
+	Update(projectName string, ent *ClientScope) *errors.Error
+	DeleteAll(projectName string) *errors.Error
+}
+
+// DefaultClientScopes returns client scopes registered when a project is created
+//   ref. https://openid.net/specs/openid-connect-core-1_0.html#ScopeClaims
+func DefaultClientScopes(projectName string, createdAt time.Time) []*ClientScope {
+	return []*ClientScope{
+		{
+			Name:        "openid",
+			ProjectName: projectName,
+			CreatedAt:   createdAt,
+			Description: "Sign in with your account",
+			Claims:      []string{},
+		},
+		{
+			Name:        "profile",
+			ProjectName: projectName,
+			CreatedAt:   createdAt,
+			Description: "Your profile",
+			Claims:      []string{"name", "family_name", "given_name", "preferred_username", "locale", "zoneinfo", "updated_at"},
+		},
+		{
+			Name:        "email",
+			ProjectName: projectName,
+			CreatedAt:   createdAt,
+			Description: "Your email address",
+			Claims:      []string{"email", "email_verified"},
+		},
+		{
+			Name:        "address",
+			ProjectName: projectName,
+			CreatedAt:   createdAt,
+			Description: "Your address",
+			Claims:      []string{"address"},
+		},
+		{
+			Name:        "phone",
+			ProjectName: projectName,
+			CreatedAt:   createdAt,
+			Description: "Your phone number",
+			Claims:      []string{"phone_number", "phone_number_verified"},
+		},
+		{
+			Name:        "offline_access",
+			ProjectName: projectName,
+			CreatedAt:   createdAt,
+			Description: "Access your data while you are offline",
+			Claims:      []string{},
+		},
+	}
+}
+
+// Validate ...
+func (s *ClientScope) Validate() *errors.Error {
+	if !ValidateClientScopeName(s.Name) {
+		return errors.Append(ErrClientScopeValidateFailed, "Invalid Client Scope Name format")
+	}
+
+	if !ValidateProjectName(s.ProjectName) {
+		return errors.Append(ErrClientScopeValidateFailed, "Invalid Project Name format")
+	}
+
+	if len(s.Description) > 256 {
+		return errors.Append(ErrClientScopeValidateFailed, "Description is too long")
+	}
+
+	for _, c := range s.Claims {
+		if !slice.Contains(StandardUserClaims, c) {
+			return errors.Append(ErrClientScopeValidateFailed, "Claim %s is not supported", c)
+		}
+	}
+
+	for _, r := range s.RequiredRoles {
+		if !ValidateCustomRoleID(r) {
+			return errors.Append(ErrClientScopeValidateFailed, "Invalid required role ID format")
+		}
+	}
+
+	if err := ValidateProtocolMappers(s.ProtocolMappers); err != nil {
+		return errors.Append(err, "Invalid protocol mapper")
+	}
+
+	return nil
+}
@@ -14,6 +14,11 @@ type Device struct {
 	ExpiresIn      int64
 	CreatedAt      time.Time
 	LoginSessionID string
+	ClientID       string
+
+	// Interval is the minimum poll interval in seconds, and LastPolledAt is the time of the last token request
+	Interval     int64
+	LastPolledAt time.Time
 }
 
 // DeviceFilter ...
@@ -25,6 +30,7 @@ type DeviceFilter struct {
 // DeviceHandler ...
 type DeviceHandler interface {
 	Add(projectName string, ent *Device) *errors.Error
+	Update(projectName string, ent *Device) *errors.Error
 	DeleteAll(projectName string) *errors.Error
 	Cleanup(now time.Time) *errors.Error
 	GetList(projectName string, filter *DeviceFilter) ([]*Device, *errors.Error)
@@ -52,9 +58,18 @@ func (d *Device) Validate() *errors.Error {
 		return errors.Append(ErrDeviceValidateFailed, "Invalid Project Name format")
 	}
 
+	if !ValidateClientID(d.ClientID) {
+		return errors.Append(ErrDeviceValidateFailed, "Invalid Client ID format")
+	}
+
 	if d.ExpiresIn <= 0 {
 		return errors.Append(ErrDeviceValidateFailed, "expires time must be positive number, but got %d", d.ExpiresIn)
 	}
 
 	return nil
 }
+
+// Expired returns true if the device code is already expired
+func (d *Device) Expired(now time.Time) bool {
+	return !now.Before(d.CreatedAt.Add(time.Second * time.Duration(d.ExpiresIn)))
+}
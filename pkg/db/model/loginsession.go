@@ -6,6 +6,17 @@ import (
 	"github.com/sh-miyoshi/hekate/pkg/errors"
 )
 
+// OneTimeCode is a short-lived single-use code sent to the user by email or SMS
+type OneTimeCode struct {
+	Purpose     string // CodePurposeOTP, CodePurposeMagicLink or CodePurposeSetup
+	UserID      string
+	CodeHash    string
+	ExpiresAt   time.Time
+	SentAt      time.Time // Time when the last message was sent
+	SentTimes   uint      // Number of messages sent for this code
+	FailedTimes uint      // Number of wrong codes entered by the user
+}
+
 // LoginSession ...
 type LoginSession struct {
 	SessionID           string
@@ -23,8 +34,51 @@ type LoginSession struct {
 	LoginDate           time.Time
 	CodeChallenge       string
 	CodeChallengeMethod string
+	Claims              string
+	AuthMethods         []string // Authentication methods used in the login (amr)
+	ACRValues           []string
+	UILocales           []string
+	Resources           []string    // Identifiers of the API resources requested by the resource parameter
+	WebAuthnChallenge   string      // Challenge of the WebAuthn authentication ceremony in progress
+	MailCode            OneTimeCode // Code sent by email in the email OTP or the magic link login
+	SMSCode             OneTimeCode // Code sent by SMS in the SMS OTP login
+	Consented           bool        // true if the user agreed to the consent page
+	CodeRedeemed        bool        // true if the authorization code was already exchanged for tokens
+	RiskMFARequired     bool        // true if the risk evaluation requires the second factor in this login
+	// PasswordChangeRequired is true if the user must change the expired or temporary password before the token is issued
+	PasswordChangeRequired bool
 }
 
+// Authentication methods recorded in the login session
+//   ref. https://tools.ietf.org/html/rfc8176
+const (
+	// AuthMethodPassword ...
+	AuthMethodPassword = "pwd"
+	// AuthMethodOTP ...
+	AuthMethodOTP = "otp"
+	// AuthMethodWebAuthn is used when the user is authenticated by the WebAuthn credential
+	AuthMethodWebAuthn = "hwk"
+	// AuthMethodMFA is used when the WebAuthn authenticator verifies the user by itself, e.g. PIN or biometrics
+	AuthMethodMFA = "mfa"
+	// AuthMethodEMail is used when the user is authenticated by the code or the link sent by email
+	AuthMethodEMail = "email"
+	// AuthMethodSMS is used when the user is authenticated by the code sent by SMS
+	AuthMethodSMS = "sms"
+	// AuthMethodTrustedDevice is used when the second factor is skipped in the device trusted by the user
+	// It is not defined in RFC 8176.
+	AuthMethodTrustedDevice = "device"
+)
+
+// Purposes of the code sent by email or SMS
+const (
+	// CodePurposeOTP ...
+	CodePurposeOTP = "otp"
+	// CodePurposeMagicLink ...
+	CodePurposeMagicLink = "magic_link"
+	// CodePurposeSetup is used to confirm the email address or the phone number when the user enables the OTP
+	CodePurposeSetup = "setup"
+)
+
 // LoginSessionFilter ...
 type LoginSessionFilter struct {
 	SessionID string
@@ -37,6 +91,8 @@ var (
 	ErrNoSuchLoginSession = errors.New("No such session", "No such session")
 	// ErrLoginSessionValidationFailed ...
 	ErrLoginSessionValidationFailed = errors.New("Login Session validation failed", "Login Session validation failed")
+	// ErrLoginSessionCodeRedeemed ...
+	ErrLoginSessionCodeRedeemed = errors.New("Code already redeemed", "Authorization code is already redeemed")
 )
 
 // LoginSessionHandler ...
@@ -47,5 +103,7 @@ type LoginSessionHandler interface {
 	DeleteAll(projectName string) *errors.Error
 	GetByCode(projectName string, code string) (*LoginSession, *errors.Error)
 	Get(projectName string, sessionID string) (*LoginSession, *errors.Error)
+	// RedeemCode marks the code redeemed only if it is not redeemed yet, and returns ErrLoginSessionCodeRedeemed otherwise
+	RedeemCode(projectName string, sessionID string) *errors.Error
 	Cleanup(now time.Time) *errors.Error
 }
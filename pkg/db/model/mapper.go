@@ -0,0 +1,101 @@
+package model
+
+import (
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+)
+
+// ProtocolMapper is a rule to add a custom claim into the tokens and the userinfo
+type ProtocolMapper struct {
+	ID   string
+	Name string
+	Type string
+
+	// ClaimName is a name of the claim to be added
+	ClaimName string
+	// UserAttribute is a name of the user attribute used in user-attribute and groups mapper
+	UserAttribute string
+	// Value is a fixed value used in hardcoded and audience mapper
+	Value string
+	// Multivalued means the claim is released as an array in user-attribute mapper
+	Multivalued bool
+
+	// Targets
+	IDToken     bool
+	AccessToken bool
+	UserInfo    bool
+}
+
+const (
+	// MapperTypeUserAttribute maps the user attribute to the claim
+	MapperTypeUserAttribute = "user-attribute"
+	// MapperTypeHardcoded sets a fixed value to the claim
+	MapperTypeHardcoded = "hardcoded"
+	// MapperTypeRoleList sets a list of custom role names of the user to the claim
+	MapperTypeRoleList = "role-list"
+	// MapperTypeGroups sets a list of groups of the user to the claim
+	MapperTypeGroups = "groups"
+	// MapperTypeAudience adds a value to the audience
+	MapperTypeAudience = "audience"
+)
+
+var (
+	// ErrNoSuchProtocolMapper ...
+	ErrNoSuchProtocolMapper = errors.New("No such protocol mapper", "No such protocol mapper")
+	// ErrProtocolMapperAlreadyExists ...
+	ErrProtocolMapperAlreadyExists = errors.New("Protocol mapper already exists", "Protocol mapper already exists")
+	// ErrProtocolMapperValidateFailed ...
+	ErrProtocolMapperValidateFailed = errors.New("Protocol mapper validation failed", "Protocol mapper validation failed")
+)
+
+// Validate ...
+func (m *ProtocolMapper) Validate() *errors.Error {
+	if !ValidateProtocolMapperID(m.ID) {
+		return errors.Append(ErrProtocolMapperValidateFailed, "Invalid mapper ID format")
+	}
+
+	if m.Name == "" {
+		return errors.Append(ErrProtocolMapperValidateFailed, "Mapper name is empty")
+	}
+
+	switch m.Type {
+	case MapperTypeUserAttribute:
+		if m.UserAttribute == "" {
+			return errors.Append(ErrProtocolMapperValidateFailed, "User attribute mapper requires attribute name")
+		}
+	case MapperTypeHardcoded, MapperTypeRoleList, MapperTypeGroups:
+	case MapperTypeAudience:
+		if m.Value == "" {
+			return errors.Append(ErrProtocolMapperValidateFailed, "Audience mapper requires value")
+		}
+		if m.UserInfo {
+			return errors.Append(ErrProtocolMapperValidateFailed, "Audience mapper can not be applied to userinfo")
+		}
+	default:
+		return errors.Append(ErrProtocolMapperValidateFailed, "Invalid mapper type %s", m.Type)
+	}
+
+	if m.Type != MapperTypeAudience && !ValidateClaimName(m.ClaimName) {
+		return errors.Append(ErrProtocolMapperValidateFailed, "Invalid claim name %s", m.ClaimName)
+	}
+
+	if !m.IDToken && !m.AccessToken && !m.UserInfo {
+		return errors.Append(ErrProtocolMapperValidateFailed, "Mapper requires at least one target")
+	}
+
+	return nil
+}
+
+// ValidateProtocolMappers validates mappers and checks duplicated name
+func ValidateProtocolMappers(mappers []ProtocolMapper) *errors.Error {
+	names := map[string]bool{}
+	for _, m := range mappers {
+		if err := m.Validate(); err != nil {
+			return err
+		}
+		if names[m.Name] {
+			return errors.Append(ErrProtocolMapperAlreadyExists, "Mapper %s is duplicated", m.Name)
+		}
+		names[m.Name] = true
+	}
+	return nil
+}
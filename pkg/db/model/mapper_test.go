@@ -0,0 +1,36 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestValidateProtocolMapper(t *testing.T) {
+	id := uuid.New().String()
+	tt := []struct {
+		mapper        ProtocolMapper
+		expectSuccess bool
+	}{
+		{ProtocolMapper{ID: id, Name: "tenant", Type: MapperTypeUserAttribute, ClaimName: "tenant_id", UserAttribute: "tenant", AccessToken: true}, true},
+		{ProtocolMapper{ID: id, Name: "tenant", Type: MapperTypeUserAttribute, ClaimName: "tenant_id", AccessToken: true}, false},
+		{ProtocolMapper{ID: id, Name: "fixed", Type: MapperTypeHardcoded, ClaimName: "department", Value: "dev", IDToken: true}, true},
+		{ProtocolMapper{ID: id, Name: "fixed", Type: MapperTypeHardcoded, ClaimName: "sub", Value: "dev", IDToken: true}, false},
+		{ProtocolMapper{ID: id, Name: "roles", Type: MapperTypeRoleList, ClaimName: "roles", UserInfo: true}, true},
+		{ProtocolMapper{ID: id, Name: "roles", Type: MapperTypeRoleList, ClaimName: "roles"}, false},
+		{ProtocolMapper{ID: id, Name: "aud", Type: MapperTypeAudience, Value: "api", AccessToken: true}, true},
+		{ProtocolMapper{ID: id, Name: "aud", Type: MapperTypeAudience, Value: "api", UserInfo: true}, false},
+		{ProtocolMapper{ID: id, Name: "invalid", Type: "invalid", ClaimName: "claim", AccessToken: true}, false},
+		{ProtocolMapper{ID: "invalid", Name: "groups", Type: MapperTypeGroups, ClaimName: "groups", AccessToken: true}, false},
+	}
+
+	for _, tc := range tt {
+		err := tc.mapper.Validate()
+		if tc.expectSuccess && err != nil {
+			t.Errorf("Protocol mapper validate %v returns wrong status. got %v, want nil", tc.mapper, err)
+		}
+		if !tc.expectSuccess && err == nil {
+			t.Errorf("Protocol mapper validate %v returns wrong status. got nil, want error", tc.mapper)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package model
 
 import (
+	"strings"
+	"text/template"
 	"time"
 
 	"github.com/sh-miyoshi/hekate/pkg/errors"
@@ -17,9 +19,15 @@ type CharacterType string
 type TokenConfig struct {
 	AccessTokenLifeSpan  uint
 	RefreshTokenLifeSpan uint
-	SigningAlgorithm     string
-	SignPublicKey        []byte
-	SignSecretKey        []byte
+	// RefreshTokenGracePeriod is a duration in which a rotated refresh token can be used for concurrent requests
+	RefreshTokenGracePeriod uint
+	// OfflineSessionLifeSpan is a max life span of offline sessions
+	OfflineSessionLifeSpan uint
+	// OfflineSessionIdleTimeout is a life span of an offline refresh token
+	OfflineSessionIdleTimeout uint
+	SigningAlgorithm          string
+	SignPublicKey             []byte
+	SignSecretKey             []byte
 }
 
 // PasswordPolicy ...
@@ -30,6 +38,12 @@ type PasswordPolicy struct {
 	UseCharacter        CharacterType
 	UseDigit            bool
 	UseSpecialCharacter bool
+	// MaxAge is a life span of the password [sec], the user must change the expired password at next login
+	MaxAge uint
+	// MinAge is a period [sec] in which the user cannot change the password again
+	MinAge uint
+	// HistoryCount is a number of the recent passwords which cannot be reused, including the current one
+	HistoryCount uint
 }
 
 // UserLock ...
@@ -40,6 +54,84 @@ type UserLock struct {
 	FailureResetTime uint
 }
 
+// WebAuthnConfig ...
+type WebAuthnConfig struct {
+	// AttestationConveyance is a preference of the attestation statement in the credential registration
+	AttestationConveyance string
+}
+
+// MailTemplate is a template of the mail sent to the user
+// Subject and Body are parsed by text/template, see pkg/mail for the available fields.
+type MailTemplate struct {
+	Type    string
+	Locale  string // The template is used for all locales if empty
+	Subject string
+	Body    string
+}
+
+// MailConfig ...
+type MailConfig struct {
+	// EMailOTPEnabled allows users to use the one-time code sent by email as the second factor
+	EMailOTPEnabled bool
+	// MagicLinkEnabled allows users to login by the link sent by email without password
+	MagicLinkEnabled bool
+	// CodeLifeSpan is a life span of the code and the link sent by email [sec]
+	CodeLifeSpan uint
+	Templates    []MailTemplate
+}
+
+// SMSConfig ...
+type SMSConfig struct {
+	// OTPEnabled allows users to use the one-time code sent by SMS as the second factor
+	OTPEnabled bool
+	// CodeLifeSpan is a life span of the code sent by SMS [sec]
+	CodeLifeSpan uint
+}
+
+// TOTPConfig is a set of parameters of TOTP
+// Algorithm, Digits and Period are saved in the user at the registration,
+// so changing them does not affect the authenticators already registered.
+//   ref. https://tools.ietf.org/html/rfc6238
+type TOTPConfig struct {
+	// Algorithm is a hash algorithm of HMAC, SHA1, SHA256 or SHA512
+	Algorithm string
+	Digits    uint
+	// Period is a time step [sec]
+	Period uint
+	// SkewSteps is the number of time steps accepted before and after the current step for the clock skew
+	SkewSteps uint
+	// Issuer is a label of the account shown in the authenticator application
+	Issuer string
+}
+
+// MFAPolicy decides the users who must use the second factor at the login
+// The users who have the write-project or write-cluster role always require MFA regardless of the policy.
+type MFAPolicy struct {
+	Mode string // MFAModeOptional is used if empty
+	// SystemRoles and CustomRoles are the roles which require MFA in the role mode
+	SystemRoles []string
+	CustomRoles []string // IDs of the custom roles
+	// TrustedDeviceLifeSpan is a life span [sec] of the trusted device which skips the second factor
+	// Users cannot trust the device if it is 0.
+	TrustedDeviceLifeSpan uint
+}
+
+// RiskRule is a condition of the risky login and the action taken when the login matches it
+type RiskRule struct {
+	Type   string // RiskRuleNewDevice, RiskRuleNewIP, RiskRuleNewCountry or RiskRuleIPVelocity
+	Action string // RiskActionNotify, RiskActionMFA or RiskActionBlock
+	// Threshold and Window are used in RiskRuleIPVelocity
+	// The rule matches if the user logs in from more than Threshold distinct IPs in Window [sec].
+	Threshold uint
+	Window    uint
+}
+
+// RiskPolicy is a set of rules which compare the login with the recent successful logins of the user
+// The most severe action of the matched rules is taken.
+type RiskPolicy struct {
+	Rules []RiskRule
+}
+
 // ProjectInfo ...
 type ProjectInfo struct {
 	Name            string
@@ -49,6 +141,13 @@ type ProjectInfo struct {
 	AllowGrantTypes []GrantType
 	PasswordPolicy  PasswordPolicy
 	UserLock        UserLock
+	DefaultLocale   string // Locale of login pages used when it is not negotiated from the request
+	WebAuthnConfig  WebAuthnConfig
+	MailConfig      MailConfig
+	SMSConfig       SMSConfig
+	TOTPConfig      TOTPConfig
+	MFAPolicy       MFAPolicy
+	RiskPolicy      RiskPolicy
 }
 
 // ProjectFilter ...
@@ -63,6 +162,12 @@ const (
 	// DefaultRefreshTokenExpiresInSec is default expires time for refresh token(14 days)
 	DefaultRefreshTokenExpiresInSec = 14 * 24 * 60 * 60
 
+	// DefaultOfflineSessionLifeSpanSec is default max life span of offline sessions(60 days)
+	DefaultOfflineSessionLifeSpanSec = 60 * 24 * 60 * 60
+
+	// DefaultOfflineSessionIdleTimeoutSec is default idle timeout of offline sessions(30 days)
+	DefaultOfflineSessionIdleTimeoutSec = 30 * 24 * 60 * 60
+
 	// DefaultMaxLoginFailure ...
 	DefaultMaxLoginFailure = 5
 
@@ -71,6 +176,76 @@ const (
 
 	// DefaultFailureResetTime is default reset time of login failure(10 minutes)
 	DefaultFailureResetTime = 10 * 60
+
+	// DefaultLocale is default locale of login pages
+	DefaultLocale = "en"
+
+	// AttestationConveyanceNone means that the attestation statement is not required
+	//   ref. https://www.w3.org/TR/webauthn-2/#enum-attestation-convey
+	AttestationConveyanceNone = "none"
+	// AttestationConveyanceIndirect means that the authenticator may return an anonymized attestation statement
+	AttestationConveyanceIndirect = "indirect"
+	// AttestationConveyanceDirect means that the attestation statement is required
+	AttestationConveyanceDirect = "direct"
+
+	// DefaultMailCodeLifeSpan is default life span of the code sent by email(5 minutes)
+	DefaultMailCodeLifeSpan = 5 * 60
+
+	// DefaultSMSCodeLifeSpan is default life span of the code sent by SMS(5 minutes)
+	DefaultSMSCodeLifeSpan = 5 * 60
+
+	// TOTPAlgorithmSHA1 ...
+	TOTPAlgorithmSHA1 = "SHA1"
+	// TOTPAlgorithmSHA256 ...
+	TOTPAlgorithmSHA256 = "SHA256"
+	// TOTPAlgorithmSHA512 ...
+	TOTPAlgorithmSHA512 = "SHA512"
+	// DefaultTOTPDigits ...
+	DefaultTOTPDigits = 6
+	// DefaultTOTPPeriod is default time step of TOTP(30 seconds)
+	DefaultTOTPPeriod = 30
+	// DefaultTOTPSkewSteps accepts the previous and the next code for the clock skew
+	DefaultTOTPSkewSteps = 1
+	// DefaultTOTPIssuer ...
+	DefaultTOTPIssuer = "hekate"
+	// MaxTOTPSkewSteps ...
+	MaxTOTPSkewSteps = 5
+
+	// MFAModeOff means that the second factor is not used at the login
+	MFAModeOff = "off"
+	// MFAModeOptional means that the second factor is used if the user configures it
+	MFAModeOptional = "optional"
+	// MFAModeRequired means that all users must use the second factor
+	MFAModeRequired = "required"
+	// MFAModeRole means that the users who have the roles in the policy must use the second factor
+	MFAModeRole = "role"
+	// MaxTrustedDeviceLifeSpan ...
+	MaxTrustedDeviceLifeSpan = 365 * 24 * 60 * 60
+	// MaxPasswordHistoryCount ...
+	MaxPasswordHistoryCount = 24
+
+	// RiskRuleNewDevice matches the login from the user agent which the user has not used
+	RiskRuleNewDevice = "new_device"
+	// RiskRuleNewIP matches the login from the IP address which the user has not used
+	RiskRuleNewIP = "new_ip"
+	// RiskRuleNewCountry matches the login from the country which the user has not logged in from
+	// It requires the GeoIP file in the server config.
+	RiskRuleNewCountry = "new_country"
+	// RiskRuleIPVelocity matches the login if the user uses too many IP addresses in a short time
+	RiskRuleIPVelocity = "ip_velocity"
+	// RiskActionNotify only records the risk event in audit
+	RiskActionNotify = "notify"
+	// RiskActionMFA requires the second factor even if the MFA policy does not require it
+	RiskActionMFA = "mfa"
+	// RiskActionBlock rejects the login
+	RiskActionBlock = "block"
+	// MaxRiskVelocityWindow is max window of RiskRuleIPVelocity(1 day)
+	MaxRiskVelocityWindow = 24 * 60 * 60
+
+	// MailTemplateEMailOTP is a type of the mail template for the one-time code
+	MailTemplateEMailOTP = "email_otp"
+	// MailTemplateMagicLink is a type of the mail template for the login link
+	MailTemplateMagicLink = "magic_link"
 )
 
 var (
@@ -97,6 +272,8 @@ var (
 	GrantTypePassword = GrantType("password")
 	// GrantTypeDevice ...
 	GrantTypeDevice = GrantType("urn:ietf:params:oauth:grant-type:device_code")
+	// GrantTypeCIBA ...
+	GrantTypeCIBA = GrantType("urn:openid:params:grant-type:ciba")
 
 	// Character Types
 
@@ -120,10 +297,30 @@ type ProjectInfoHandler interface {
 	Update(ent *ProjectInfo) *errors.Error
 }
 
+// OfflineSessionLifeSpans returns max life span and idle timeout of offline sessions.
+// Default values are used if they are not set.
+func (c *TokenConfig) OfflineSessionLifeSpans() (uint, uint) {
+	lifeSpan := c.OfflineSessionLifeSpan
+	if lifeSpan == 0 {
+		lifeSpan = DefaultOfflineSessionLifeSpanSec
+	}
+	idleTimeout := c.OfflineSessionIdleTimeout
+	if idleTimeout == 0 {
+		idleTimeout = DefaultOfflineSessionIdleTimeoutSec
+	}
+	return lifeSpan, idleTimeout
+}
+
 func (p *PasswordPolicy) validate() *errors.Error {
 	if p.UseCharacter != "" && !slice.Contains(AllCharacterTypes, p.UseCharacter) {
 		return errors.Append(ErrProjectValidateFailed, "Invalid Character type")
 	}
+	if p.HistoryCount > MaxPasswordHistoryCount {
+		return errors.Append(ErrProjectValidateFailed, "Password history count must be less than or equal to %d", MaxPasswordHistoryCount)
+	}
+	if p.MaxAge > 0 && p.MinAge >= p.MaxAge {
+		return errors.Append(ErrProjectValidateFailed, "Password min age must be less than max age")
+	}
 	return nil
 }
 
@@ -149,9 +346,168 @@ func (p *ProjectInfo) Validate() *errors.Error {
 		return err
 	}
 
+	if p.DefaultLocale != "" && !ValidateLocale(p.DefaultLocale) {
+		return errors.Append(ErrProjectValidateFailed, "Invalid default locale format")
+	}
+
+	switch p.WebAuthnConfig.AttestationConveyance {
+	case "", AttestationConveyanceNone, AttestationConveyanceIndirect, AttestationConveyanceDirect:
+	default:
+		return errors.Append(ErrProjectValidateFailed, "Invalid attestation conveyance")
+	}
+
+	if err := p.MailConfig.validate(); err != nil {
+		return err
+	}
+
+	if err := p.TOTPConfig.validate(); err != nil {
+		return err
+	}
+
+	switch p.MFAPolicy.Mode {
+	case "", MFAModeOff, MFAModeOptional, MFAModeRequired, MFAModeRole:
+	default:
+		return errors.Append(ErrProjectValidateFailed, "Invalid MFA policy mode")
+	}
+	if p.MFAPolicy.TrustedDeviceLifeSpan > MaxTrustedDeviceLifeSpan {
+		return errors.Append(ErrProjectValidateFailed, "Trusted device life span must be less than %d", MaxTrustedDeviceLifeSpan)
+	}
+
+	if err := p.RiskPolicy.validate(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (p *RiskPolicy) validate() *errors.Error {
+	for _, r := range p.Rules {
+		switch r.Type {
+		case RiskRuleNewDevice, RiskRuleNewIP, RiskRuleNewCountry:
+		case RiskRuleIPVelocity:
+			if r.Threshold == 0 || r.Threshold >= MaxLoginHistory {
+				return errors.Append(ErrProjectValidateFailed, "Threshold of the risk rule must be between 1 and %d", MaxLoginHistory-1)
+			}
+			if r.Window == 0 || r.Window > MaxRiskVelocityWindow {
+				return errors.Append(ErrProjectValidateFailed, "Window of the risk rule must be between 1 and %d", MaxRiskVelocityWindow)
+			}
+		default:
+			return errors.Append(ErrProjectValidateFailed, "Invalid risk rule type %s", r.Type)
+		}
+
+		switch r.Action {
+		case RiskActionNotify, RiskActionMFA, RiskActionBlock:
+		default:
+			return errors.Append(ErrProjectValidateFailed, "Invalid risk rule action %s", r.Action)
+		}
+	}
+	return nil
+}
+
+func (c *MailConfig) validate() *errors.Error {
+	used := map[string]bool{}
+	for _, t := range c.Templates {
+		if t.Type != MailTemplateEMailOTP && t.Type != MailTemplateMagicLink {
+			return errors.Append(ErrProjectValidateFailed, "Invalid mail template type %s", t.Type)
+		}
+		if t.Locale != "" && !ValidateLocale(t.Locale) {
+			return errors.Append(ErrProjectValidateFailed, "Invalid locale %s of mail template", t.Locale)
+		}
+		key := t.Type + "/" + t.Locale
+		if used[key] {
+			return errors.Append(ErrProjectValidateFailed, "Mail template %s is duplicated", key)
+		}
+		used[key] = true
+
+		if t.Subject == "" || t.Body == "" {
+			return errors.Append(ErrProjectValidateFailed, "Mail template %s requires subject and body", key)
+		}
+		if _, err := template.New("subject").Parse(t.Subject); err != nil {
+			return errors.Append(ErrProjectValidateFailed, "Invalid subject of mail template %s: %v", key, err)
+		}
+		if _, err := template.New("body").Parse(t.Body); err != nil {
+			return errors.Append(ErrProjectValidateFailed, "Invalid body of mail template %s: %v", key, err)
+		}
+	}
+	return nil
+}
+
+// GetCodeLifeSpan returns the life span of the code sent by email
+// Default value is used if it is not set.
+func (c *MailConfig) GetCodeLifeSpan() uint {
+	if c.CodeLifeSpan == 0 {
+		return DefaultMailCodeLifeSpan
+	}
+	return c.CodeLifeSpan
+}
+
+func (c *TOTPConfig) validate() *errors.Error {
+	switch c.Algorithm {
+	case "", TOTPAlgorithmSHA1, TOTPAlgorithmSHA256, TOTPAlgorithmSHA512:
+	default:
+		return errors.Append(ErrProjectValidateFailed, "Invalid TOTP algorithm %s", c.Algorithm)
+	}
+	if c.Digits != 0 && c.Digits != 6 && c.Digits != 8 {
+		return errors.Append(ErrProjectValidateFailed, "TOTP digits must be 6 or 8")
+	}
+	if c.Period != 0 && (c.Period < 15 || c.Period > 300) {
+		return errors.Append(ErrProjectValidateFailed, "TOTP period must be between 15 and 300 seconds")
+	}
+	if c.SkewSteps > MaxTOTPSkewSteps {
+		return errors.Append(ErrProjectValidateFailed, "TOTP skew steps must be %d or less", MaxTOTPSkewSteps)
+	}
+	// the issuer is a prefix of the label separated by colon in the key URI
+	if len(c.Issuer) > 64 || strings.Contains(c.Issuer, ":") {
+		return errors.Append(ErrProjectValidateFailed, "Invalid TOTP issuer")
+	}
 	return nil
 }
 
+// GetAlgorithm returns the hash algorithm of TOTP
+// Default value is used if it is not set.
+func (c *TOTPConfig) GetAlgorithm() string {
+	if c.Algorithm == "" {
+		return TOTPAlgorithmSHA1
+	}
+	return c.Algorithm
+}
+
+// GetDigits returns the number of digits of TOTP
+// Default value is used if it is not set.
+func (c *TOTPConfig) GetDigits() uint {
+	if c.Digits == 0 {
+		return DefaultTOTPDigits
+	}
+	return c.Digits
+}
+
+// GetPeriod returns the time step of TOTP
+// Default value is used if it is not set.
+func (c *TOTPConfig) GetPeriod() uint {
+	if c.Period == 0 {
+		return DefaultTOTPPeriod
+	}
+	return c.Period
+}
+
+// GetIssuer returns the issuer label of TOTP
+// Default value is used if it is not set.
+func (c *TOTPConfig) GetIssuer() string {
+	if c.Issuer == "" {
+		return DefaultTOTPIssuer
+	}
+	return c.Issuer
+}
+
+// GetCodeLifeSpan returns the life span of the code sent by SMS
+// Default value is used if it is not set.
+func (c *SMSConfig) GetCodeLifeSpan() uint {
+	if c.CodeLifeSpan == 0 {
+		return DefaultSMSCodeLifeSpan
+	}
+	return c.CodeLifeSpan
+}
+
 // GetGrantType ...
 func GetGrantType(str string) (GrantType, *errors.Error) {
 	switch GrantType(str) {
@@ -165,6 +521,8 @@ func GetGrantType(str string) (GrantType, *errors.Error) {
 		return GrantTypePassword, nil
 	case GrantTypeDevice:
 		return GrantTypeDevice, nil
+	case GrantTypeCIBA:
+		return GrantTypeCIBA, nil
 	}
 
 	return GrantType(""), errors.New("No such grant type", "No such grant type")
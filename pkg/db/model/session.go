@@ -16,12 +16,26 @@ type Session struct {
 	ExpiresIn    int64
 	FromIP       string // Used to identify the user using this session
 	LastAuthTime time.Time
+	AuthMethods  []string // Authentication methods used in the login (amr)
+	FamilyID     string   // Sessions rotated from the same login have the same family ID
+	// PreviousSessionID is the session of the refresh token which was rotated to this session.
+	PreviousSessionID string
+	// LoginSessionID is the login session which the session was issued from.
+	// It is used to revoke all tokens issued from a replayed authorization code.
+	LoginSessionID string
+
+	// Offline session is issued by offline_access scope.
+	// It is independent of the SSO session, and it is never refreshed after OfflineExpiresAt.
+	Offline          bool
+	OfflineExpiresAt time.Time
 }
 
 // SessionFilter ...
 type SessionFilter struct {
-	SessionID string
-	UserID    string
+	SessionID      string
+	UserID         string
+	FamilyID       string
+	LoginSessionID string
 }
 
 // SessionHandler ...
@@ -60,6 +74,16 @@ func (s *Session) Validate() *errors.Error {
 		return errors.Append(ErrSessionValidateFailed, "Invalid user ID format")
 	}
 
+	// Check Family ID
+	if s.FamilyID != "" && !ValidateSessionID(s.FamilyID) {
+		return errors.Append(ErrSessionValidateFailed, "Invalid family ID format")
+	}
+
+	// Check Login Session ID
+	if s.LoginSessionID != "" && !ValidateSessionID(s.LoginSessionID) {
+		return errors.Append(ErrSessionValidateFailed, "Invalid login session ID format")
+	}
+
 	// Check From IP
 	if ok := govalidator.IsIP(s.FromIP); !ok {
 		return errors.Append(ErrSessionValidateFailed, "Invalid from IP")
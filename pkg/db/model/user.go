@@ -17,6 +17,86 @@ type OTPInfo struct {
 	ID         string
 	PrivateKey string
 	Enabled    bool
+
+	// Algorithm, Digits and Period are the parameters in the registration
+	// Default values of TOTPConfig are used if they are not set.
+	Algorithm string
+	Digits    uint
+	Period    uint
+
+	// LastUsedStep is the time step of the last accepted code to prevent the replay
+	LastUsedStep int64
+
+	// RecoveryCodes are hashes of the unused recovery codes
+	// A recovery code can be used instead of the TOTP code only once.
+	RecoveryCodes []string
+}
+
+// EMailOTPInfo ...
+type EMailOTPInfo struct {
+	Enabled bool
+
+	// SetupCode is a code to confirm the email address when the user enables email OTP
+	SetupCode OneTimeCode
+}
+
+// SMSOTPInfo ...
+// The phone number is saved in UserInfo.PhoneNumber, and it is verified by SetupCode.
+type SMSOTPInfo struct {
+	Enabled bool
+
+	// SetupCode is a code to confirm the phone number when the user enables SMS OTP
+	SetupCode OneTimeCode
+}
+
+// WebAuthnCredential is a public key credential which the user registers by the authenticator
+type WebAuthnCredential struct {
+	// ID is a base64url encoded credential id
+	ID                string
+	Name              string
+	PublicKey         []byte
+	SignCount         uint32
+	Transports        []string
+	AAGUID            string
+	AttestationFormat string
+	CreatedAt         time.Time
+	LastUsedAt        time.Time
+}
+
+// TrustedDevice is a browser in which the user skips the second factor until it expires
+type TrustedDevice struct {
+	ID         string
+	UserAgent  string
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	LastUsedAt time.Time
+}
+
+// LoginRecord is a successful login which is compared with the next login in the risk evaluation
+type LoginRecord struct {
+	IP        string
+	UserAgent string
+	Country   string // ISO 3166-1 alpha-2 code resolved by GeoIP, empty if unknown
+	Time      time.Time
+}
+
+// WebAuthnInfo ...
+type WebAuthnInfo struct {
+	Credentials []WebAuthnCredential
+
+	// RegistrationChallenge is a challenge of the registration ceremony in progress
+	RegistrationChallenge string
+	RegistrationExpiresAt time.Time
+}
+
+// Address is a postal address of the user defined in OpenID Connect Core 1.0 section 5.1.1
+type Address struct {
+	Formatted     string
+	StreetAddress string
+	Locality      string
+	Region        string
+	PostalCode    string
+	Country       string
 }
 
 // UserInfo ...
@@ -27,16 +107,44 @@ type UserInfo struct {
 	EMail        string
 	CreatedAt    time.Time
 	PasswordHash string
-	SystemRoles  []string
-	CustomRoles  []string
-	LockState    LockState
-	OTPInfo      OTPInfo
+	// PasswordUpdatedAt is used for the password age, CreatedAt is used if it is zero
+	PasswordUpdatedAt time.Time
+	// PasswordHistory is the hashes of the previous passwords in chronological order
+	PasswordHistory []string
+	// PasswordChangeRequired means that the user must change the password at next login
+	PasswordChangeRequired bool
+	SystemRoles            []string
+	CustomRoles            []string
+	LockState              LockState
+	OTPInfo                OTPInfo
+	WebAuthnInfo           WebAuthnInfo
+	EMailOTPInfo           EMailOTPInfo
+	SMSOTPInfo             SMSOTPInfo
+	// TrustedDevices are removed when the password is changed or OTP is reset
+	TrustedDevices []TrustedDevice
+	// LoginHistory is the recent successful logins in chronological order, up to MaxLoginHistory
+	LoginHistory []LoginRecord
+
+	// Standard profile claims
+	GivenName           string
+	FamilyName          string
+	Locale              string
+	Zoneinfo            string
+	PhoneNumber         string
+	PhoneNumberVerified bool
+	Address             Address
+	EMailVerified       bool
+	UpdatedAt           time.Time
+
+	// Attributes are custom user attributes which can be released by the protocol mapper
+	Attributes map[string][]string
 }
 
 // UserFilter ...
 type UserFilter struct {
-	ID   string
-	Name string
+	ID    string
+	Name  string
+	EMail string
 }
 
 // RoleType ...
@@ -49,6 +157,11 @@ func (t RoleType) String() string {
 	return t.value
 }
 
+const (
+	// MaxLoginHistory is max number of the successful logins kept in the user
+	MaxLoginHistory = 30
+)
+
 var (
 	// ErrUserAlreadyExists ...
 	ErrUserAlreadyExists = errors.New("User already exists", "User already exists")
@@ -62,6 +175,20 @@ var (
 	ErrUserValidateFailed = errors.New("User validation failed", "User validation failed")
 	// ErrUserOTPAlreadyEnabled ...
 	ErrUserOTPAlreadyEnabled = errors.New("User OTP already enabled", "User OTP already enabled")
+	// ErrUserOTPNotEnabled ...
+	ErrUserOTPNotEnabled = errors.New("User OTP is not enabled", "User OTP is not enabled")
+	// ErrOTPCodeAlreadyUsed ...
+	ErrOTPCodeAlreadyUsed = errors.New("OTP code already used", "OTP code already used")
+	// ErrNoSuchRecoveryCode ...
+	ErrNoSuchRecoveryCode = errors.New("Invalid recovery code", "No such recovery code")
+	// ErrWebAuthnCredentialAlreadyExists ...
+	ErrWebAuthnCredentialAlreadyExists = errors.New("WebAuthn credential already exists", "WebAuthn credential already exists")
+	// ErrNoSuchWebAuthnCredential ...
+	ErrNoSuchWebAuthnCredential = errors.New("No such WebAuthn credential", "No such WebAuthn credential")
+	// ErrNoSuchTrustedDevice ...
+	ErrNoSuchTrustedDevice = errors.New("No such trusted device", "No such trusted device")
+	// ErrWebAuthnCredentialValidateFailed ...
+	ErrWebAuthnCredentialValidateFailed = errors.New("WebAuthn credential validation failed", "WebAuthn credential validation failed")
 
 	// RoleSystem ...
 	RoleSystem = RoleType{"system_management"}
@@ -99,3 +226,40 @@ func (ui *UserInfo) Validate() *errors.Error {
 
 	return nil
 }
+
+// Validate ...
+func (c *WebAuthnCredential) Validate() *errors.Error {
+	if c.ID == "" {
+		return errors.Append(ErrWebAuthnCredentialValidateFailed, "Credential ID is empty")
+	}
+
+	if len(c.PublicKey) == 0 {
+		return errors.Append(ErrWebAuthnCredentialValidateFailed, "Public key is empty")
+	}
+
+	if len(c.Name) > 64 {
+		return errors.Append(ErrWebAuthnCredentialValidateFailed, "Too long credential name")
+	}
+
+	return nil
+}
+
+// GetWebAuthnCredential returns the credential which has the id
+func (ui *UserInfo) GetWebAuthnCredential(credentialID string) *WebAuthnCredential {
+	for i, c := range ui.WebAuthnInfo.Credentials {
+		if c.ID == credentialID {
+			return &ui.WebAuthnInfo.Credentials[i]
+		}
+	}
+	return nil
+}
+
+// GetTrustedDevice returns the trusted device which is not expired, or nil if not found
+func (ui *UserInfo) GetTrustedDevice(deviceID string, now time.Time) *TrustedDevice {
+	for i, d := range ui.TrustedDevices {
+		if d.ID == deviceID && now.Before(d.ExpiresAt) {
+			return &ui.TrustedDevices[i]
+		}
+	}
+	return nil
+}
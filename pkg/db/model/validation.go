@@ -1,7 +1,9 @@
 package model
 
 import (
+	"net/url"
 	"regexp"
+	"strings"
 
 	"github.com/asaskevich/govalidator"
 )
@@ -49,6 +51,22 @@ func ValidateClientSecret(secret string, accessType string) bool {
 	return true
 }
 
+// ValidateResponseType ...
+func ValidateResponseType(typ string) bool {
+	types := strings.Split(typ, " ")
+	for i, t := range types {
+		if t != "code" && t != "id_token" && t != "token" {
+			return false
+		}
+		for _, prev := range types[:i] {
+			if prev == t {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // ValidateClientAccessType ...
 func ValidateClientAccessType(typ string) bool {
 	allowedTypes := []string{
@@ -63,6 +81,42 @@ func ValidateClientAccessType(typ string) bool {
 	return false
 }
 
+// ValidateClientScopeName ...
+//   ref. https://tools.ietf.org/html/rfc6749#section-3.3
+func ValidateClientScopeName(name string) bool {
+	scopeNameRegExp := regexp.MustCompile(`^[\x21\x23-\x5B\x5D-\x7E]{1,64}$`)
+	return scopeNameRegExp.MatchString(name)
+}
+
+// ValidateLocale validates a language tag such as "en" or "ja-JP"
+//   ref. https://tools.ietf.org/html/rfc5646
+func ValidateLocale(locale string) bool {
+	localeRegExp := regexp.MustCompile(`^[a-zA-Z]{2,8}(-[a-zA-Z0-9]{1,8})*$`)
+	return localeRegExp.MatchString(locale)
+}
+
+// ValidateProtocolMapperID ...
+func ValidateProtocolMapperID(id string) bool {
+	return govalidator.IsUUID(id)
+}
+
+// ValidateClaimName ...
+func ValidateClaimName(name string) bool {
+	claimNameRegExp := regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9\-\_\.:]{0,127}$`)
+	if !claimNameRegExp.MatchString(name) {
+		return false
+	}
+
+	// registered claims can not be overwritten
+	reserved := []string{"iss", "sub", "aud", "exp", "nbf", "iat", "jti", "nonce", "auth_time", "format", "project", "scope"}
+	for _, r := range reserved {
+		if name == r {
+			return false
+		}
+	}
+	return true
+}
+
 // ValidateUserName ...
 func ValidateUserName(name string) bool {
 	if !(3 <= len(name) && len(name) < 64) {
@@ -94,6 +148,25 @@ func ValidateCustomRoleName(name string) bool {
 	return true
 }
 
+// ValidateAPIResourceID ...
+func ValidateAPIResourceID(id string) bool {
+	return govalidator.IsUUID(id)
+}
+
+// ValidateAPIResourceIdentifier validates the resource indicator
+// It must be an absolute URI, and must not include a fragment component.
+//   ref. https://tools.ietf.org/html/rfc8707#section-2
+func ValidateAPIResourceIdentifier(identifier string) bool {
+	if len(identifier) > 256 {
+		return false
+	}
+	u, err := url.Parse(identifier)
+	if err != nil {
+		return false
+	}
+	return u.IsAbs() && u.Fragment == "" && !strings.Contains(identifier, "#")
+}
+
 // ValidateAuthCode ...
 func ValidateAuthCode(code string) bool {
 	return govalidator.IsUUID(code)
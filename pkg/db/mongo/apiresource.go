@@ -0,0 +1,196 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// APIResourceHandler implement db.APIResourceHandler
+type APIResourceHandler struct {
+	dbClient *mongo.Client
+}
+
+// NewAPIResourceHandler ...
+func NewAPIResourceHandler(dbClient *mongo.Client) (*APIResourceHandler, *errors.Error) {
+	res := &APIResourceHandler{
+		dbClient: dbClient,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
+	defer cancel()
+
+	// Get index info
+	col := res.dbClient.Database(databaseName).Collection(apiResourceCollectionName)
+	iv := col.Indexes()
+	var ires []bson.M
+	cur, err := iv.List(ctx)
+	if err != nil {
+		return nil, errors.New("DB failed", "Failed to get index info: %v", err)
+	}
+	if err := cur.All(ctx, &ires); err != nil {
+		return nil, errors.New("DB failed", "Failed to get index info: %v", err)
+	}
+
+	if len(ires) == 0 {
+		logger.Info("Create index for API resource")
+		// Create Index to Project Name and API Resource ID
+		mod := mongo.IndexModel{
+			Keys: bson.M{
+				"project_name": 1, // index in ascending order
+				"id":           1, // index in ascending order
+			},
+		}
+		if _, err := iv.CreateOne(ctx, mod); err != nil {
+			return nil, errors.New("DB failed", "Failed to create index: %v", err)
+		}
+	}
+
+	return res, nil
+}
+
+// Add ...
+func (h *APIResourceHandler) Add(projectName string, ent *model.APIResource) *errors.Error {
+	v := &apiResource{
+		ID:                  ent.ID,
+		ProjectName:         ent.ProjectName,
+		CreatedAt:           ent.CreatedAt,
+		Identifier:          ent.Identifier,
+		Name:                ent.Name,
+		Scopes:              ent.Scopes,
+		RequiredRoles:       ent.RequiredRoles,
+		AccessTokenLifeSpan: ent.AccessTokenLifeSpan,
+	}
+
+	col := h.dbClient.Database(databaseName).Collection(apiResourceCollectionName)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
+	defer cancel()
+
+	_, err := col.InsertOne(ctx, v)
+	if err != nil {
+		return errors.New("DB failed", "Failed to insert API resource to mongodb: %v", err)
+	}
+
+	return nil
+}
+
+// Delete ...
+func (h *APIResourceHandler) Delete(projectName string, resourceID string) *errors.Error {
+	col := h.dbClient.Database(databaseName).Collection(apiResourceCollectionName)
+	filter := bson.D{
+		{Key: "project_name", Value: projectName},
+		{Key: "id", Value: resourceID},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
+	defer cancel()
+
+	_, err := col.DeleteOne(ctx, filter)
+	if err != nil {
+		return errors.New("DB failed", "Failed to delete API resource from mongodb: %v", err)
+	}
+	return nil
+}
+
+// GetList ...
+func (h *APIResourceHandler) GetList(projectName string, filter *model.APIResourceFilter) ([]*model.APIResource, *errors.Error) {
+	col := h.dbClient.Database(databaseName).Collection(apiResourceCollectionName)
+
+	f := bson.D{
+		{Key: "project_name", Value: projectName},
+	}
+
+	if filter != nil {
+		if filter.ID != "" {
+			f = append(f, bson.E{Key: "id", Value: filter.ID})
+		}
+		if filter.Identifier != "" {
+			f = append(f, bson.E{Key: "identifier", Value: filter.Identifier})
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
+	defer cancel()
+
+	cursor, err := col.Find(ctx, f)
+	if err != nil {
+		return nil, errors.New("DB failed", "Failed to get API resource list from mongodb: %v", err)
+	}
+
+	resources := []apiResource{}
+	if err := cursor.All(ctx, &resources); err != nil {
+		return nil, errors.New("DB failed", "Failed to get API resource list from mongodb: %v", err)
+	}
+
+	res := []*model.APIResource{}
+	for _, r := range resources {
+		res = append(res, &model.APIResource{
+			ID:                  r.ID,
+			ProjectName:         r.ProjectName,
+			CreatedAt:           r.CreatedAt,
+			Identifier:          r.Identifier,
+			Name:                r.Name,
+			Scopes:              r.Scopes,
+			RequiredRoles:       r.RequiredRoles,
+			AccessTokenLifeSpan: r.AccessTokenLifeSpan,
+		})
+	}
+
+	return res, nil
+}
+
+// Update ...
+func (h *APIResourceHandler) Update(projectName string, ent *model.APIResource) *errors.Error {
+	col := h.dbClient.Database(databaseName).Collection(apiResourceCollectionName)
+	filter := bson.D{
+		{Key: "project_name", Value: projectName},
+		{Key: "id", Value: ent.ID},
+	}
+
+	v := &apiResource{
+		ID:                  ent.ID,
+		ProjectName:         ent.ProjectName,
+		CreatedAt:           ent.CreatedAt,
+		Identifier:          ent.Identifier,
+		Name:                ent.Name,
+		Scopes:              ent.Scopes,
+		RequiredRoles:       ent.RequiredRoles,
+		AccessTokenLifeSpan: ent.AccessTokenLifeSpan,
+	}
+
+	updates := bson.D{
+		{Key: "$set", Value: v},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
+	defer cancel()
+
+	if _, err := col.UpdateOne(ctx, filter, updates); err != nil {
+		return errors.New("DB failed", "Failed to update API resource in mongodb: %v", err)
+	}
+
+	return nil
+}
+
+// DeleteAll ...
+func (h *APIResourceHandler) DeleteAll(projectName string) *errors.Error {
+	col := h.dbClient.Database(databaseName).Collection(apiResourceCollectionName)
+	filter := bson.D{
+		{Key: "project_name", Value: projectName},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
+	defer cancel()
+
+	_, err := col.DeleteMany(ctx, filter)
+	if err != nil {
+		return errors.New("DB failed", "Failed to delete API resource from mongodb: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// BackchannelAuthHandler implement db.BackchannelAuthHandler
+type BackchannelAuthHandler struct {
+	dbClient *mongo.Client
+}
+
+// NewBackchannelAuthHandler ...
+func NewBackchannelAuthHandler(dbClient *mongo.Client) (*BackchannelAuthHandler, *errors.Error) {
+	res := &BackchannelAuthHandler{
+		dbClient: dbClient,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
+	defer cancel()
+
+	// Get index info
+	col := res.dbClient.Database(databaseName).Collection(backchannelAuthCollectionName)
+	iv := col.Indexes()
+	var ires []bson.M
+	cur, err := iv.List(ctx)
+	if err != nil {
+		return nil, errors.New("DB failed", "Failed to get index info: %v", err)
+	}
+	if err := cur.All(ctx, &ires); err != nil {
+		return nil, errors.New("DB failed", "Failed to get index info: %v", err)
+	}
+
+	if len(ires) == 0 {
+		logger.Info("Create index for backchannel auth")
+		// Create Index to Project Name and Auth Request ID
+		mod := mongo.IndexModel{
+			Keys: bson.M{
+				"project_name": 1, // index in ascending order
+				"auth_req_id":  1, // index in ascending order
+			},
+		}
+		if _, err := iv.CreateOne(ctx, mod); err != nil {
+			return nil, errors.New("DB failed", "Failed to create index: %v", err)
+		}
+	}
+
+	return res, nil
+}
+
+// Add ...
+func (h *BackchannelAuthHandler) Add(projectName string, ent *model.BackchannelAuth) *errors.Error {
+	v := &backchannelAuth{
+		AuthReqID:               ent.AuthReqID,
+		ProjectName:             ent.ProjectName,
+		ClientID:                ent.ClientID,
+		UserID:                  ent.UserID,
+		Scopes:                  ent.Scopes,
+		BindingMessage:          ent.BindingMessage,
+		ExpiresIn:               ent.ExpiresIn,
+		ExpiresAt:               ent.CreatedAt.Add(time.Second * time.Duration(ent.ExpiresIn)),
+		CreatedAt:               ent.CreatedAt,
+		Status:                  string(ent.Status),
+		Interval:                ent.Interval,
+		LastPolledAt:            ent.LastPolledAt,
+		ClientNotificationToken: ent.ClientNotificationToken,
+		AuthDate:                ent.AuthDate,
+		AuthMethods:             ent.AuthMethods,
+	}
+
+	col := h.dbClient.Database(databaseName).Collection(backchannelAuthCollectionName)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
+	defer cancel()
+
+	_, err := col.InsertOne(ctx, v)
+	if err != nil {
+		return errors.New("DB failed", "Failed to insert backchannel auth to mongodb: %v", err)
+	}
+
+	return nil
+}
+
+// Update ...
+func (h *BackchannelAuthHandler) Update(projectName string, ent *model.BackchannelAuth) *errors.Error {
+	col := h.dbClient.Database(databaseName).Collection(backchannelAuthCollectionName)
+	filter := bson.D{
+		{Key: "project_name", Value: projectName},
+		{Key: "auth_req_id", Value: ent.AuthReqID},
+	}
+
+	v := &backchannelAuth{
+		AuthReqID:               ent.AuthReqID,
+		ProjectName:             ent.ProjectName,
+		ClientID:                ent.ClientID,
+		UserID:                  ent.UserID,
+		Scopes:                  ent.Scopes,
+		BindingMessage:          ent.BindingMessage,
+		ExpiresIn:               ent.ExpiresIn,
+		ExpiresAt:               ent.CreatedAt.Add(time.Second * time.Duration(ent.ExpiresIn)),
+		CreatedAt:               ent.CreatedAt,
+		Status:                  string(ent.Status),
+		Interval:                ent.Interval,
+		LastPolledAt:            ent.LastPolledAt,
+		ClientNotificationToken: ent.ClientNotificationToken,
+		AuthDate:                ent.AuthDate,
+		AuthMethods:             ent.AuthMethods,
+	}
+
+	updates := bson.D{
+		{Key: "$set", Value: v},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
+	defer cancel()
+
+	if _, err := col.UpdateOne(ctx, filter, updates); err != nil {
+		return errors.New("DB failed", "Failed to update backchannel auth in mongodb: %v", err)
+	}
+
+	return nil
+}
+
+// DeleteAll ...
+func (h *BackchannelAuthHandler) DeleteAll(projectName string) *errors.Error {
+	col := h.dbClient.Database(databaseName).Collection(backchannelAuthCollectionName)
+	filter := bson.D{
+		{Key: "project_name", Value: projectName},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
+	defer cancel()
+
+	_, err := col.DeleteMany(ctx, filter)
+	if err != nil {
+		return errors.New("DB failed", "Failed to delete backchannel auth from mongodb: %v", err)
+	}
+	return nil
+}
+
+// Cleanup ...
+func (h *BackchannelAuthHandler) Cleanup(now time.Time) *errors.Error {
+	col := h.dbClient.Database(databaseName).Collection(backchannelAuthCollectionName)
+	filter := bson.D{
+		{Key: "expires_at", Value: bson.D{{Key: "$lte", Value: now}}},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
+	defer cancel()
+
+	_, err := col.DeleteMany(ctx, filter)
+	if err != nil {
+		return errors.New("DB failed", "Failed to delete expired backchannel auth from mongodb: %v", err)
+	}
+
+	return nil
+}
+
+// GetList ...
+func (h *BackchannelAuthHandler) GetList(projectName string, filter *model.BackchannelAuthFilter) ([]*model.BackchannelAuth, *errors.Error) {
+	col := h.dbClient.Database(databaseName).Collection(backchannelAuthCollectionName)
+
+	f := bson.D{
+		{Key: "project_name", Value: projectName},
+	}
+
+	if filter != nil {
+		if filter.AuthReqID != "" {
+			f = append(f, bson.E{Key: "auth_req_id", Value: filter.AuthReqID})
+		}
+		if filter.UserID != "" {
+			f = append(f, bson.E{Key: "user_id", Value: filter.UserID})
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
+	defer cancel()
+
+	cursor, err := col.Find(ctx, f)
+	if err != nil {
+		return nil, errors.New("DB failed", "Failed to get backchannel auth list from mongodb: %v", err)
+	}
+
+	requests := []backchannelAuth{}
+	if err := cursor.All(ctx, &requests); err != nil {
+		return nil, errors.New("DB failed", "Failed to get backchannel auth list from mongodb: %v", err)
+	}
+
+	res := []*model.BackchannelAuth{}
+	for _, ent := range requests {
+		res = append(res, &model.BackchannelAuth{
+			AuthReqID:               ent.AuthReqID,
+			ProjectName:             ent.ProjectName,
+			ClientID:                ent.ClientID,
+			UserID:                  ent.UserID,
+			Scopes:                  ent.Scopes,
+			BindingMessage:          ent.BindingMessage,
+			ExpiresIn:               ent.ExpiresIn,
+			CreatedAt:               ent.CreatedAt,
+			Status:                  model.BackchannelAuthStatus(ent.Status),
+			Interval:                ent.Interval,
+			LastPolledAt:            ent.LastPolledAt,
+			ClientNotificationToken: ent.ClientNotificationToken,
+			AuthDate:                ent.AuthDate,
+			AuthMethods:             ent.AuthMethods,
+		})
+	}
+
+	return res, nil
+}
+
+// Delete ...
+func (h *BackchannelAuthHandler) Delete(projectName string, authReqID string) *errors.Error {
+	col := h.dbClient.Database(databaseName).Collection(backchannelAuthCollectionName)
+	filter := bson.D{
+		{Key: "project_name", Value: projectName},
+		{Key: "auth_req_id", Value: authReqID},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
+	defer cancel()
+
+	res, err := col.DeleteOne(ctx, filter)
+	if err != nil {
+		return errors.New("DB failed", "Failed to delete backchannel auth from mongodb: %v", err)
+	}
+	if res.DeletedCount == 0 {
+		return model.ErrNoSuchBackchannelAuth
+	}
+	return nil
+}
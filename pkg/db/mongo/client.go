@@ -57,12 +57,29 @@ func NewClientHandler(dbClient *mongo.Client) (*ClientInfoHandler, *errors.Error
 // Add ...
 func (h *ClientInfoHandler) Add(projectName string, ent *model.ClientInfo) *errors.Error {
 	v := &clientInfo{
-		ID:                  ent.ID,
-		ProjectName:         ent.ProjectName,
-		Secret:              ent.Secret,
-		AccessType:          ent.AccessType,
-		CreatedAt:           ent.CreatedAt,
-		AllowedCallbackURLs: ent.AllowedCallbackURLs,
+		ID:                                    ent.ID,
+		ProjectName:                           ent.ProjectName,
+		Secret:                                ent.Secret,
+		AccessType:                            ent.AccessType,
+		CreatedAt:                             ent.CreatedAt,
+		AllowedCallbackURLs:                   ent.AllowedCallbackURLs,
+		ProtocolMappers:                       toMongoMappers(ent.ProtocolMappers),
+		DefaultScopes:                         ent.DefaultScopes,
+		OptionalScopes:                        ent.OptionalScopes,
+		AccessTokenLifeSpan:                   ent.AccessTokenLifeSpan,
+		RefreshTokenLifeSpan:                  ent.RefreshTokenLifeSpan,
+		IDTokenLifeSpan:                       ent.IDTokenLifeSpan,
+		AllowGrantTypes:                       toMongoGrantTypes(ent.AllowGrantTypes),
+		AllowResponseTypes:                    ent.AllowResponseTypes,
+		RequirePKCE:                           ent.RequirePKCE,
+		JWKS:                                  ent.JWKS,
+		IDTokenEncryptedResponseAlg:           ent.IDTokenEncryptedResponseAlg,
+		IDTokenEncryptedResponseEnc:           ent.IDTokenEncryptedResponseEnc,
+		UserinfoSignedResponseAlg:             ent.UserinfoSignedResponseAlg,
+		UserinfoEncryptedResponseAlg:          ent.UserinfoEncryptedResponseAlg,
+		UserinfoEncryptedResponseEnc:          ent.UserinfoEncryptedResponseEnc,
+		BackchannelTokenDeliveryMode:          ent.BackchannelTokenDeliveryMode,
+		BackchannelClientNotificationEndpoint: ent.BackchannelClientNotificationEndpoint,
 	}
 
 	col := h.dbClient.Database(databaseName).Collection(clientCollectionName)
@@ -126,12 +143,29 @@ func (h *ClientInfoHandler) GetList(projectName string, filter *model.ClientFilt
 	res := []*model.ClientInfo{}
 	for _, client := range clients {
 		res = append(res, &model.ClientInfo{
-			ID:                  client.ID,
-			ProjectName:         client.ProjectName,
-			Secret:              client.Secret,
-			AccessType:          client.AccessType,
-			CreatedAt:           client.CreatedAt,
-			AllowedCallbackURLs: client.AllowedCallbackURLs,
+			ID:                                    client.ID,
+			ProjectName:                           client.ProjectName,
+			Secret:                                client.Secret,
+			AccessType:                            client.AccessType,
+			CreatedAt:                             client.CreatedAt,
+			AllowedCallbackURLs:                   client.AllowedCallbackURLs,
+			ProtocolMappers:                       toModelMappers(client.ProtocolMappers),
+			DefaultScopes:                         client.DefaultScopes,
+			OptionalScopes:                        client.OptionalScopes,
+			AccessTokenLifeSpan:                   client.AccessTokenLifeSpan,
+			RefreshTokenLifeSpan:                  client.RefreshTokenLifeSpan,
+			IDTokenLifeSpan:                       client.IDTokenLifeSpan,
+			AllowGrantTypes:                       toModelGrantTypes(client.AllowGrantTypes),
+			AllowResponseTypes:                    client.AllowResponseTypes,
+			RequirePKCE:                           client.RequirePKCE,
+			JWKS:                                  client.JWKS,
+			IDTokenEncryptedResponseAlg:           client.IDTokenEncryptedResponseAlg,
+			IDTokenEncryptedResponseEnc:           client.IDTokenEncryptedResponseEnc,
+			UserinfoSignedResponseAlg:             client.UserinfoSignedResponseAlg,
+			UserinfoEncryptedResponseAlg:          client.UserinfoEncryptedResponseAlg,
+			UserinfoEncryptedResponseEnc:          client.UserinfoEncryptedResponseEnc,
+			BackchannelTokenDeliveryMode:          client.BackchannelTokenDeliveryMode,
+			BackchannelClientNotificationEndpoint: client.BackchannelClientNotificationEndpoint,
 		})
 	}
 
@@ -147,12 +181,29 @@ func (h *ClientInfoHandler) Update(projectName string, ent *model.ClientInfo) *e
 	}
 
 	v := &clientInfo{
-		ID:                  ent.ID,
-		ProjectName:         ent.ProjectName,
-		Secret:              ent.Secret,
-		AccessType:          ent.AccessType,
-		CreatedAt:           ent.CreatedAt,
-		AllowedCallbackURLs: ent.AllowedCallbackURLs,
+		ID:                                    ent.ID,
+		ProjectName:                           ent.ProjectName,
+		Secret:                                ent.Secret,
+		AccessType:                            ent.AccessType,
+		CreatedAt:                             ent.CreatedAt,
+		AllowedCallbackURLs:                   ent.AllowedCallbackURLs,
+		ProtocolMappers:                       toMongoMappers(ent.ProtocolMappers),
+		DefaultScopes:                         ent.DefaultScopes,
+		OptionalScopes:                        ent.OptionalScopes,
+		AccessTokenLifeSpan:                   ent.AccessTokenLifeSpan,
+		RefreshTokenLifeSpan:                  ent.RefreshTokenLifeSpan,
+		IDTokenLifeSpan:                       ent.IDTokenLifeSpan,
+		AllowGrantTypes:                       toMongoGrantTypes(ent.AllowGrantTypes),
+		AllowResponseTypes:                    ent.AllowResponseTypes,
+		RequirePKCE:                           ent.RequirePKCE,
+		JWKS:                                  ent.JWKS,
+		IDTokenEncryptedResponseAlg:           ent.IDTokenEncryptedResponseAlg,
+		IDTokenEncryptedResponseEnc:           ent.IDTokenEncryptedResponseEnc,
+		UserinfoSignedResponseAlg:             ent.UserinfoSignedResponseAlg,
+		UserinfoEncryptedResponseAlg:          ent.UserinfoEncryptedResponseAlg,
+		UserinfoEncryptedResponseEnc:          ent.UserinfoEncryptedResponseEnc,
+		BackchannelTokenDeliveryMode:          ent.BackchannelTokenDeliveryMode,
+		BackchannelClientNotificationEndpoint: ent.BackchannelClientNotificationEndpoint,
 	}
 
 	updates := bson.D{
@@ -185,3 +236,19 @@ func (h *ClientInfoHandler) DeleteAll(projectName string) *errors.Error {
 	}
 	return nil
 }
+
+func toMongoGrantTypes(types []model.GrantType) []string {
+	res := []string{}
+	for _, t := range types {
+		res = append(res, string(t))
+	}
+	return res
+}
+
+func toModelGrantTypes(types []string) []model.GrantType {
+	res := []model.GrantType{}
+	for _, t := range types {
+		res = append(res, model.GrantType(t))
+	}
+	return res
+}
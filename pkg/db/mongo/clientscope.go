@@ -0,0 +1,190 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ClientScopeHandler implement db.ClientScopeHandler
+type ClientScopeHandler struct {
+	dbClient *mongo.Client
+}
+
+// NewClientScopeHandler ...
+func NewClientScopeHandler(dbClient *mongo.Client) (*ClientScopeHandler, *errors.Error) {
+	res := &ClientScopeHandler{
+		dbClient: dbClient,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
+	defer cancel()
+
+	// Get index info
+	col := res.dbClient.Database(databaseName).Collection(clientScopeCollectionName)
+	iv := col.Indexes()
+	var ires []bson.M
+	cur, err := iv.List(ctx)
+	if err != nil {
+		return nil, errors.New("DB failed", "Failed to get index info: %v", err)
+	}
+	if err := cur.All(ctx, &ires); err != nil {
+		return nil, errors.New("DB failed", "Failed to get index info: %v", err)
+	}
+
+	if len(ires) == 0 {
+		logger.Info("Create index for client scope")
+		// Create Index to Project Name and Client Scope Name
+		mod := mongo.IndexModel{
+			Keys: bson.M{
+				"project_name": 1, // index in ascending order
+				"name":         1, // index in ascending order
+			},
+		}
+		if _, err := iv.CreateOne(ctx, mod); err != nil {
+			return nil, errors.New("DB failed", "Failed to create index: %v", err)
+		}
+	}
+
+	return res, nil
+}
+
+// Add ...
+func (h *ClientScopeHandler) Add(projectName string, ent *model.ClientScope) *errors.Error {
+	v := &clientScope{
+		Name:            ent.Name,
+		ProjectName:     ent.ProjectName,
+		CreatedAt:       ent.CreatedAt,
+		Description:     ent.Description,
+		Claims:          ent.Claims,
+		ProtocolMappers: toMongoMappers(ent.ProtocolMappers),
+		RequiredRoles:   ent.RequiredRoles,
+	}
+
+	col := h.dbClient.Database(databaseName).Collection(clientScopeCollectionName)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
+	defer cancel()
+
+	_, err := col.InsertOne(ctx, v)
+	if err != nil {
+		return errors.New("DB failed", "Failed to insert client scope to mongodb: %v", err)
+	}
+
+	return nil
+}
+
+// Delete ...
+func (h *ClientScopeHandler) Delete(projectName string, name string) *errors.Error {
+	col := h.dbClient.Database(databaseName).Collection(clientScopeCollectionName)
+	filter := bson.D{
+		{Key: "project_name", Value: projectName},
+		{Key: "name", Value: name},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
+	defer cancel()
+
+	_, err := col.DeleteOne(ctx, filter)
+	if err != nil {
+		return errors.New("DB failed", "Failed to delete client scope from mongodb: %v", err)
+	}
+	return nil
+}
+
+// GetList ...
+func (h *ClientScopeHandler) GetList(projectName string, filter *model.ClientScopeFilter) ([]*model.ClientScope, *errors.Error) {
+	col := h.dbClient.Database(databaseName).Collection(clientScopeCollectionName)
+
+	f := bson.D{
+		{Key: "project_name", Value: projectName},
+	}
+
+	if filter != nil {
+		if filter.Name != "" {
+			f = append(f, bson.E{Key: "name", Value: filter.Name})
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
+	defer cancel()
+
+	cursor, err := col.Find(ctx, f)
+	if err != nil {
+		return nil, errors.New("DB failed", "Failed to get client scope list from mongodb: %v", err)
+	}
+
+	scopes := []clientScope{}
+	if err := cursor.All(ctx, &scopes); err != nil {
+		return nil, errors.New("DB failed", "Failed to get client scope list from mongodb: %v", err)
+	}
+
+	res := []*model.ClientScope{}
+	for _, s := range scopes {
+		res = append(res, &model.ClientScope{
+			Name:            s.Name,
+			ProjectName:     s.ProjectName,
+			CreatedAt:       s.CreatedAt,
+			Description:     s.Description,
+			Claims:          s.Claims,
+			ProtocolMappers: toModelMappers(s.ProtocolMappers),
+			RequiredRoles:   s.RequiredRoles,
+		})
+	}
+
+	return res, nil
+}
+
+// Update ...
+func (h *ClientScopeHandler) Update(projectName string, ent *model.ClientScope) *errors.Error {
+	col := h.dbClient.Database(databaseName).Collection(clientScopeCollectionName)
+	filter := bson.D{
+		{Key: "project_name", Value: projectName},
+		{Key: "name", Value: ent.Name},
+	}
+
+	v := &clientScope{
+		Name:            ent.Name,
+		ProjectName:     ent.ProjectName,
+		CreatedAt:       ent.CreatedAt,
+		Description:     ent.Description,
+		Claims:          ent.Claims,
+		ProtocolMappers: toMongoMappers(ent.ProtocolMappers),
+		RequiredRoles:   ent.RequiredRoles,
+	}
+
+	updates := bson.D{
+		{Key: "$set", Value: v},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
+	defer cancel()
+
+	if _, err := col.UpdateOne(ctx, filter, updates); err != nil {
+		return errors.New("DB failed", "Failed to update client scope in mongodb: %v", err)
+	}
+
+	return nil
+}
+
+// DeleteAll ...
+func (h *ClientScopeHandler) DeleteAll(projectName string) *errors.Error {
+	col := h.dbClient.Database(databaseName).Collection(clientScopeCollectionName)
+	filter := bson.D{
+		{Key: "project_name", Value: projectName},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
+	defer cancel()
+
+	_, err := col.DeleteMany(ctx, filter)
+	if err != nil {
+		return errors.New("DB failed", "Failed to delete client scope from mongodb: %v", err)
+	}
+	return nil
+}
@@ -61,8 +61,12 @@ func (h *DeviceHandler) Add(projectName string, ent *model.Device) *errors.Error
 		UserCode:       ent.UserCode,
 		ProjectName:    ent.ProjectName,
 		ExpiresIn:      ent.ExpiresIn,
+		ExpiresAt:      ent.CreatedAt.Add(time.Second * time.Duration(ent.ExpiresIn)),
 		CreatedAt:      ent.CreatedAt,
 		LoginSessionID: ent.LoginSessionID,
+		ClientID:       ent.ClientID,
+		Interval:       ent.Interval,
+		LastPolledAt:   ent.LastPolledAt,
 	}
 
 	col := h.dbClient.Database(databaseName).Collection(deviceCollectionName)
@@ -78,6 +82,41 @@ func (h *DeviceHandler) Add(projectName string, ent *model.Device) *errors.Error
 	return nil
 }
 
+// Update ...
+func (h *DeviceHandler) Update(projectName string, ent *model.Device) *errors.Error {
+	col := h.dbClient.Database(databaseName).Collection(deviceCollectionName)
+	filter := bson.D{
+		{Key: "project_name", Value: projectName},
+		{Key: "device_code", Value: ent.DeviceCode},
+	}
+
+	v := &device{
+		DeviceCode:     ent.DeviceCode,
+		UserCode:       ent.UserCode,
+		ProjectName:    ent.ProjectName,
+		ExpiresIn:      ent.ExpiresIn,
+		ExpiresAt:      ent.CreatedAt.Add(time.Second * time.Duration(ent.ExpiresIn)),
+		CreatedAt:      ent.CreatedAt,
+		LoginSessionID: ent.LoginSessionID,
+		ClientID:       ent.ClientID,
+		Interval:       ent.Interval,
+		LastPolledAt:   ent.LastPolledAt,
+	}
+
+	updates := bson.D{
+		{Key: "$set", Value: v},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
+	defer cancel()
+
+	if _, err := col.UpdateOne(ctx, filter, updates); err != nil {
+		return errors.New("DB failed", "Failed to update device in mongodb: %v", err)
+	}
+
+	return nil
+}
+
 // DeleteAll ...
 func (h *DeviceHandler) DeleteAll(projectName string) *errors.Error {
 	col := h.dbClient.Database(databaseName).Collection(deviceCollectionName)
@@ -99,7 +138,7 @@ func (h *DeviceHandler) DeleteAll(projectName string) *errors.Error {
 func (h *DeviceHandler) Cleanup(now time.Time) *errors.Error {
 	col := h.dbClient.Database(databaseName).Collection(deviceCollectionName)
 	filter := bson.D{
-		{Key: "expires_in", Value: bson.D{{Key: "$lt", Value: now.Unix()}}},
+		{Key: "expires_at", Value: bson.D{{Key: "$lt", Value: now}}},
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
@@ -152,6 +191,9 @@ func (h *DeviceHandler) GetList(projectName string, filter *model.DeviceFilter)
 			ExpiresIn:      ent.ExpiresIn,
 			CreatedAt:      ent.CreatedAt,
 			LoginSessionID: ent.LoginSessionID,
+			ClientID:       ent.ClientID,
+			Interval:       ent.Interval,
+			LastPolledAt:   ent.LastPolledAt,
 		})
 	}
 
@@ -57,21 +57,33 @@ func NewLoginSessionHandler(dbClient *mongo.Client) (*LoginSessionHandler, *erro
 // Add ...
 func (h *LoginSessionHandler) Add(projectName string, ent *model.LoginSession) *errors.Error {
 	v := &loginSession{
-		SessionID:           ent.SessionID,
-		Code:                ent.Code,
-		ExpiresDate:         ent.ExpiresDate,
-		Scopes:              ent.Scopes,
-		ResponseType:        ent.ResponseType,
-		ClientID:            ent.ClientID,
-		RedirectURI:         ent.RedirectURI,
-		Nonce:               ent.Nonce,
-		ProjectName:         ent.ProjectName,
-		ResponseMode:        ent.ResponseMode,
-		Prompt:              ent.Prompt,
-		UserID:              ent.UserID,
-		LoginDate:           ent.LoginDate,
-		CodeChallenge:       ent.CodeChallenge,
-		CodeChallengeMethod: ent.CodeChallengeMethod,
+		SessionID:              ent.SessionID,
+		Code:                   ent.Code,
+		ExpiresDate:            ent.ExpiresDate,
+		Scopes:                 ent.Scopes,
+		ResponseType:           ent.ResponseType,
+		ClientID:               ent.ClientID,
+		RedirectURI:            ent.RedirectURI,
+		Nonce:                  ent.Nonce,
+		ProjectName:            ent.ProjectName,
+		ResponseMode:           ent.ResponseMode,
+		Prompt:                 ent.Prompt,
+		UserID:                 ent.UserID,
+		LoginDate:              ent.LoginDate,
+		CodeChallenge:          ent.CodeChallenge,
+		CodeChallengeMethod:    ent.CodeChallengeMethod,
+		Claims:                 ent.Claims,
+		AuthMethods:            ent.AuthMethods,
+		ACRValues:              ent.ACRValues,
+		UILocales:              ent.UILocales,
+		Resources:              ent.Resources,
+		WebAuthnChallenge:      ent.WebAuthnChallenge,
+		MailCode:               toMongoOneTimeCode(ent.MailCode),
+		SMSCode:                toMongoOneTimeCode(ent.SMSCode),
+		Consented:              ent.Consented,
+		CodeRedeemed:           ent.CodeRedeemed,
+		RiskMFARequired:        ent.RiskMFARequired,
+		PasswordChangeRequired: ent.PasswordChangeRequired,
 	}
 
 	col := h.dbClient.Database(databaseName).Collection(authcodeSessionCollectionName)
@@ -96,21 +108,33 @@ func (h *LoginSessionHandler) Update(projectName string, ent *model.LoginSession
 	}
 
 	v := &loginSession{
-		SessionID:           ent.SessionID,
-		Code:                ent.Code,
-		ExpiresDate:         ent.ExpiresDate,
-		Scopes:              ent.Scopes,
-		ResponseType:        ent.ResponseType,
-		ClientID:            ent.ClientID,
-		RedirectURI:         ent.RedirectURI,
-		Nonce:               ent.Nonce,
-		ProjectName:         ent.ProjectName,
-		ResponseMode:        ent.ResponseMode,
-		Prompt:              ent.Prompt,
-		UserID:              ent.UserID,
-		LoginDate:           ent.LoginDate,
-		CodeChallenge:       ent.CodeChallenge,
-		CodeChallengeMethod: ent.CodeChallengeMethod,
+		SessionID:              ent.SessionID,
+		Code:                   ent.Code,
+		ExpiresDate:            ent.ExpiresDate,
+		Scopes:                 ent.Scopes,
+		ResponseType:           ent.ResponseType,
+		ClientID:               ent.ClientID,
+		RedirectURI:            ent.RedirectURI,
+		Nonce:                  ent.Nonce,
+		ProjectName:            ent.ProjectName,
+		ResponseMode:           ent.ResponseMode,
+		Prompt:                 ent.Prompt,
+		UserID:                 ent.UserID,
+		LoginDate:              ent.LoginDate,
+		CodeChallenge:          ent.CodeChallenge,
+		CodeChallengeMethod:    ent.CodeChallengeMethod,
+		Claims:                 ent.Claims,
+		AuthMethods:            ent.AuthMethods,
+		ACRValues:              ent.ACRValues,
+		UILocales:              ent.UILocales,
+		Resources:              ent.Resources,
+		WebAuthnChallenge:      ent.WebAuthnChallenge,
+		MailCode:               toMongoOneTimeCode(ent.MailCode),
+		SMSCode:                toMongoOneTimeCode(ent.SMSCode),
+		Consented:              ent.Consented,
+		CodeRedeemed:           ent.CodeRedeemed,
+		RiskMFARequired:        ent.RiskMFARequired,
+		PasswordChangeRequired: ent.PasswordChangeRequired,
 	}
 
 	updates := bson.D{
@@ -180,21 +204,33 @@ func (h *LoginSessionHandler) GetByCode(projectName string, code string) (*model
 	}
 
 	return &model.LoginSession{
-		SessionID:           res.SessionID,
-		Code:                res.Code,
-		ExpiresDate:         res.ExpiresDate,
-		Scopes:              res.Scopes,
-		ResponseType:        res.ResponseType,
-		ClientID:            res.ClientID,
-		RedirectURI:         res.RedirectURI,
-		Nonce:               res.Nonce,
-		ProjectName:         res.ProjectName,
-		ResponseMode:        res.ResponseMode,
-		Prompt:              res.Prompt,
-		UserID:              res.UserID,
-		LoginDate:           res.LoginDate,
-		CodeChallenge:       res.CodeChallenge,
-		CodeChallengeMethod: res.CodeChallengeMethod,
+		SessionID:              res.SessionID,
+		Code:                   res.Code,
+		ExpiresDate:            res.ExpiresDate,
+		Scopes:                 res.Scopes,
+		ResponseType:           res.ResponseType,
+		ClientID:               res.ClientID,
+		RedirectURI:            res.RedirectURI,
+		Nonce:                  res.Nonce,
+		ProjectName:            res.ProjectName,
+		ResponseMode:           res.ResponseMode,
+		Prompt:                 res.Prompt,
+		UserID:                 res.UserID,
+		LoginDate:              res.LoginDate,
+		CodeChallenge:          res.CodeChallenge,
+		CodeChallengeMethod:    res.CodeChallengeMethod,
+		Claims:                 res.Claims,
+		AuthMethods:            res.AuthMethods,
+		ACRValues:              res.ACRValues,
+		UILocales:              res.UILocales,
+		Resources:              res.Resources,
+		WebAuthnChallenge:      res.WebAuthnChallenge,
+		MailCode:               toModelOneTimeCode(res.MailCode),
+		SMSCode:                toModelOneTimeCode(res.SMSCode),
+		Consented:              res.Consented,
+		CodeRedeemed:           res.CodeRedeemed,
+		RiskMFARequired:        res.RiskMFARequired,
+		PasswordChangeRequired: res.PasswordChangeRequired,
 	}, nil
 }
 
@@ -218,24 +254,65 @@ func (h *LoginSessionHandler) Get(projectName string, id string) (*model.LoginSe
 	}
 
 	return &model.LoginSession{
-		SessionID:           res.SessionID,
-		Code:                res.Code,
-		ExpiresDate:         res.ExpiresDate,
-		Scopes:              res.Scopes,
-		ResponseType:        res.ResponseType,
-		ClientID:            res.ClientID,
-		RedirectURI:         res.RedirectURI,
-		Nonce:               res.Nonce,
-		ProjectName:         res.ProjectName,
-		ResponseMode:        res.ResponseMode,
-		Prompt:              res.Prompt,
-		UserID:              res.UserID,
-		LoginDate:           res.LoginDate,
-		CodeChallenge:       res.CodeChallenge,
-		CodeChallengeMethod: res.CodeChallengeMethod,
+		SessionID:              res.SessionID,
+		Code:                   res.Code,
+		ExpiresDate:            res.ExpiresDate,
+		Scopes:                 res.Scopes,
+		ResponseType:           res.ResponseType,
+		ClientID:               res.ClientID,
+		RedirectURI:            res.RedirectURI,
+		Nonce:                  res.Nonce,
+		ProjectName:            res.ProjectName,
+		ResponseMode:           res.ResponseMode,
+		Prompt:                 res.Prompt,
+		UserID:                 res.UserID,
+		LoginDate:              res.LoginDate,
+		CodeChallenge:          res.CodeChallenge,
+		CodeChallengeMethod:    res.CodeChallengeMethod,
+		Claims:                 res.Claims,
+		AuthMethods:            res.AuthMethods,
+		ACRValues:              res.ACRValues,
+		UILocales:              res.UILocales,
+		Resources:              res.Resources,
+		WebAuthnChallenge:      res.WebAuthnChallenge,
+		MailCode:               toModelOneTimeCode(res.MailCode),
+		SMSCode:                toModelOneTimeCode(res.SMSCode),
+		Consented:              res.Consented,
+		CodeRedeemed:           res.CodeRedeemed,
+		RiskMFARequired:        res.RiskMFARequired,
+		PasswordChangeRequired: res.PasswordChangeRequired,
 	}, nil
 }
 
+// RedeemCode ...
+func (h *LoginSessionHandler) RedeemCode(projectName string, sessionID string) *errors.Error {
+	col := h.dbClient.Database(databaseName).Collection(authcodeSessionCollectionName)
+	// the condition of code_redeemed makes the update atomic against the concurrent redemption
+	filter := bson.D{
+		{Key: "project_name", Value: projectName},
+		{Key: "session_id", Value: sessionID},
+		{Key: "code_redeemed", Value: false},
+	}
+	updates := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "code_redeemed", Value: true},
+		}},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
+	defer cancel()
+
+	res, err := col.UpdateOne(ctx, filter, updates)
+	if err != nil {
+		return errors.New("DB failed", "Failed to redeem code in mongodb: %v", err)
+	}
+	if res.MatchedCount == 0 {
+		return model.ErrLoginSessionCodeRedeemed
+	}
+
+	return nil
+}
+
 // DeleteAll ...
 func (h *LoginSessionHandler) DeleteAll(projectName string) *errors.Error {
 	col := h.dbClient.Database(databaseName).Collection(authcodeSessionCollectionName)
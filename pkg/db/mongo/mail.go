@@ -0,0 +1,78 @@
+package mongo
+
+import (
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+)
+
+func toMongoOneTimeCode(c model.OneTimeCode) oneTimeCode {
+	return oneTimeCode{
+		Purpose:     c.Purpose,
+		UserID:      c.UserID,
+		CodeHash:    c.CodeHash,
+		ExpiresAt:   c.ExpiresAt,
+		SentAt:      c.SentAt,
+		SentTimes:   c.SentTimes,
+		FailedTimes: c.FailedTimes,
+	}
+}
+
+func toModelOneTimeCode(c oneTimeCode) model.OneTimeCode {
+	return model.OneTimeCode{
+		Purpose:     c.Purpose,
+		UserID:      c.UserID,
+		CodeHash:    c.CodeHash,
+		ExpiresAt:   c.ExpiresAt,
+		SentAt:      c.SentAt,
+		SentTimes:   c.SentTimes,
+		FailedTimes: c.FailedTimes,
+	}
+}
+
+func toMongoMailConfig(c model.MailConfig) mailConfig {
+	res := mailConfig{
+		EMailOTPEnabled:  c.EMailOTPEnabled,
+		MagicLinkEnabled: c.MagicLinkEnabled,
+		CodeLifeSpan:     c.CodeLifeSpan,
+		Templates:        []mailTemplate{},
+	}
+	for _, t := range c.Templates {
+		res.Templates = append(res.Templates, mailTemplate{
+			Type:    t.Type,
+			Locale:  t.Locale,
+			Subject: t.Subject,
+			Body:    t.Body,
+		})
+	}
+	return res
+}
+
+func toModelMailConfig(c mailConfig) model.MailConfig {
+	res := model.MailConfig{
+		EMailOTPEnabled:  c.EMailOTPEnabled,
+		MagicLinkEnabled: c.MagicLinkEnabled,
+		CodeLifeSpan:     c.CodeLifeSpan,
+	}
+	for _, t := range c.Templates {
+		res.Templates = append(res.Templates, model.MailTemplate{
+			Type:    t.Type,
+			Locale:  t.Locale,
+			Subject: t.Subject,
+			Body:    t.Body,
+		})
+	}
+	return res
+}
+
+func toMongoEMailOTPInfo(info model.EMailOTPInfo) emailOTPInfo {
+	return emailOTPInfo{
+		Enabled:   info.Enabled,
+		SetupCode: toMongoOneTimeCode(info.SetupCode),
+	}
+}
+
+func toModelEMailOTPInfo(info emailOTPInfo) model.EMailOTPInfo {
+	return model.EMailOTPInfo{
+		Enabled:   info.Enabled,
+		SetupCode: toModelOneTimeCode(info.SetupCode),
+	}
+}
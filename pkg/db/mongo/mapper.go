@@ -0,0 +1,43 @@
+package mongo
+
+import (
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+)
+
+func toMongoMappers(mappers []model.ProtocolMapper) []protocolMapper {
+	res := []protocolMapper{}
+	for _, m := range mappers {
+		res = append(res, protocolMapper{
+			ID:            m.ID,
+			Name:          m.Name,
+			Type:          m.Type,
+			ClaimName:     m.ClaimName,
+			UserAttribute: m.UserAttribute,
+			Value:         m.Value,
+			Multivalued:   m.Multivalued,
+			IDToken:       m.IDToken,
+			AccessToken:   m.AccessToken,
+			UserInfo:      m.UserInfo,
+		})
+	}
+	return res
+}
+
+func toModelMappers(mappers []protocolMapper) []model.ProtocolMapper {
+	res := []model.ProtocolMapper{}
+	for _, m := range mappers {
+		res = append(res, model.ProtocolMapper{
+			ID:            m.ID,
+			Name:          m.Name,
+			Type:          m.Type,
+			ClaimName:     m.ClaimName,
+			UserAttribute: m.UserAttribute,
+			Value:         m.Value,
+			Multivalued:   m.Multivalued,
+			IDToken:       m.IDToken,
+			AccessToken:   m.AccessToken,
+			UserInfo:      m.UserInfo,
+		})
+	}
+	return res
+}
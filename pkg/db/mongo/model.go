@@ -5,11 +5,14 @@ import (
 )
 
 type tokenConfig struct {
-	AccessTokenLifeSpan  uint   `bson:"access_token_life_span"`
-	RefreshTokenLifeSpan uint   `bson:"refresh_token_life_span"`
-	SigningAlgorithm     string `bson:"signing_algorithm"`
-	SignPublicKey        []byte `bson:"sign_public_key"`
-	SignSecretKey        []byte `bson:"sign_secret_key"`
+	AccessTokenLifeSpan       uint   `bson:"access_token_life_span"`
+	RefreshTokenLifeSpan      uint   `bson:"refresh_token_life_span"`
+	RefreshTokenGracePeriod   uint   `bson:"refresh_token_grace_period"`
+	OfflineSessionLifeSpan    uint   `bson:"offline_session_life_span"`
+	OfflineSessionIdleTimeout uint   `bson:"offline_session_idle_timeout"`
+	SigningAlgorithm          string `bson:"signing_algorithm"`
+	SignPublicKey             []byte `bson:"sign_public_key"`
+	SignSecretKey             []byte `bson:"sign_secret_key"`
 }
 
 type passwordPolicy struct {
@@ -19,6 +22,9 @@ type passwordPolicy struct {
 	UseCharacter        string   `bson:"use_character"`
 	UseDigit            bool     `bson:"use_digit"`
 	UseSpecialCharacter bool     `bson:"use_special_character"`
+	MaxAge              uint     `bson:"max_age"`
+	MinAge              uint     `bson:"min_age"`
+	HistoryCount        uint     `bson:"history_count"`
 }
 
 type userLock struct {
@@ -28,6 +34,55 @@ type userLock struct {
 	FailureResetTime uint `bson:"failure_reset_time"`
 }
 
+type webAuthnConfig struct {
+	AttestationConveyance string `bson:"attestation_conveyance"`
+}
+
+type mailTemplate struct {
+	Type    string `bson:"type"`
+	Locale  string `bson:"locale"`
+	Subject string `bson:"subject"`
+	Body    string `bson:"body"`
+}
+
+type mailConfig struct {
+	EMailOTPEnabled  bool           `bson:"email_otp_enabled"`
+	MagicLinkEnabled bool           `bson:"magic_link_enabled"`
+	CodeLifeSpan     uint           `bson:"code_life_span"`
+	Templates        []mailTemplate `bson:"templates"`
+}
+
+type smsConfig struct {
+	OTPEnabled   bool `bson:"otp_enabled"`
+	CodeLifeSpan uint `bson:"code_life_span"`
+}
+
+type mfaPolicy struct {
+	Mode                  string   `bson:"mode"`
+	SystemRoles           []string `bson:"system_roles"`
+	CustomRoles           []string `bson:"custom_roles"`
+	TrustedDeviceLifeSpan uint     `bson:"trusted_device_life_span"`
+}
+
+type riskRule struct {
+	Type      string `bson:"type"`
+	Action    string `bson:"action"`
+	Threshold uint   `bson:"threshold"`
+	Window    uint   `bson:"window"`
+}
+
+type riskPolicy struct {
+	Rules []riskRule `bson:"rules"`
+}
+
+type totpConfig struct {
+	Algorithm string `bson:"algorithm"`
+	Digits    uint   `bson:"digits"`
+	Period    uint   `bson:"period"`
+	SkewSteps uint   `bson:"skew_steps"`
+	Issuer    string `bson:"issuer"`
+}
+
 type projectInfo struct {
 	Name            string         `bson:"name"`
 	CreatedAt       time.Time      `bson:"create_at"`
@@ -36,34 +91,69 @@ type projectInfo struct {
 	AllowGrantTypes []string       `bson:"allow_grant_types"`
 	PasswordPolicy  passwordPolicy `bson:"password_policy"`
 	UserLock        userLock       `bson:"user_lock"`
+	DefaultLocale   string         `bson:"default_locale"`
+	WebAuthnConfig  webAuthnConfig `bson:"webauthn_config"`
+	MailConfig      mailConfig     `bson:"mail_config"`
+	SMSConfig       smsConfig      `bson:"sms_config"`
+	TOTPConfig      totpConfig     `bson:"totp_config"`
+	MFAPolicy       mfaPolicy      `bson:"mfa_policy"`
+	RiskPolicy      riskPolicy     `bson:"risk_policy"`
 }
 
 type session struct {
-	UserID       string    `bson:"user_id"`
-	ProjectName  string    `bson:"project_name"`
-	SessionID    string    `bson:"session_id"`
-	CreatedAt    time.Time `bson:"created_at"`
-	ExpiresIn    int64     `bson:"expires_in"`
-	FromIP       string    `bson:"from_ip"`
-	LastAuthTime time.Time `bson:"last_auth_time"`
+	UserID            string    `bson:"user_id"`
+	ProjectName       string    `bson:"project_name"`
+	SessionID         string    `bson:"session_id"`
+	CreatedAt         time.Time `bson:"created_at"`
+	ExpiresIn         int64     `bson:"expires_in"`
+	FromIP            string    `bson:"from_ip"`
+	LastAuthTime      time.Time `bson:"last_auth_time"`
+	AuthMethods       []string  `bson:"auth_methods"`
+	FamilyID          string    `bson:"family_id"`
+	PreviousSessionID string    `bson:"previous_session_id"`
+	LoginSessionID    string    `bson:"login_session_id"`
+	Offline           bool      `bson:"offline"`
+	OfflineExpiresAt  time.Time `bson:"offline_expires_at"`
+}
+
+type oneTimeCode struct {
+	Purpose     string    `bson:"purpose"`
+	UserID      string    `bson:"user_id"`
+	CodeHash    string    `bson:"code_hash"`
+	ExpiresAt   time.Time `bson:"expires_at"`
+	SentAt      time.Time `bson:"sent_at"`
+	SentTimes   uint      `bson:"sent_times"`
+	FailedTimes uint      `bson:"failed_times"`
 }
 
 type loginSession struct {
-	SessionID           string    `bson:"session_id"`
-	Code                string    `bson:"code"`
-	ExpiresDate         time.Time `bson:"expires_in"`
-	Scopes              []string  `bson:"scopes"`
-	ResponseType        []string  `bson:"response_type"`
-	ClientID            string    `bson:"client_id"`
-	RedirectURI         string    `bson:"redirect_uri"`
-	Nonce               string    `bson:"nonce"`
-	ProjectName         string    `bson:"project_name"`
-	ResponseMode        string    `bson:"response_mode"`
-	Prompt              []string  `bson:"prompt"`
-	UserID              string    `bson:"user_id"`
-	LoginDate           time.Time `bson:"login_date"`
-	CodeChallenge       string    `bson:"code_challenge"`
-	CodeChallengeMethod string    `bson:"code_challenge_method"`
+	SessionID              string      `bson:"session_id"`
+	Code                   string      `bson:"code"`
+	ExpiresDate            time.Time   `bson:"expires_in"`
+	Scopes                 []string    `bson:"scopes"`
+	ResponseType           []string    `bson:"response_type"`
+	ClientID               string      `bson:"client_id"`
+	RedirectURI            string      `bson:"redirect_uri"`
+	Nonce                  string      `bson:"nonce"`
+	ProjectName            string      `bson:"project_name"`
+	ResponseMode           string      `bson:"response_mode"`
+	Prompt                 []string    `bson:"prompt"`
+	UserID                 string      `bson:"user_id"`
+	LoginDate              time.Time   `bson:"login_date"`
+	CodeChallenge          string      `bson:"code_challenge"`
+	CodeChallengeMethod    string      `bson:"code_challenge_method"`
+	Claims                 string      `bson:"claims"`
+	AuthMethods            []string    `bson:"auth_methods"`
+	ACRValues              []string    `bson:"acr_values"`
+	UILocales              []string    `bson:"ui_locales"`
+	Resources              []string    `bson:"resources"`
+	WebAuthnChallenge      string      `bson:"webauthn_challenge"`
+	MailCode               oneTimeCode `bson:"mail_code"`
+	SMSCode                oneTimeCode `bson:"sms_code"`
+	Consented              bool        `bson:"consented"`
+	CodeRedeemed           bool        `bson:"code_redeemed"`
+	RiskMFARequired        bool        `bson:"risk_mfa_required"`
+	PasswordChangeRequired bool        `bson:"password_change_required"`
 }
 
 type lockState struct {
@@ -72,31 +162,146 @@ type lockState struct {
 }
 
 type otpInfo struct {
-	ID         string `bson:"id"`
-	PrivateKey string `bson:"private_key"`
-	Enabled    bool   `bson:"enabled"`
+	ID            string   `bson:"id"`
+	PrivateKey    string   `bson:"private_key"`
+	Enabled       bool     `bson:"enabled"`
+	Algorithm     string   `bson:"algorithm"`
+	Digits        uint     `bson:"digits"`
+	Period        uint     `bson:"period"`
+	LastUsedStep  int64    `bson:"last_used_step"`
+	RecoveryCodes []string `bson:"recovery_codes"`
+}
+
+type webAuthnCredential struct {
+	ID                string    `bson:"id"`
+	Name              string    `bson:"name"`
+	PublicKey         []byte    `bson:"public_key"`
+	SignCount         uint32    `bson:"sign_count"`
+	Transports        []string  `bson:"transports"`
+	AAGUID            string    `bson:"aaguid"`
+	AttestationFormat string    `bson:"attestation_format"`
+	CreatedAt         time.Time `bson:"created_at"`
+	LastUsedAt        time.Time `bson:"last_used_at"`
+}
+
+type webAuthnInfo struct {
+	Credentials           []webAuthnCredential `bson:"credentials"`
+	RegistrationChallenge string               `bson:"registration_challenge"`
+	RegistrationExpiresAt time.Time            `bson:"registration_expires_at"`
+}
+
+type trustedDevice struct {
+	ID         string    `bson:"id"`
+	UserAgent  string    `bson:"user_agent"`
+	CreatedAt  time.Time `bson:"created_at"`
+	ExpiresAt  time.Time `bson:"expires_at"`
+	LastUsedAt time.Time `bson:"last_used_at"`
+}
+
+type loginRecord struct {
+	IP        string    `bson:"ip"`
+	UserAgent string    `bson:"user_agent"`
+	Country   string    `bson:"country"`
+	Time      time.Time `bson:"time"`
+}
+
+type emailOTPInfo struct {
+	Enabled   bool        `bson:"enabled"`
+	SetupCode oneTimeCode `bson:"setup_code"`
+}
+
+type smsOTPInfo struct {
+	Enabled   bool        `bson:"enabled"`
+	SetupCode oneTimeCode `bson:"setup_code"`
+}
+
+type address struct {
+	Formatted     string `bson:"formatted"`
+	StreetAddress string `bson:"street_address"`
+	Locality      string `bson:"locality"`
+	Region        string `bson:"region"`
+	PostalCode    string `bson:"postal_code"`
+	Country       string `bson:"country"`
 }
 
 type userInfo struct {
-	ID           string    `bson:"id"`
-	ProjectName  string    `bson:"project_name"`
-	Name         string    `bson:"name"`
-	EMail        string    `bson:"email"`
-	CreatedAt    time.Time `bson:"created_at"`
-	PasswordHash string    `bson:"password_hash"`
-	SystemRoles  []string  `bson:"system_roles"`
-	CustomRoles  []string  `bson:"custom_roles"`
-	LockState    lockState `bson:"lock_state"`
-	OTPInfo      otpInfo   `bson:"otp_info"`
+	ID                     string              `bson:"id"`
+	ProjectName            string              `bson:"project_name"`
+	Name                   string              `bson:"name"`
+	EMail                  string              `bson:"email"`
+	CreatedAt              time.Time           `bson:"created_at"`
+	PasswordHash           string              `bson:"password_hash"`
+	PasswordUpdatedAt      time.Time           `bson:"password_updated_at"`
+	PasswordHistory        []string            `bson:"password_history"`
+	PasswordChangeRequired bool                `bson:"password_change_required"`
+	SystemRoles            []string            `bson:"system_roles"`
+	CustomRoles            []string            `bson:"custom_roles"`
+	LockState              lockState           `bson:"lock_state"`
+	OTPInfo                otpInfo             `bson:"otp_info"`
+	WebAuthnInfo           webAuthnInfo        `bson:"webauthn_info"`
+	EMailOTPInfo           emailOTPInfo        `bson:"email_otp_info"`
+	SMSOTPInfo             smsOTPInfo          `bson:"sms_otp_info"`
+	TrustedDevices         []trustedDevice     `bson:"trusted_devices"`
+	LoginHistory           []loginRecord       `bson:"login_history"`
+	GivenName              string              `bson:"given_name"`
+	FamilyName             string              `bson:"family_name"`
+	Locale                 string              `bson:"locale"`
+	Zoneinfo               string              `bson:"zoneinfo"`
+	PhoneNumber            string              `bson:"phone_number"`
+	PhoneNumberVerified    bool                `bson:"phone_number_verified"`
+	Address                address             `bson:"address"`
+	EMailVerified          bool                `bson:"email_verified"`
+	UpdatedAt              time.Time           `bson:"updated_at"`
+	Attributes             map[string][]string `bson:"attributes"`
+}
+
+type protocolMapper struct {
+	ID            string `bson:"id"`
+	Name          string `bson:"name"`
+	Type          string `bson:"type"`
+	ClaimName     string `bson:"claim_name"`
+	UserAttribute string `bson:"user_attribute"`
+	Value         string `bson:"value"`
+	Multivalued   bool   `bson:"multivalued"`
+	IDToken       bool   `bson:"id_token"`
+	AccessToken   bool   `bson:"access_token"`
+	UserInfo      bool   `bson:"userinfo"`
 }
 
 type clientInfo struct {
-	ID                  string    `bson:"id"`
-	ProjectName         string    `bson:"project_name"`
-	Secret              string    `bson:"secret"`
-	AccessType          string    `bson:"access_type"`
-	CreatedAt           time.Time `bson:"created_at"`
-	AllowedCallbackURLs []string  `bson:"allowed_callback_urls"`
+	ID                                    string           `bson:"id"`
+	ProjectName                           string           `bson:"project_name"`
+	Secret                                string           `bson:"secret"`
+	AccessType                            string           `bson:"access_type"`
+	CreatedAt                             time.Time        `bson:"created_at"`
+	AllowedCallbackURLs                   []string         `bson:"allowed_callback_urls"`
+	ProtocolMappers                       []protocolMapper `bson:"protocol_mappers"`
+	DefaultScopes                         []string         `bson:"default_scopes"`
+	OptionalScopes                        []string         `bson:"optional_scopes"`
+	AccessTokenLifeSpan                   uint             `bson:"access_token_life_span"`
+	RefreshTokenLifeSpan                  uint             `bson:"refresh_token_life_span"`
+	IDTokenLifeSpan                       uint             `bson:"id_token_life_span"`
+	AllowGrantTypes                       []string         `bson:"allow_grant_types"`
+	AllowResponseTypes                    []string         `bson:"allow_response_types"`
+	RequirePKCE                           bool             `bson:"require_pkce"`
+	JWKS                                  string           `bson:"jwks"`
+	IDTokenEncryptedResponseAlg           string           `bson:"id_token_encrypted_response_alg"`
+	IDTokenEncryptedResponseEnc           string           `bson:"id_token_encrypted_response_enc"`
+	UserinfoSignedResponseAlg             string           `bson:"userinfo_signed_response_alg"`
+	UserinfoEncryptedResponseAlg          string           `bson:"userinfo_encrypted_response_alg"`
+	UserinfoEncryptedResponseEnc          string           `bson:"userinfo_encrypted_response_enc"`
+	BackchannelTokenDeliveryMode          string           `bson:"backchannel_token_delivery_mode"`
+	BackchannelClientNotificationEndpoint string           `bson:"backchannel_client_notification_endpoint"`
+}
+
+type clientScope struct {
+	Name            string           `bson:"name"`
+	ProjectName     string           `bson:"project_name"`
+	CreatedAt       time.Time        `bson:"created_at"`
+	Description     string           `bson:"description"`
+	Claims          []string         `bson:"claims"`
+	ProtocolMappers []protocolMapper `bson:"protocol_mappers"`
+	RequiredRoles   []string         `bson:"required_roles"`
 }
 
 type customRole struct {
@@ -106,6 +311,17 @@ type customRole struct {
 	ProjectName string    `bson:"project_name"`
 }
 
+type apiResource struct {
+	ID                  string              `bson:"id"`
+	ProjectName         string              `bson:"project_name"`
+	CreatedAt           time.Time           `bson:"created_at"`
+	Identifier          string              `bson:"identifier"`
+	Name                string              `bson:"name"`
+	Scopes              []string            `bson:"scopes"`
+	RequiredRoles       map[string][]string `bson:"required_roles"`
+	AccessTokenLifeSpan uint                `bson:"access_token_life_span"`
+}
+
 type customRoleInUser struct {
 	ProjectName  string `bson:"project_name"`
 	UserID       string `bson:"user_id"`
@@ -117,6 +333,28 @@ type device struct {
 	UserCode       string    `bson:"user_code"`
 	ProjectName    string    `bson:"project_name"`
 	ExpiresIn      int64     `bson:"expires_in"`
+	ExpiresAt      time.Time `bson:"expires_at"`
 	CreatedAt      time.Time `bson:"created_at"`
 	LoginSessionID string    `bson:"login_session_id"`
+	ClientID       string    `bson:"client_id"`
+	Interval       int64     `bson:"interval"`
+	LastPolledAt   time.Time `bson:"last_polled_at"`
+}
+
+type backchannelAuth struct {
+	AuthReqID               string    `bson:"auth_req_id"`
+	ProjectName             string    `bson:"project_name"`
+	ClientID                string    `bson:"client_id"`
+	UserID                  string    `bson:"user_id"`
+	Scopes                  []string  `bson:"scopes"`
+	BindingMessage          string    `bson:"binding_message"`
+	ExpiresIn               int64     `bson:"expires_in"`
+	ExpiresAt               time.Time `bson:"expires_at"`
+	CreatedAt               time.Time `bson:"created_at"`
+	Status                  string    `bson:"status"`
+	Interval                int64     `bson:"interval"`
+	LastPolledAt            time.Time `bson:"last_polled_at"`
+	ClientNotificationToken string    `bson:"client_notification_token"`
+	AuthDate                time.Time `bson:"auth_date"`
+	AuthMethods             []string  `bson:"auth_methods"`
 }
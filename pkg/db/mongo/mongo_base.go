@@ -18,6 +18,9 @@ const (
 	authcodeSessionCollectionName = "authcodesession"
 	roleInUserCollectionName      = "customroleinuser"
 	deviceCollectionName          = "device"
+	clientScopeCollectionName     = "clientscope"
+	backchannelAuthCollectionName = "backchannelauth"
+	apiResourceCollectionName     = "apiresource"
 
 	timeoutSecond = 5
 )
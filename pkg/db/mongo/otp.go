@@ -0,0 +1,51 @@
+package mongo
+
+import (
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+)
+
+func toMongoOTPInfo(info model.OTPInfo) otpInfo {
+	return otpInfo{
+		ID:            info.ID,
+		PrivateKey:    info.PrivateKey,
+		Enabled:       info.Enabled,
+		Algorithm:     info.Algorithm,
+		Digits:        info.Digits,
+		Period:        info.Period,
+		LastUsedStep:  info.LastUsedStep,
+		RecoveryCodes: info.RecoveryCodes,
+	}
+}
+
+func toModelOTPInfo(info otpInfo) model.OTPInfo {
+	return model.OTPInfo{
+		ID:            info.ID,
+		PrivateKey:    info.PrivateKey,
+		Enabled:       info.Enabled,
+		Algorithm:     info.Algorithm,
+		Digits:        info.Digits,
+		Period:        info.Period,
+		LastUsedStep:  info.LastUsedStep,
+		RecoveryCodes: info.RecoveryCodes,
+	}
+}
+
+func toMongoTOTPConfig(c model.TOTPConfig) totpConfig {
+	return totpConfig{
+		Algorithm: c.Algorithm,
+		Digits:    c.Digits,
+		Period:    c.Period,
+		SkewSteps: c.SkewSteps,
+		Issuer:    c.Issuer,
+	}
+}
+
+func toModelTOTPConfig(c totpConfig) model.TOTPConfig {
+	return model.TOTPConfig{
+		Algorithm: c.Algorithm,
+		Digits:    c.Digits,
+		Period:    c.Period,
+		SkewSteps: c.SkewSteps,
+		Issuer:    c.Issuer,
+	}
+}
@@ -62,11 +62,14 @@ func (h *ProjectInfoHandler) Add(ent *model.ProjectInfo) *errors.Error {
 		CreatedAt:    ent.CreatedAt,
 		PermitDelete: ent.PermitDelete,
 		TokenConfig: &tokenConfig{
-			AccessTokenLifeSpan:  ent.TokenConfig.AccessTokenLifeSpan,
-			RefreshTokenLifeSpan: ent.TokenConfig.RefreshTokenLifeSpan,
-			SigningAlgorithm:     ent.TokenConfig.SigningAlgorithm,
-			SignPublicKey:        ent.TokenConfig.SignPublicKey,
-			SignSecretKey:        ent.TokenConfig.SignSecretKey,
+			AccessTokenLifeSpan:       ent.TokenConfig.AccessTokenLifeSpan,
+			RefreshTokenLifeSpan:      ent.TokenConfig.RefreshTokenLifeSpan,
+			RefreshTokenGracePeriod:   ent.TokenConfig.RefreshTokenGracePeriod,
+			OfflineSessionLifeSpan:    ent.TokenConfig.OfflineSessionLifeSpan,
+			OfflineSessionIdleTimeout: ent.TokenConfig.OfflineSessionIdleTimeout,
+			SigningAlgorithm:          ent.TokenConfig.SigningAlgorithm,
+			SignPublicKey:             ent.TokenConfig.SignPublicKey,
+			SignSecretKey:             ent.TokenConfig.SignSecretKey,
 		},
 		PasswordPolicy: passwordPolicy{
 			MinimumLength:       ent.PasswordPolicy.MinimumLength,
@@ -75,6 +78,9 @@ func (h *ProjectInfoHandler) Add(ent *model.ProjectInfo) *errors.Error {
 			UseCharacter:        string(ent.PasswordPolicy.UseCharacter),
 			UseDigit:            ent.PasswordPolicy.UseDigit,
 			UseSpecialCharacter: ent.PasswordPolicy.UseSpecialCharacter,
+			MaxAge:              ent.PasswordPolicy.MaxAge,
+			MinAge:              ent.PasswordPolicy.MinAge,
+			HistoryCount:        ent.PasswordPolicy.HistoryCount,
 		},
 		UserLock: userLock{
 			Enabled:          ent.UserLock.Enabled,
@@ -82,6 +88,23 @@ func (h *ProjectInfoHandler) Add(ent *model.ProjectInfo) *errors.Error {
 			LockDuration:     ent.UserLock.LockDuration,
 			FailureResetTime: ent.UserLock.FailureResetTime,
 		},
+		DefaultLocale: ent.DefaultLocale,
+		WebAuthnConfig: webAuthnConfig{
+			AttestationConveyance: ent.WebAuthnConfig.AttestationConveyance,
+		},
+		MailConfig: toMongoMailConfig(ent.MailConfig),
+		SMSConfig: smsConfig{
+			OTPEnabled:   ent.SMSConfig.OTPEnabled,
+			CodeLifeSpan: ent.SMSConfig.CodeLifeSpan,
+		},
+		TOTPConfig: toMongoTOTPConfig(ent.TOTPConfig),
+		MFAPolicy: mfaPolicy{
+			Mode:                  ent.MFAPolicy.Mode,
+			SystemRoles:           ent.MFAPolicy.SystemRoles,
+			CustomRoles:           ent.MFAPolicy.CustomRoles,
+			TrustedDeviceLifeSpan: ent.MFAPolicy.TrustedDeviceLifeSpan,
+		},
+		RiskPolicy: toMongoRiskPolicy(ent.RiskPolicy),
 	}
 	for _, t := range ent.AllowGrantTypes {
 		v.AllowGrantTypes = append(v.AllowGrantTypes, string(t))
@@ -147,11 +170,14 @@ func (h *ProjectInfoHandler) GetList(filter *model.ProjectFilter) ([]*model.Proj
 			CreatedAt:    prj.CreatedAt,
 			PermitDelete: prj.PermitDelete,
 			TokenConfig: &model.TokenConfig{
-				AccessTokenLifeSpan:  prj.TokenConfig.AccessTokenLifeSpan,
-				RefreshTokenLifeSpan: prj.TokenConfig.RefreshTokenLifeSpan,
-				SigningAlgorithm:     prj.TokenConfig.SigningAlgorithm,
-				SignPublicKey:        prj.TokenConfig.SignPublicKey,
-				SignSecretKey:        prj.TokenConfig.SignSecretKey,
+				AccessTokenLifeSpan:       prj.TokenConfig.AccessTokenLifeSpan,
+				RefreshTokenLifeSpan:      prj.TokenConfig.RefreshTokenLifeSpan,
+				RefreshTokenGracePeriod:   prj.TokenConfig.RefreshTokenGracePeriod,
+				OfflineSessionLifeSpan:    prj.TokenConfig.OfflineSessionLifeSpan,
+				OfflineSessionIdleTimeout: prj.TokenConfig.OfflineSessionIdleTimeout,
+				SigningAlgorithm:          prj.TokenConfig.SigningAlgorithm,
+				SignPublicKey:             prj.TokenConfig.SignPublicKey,
+				SignSecretKey:             prj.TokenConfig.SignSecretKey,
 			},
 			PasswordPolicy: model.PasswordPolicy{
 				MinimumLength:       prj.PasswordPolicy.MinimumLength,
@@ -160,6 +186,9 @@ func (h *ProjectInfoHandler) GetList(filter *model.ProjectFilter) ([]*model.Proj
 				UseCharacter:        model.CharacterType(prj.PasswordPolicy.UseCharacter),
 				UseDigit:            prj.PasswordPolicy.UseDigit,
 				UseSpecialCharacter: prj.PasswordPolicy.UseSpecialCharacter,
+				MaxAge:              prj.PasswordPolicy.MaxAge,
+				MinAge:              prj.PasswordPolicy.MinAge,
+				HistoryCount:        prj.PasswordPolicy.HistoryCount,
 			},
 			UserLock: model.UserLock{
 				Enabled:          prj.UserLock.Enabled,
@@ -167,6 +196,23 @@ func (h *ProjectInfoHandler) GetList(filter *model.ProjectFilter) ([]*model.Proj
 				LockDuration:     prj.UserLock.LockDuration,
 				FailureResetTime: prj.UserLock.FailureResetTime,
 			},
+			DefaultLocale: prj.DefaultLocale,
+			WebAuthnConfig: model.WebAuthnConfig{
+				AttestationConveyance: prj.WebAuthnConfig.AttestationConveyance,
+			},
+			MailConfig: toModelMailConfig(prj.MailConfig),
+			SMSConfig: model.SMSConfig{
+				OTPEnabled:   prj.SMSConfig.OTPEnabled,
+				CodeLifeSpan: prj.SMSConfig.CodeLifeSpan,
+			},
+			TOTPConfig: toModelTOTPConfig(prj.TOTPConfig),
+			MFAPolicy: model.MFAPolicy{
+				Mode:                  prj.MFAPolicy.Mode,
+				SystemRoles:           prj.MFAPolicy.SystemRoles,
+				CustomRoles:           prj.MFAPolicy.CustomRoles,
+				TrustedDeviceLifeSpan: prj.MFAPolicy.TrustedDeviceLifeSpan,
+			},
+			RiskPolicy: toModelRiskPolicy(prj.RiskPolicy),
 		}
 		for _, t := range prj.AllowGrantTypes {
 			info.AllowGrantTypes = append(info.AllowGrantTypes, model.GrantType(t))
@@ -189,11 +235,14 @@ func (h *ProjectInfoHandler) Update(ent *model.ProjectInfo) *errors.Error {
 		CreatedAt:    ent.CreatedAt,
 		PermitDelete: ent.PermitDelete,
 		TokenConfig: &tokenConfig{
-			AccessTokenLifeSpan:  ent.TokenConfig.AccessTokenLifeSpan,
-			RefreshTokenLifeSpan: ent.TokenConfig.RefreshTokenLifeSpan,
-			SigningAlgorithm:     ent.TokenConfig.SigningAlgorithm,
-			SignPublicKey:        ent.TokenConfig.SignPublicKey,
-			SignSecretKey:        ent.TokenConfig.SignSecretKey,
+			AccessTokenLifeSpan:       ent.TokenConfig.AccessTokenLifeSpan,
+			RefreshTokenLifeSpan:      ent.TokenConfig.RefreshTokenLifeSpan,
+			RefreshTokenGracePeriod:   ent.TokenConfig.RefreshTokenGracePeriod,
+			OfflineSessionLifeSpan:    ent.TokenConfig.OfflineSessionLifeSpan,
+			OfflineSessionIdleTimeout: ent.TokenConfig.OfflineSessionIdleTimeout,
+			SigningAlgorithm:          ent.TokenConfig.SigningAlgorithm,
+			SignPublicKey:             ent.TokenConfig.SignPublicKey,
+			SignSecretKey:             ent.TokenConfig.SignSecretKey,
 		},
 		PasswordPolicy: passwordPolicy{
 			MinimumLength:       ent.PasswordPolicy.MinimumLength,
@@ -202,6 +251,9 @@ func (h *ProjectInfoHandler) Update(ent *model.ProjectInfo) *errors.Error {
 			UseCharacter:        string(ent.PasswordPolicy.UseCharacter),
 			UseDigit:            ent.PasswordPolicy.UseDigit,
 			UseSpecialCharacter: ent.PasswordPolicy.UseSpecialCharacter,
+			MaxAge:              ent.PasswordPolicy.MaxAge,
+			MinAge:              ent.PasswordPolicy.MinAge,
+			HistoryCount:        ent.PasswordPolicy.HistoryCount,
 		},
 		UserLock: userLock{
 			Enabled:          ent.UserLock.Enabled,
@@ -209,6 +261,23 @@ func (h *ProjectInfoHandler) Update(ent *model.ProjectInfo) *errors.Error {
 			LockDuration:     ent.UserLock.LockDuration,
 			FailureResetTime: ent.UserLock.FailureResetTime,
 		},
+		DefaultLocale: ent.DefaultLocale,
+		WebAuthnConfig: webAuthnConfig{
+			AttestationConveyance: ent.WebAuthnConfig.AttestationConveyance,
+		},
+		MailConfig: toMongoMailConfig(ent.MailConfig),
+		SMSConfig: smsConfig{
+			OTPEnabled:   ent.SMSConfig.OTPEnabled,
+			CodeLifeSpan: ent.SMSConfig.CodeLifeSpan,
+		},
+		TOTPConfig: toMongoTOTPConfig(ent.TOTPConfig),
+		MFAPolicy: mfaPolicy{
+			Mode:                  ent.MFAPolicy.Mode,
+			SystemRoles:           ent.MFAPolicy.SystemRoles,
+			CustomRoles:           ent.MFAPolicy.CustomRoles,
+			TrustedDeviceLifeSpan: ent.MFAPolicy.TrustedDeviceLifeSpan,
+		},
+		RiskPolicy: toMongoRiskPolicy(ent.RiskPolicy),
 	}
 	for _, t := range ent.AllowGrantTypes {
 		v.AllowGrantTypes = append(v.AllowGrantTypes, string(t))
@@ -0,0 +1,59 @@
+package mongo
+
+import (
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+)
+
+func toMongoLoginHistory(history []model.LoginRecord) []loginRecord {
+	res := []loginRecord{}
+	for _, h := range history {
+		res = append(res, loginRecord{
+			IP:        h.IP,
+			UserAgent: h.UserAgent,
+			Country:   h.Country,
+			Time:      h.Time,
+		})
+	}
+	return res
+}
+
+func toModelLoginHistory(history []loginRecord) []model.LoginRecord {
+	res := []model.LoginRecord{}
+	for _, h := range history {
+		res = append(res, model.LoginRecord{
+			IP:        h.IP,
+			UserAgent: h.UserAgent,
+			Country:   h.Country,
+			Time:      h.Time,
+		})
+	}
+	return res
+}
+
+func toMongoRiskPolicy(p model.RiskPolicy) riskPolicy {
+	res := riskPolicy{
+		Rules: []riskRule{},
+	}
+	for _, r := range p.Rules {
+		res.Rules = append(res.Rules, riskRule{
+			Type:      r.Type,
+			Action:    r.Action,
+			Threshold: r.Threshold,
+			Window:    r.Window,
+		})
+	}
+	return res
+}
+
+func toModelRiskPolicy(p riskPolicy) model.RiskPolicy {
+	res := model.RiskPolicy{}
+	for _, r := range p.Rules {
+		res.Rules = append(res.Rules, model.RiskRule{
+			Type:      r.Type,
+			Action:    r.Action,
+			Threshold: r.Threshold,
+			Window:    r.Window,
+		})
+	}
+	return res
+}
@@ -57,13 +57,19 @@ func NewSessionHandler(dbClient *mongo.Client) (*SessionHandler, *errors.Error)
 // Add ...
 func (h *SessionHandler) Add(projectName string, s *model.Session) *errors.Error {
 	v := &session{
-		UserID:       s.UserID,
-		ProjectName:  s.ProjectName,
-		SessionID:    s.SessionID,
-		CreatedAt:    s.CreatedAt,
-		ExpiresIn:    s.ExpiresIn,
-		FromIP:       s.FromIP,
-		LastAuthTime: s.LastAuthTime,
+		UserID:            s.UserID,
+		ProjectName:       s.ProjectName,
+		SessionID:         s.SessionID,
+		CreatedAt:         s.CreatedAt,
+		ExpiresIn:         s.ExpiresIn,
+		FromIP:            s.FromIP,
+		LastAuthTime:      s.LastAuthTime,
+		AuthMethods:       s.AuthMethods,
+		FamilyID:          s.FamilyID,
+		PreviousSessionID: s.PreviousSessionID,
+		LoginSessionID:    s.LoginSessionID,
+		Offline:           s.Offline,
+		OfflineExpiresAt:  s.OfflineExpiresAt,
 	}
 
 	col := h.dbClient.Database(databaseName).Collection(sessionCollectionName)
@@ -96,6 +102,12 @@ func (h *SessionHandler) Delete(projectName string, filter *model.SessionFilter)
 		if filter.UserID != "" {
 			f = append(f, bson.E{Key: "user_id", Value: filter.UserID})
 		}
+		if filter.FamilyID != "" {
+			f = append(f, bson.E{Key: "family_id", Value: filter.FamilyID})
+		}
+		if filter.LoginSessionID != "" {
+			f = append(f, bson.E{Key: "login_session_id", Value: filter.LoginSessionID})
+		}
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
@@ -139,6 +151,12 @@ func (h *SessionHandler) GetList(projectName string, filter *model.SessionFilter
 		if filter.UserID != "" {
 			f = append(f, bson.E{Key: "user_id", Value: filter.UserID})
 		}
+		if filter.FamilyID != "" {
+			f = append(f, bson.E{Key: "family_id", Value: filter.FamilyID})
+		}
+		if filter.LoginSessionID != "" {
+			f = append(f, bson.E{Key: "login_session_id", Value: filter.LoginSessionID})
+		}
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
@@ -157,13 +175,19 @@ func (h *SessionHandler) GetList(projectName string, filter *model.SessionFilter
 	res := []*model.Session{}
 	for _, s := range sessions {
 		res = append(res, &model.Session{
-			UserID:       s.UserID,
-			ProjectName:  s.ProjectName,
-			SessionID:    s.SessionID,
-			CreatedAt:    s.CreatedAt,
-			ExpiresIn:    s.ExpiresIn,
-			FromIP:       s.FromIP,
-			LastAuthTime: s.LastAuthTime,
+			UserID:            s.UserID,
+			ProjectName:       s.ProjectName,
+			SessionID:         s.SessionID,
+			CreatedAt:         s.CreatedAt,
+			ExpiresIn:         s.ExpiresIn,
+			FromIP:            s.FromIP,
+			LastAuthTime:      s.LastAuthTime,
+			AuthMethods:       s.AuthMethods,
+			FamilyID:          s.FamilyID,
+			PreviousSessionID: s.PreviousSessionID,
+			LoginSessionID:    s.LoginSessionID,
+			Offline:           s.Offline,
+			OfflineExpiresAt:  s.OfflineExpiresAt,
 		})
 	}
 
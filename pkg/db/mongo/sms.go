@@ -0,0 +1,19 @@
+package mongo
+
+import (
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+)
+
+func toMongoSMSOTPInfo(info model.SMSOTPInfo) smsOTPInfo {
+	return smsOTPInfo{
+		Enabled:   info.Enabled,
+		SetupCode: toMongoOneTimeCode(info.SetupCode),
+	}
+}
+
+func toModelSMSOTPInfo(info smsOTPInfo) model.SMSOTPInfo {
+	return model.SMSOTPInfo{
+		Enabled:   info.Enabled,
+		SetupCode: toModelOneTimeCode(info.SetupCode),
+	}
+}
@@ -0,0 +1,33 @@
+package mongo
+
+import (
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+)
+
+func toMongoTrustedDevices(devices []model.TrustedDevice) []trustedDevice {
+	res := []trustedDevice{}
+	for _, d := range devices {
+		res = append(res, trustedDevice{
+			ID:         d.ID,
+			UserAgent:  d.UserAgent,
+			CreatedAt:  d.CreatedAt,
+			ExpiresAt:  d.ExpiresAt,
+			LastUsedAt: d.LastUsedAt,
+		})
+	}
+	return res
+}
+
+func toModelTrustedDevices(devices []trustedDevice) []model.TrustedDevice {
+	res := []model.TrustedDevice{}
+	for _, d := range devices {
+		res = append(res, model.TrustedDevice{
+			ID:         d.ID,
+			UserAgent:  d.UserAgent,
+			CreatedAt:  d.CreatedAt,
+			ExpiresAt:  d.ExpiresAt,
+			LastUsedAt: d.LastUsedAt,
+		})
+	}
+	return res
+}
@@ -57,23 +57,44 @@ func NewUserHandler(dbClient *mongo.Client) (*UserInfoHandler, *errors.Error) {
 // Add ...
 func (h *UserInfoHandler) Add(projectName string, ent *model.UserInfo) *errors.Error {
 	usr := &userInfo{
-		ID:           ent.ID,
-		ProjectName:  ent.ProjectName,
-		Name:         ent.Name,
-		EMail:        ent.EMail,
-		CreatedAt:    ent.CreatedAt,
-		PasswordHash: ent.PasswordHash,
-		SystemRoles:  ent.SystemRoles,
-		CustomRoles:  ent.CustomRoles,
+		ID:                     ent.ID,
+		ProjectName:            ent.ProjectName,
+		Name:                   ent.Name,
+		EMail:                  ent.EMail,
+		CreatedAt:              ent.CreatedAt,
+		PasswordHash:           ent.PasswordHash,
+		PasswordUpdatedAt:      ent.PasswordUpdatedAt,
+		PasswordHistory:        ent.PasswordHistory,
+		PasswordChangeRequired: ent.PasswordChangeRequired,
+		SystemRoles:            ent.SystemRoles,
+		CustomRoles:            ent.CustomRoles,
 		LockState: lockState{
 			Locked:            ent.LockState.Locked,
 			VerifyFailedTimes: ent.LockState.VerifyFailedTimes,
 		},
-		OTPInfo: otpInfo{
-			ID:         ent.OTPInfo.ID,
-			PrivateKey: ent.OTPInfo.PrivateKey,
-			Enabled:    ent.OTPInfo.Enabled,
+		OTPInfo:             toMongoOTPInfo(ent.OTPInfo),
+		WebAuthnInfo:        toMongoWebAuthnInfo(ent.WebAuthnInfo),
+		EMailOTPInfo:        toMongoEMailOTPInfo(ent.EMailOTPInfo),
+		SMSOTPInfo:          toMongoSMSOTPInfo(ent.SMSOTPInfo),
+		TrustedDevices:      toMongoTrustedDevices(ent.TrustedDevices),
+		LoginHistory:        toMongoLoginHistory(ent.LoginHistory),
+		GivenName:           ent.GivenName,
+		FamilyName:          ent.FamilyName,
+		Locale:              ent.Locale,
+		Zoneinfo:            ent.Zoneinfo,
+		PhoneNumber:         ent.PhoneNumber,
+		PhoneNumberVerified: ent.PhoneNumberVerified,
+		Address: address{
+			Formatted:     ent.Address.Formatted,
+			StreetAddress: ent.Address.StreetAddress,
+			Locality:      ent.Address.Locality,
+			Region:        ent.Address.Region,
+			PostalCode:    ent.Address.PostalCode,
+			Country:       ent.Address.Country,
 		},
+		EMailVerified: ent.EMailVerified,
+		UpdatedAt:     ent.UpdatedAt,
+		Attributes:    ent.Attributes,
 	}
 
 	uroles := []interface{}{}
@@ -147,6 +168,9 @@ func (h *UserInfoHandler) GetList(projectName string, filter *model.UserFilter)
 		if filter.ID != "" {
 			f = append(f, bson.E{Key: "id", Value: filter.ID})
 		}
+		if filter.EMail != "" {
+			f = append(f, bson.E{Key: "email", Value: filter.EMail})
+		}
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
@@ -165,23 +189,44 @@ func (h *UserInfoHandler) GetList(projectName string, filter *model.UserFilter)
 	res := []*model.UserInfo{}
 	for _, user := range users {
 		res = append(res, &model.UserInfo{
-			ID:           user.ID,
-			ProjectName:  user.ProjectName,
-			Name:         user.Name,
-			EMail:        user.EMail,
-			CreatedAt:    user.CreatedAt,
-			PasswordHash: user.PasswordHash,
-			SystemRoles:  user.SystemRoles,
-			CustomRoles:  user.CustomRoles,
+			ID:                     user.ID,
+			ProjectName:            user.ProjectName,
+			Name:                   user.Name,
+			EMail:                  user.EMail,
+			CreatedAt:              user.CreatedAt,
+			PasswordHash:           user.PasswordHash,
+			PasswordUpdatedAt:      user.PasswordUpdatedAt,
+			PasswordHistory:        user.PasswordHistory,
+			PasswordChangeRequired: user.PasswordChangeRequired,
+			SystemRoles:            user.SystemRoles,
+			CustomRoles:            user.CustomRoles,
 			LockState: model.LockState{
 				Locked:            user.LockState.Locked,
 				VerifyFailedTimes: user.LockState.VerifyFailedTimes,
 			},
-			OTPInfo: model.OTPInfo{
-				ID:         user.OTPInfo.ID,
-				PrivateKey: user.OTPInfo.PrivateKey,
-				Enabled:    user.OTPInfo.Enabled,
+			OTPInfo:             toModelOTPInfo(user.OTPInfo),
+			WebAuthnInfo:        toModelWebAuthnInfo(user.WebAuthnInfo),
+			EMailOTPInfo:        toModelEMailOTPInfo(user.EMailOTPInfo),
+			SMSOTPInfo:          toModelSMSOTPInfo(user.SMSOTPInfo),
+			TrustedDevices:      toModelTrustedDevices(user.TrustedDevices),
+			LoginHistory:        toModelLoginHistory(user.LoginHistory),
+			GivenName:           user.GivenName,
+			FamilyName:          user.FamilyName,
+			Locale:              user.Locale,
+			Zoneinfo:            user.Zoneinfo,
+			PhoneNumber:         user.PhoneNumber,
+			PhoneNumberVerified: user.PhoneNumberVerified,
+			Address: model.Address{
+				Formatted:     user.Address.Formatted,
+				StreetAddress: user.Address.StreetAddress,
+				Locality:      user.Address.Locality,
+				Region:        user.Address.Region,
+				PostalCode:    user.Address.PostalCode,
+				Country:       user.Address.Country,
 			},
+			EMailVerified: user.EMailVerified,
+			UpdatedAt:     user.UpdatedAt,
+			Attributes:    user.Attributes,
 		})
 	}
 
@@ -197,23 +242,44 @@ func (h *UserInfoHandler) Update(projectName string, ent *model.UserInfo) *error
 	}
 
 	v := &userInfo{
-		ID:           ent.ID,
-		ProjectName:  ent.ProjectName,
-		Name:         ent.Name,
-		EMail:        ent.EMail,
-		CreatedAt:    ent.CreatedAt,
-		PasswordHash: ent.PasswordHash,
-		SystemRoles:  ent.SystemRoles,
-		CustomRoles:  ent.CustomRoles,
+		ID:                     ent.ID,
+		ProjectName:            ent.ProjectName,
+		Name:                   ent.Name,
+		EMail:                  ent.EMail,
+		CreatedAt:              ent.CreatedAt,
+		PasswordHash:           ent.PasswordHash,
+		PasswordUpdatedAt:      ent.PasswordUpdatedAt,
+		PasswordHistory:        ent.PasswordHistory,
+		PasswordChangeRequired: ent.PasswordChangeRequired,
+		SystemRoles:            ent.SystemRoles,
+		CustomRoles:            ent.CustomRoles,
 		LockState: lockState{
 			Locked:            ent.LockState.Locked,
 			VerifyFailedTimes: ent.LockState.VerifyFailedTimes,
 		},
-		OTPInfo: otpInfo{
-			ID:         ent.OTPInfo.ID,
-			PrivateKey: ent.OTPInfo.PrivateKey,
-			Enabled:    ent.OTPInfo.Enabled,
+		OTPInfo:             toMongoOTPInfo(ent.OTPInfo),
+		WebAuthnInfo:        toMongoWebAuthnInfo(ent.WebAuthnInfo),
+		EMailOTPInfo:        toMongoEMailOTPInfo(ent.EMailOTPInfo),
+		SMSOTPInfo:          toMongoSMSOTPInfo(ent.SMSOTPInfo),
+		TrustedDevices:      toMongoTrustedDevices(ent.TrustedDevices),
+		LoginHistory:        toMongoLoginHistory(ent.LoginHistory),
+		GivenName:           ent.GivenName,
+		FamilyName:          ent.FamilyName,
+		Locale:              ent.Locale,
+		Zoneinfo:            ent.Zoneinfo,
+		PhoneNumber:         ent.PhoneNumber,
+		PhoneNumberVerified: ent.PhoneNumberVerified,
+		Address: address{
+			Formatted:     ent.Address.Formatted,
+			StreetAddress: ent.Address.StreetAddress,
+			Locality:      ent.Address.Locality,
+			Region:        ent.Address.Region,
+			PostalCode:    ent.Address.PostalCode,
+			Country:       ent.Address.Country,
 		},
+		EMailVerified: ent.EMailVerified,
+		UpdatedAt:     ent.UpdatedAt,
+		Attributes:    ent.Attributes,
 	}
 
 	updates := bson.D{
@@ -0,0 +1,48 @@
+package mongo
+
+import (
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+)
+
+func toMongoWebAuthnInfo(info model.WebAuthnInfo) webAuthnInfo {
+	res := webAuthnInfo{
+		Credentials:           []webAuthnCredential{},
+		RegistrationChallenge: info.RegistrationChallenge,
+		RegistrationExpiresAt: info.RegistrationExpiresAt,
+	}
+	for _, c := range info.Credentials {
+		res.Credentials = append(res.Credentials, webAuthnCredential{
+			ID:                c.ID,
+			Name:              c.Name,
+			PublicKey:         c.PublicKey,
+			SignCount:         c.SignCount,
+			Transports:        c.Transports,
+			AAGUID:            c.AAGUID,
+			AttestationFormat: c.AttestationFormat,
+			CreatedAt:         c.CreatedAt,
+			LastUsedAt:        c.LastUsedAt,
+		})
+	}
+	return res
+}
+
+func toModelWebAuthnInfo(info webAuthnInfo) model.WebAuthnInfo {
+	res := model.WebAuthnInfo{
+		RegistrationChallenge: info.RegistrationChallenge,
+		RegistrationExpiresAt: info.RegistrationExpiresAt,
+	}
+	for _, c := range info.Credentials {
+		res.Credentials = append(res.Credentials, model.WebAuthnCredential{
+			ID:                c.ID,
+			Name:              c.Name,
+			PublicKey:         c.PublicKey,
+			SignCount:         c.SignCount,
+			Transports:        c.Transports,
+			AAGUID:            c.AAGUID,
+			AttestationFormat: c.AttestationFormat,
+			CreatedAt:         c.CreatedAt,
+			LastUsedAt:        c.LastUsedAt,
+		})
+	}
+	return res
+}
@@ -137,6 +137,16 @@ var (
 		httpResponseCode: http.StatusBadRequest,
 	}
 
+	//-------------------------------------
+	// Define in OpenID Connect Core Unmet Authentication Requirements
+	//-------------------------------------
+
+	// ErrUnmetAuthenticationRequirements ...
+	ErrUnmetAuthenticationRequirements = &Error{
+		publicMsg:        "unmet_authentication_requirements",
+		httpResponseCode: http.StatusBadRequest,
+	}
+
 	//-------------------------------------
 	// Define in OAuth 2.0 Token Revocation
 	//-------------------------------------
@@ -175,6 +185,32 @@ var (
 		httpResponseCode: http.StatusBadRequest,
 	}
 
+	//-------------------------------------
+	// RFC 8707
+	//-------------------------------------
+
+	// ErrInvalidTarget ...
+	ErrInvalidTarget = &Error{
+		publicMsg:        "invalid_target",
+		httpResponseCode: http.StatusBadRequest,
+	}
+
+	//-------------------------------------
+	// Define in OpenID Connect CIBA
+	//-------------------------------------
+
+	// ErrUnknownUserID ...
+	ErrUnknownUserID = &Error{
+		publicMsg:        "unknown_user_id",
+		httpResponseCode: http.StatusBadRequest,
+	}
+
+	// ErrInvalidBindingMessage ...
+	ErrInvalidBindingMessage = &Error{
+		publicMsg:        "invalid_binding_message",
+		httpResponseCode: http.StatusBadRequest,
+	}
+
 	//-------------------------------------
 	// Original
 	//-------------------------------------
@@ -202,4 +238,10 @@ var (
 		publicMsg:        "no_permission",
 		httpResponseCode: http.StatusForbidden,
 	}
+
+	// ErrTooManyRequests ...
+	ErrTooManyRequests = &Error{
+		publicMsg:        "too_many_requests",
+		httpResponseCode: http.StatusTooManyRequests,
+	}
 )
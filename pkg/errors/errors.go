@@ -110,7 +110,11 @@ func Contains(err, target *Error) bool {
 	}
 
 	if err.publicMsg == target.publicMsg {
-		if len(err.privateInfo) == 0 || len(target.privateInfo) == 0 {
+		// predefined errors do not have private info, so compare only public message
+		if len(target.privateInfo) == 0 {
+			return true
+		}
+		if len(err.privateInfo) == 0 {
 			return false
 		}
 		if err.privateInfo[0].msg != target.privateInfo[0].msg {
@@ -32,4 +32,12 @@ func TestContains(t *testing.T) {
 	if Contains(nil, err1) {
 		t.Errorf("Unexpect result: nil contains Err1")
 	}
+
+	if !Contains(Append(ErrInvalidRequest, "detail"), ErrInvalidRequest) {
+		t.Errorf("Unexpect result: appended error does not contain predefined error")
+	}
+
+	if Contains(ErrInvalidRequest, ErrInvalidGrant) {
+		t.Errorf("Unexpect result: ErrInvalidRequest contains ErrInvalidGrant")
+	}
 }
@@ -0,0 +1,118 @@
+package geoip
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/logger"
+)
+
+type entry struct {
+	network *net.IPNet
+	country string
+}
+
+// Manager resolves the country of the IP address from the local GeoIP file
+type Manager struct {
+	entries []entry
+}
+
+var (
+	inst *Manager
+)
+
+// Init loads the GeoIP file
+// The file is a CSV of the network in CIDR notation and the ISO 3166-1 alpha-2 country code, e.g. "203.0.113.0/24,JP".
+// Empty lines, lines starting with # and the header line "network,country" are ignored.
+func Init(file string) *errors.Error {
+	if inst != nil {
+		return errors.New("Internal server error", "GeoIPManager is already initialized")
+	}
+
+	if file == "" {
+		logger.Info("GeoIP file is not configured, so the country of the login is not resolved")
+		inst = &Manager{}
+		return nil
+	}
+
+	fp, err := os.Open(file)
+	if err != nil {
+		return errors.New("Internal server error", "Failed to open GeoIP file: %v", err)
+	}
+	defer fp.Close()
+
+	entries, err := parse(fp)
+	if err != nil {
+		return errors.New("Internal server error", "Failed to parse GeoIP file %s: %v", file, err)
+	}
+	logger.Info("Initialize GeoIPManager with %d networks", len(entries))
+	inst = &Manager{
+		entries: entries,
+	}
+	return nil
+}
+
+// GetInst returns an instance of GeoIP Manager
+func GetInst() *Manager {
+	return inst
+}
+
+// Country returns the country code of the IP address
+// It returns empty string if the address is not found or the GeoIP file is not configured.
+// The most specific network is used if the networks overlap.
+func (m *Manager) Country(ip string) string {
+	if m == nil {
+		return ""
+	}
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return ""
+	}
+
+	res := ""
+	maxBits := -1
+	for _, e := range m.entries {
+		if !e.network.Contains(addr) {
+			continue
+		}
+		if bits, _ := e.network.Mask.Size(); bits > maxBits {
+			res = e.country
+			maxBits = bits
+		}
+	}
+	return res
+}
+
+func parse(r io.Reader) ([]entry, error) {
+	res := []entry{}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "network,") {
+			continue
+		}
+
+		fields := strings.Split(line, ",")
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected network and country", n)
+		}
+		_, network, err := net.ParseCIDR(strings.TrimSpace(fields[0]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		country := strings.ToUpper(strings.TrimSpace(fields[1]))
+		if len(country) != 2 {
+			return nil, fmt.Errorf("line %d: invalid country code %s", n, fields[1])
+		}
+		res = append(res, entry{network: network, country: country})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
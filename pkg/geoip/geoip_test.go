@@ -0,0 +1,57 @@
+package geoip
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCountry(t *testing.T) {
+	data := `network,country
+# comment
+203.0.113.0/24,jp
+203.0.113.128/25,US
+
+2001:db8::/32,DE
+`
+	entries, err := parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to parse GeoIP data: %v", err)
+	}
+	m := &Manager{entries: entries}
+
+	tt := []struct {
+		ip     string
+		expect string
+	}{
+		{"203.0.113.1", "JP"},
+		{"203.0.113.200", "US"},
+		{"2001:db8::1", "DE"},
+		{"198.51.100.1", ""},
+		{"invalid", ""},
+	}
+
+	for _, tc := range tt {
+		if res := m.Country(tc.ip); res != tc.expect {
+			t.Errorf("Country(%s) expect %s, but got %s", tc.ip, tc.expect, res)
+		}
+	}
+
+	var nilManager *Manager
+	if res := nilManager.Country("203.0.113.1"); res != "" {
+		t.Errorf("Country of nil manager expect empty, but got %s", res)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tt := []string{
+		"203.0.113.0/24",
+		"203.0.113.0,JP",
+		"203.0.113.0/24,JPN",
+	}
+
+	for _, tc := range tt {
+		if _, err := parse(strings.NewReader(tc)); err == nil {
+			t.Errorf("parse(%q) expect error, but got nil", tc)
+		}
+	}
+}
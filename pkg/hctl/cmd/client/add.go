@@ -63,6 +63,16 @@ var addClientCmd = &cobra.Command{
 			req.Secret = secret
 			req.AccessType = accessType
 			req.AllowedCallbackURLs, _ = cmd.Flags().GetStringSlice("callbacks")
+			req.DefaultScopes, _ = cmd.Flags().GetStringSlice("defaultScopes")
+			req.OptionalScopes, _ = cmd.Flags().GetStringSlice("optionalScopes")
+			req.AccessTokenLifeSpan, _ = cmd.Flags().GetUint("accessExpires")
+			req.RefreshTokenLifeSpan, _ = cmd.Flags().GetUint("refreshExpires")
+			req.IDTokenLifeSpan, _ = cmd.Flags().GetUint("idTokenExpires")
+			req.AllowGrantTypes, _ = cmd.Flags().GetStringSlice("grantTypes")
+			req.AllowResponseTypes, _ = cmd.Flags().GetStringArray("responseTypes")
+			req.RequirePKCE, _ = cmd.Flags().GetBool("requirePKCE")
+			req.BackchannelTokenDeliveryMode, _ = cmd.Flags().GetString("backchannelMode")
+			req.BackchannelClientNotificationEndpoint, _ = cmd.Flags().GetString("backchannelNotificationEndpoint")
 		}
 
 		c := config.Get()
@@ -84,5 +94,15 @@ func init() {
 	addClientCmd.Flags().String("secret", "", "secret of new client")
 	addClientCmd.Flags().String("accessType", "confidential", "access type of client (public or confidential)")
 	addClientCmd.Flags().StringSlice("callbacks", nil, "list of allowed callback url")
+	addClientCmd.Flags().StringSlice("defaultScopes", nil, "list of scopes always granted to the client")
+	addClientCmd.Flags().StringSlice("optionalScopes", nil, "list of scopes granted only when the client requests")
+	addClientCmd.Flags().Uint("accessExpires", 0, "access token life span [sec], 0 means the project setting")
+	addClientCmd.Flags().Uint("refreshExpires", 0, "refresh token life span [sec], 0 means the project setting")
+	addClientCmd.Flags().Uint("idTokenExpires", 0, "id token life span [sec], 0 means the access token life span")
+	addClientCmd.Flags().StringSlice("grantTypes", nil, "list of allowed grant types, empty means the project setting")
+	addClientCmd.Flags().StringArray("responseTypes", nil, "allowed response type such as \"code\" or \"code id_token\", empty means all types")
+	addClientCmd.Flags().Bool("requirePKCE", false, "require PKCE in authorization code flow")
+	addClientCmd.Flags().String("backchannelMode", "", "token delivery mode of CIBA (poll or ping), empty means poll")
+	addClientCmd.Flags().String("backchannelNotificationEndpoint", "", "client notification endpoint of CIBA ping mode")
 	addClientCmd.MarkFlagRequired("project")
 }
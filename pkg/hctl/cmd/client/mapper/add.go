@@ -0,0 +1,103 @@
+package mapper
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	apiclient "github.com/sh-miyoshi/hekate/pkg/apiclient/v1"
+	clientapi "github.com/sh-miyoshi/hekate/pkg/apihandler/admin/v1/client"
+	"github.com/sh-miyoshi/hekate/pkg/hctl/config"
+	"github.com/sh-miyoshi/hekate/pkg/hctl/output"
+	"github.com/sh-miyoshi/hekate/pkg/hctl/print"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addMapperCmd.Flags().String("project", "", "[Required] name of the project to which the client belongs")
+	addMapperCmd.Flags().String("client", "", "[Required] id of the client")
+	addMapperCmd.Flags().StringP("file", "f", "", "file path for new protocol mapper info")
+	addMapperCmd.Flags().StringP("name", "n", "", "name of new protocol mapper")
+	addMapperCmd.Flags().String("type", "", "mapper type (user-attribute, hardcoded, role-list, groups or audience)")
+	addMapperCmd.Flags().String("claim", "", "name of the claim")
+	addMapperCmd.Flags().String("attribute", "", "name of the user attribute")
+	addMapperCmd.Flags().String("value", "", "value of hardcoded or audience mapper")
+	addMapperCmd.Flags().Bool("multivalued", false, "release the user attribute as an array")
+	addMapperCmd.Flags().StringSlice("targets", []string{"id_token", "access_token", "userinfo"}, "list of targets (id_token, access_token and/or userinfo)")
+
+	addMapperCmd.MarkFlagRequired("project")
+	addMapperCmd.MarkFlagRequired("client")
+}
+
+var addMapperCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add protocol mapper to the client",
+	Long:  "Add protocol mapper to the client",
+	Run: func(cmd *cobra.Command, args []string) {
+		projectName, _ := cmd.Flags().GetString("project")
+		clientID, _ := cmd.Flags().GetString("client")
+		file, _ := cmd.Flags().GetString("file")
+		name, _ := cmd.Flags().GetString("name")
+
+		if file == "" && name == "" {
+			print.Error("\"name\" or \"file\" flag must be required.")
+			os.Exit(1)
+		}
+
+		if file != "" && name != "" {
+			print.Error("Either \"name\" or \"file\" flag must be specified.")
+			os.Exit(1)
+		}
+
+		token, err := config.GetAccessToken()
+		if err != nil {
+			print.Error("Token get failed: %v", err)
+			os.Exit(1)
+		}
+
+		req := &clientapi.ProtocolMapper{}
+		if file != "" {
+			bytes, err := ioutil.ReadFile(file)
+			if err != nil {
+				print.Error("Failed to read file %s: %v", file, err)
+				os.Exit(1)
+			}
+			if err := json.Unmarshal(bytes, req); err != nil {
+				print.Error("Failed to parse input file to json: %v", err)
+				os.Exit(1)
+			}
+		} else {
+			req.Name = name
+			req.Type, _ = cmd.Flags().GetString("type")
+			req.ClaimName, _ = cmd.Flags().GetString("claim")
+			req.UserAttribute, _ = cmd.Flags().GetString("attribute")
+			req.Value, _ = cmd.Flags().GetString("value")
+			req.Multivalued, _ = cmd.Flags().GetBool("multivalued")
+
+			targets, _ := cmd.Flags().GetStringSlice("targets")
+			for _, t := range targets {
+				switch t {
+				case "id_token":
+					req.IDToken = true
+				case "access_token":
+					req.AccessToken = true
+				case "userinfo":
+					req.UserInfo = true
+				default:
+					print.Error("Invalid target %s is specified.", t)
+					os.Exit(1)
+				}
+			}
+		}
+
+		c := config.Get()
+		handler := apiclient.NewHandler(c.ServerAddr, token, c.Insecure, c.RequestTimeout)
+		res, err := handler.ClientMapperAdd(projectName, clientID, req)
+		if err != nil {
+			print.Fatal("Failed to add protocol mapper %s to client %s: %v", req.Name, clientID, err)
+		}
+
+		format := output.NewMapperInfoFormat(res)
+		output.Print(format)
+	},
+}
@@ -0,0 +1,45 @@
+package mapper
+
+import (
+	"os"
+
+	apiclient "github.com/sh-miyoshi/hekate/pkg/apiclient/v1"
+	"github.com/sh-miyoshi/hekate/pkg/hctl/config"
+	"github.com/sh-miyoshi/hekate/pkg/hctl/print"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	deleteMapperCmd.Flags().String("project", "", "[Required] name of the project to which the client belongs")
+	deleteMapperCmd.Flags().String("client", "", "[Required] id of the client")
+	deleteMapperCmd.Flags().String("id", "", "[Required] id of the protocol mapper")
+
+	deleteMapperCmd.MarkFlagRequired("project")
+	deleteMapperCmd.MarkFlagRequired("client")
+	deleteMapperCmd.MarkFlagRequired("id")
+}
+
+var deleteMapperCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete protocol mapper from the client",
+	Long:  "Delete protocol mapper from the client",
+	Run: func(cmd *cobra.Command, args []string) {
+		projectName, _ := cmd.Flags().GetString("project")
+		clientID, _ := cmd.Flags().GetString("client")
+		id, _ := cmd.Flags().GetString("id")
+
+		token, err := config.GetAccessToken()
+		if err != nil {
+			print.Error("Token get failed: %v", err)
+			os.Exit(1)
+		}
+
+		c := config.Get()
+		handler := apiclient.NewHandler(c.ServerAddr, token, c.Insecure, c.RequestTimeout)
+		if err := handler.ClientMapperDelete(projectName, clientID, id); err != nil {
+			print.Fatal("Failed to delete protocol mapper %s from client %s: %v", id, clientID, err)
+		}
+
+		print.Print("Protocol mapper %s successfully deleted", id)
+	},
+}
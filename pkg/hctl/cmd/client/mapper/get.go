@@ -0,0 +1,45 @@
+package mapper
+
+import (
+	"os"
+
+	apiclient "github.com/sh-miyoshi/hekate/pkg/apiclient/v1"
+	"github.com/sh-miyoshi/hekate/pkg/hctl/config"
+	"github.com/sh-miyoshi/hekate/pkg/hctl/output"
+	"github.com/sh-miyoshi/hekate/pkg/hctl/print"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	getMapperCmd.Flags().String("project", "", "[Required] name of the project to which the client belongs")
+	getMapperCmd.Flags().String("client", "", "[Required] id of the client")
+
+	getMapperCmd.MarkFlagRequired("project")
+	getMapperCmd.MarkFlagRequired("client")
+}
+
+var getMapperCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Get protocol mappers in the client",
+	Long:  "Get protocol mappers in the client",
+	Run: func(cmd *cobra.Command, args []string) {
+		projectName, _ := cmd.Flags().GetString("project")
+		clientID, _ := cmd.Flags().GetString("client")
+
+		token, err := config.GetAccessToken()
+		if err != nil {
+			print.Error("Token get failed: %v", err)
+			os.Exit(1)
+		}
+
+		c := config.Get()
+		handler := apiclient.NewHandler(c.ServerAddr, token, c.Insecure, c.RequestTimeout)
+		res, err := handler.ClientMapperGetList(projectName, clientID)
+		if err != nil {
+			print.Fatal("Failed to get protocol mappers in client %s: %v", clientID, err)
+		}
+
+		format := output.NewMappersInfoFormat(res)
+		output.Print(format)
+	},
+}
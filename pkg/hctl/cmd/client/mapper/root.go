@@ -0,0 +1,28 @@
+package mapper
+
+import (
+	"github.com/sh-miyoshi/hekate/pkg/hctl/print"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	mapperCmd.AddCommand(addMapperCmd)
+	mapperCmd.AddCommand(deleteMapperCmd)
+	mapperCmd.AddCommand(getMapperCmd)
+	mapperCmd.AddCommand(updateMapperCmd)
+}
+
+var mapperCmd = &cobra.Command{
+	Use:   "mapper",
+	Short: "Manage protocol mapper in the client",
+	Long:  `Manage protocol mapper in the client`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+		print.Print("mapper command requires subcommand")
+	},
+}
+
+// GetCommand ...
+func GetCommand() *cobra.Command {
+	return mapperCmd
+}
@@ -0,0 +1,62 @@
+package mapper
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	apiclient "github.com/sh-miyoshi/hekate/pkg/apiclient/v1"
+	clientapi "github.com/sh-miyoshi/hekate/pkg/apihandler/admin/v1/client"
+	"github.com/sh-miyoshi/hekate/pkg/hctl/config"
+	"github.com/sh-miyoshi/hekate/pkg/hctl/print"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	updateMapperCmd.Flags().String("project", "", "[Required] name of the project to which the client belongs")
+	updateMapperCmd.Flags().String("client", "", "[Required] id of the client")
+	updateMapperCmd.Flags().String("id", "", "[Required] id of the protocol mapper")
+	updateMapperCmd.Flags().StringP("file", "f", "", "[Required] file path for protocol mapper info")
+
+	updateMapperCmd.MarkFlagRequired("project")
+	updateMapperCmd.MarkFlagRequired("client")
+	updateMapperCmd.MarkFlagRequired("id")
+	updateMapperCmd.MarkFlagRequired("file")
+}
+
+var updateMapperCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update protocol mapper in the client",
+	Long:  "Update protocol mapper in the client",
+	Run: func(cmd *cobra.Command, args []string) {
+		projectName, _ := cmd.Flags().GetString("project")
+		clientID, _ := cmd.Flags().GetString("client")
+		id, _ := cmd.Flags().GetString("id")
+		file, _ := cmd.Flags().GetString("file")
+
+		req := &clientapi.ProtocolMapper{}
+		bytes, err := ioutil.ReadFile(file)
+		if err != nil {
+			print.Error("Failed to read file %s: %v", file, err)
+			os.Exit(1)
+		}
+		if err := json.Unmarshal(bytes, req); err != nil {
+			print.Error("Failed to parse input file to json: %v", err)
+			os.Exit(1)
+		}
+
+		token, err := config.GetAccessToken()
+		if err != nil {
+			print.Error("Token get failed: %v", err)
+			os.Exit(1)
+		}
+
+		c := config.Get()
+		handler := apiclient.NewHandler(c.ServerAddr, token, c.Insecure, c.RequestTimeout)
+		if err := handler.ClientMapperUpdate(projectName, clientID, id, req); err != nil {
+			print.Fatal("Failed to update protocol mapper %s in client %s: %v", id, clientID, err)
+		}
+
+		print.Print("Protocol mapper %s successfully updated", id)
+	},
+}
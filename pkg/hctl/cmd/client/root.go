@@ -1,6 +1,7 @@
 package client
 
 import (
+	"github.com/sh-miyoshi/hekate/pkg/hctl/cmd/client/mapper"
 	"github.com/sh-miyoshi/hekate/pkg/hctl/print"
 	"github.com/spf13/cobra"
 )
@@ -10,6 +11,7 @@ func init() {
 	clientCmd.AddCommand(deleteClientCmd)
 	clientCmd.AddCommand(getClientCmd)
 	clientCmd.AddCommand(updateClientCmd)
+	clientCmd.AddCommand(mapper.GetCommand())
 }
 
 var clientCmd = &cobra.Command{
@@ -77,6 +77,61 @@ var updateClientCmd = &cobra.Command{
 			} else {
 				req.AllowedCallbackURLs = prev.AllowedCallbackURLs
 			}
+
+			defaultScopes := cmd.Flag("defaultScopes")
+			if defaultScopes.Changed {
+				req.DefaultScopes, _ = cmd.Flags().GetStringSlice("defaultScopes")
+			} else {
+				req.DefaultScopes = prev.DefaultScopes
+			}
+
+			optionalScopes := cmd.Flag("optionalScopes")
+			if optionalScopes.Changed {
+				req.OptionalScopes, _ = cmd.Flags().GetStringSlice("optionalScopes")
+			} else {
+				req.OptionalScopes = prev.OptionalScopes
+			}
+
+			req.AccessTokenLifeSpan = prev.AccessTokenLifeSpan
+			if cmd.Flag("accessExpires").Changed {
+				req.AccessTokenLifeSpan, _ = cmd.Flags().GetUint("accessExpires")
+			}
+			req.RefreshTokenLifeSpan = prev.RefreshTokenLifeSpan
+			if cmd.Flag("refreshExpires").Changed {
+				req.RefreshTokenLifeSpan, _ = cmd.Flags().GetUint("refreshExpires")
+			}
+			req.IDTokenLifeSpan = prev.IDTokenLifeSpan
+			if cmd.Flag("idTokenExpires").Changed {
+				req.IDTokenLifeSpan, _ = cmd.Flags().GetUint("idTokenExpires")
+			}
+			req.AllowGrantTypes = prev.AllowGrantTypes
+			if cmd.Flag("grantTypes").Changed {
+				req.AllowGrantTypes, _ = cmd.Flags().GetStringSlice("grantTypes")
+			}
+			req.AllowResponseTypes = prev.AllowResponseTypes
+			if cmd.Flag("responseTypes").Changed {
+				req.AllowResponseTypes, _ = cmd.Flags().GetStringArray("responseTypes")
+			}
+			req.RequirePKCE = prev.RequirePKCE
+			if cmd.Flag("requirePKCE").Changed {
+				req.RequirePKCE, _ = cmd.Flags().GetBool("requirePKCE")
+			}
+			req.BackchannelTokenDeliveryMode = prev.BackchannelTokenDeliveryMode
+			if cmd.Flag("backchannelMode").Changed {
+				req.BackchannelTokenDeliveryMode, _ = cmd.Flags().GetString("backchannelMode")
+			}
+			req.BackchannelClientNotificationEndpoint = prev.BackchannelClientNotificationEndpoint
+			if cmd.Flag("backchannelNotificationEndpoint").Changed {
+				req.BackchannelClientNotificationEndpoint, _ = cmd.Flags().GetString("backchannelNotificationEndpoint")
+			}
+
+			// the response encryption settings can be changed only by the file
+			req.JWKS = prev.JWKS
+			req.IDTokenEncryptedResponseAlg = prev.IDTokenEncryptedResponseAlg
+			req.IDTokenEncryptedResponseEnc = prev.IDTokenEncryptedResponseEnc
+			req.UserinfoSignedResponseAlg = prev.UserinfoSignedResponseAlg
+			req.UserinfoEncryptedResponseAlg = prev.UserinfoEncryptedResponseAlg
+			req.UserinfoEncryptedResponseEnc = prev.UserinfoEncryptedResponseEnc
 		}
 
 		if err := handler.ClientUpdate(projectName, id, req); err != nil {
@@ -94,6 +149,16 @@ func init() {
 	updateClientCmd.Flags().String("secret", "", "secret of new client")
 	updateClientCmd.Flags().String("accessType", "confidential", "access type of client (public or confidential)")
 	updateClientCmd.Flags().StringSlice("callbacks", nil, "list of allowed callback url")
+	updateClientCmd.Flags().StringSlice("defaultScopes", nil, "list of scopes always granted to the client")
+	updateClientCmd.Flags().StringSlice("optionalScopes", nil, "list of scopes granted only when the client requests")
+	updateClientCmd.Flags().Uint("accessExpires", 0, "access token life span [sec], 0 means the project setting")
+	updateClientCmd.Flags().Uint("refreshExpires", 0, "refresh token life span [sec], 0 means the project setting")
+	updateClientCmd.Flags().Uint("idTokenExpires", 0, "id token life span [sec], 0 means the access token life span")
+	updateClientCmd.Flags().StringSlice("grantTypes", nil, "list of allowed grant types, empty means the project setting")
+	updateClientCmd.Flags().StringArray("responseTypes", nil, "allowed response type such as \"code\" or \"code id_token\", empty means all types")
+	updateClientCmd.Flags().Bool("requirePKCE", false, "require PKCE in authorization code flow")
+	updateClientCmd.Flags().String("backchannelMode", "", "token delivery mode of CIBA (poll or ping), empty means poll")
+	updateClientCmd.Flags().String("backchannelNotificationEndpoint", "", "client notification endpoint of CIBA ping mode")
 
 	updateClientCmd.MarkFlagRequired("project")
 	updateClientCmd.MarkFlagRequired("id")
@@ -55,6 +55,9 @@ var addProjectCmd = &cobra.Command{
 			req.Name = projectName
 			req.TokenConfig.AccessTokenLifeSpan, _ = cmd.Flags().GetUint("accessExpires")
 			req.TokenConfig.RefreshTokenLifeSpan, _ = cmd.Flags().GetUint("refreshExpires")
+			req.TokenConfig.RefreshTokenGracePeriod, _ = cmd.Flags().GetUint("refreshGracePeriod")
+			req.TokenConfig.OfflineSessionLifeSpan, _ = cmd.Flags().GetUint("offlineSessionExpires")
+			req.TokenConfig.OfflineSessionIdleTimeout, _ = cmd.Flags().GetUint("offlineSessionIdleTimeout")
 			req.TokenConfig.SigningAlgorithm, _ = cmd.Flags().GetString("signAlg")
 			req.AllowGrantTypes, _ = cmd.Flags().GetStringArray("grantTypes")
 
@@ -71,6 +74,7 @@ var addProjectCmd = &cobra.Command{
 				req.UserLock.LockDuration, _ = cmd.Flags().GetUint("lockDuration")
 				req.UserLock.FailureResetTime, _ = cmd.Flags().GetUint("failureResetTime")
 			}
+			req.DefaultLocale, _ = cmd.Flags().GetString("defaultLocale")
 		}
 
 		c := config.Get()
@@ -89,12 +93,16 @@ func init() {
 	addProjectCmd.Flags().StringP("name", "n", "", "name of new project")
 	addProjectCmd.Flags().Uint("accessExpires", 5*60, "access token life span [sec]")
 	addProjectCmd.Flags().Uint("refreshExpires", 14*24*60*60, "refresh token life span [sec]")
+	addProjectCmd.Flags().Uint("offlineSessionExpires", 60*24*60*60, "max life span of offline sessions [sec]")
+	addProjectCmd.Flags().Uint("offlineSessionIdleTimeout", 30*24*60*60, "idle timeout of offline refresh tokens [sec]")
+	addProjectCmd.Flags().Uint("refreshGracePeriod", 0, "grace period in which a rotated refresh token can be used for concurrent requests [sec]")
 	addProjectCmd.Flags().String("signAlg", "RS256", "token sigining algorithm, only support RS256")
 	addProjectCmd.Flags().StringArray("grantTypes", []string{}, "allowed grant type list")
-	addProjectCmd.Flags().StringArray("passwordPolicies", []string{}, "password policy of users, supports \"minLen=<uint>\", \"notUserName=<bool>\", \"useChar=<lower|upper|both|either>\", \"useDigit=<bool>\", \"useSpecialChar=<bool>\", \"blackLists=<string separated by semicolon(;)>\"")
+	addProjectCmd.Flags().StringArray("passwordPolicies", []string{}, "password policy of users, supports \"minLen=<uint>\", \"notUserName=<bool>\", \"useChar=<lower|upper|both|either>\", \"useDigit=<bool>\", \"useSpecialChar=<bool>\", \"blackLists=<string separated by semicolon(;)>\", \"maxAge=<seconds>\", \"minAge=<seconds>\", \"historyCount=<uint>\"")
 	addProjectCmd.Flags().Bool("userLockEnabled", false, "enable user lock")
 	addProjectCmd.Flags().Uint("maxLoginFailure", 5, "the max number of user login failure")
 	addProjectCmd.Flags().Uint("lockDuration", 10*60, "a duration of couting login failure [sec]")
 	addProjectCmd.Flags().Uint("failureResetTime", 10*60, "reset time of user locked [sec]")
+	addProjectCmd.Flags().String("defaultLocale", "en", "default locale of login pages, supports \"en\", \"ja\"")
 	addProjectCmd.Flags().StringP("file", "f", "", "json file name of project info")
 }
@@ -73,18 +73,38 @@ var updateProjectCmd = &cobra.Command{
 
 			req.TokenConfig.AccessTokenLifeSpan = getData(cmd, "accessExpires", prev.TokenConfig.AccessTokenLifeSpan, "uint").(uint)
 			req.TokenConfig.RefreshTokenLifeSpan = getData(cmd, "refreshExpires", prev.TokenConfig.RefreshTokenLifeSpan, "uint").(uint)
+			req.TokenConfig.RefreshTokenGracePeriod = getData(cmd, "refreshGracePeriod", prev.TokenConfig.RefreshTokenGracePeriod, "uint").(uint)
+			req.TokenConfig.OfflineSessionLifeSpan = getData(cmd, "offlineSessionExpires", prev.TokenConfig.OfflineSessionLifeSpan, "uint").(uint)
+			req.TokenConfig.OfflineSessionIdleTimeout = getData(cmd, "offlineSessionIdleTimeout", prev.TokenConfig.OfflineSessionIdleTimeout, "uint").(uint)
 			req.TokenConfig.SigningAlgorithm = getData(cmd, "signAlg", prev.TokenConfig.SigningAlgorithm, "string").(string)
 			req.AllowGrantTypes = getData(cmd, "grantTypes", prev.AllowGrantTypes, "stringarray").([]string)
-			pwPols := getData(cmd, "passwordPolicies", prev.PasswordPolicy, "stringarray").([]string)
-			req.PasswordPolicy, err = util.ParsePolicies(pwPols)
-			if err != nil {
-				print.Error("Failed to parse password policy: %v", err)
-				os.Exit(1)
+			req.PasswordPolicy = prev.PasswordPolicy
+			if cmd.Flag("passwordPolicies").Changed {
+				pwPols, _ := cmd.Flags().GetStringArray("passwordPolicies")
+				req.PasswordPolicy, err = util.ParsePolicies(pwPols)
+				if err != nil {
+					print.Error("Failed to parse password policy: %v", err)
+					os.Exit(1)
+				}
 			}
 			req.UserLock.Enabled = getData(cmd, "userLockEnabled", prev.UserLock.Enabled, "bool").(bool)
 			req.UserLock.MaxLoginFailure = getData(cmd, "maxLoginFailure", prev.UserLock.MaxLoginFailure, "uint").(uint)
 			req.UserLock.LockDuration = getData(cmd, "lockDuration", prev.UserLock.LockDuration, "uint").(uint)
 			req.UserLock.FailureResetTime = getData(cmd, "failureResetTime", prev.UserLock.FailureResetTime, "uint").(uint)
+			req.DefaultLocale = getData(cmd, "defaultLocale", prev.DefaultLocale, "string").(string)
+
+			// the other settings can be changed only by the file except for the enablement
+			req.WebAuthnConfig = prev.WebAuthnConfig
+			req.WebAuthnConfig.AttestationConveyance = getData(cmd, "attestationConveyance", prev.WebAuthnConfig.AttestationConveyance, "string").(string)
+			req.MailConfig = prev.MailConfig
+			req.MailConfig.EMailOTPEnabled = getData(cmd, "emailOTPEnabled", prev.MailConfig.EMailOTPEnabled, "bool").(bool)
+			req.MailConfig.MagicLinkEnabled = getData(cmd, "magicLinkEnabled", prev.MailConfig.MagicLinkEnabled, "bool").(bool)
+			req.SMSConfig = prev.SMSConfig
+			req.SMSConfig.OTPEnabled = getData(cmd, "smsOTPEnabled", prev.SMSConfig.OTPEnabled, "bool").(bool)
+			req.TOTPConfig = prev.TOTPConfig
+			req.MFAPolicy = prev.MFAPolicy
+			req.MFAPolicy.Mode = getData(cmd, "mfaMode", prev.MFAPolicy.Mode, "string").(string)
+			req.RiskPolicy = prev.RiskPolicy
 		}
 
 		if err := handler.ProjectUpdate(projectName, req); err != nil {
@@ -99,13 +119,22 @@ func init() {
 	updateProjectCmd.Flags().StringP("name", "n", "", "name of update project")
 	updateProjectCmd.Flags().Uint("accessExpires", 5*60, "access token life span [sec]")
 	updateProjectCmd.Flags().Uint("refreshExpires", 14*24*60*60, "refresh token life span [sec]")
+	updateProjectCmd.Flags().Uint("offlineSessionExpires", 60*24*60*60, "max life span of offline sessions [sec]")
+	updateProjectCmd.Flags().Uint("offlineSessionIdleTimeout", 30*24*60*60, "idle timeout of offline refresh tokens [sec]")
+	updateProjectCmd.Flags().Uint("refreshGracePeriod", 0, "grace period in which a rotated refresh token can be used for concurrent requests [sec]")
 	updateProjectCmd.Flags().String("signAlg", "RS256", "token sigining algorithm, only support RS256")
 	updateProjectCmd.Flags().StringArray("grantTypes", []string{}, "allowed grant type list")
-	updateProjectCmd.Flags().StringArray("passwordPolicies", []string{}, "password policy of users, supports \"minLen=<uint>\", \"notUserName=<bool>\", \"useChar=<lower|upper|both|either>\", \"useDigit=<bool>\", \"useSpecialChar=<bool>\", \"blackLists=<string separated by semicolon(;)>\"")
+	updateProjectCmd.Flags().StringArray("passwordPolicies", []string{}, "password policy of users, supports \"minLen=<uint>\", \"notUserName=<bool>\", \"useChar=<lower|upper|both|either>\", \"useDigit=<bool>\", \"useSpecialChar=<bool>\", \"blackLists=<string separated by semicolon(;)>\", \"maxAge=<seconds>\", \"minAge=<seconds>\", \"historyCount=<uint>\"")
 	updateProjectCmd.Flags().Bool("userLockEnabled", false, "enable user lock")
 	updateProjectCmd.Flags().Uint("maxLoginFailure", 5, "the max number of user login failure")
 	updateProjectCmd.Flags().Uint("lockDuration", 10*60, "a duration of couting login failure [sec]")
 	updateProjectCmd.Flags().Uint("failureResetTime", 10*60, "reset time of user locked [sec]")
+	updateProjectCmd.Flags().String("defaultLocale", "en", "default locale of login pages, supports \"en\", \"ja\"")
+	updateProjectCmd.Flags().String("attestationConveyance", "none", "attestation conveyance of WebAuthn credentials, supports \"none\", \"indirect\", \"direct\"")
+	updateProjectCmd.Flags().Bool("emailOTPEnabled", false, "enable one-time code by email as the second factor")
+	updateProjectCmd.Flags().Bool("magicLinkEnabled", false, "enable passwordless login by the link sent by email")
+	updateProjectCmd.Flags().Bool("smsOTPEnabled", false, "enable one-time code by SMS as the second factor")
+	updateProjectCmd.Flags().String("mfaMode", "off", "MFA enforcement mode, supports \"off\", \"optional\", \"required\", \"role\"")
 	updateProjectCmd.Flags().StringP("file", "f", "", "json file name of project info")
 
 	updateProjectCmd.MarkFlagRequired("name")
@@ -7,6 +7,7 @@ import (
 	"github.com/sh-miyoshi/hekate/pkg/hctl/cmd/logout"
 	"github.com/sh-miyoshi/hekate/pkg/hctl/cmd/project"
 	"github.com/sh-miyoshi/hekate/pkg/hctl/cmd/role"
+	"github.com/sh-miyoshi/hekate/pkg/hctl/cmd/scope"
 	"github.com/sh-miyoshi/hekate/pkg/hctl/cmd/user"
 	globalconfig "github.com/sh-miyoshi/hekate/pkg/hctl/config"
 	"github.com/sh-miyoshi/hekate/pkg/hctl/output"
@@ -35,6 +36,7 @@ func init() {
 	rootCmd.AddCommand(user.GetCommand())
 	rootCmd.AddCommand(client.GetCommand())
 	rootCmd.AddCommand(role.GetCommand())
+	rootCmd.AddCommand(scope.GetCommand())
 	rootCmd.AddCommand(config.GetCommand())
 }
 
@@ -0,0 +1,90 @@
+package scope
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	apiclient "github.com/sh-miyoshi/hekate/pkg/apiclient/v1"
+	clientscopeapi "github.com/sh-miyoshi/hekate/pkg/apihandler/admin/v1/clientscope"
+	"github.com/sh-miyoshi/hekate/pkg/hctl/config"
+	"github.com/sh-miyoshi/hekate/pkg/hctl/output"
+	"github.com/sh-miyoshi/hekate/pkg/hctl/print"
+	"github.com/spf13/cobra"
+)
+
+var addScopeCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add New Scope",
+	Long:  "Add new client scope into the project",
+	Run: func(cmd *cobra.Command, args []string) {
+		projectName, _ := cmd.Flags().GetString("project")
+		file, _ := cmd.Flags().GetString("file")
+		name, _ := cmd.Flags().GetString("name")
+
+		if file == "" && name == "" {
+			print.Error("\"name\" or \"file\" flag must be required.")
+			os.Exit(1)
+		}
+
+		if file != "" && name != "" {
+			print.Error("Either \"name\" or \"file\" flag must be specified.")
+			os.Exit(1)
+		}
+
+		req := &clientscopeapi.ClientScopeCreateRequest{}
+		if file != "" {
+			bytes, err := ioutil.ReadFile(file)
+			if err != nil {
+				print.Error("Failed to read file %s: %v", file, err)
+				os.Exit(1)
+			}
+			if err := json.Unmarshal(bytes, req); err != nil {
+				print.Error("Failed to parse input file to json: %v", err)
+				os.Exit(1)
+			}
+		} else {
+			req.Name = name
+			req.Description, _ = cmd.Flags().GetString("description")
+			req.Claims, _ = cmd.Flags().GetStringSlice("claims")
+		}
+
+		token, err := config.GetAccessToken()
+		if err != nil {
+			print.Error("Token get failed: %v", err)
+			os.Exit(1)
+		}
+
+		c := config.Get()
+		handler := apiclient.NewHandler(c.ServerAddr, token, c.Insecure, c.RequestTimeout)
+
+		if file == "" {
+			roles, _ := cmd.Flags().GetStringSlice("roles")
+			for _, r := range roles {
+				res, err := handler.RoleGetList(projectName, r)
+				if err != nil || len(res) != 1 {
+					print.Fatal("Failed to get role %s: %v", r, err)
+				}
+				req.RequiredRoles = append(req.RequiredRoles, res[0].ID)
+			}
+		}
+
+		res, err := handler.ScopeAdd(projectName, req)
+		if err != nil {
+			print.Fatal("Failed to add new scope %s to %s: %v", req.Name, projectName, err)
+		}
+
+		format := output.NewScopeInfoFormat(res)
+		output.Print(format)
+	},
+}
+
+func init() {
+	addScopeCmd.Flags().String("project", "", "[Required] name of the project to which the scope belongs")
+	addScopeCmd.Flags().StringP("file", "f", "", "file path for new scope info")
+	addScopeCmd.Flags().StringP("name", "n", "", "name of new scope")
+	addScopeCmd.Flags().String("description", "", "description of the scope shown in the consent page")
+	addScopeCmd.Flags().StringSlice("claims", nil, "list of standard user claims released by the scope")
+	addScopeCmd.Flags().StringSlice("roles", nil, "list of custom role names required to grant the scope")
+	addScopeCmd.MarkFlagRequired("project")
+}
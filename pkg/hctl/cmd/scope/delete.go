@@ -0,0 +1,41 @@
+package scope
+
+import (
+	"os"
+
+	apiclient "github.com/sh-miyoshi/hekate/pkg/apiclient/v1"
+	"github.com/sh-miyoshi/hekate/pkg/hctl/config"
+	"github.com/sh-miyoshi/hekate/pkg/hctl/print"
+	"github.com/spf13/cobra"
+)
+
+var deleteScopeCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete the scope",
+	Long:  "Delete the client scope from the project",
+	Run: func(cmd *cobra.Command, args []string) {
+		projectName, _ := cmd.Flags().GetString("project")
+		name, _ := cmd.Flags().GetString("name")
+
+		token, err := config.GetAccessToken()
+		if err != nil {
+			print.Error("Token get failed: %v", err)
+			os.Exit(1)
+		}
+
+		c := config.Get()
+		handler := apiclient.NewHandler(c.ServerAddr, token, c.Insecure, c.RequestTimeout)
+		if err := handler.ScopeDelete(projectName, name); err != nil {
+			print.Fatal("Failed to delete scope %s: %v", name, err)
+		}
+
+		print.Print("Scope %s successfully deleted", name)
+	},
+}
+
+func init() {
+	deleteScopeCmd.Flags().String("project", "", "[Required] name of the project to which the scope belongs")
+	deleteScopeCmd.Flags().StringP("name", "n", "", "[Required] name of the scope")
+	deleteScopeCmd.MarkFlagRequired("project")
+	deleteScopeCmd.MarkFlagRequired("name")
+}
@@ -0,0 +1,52 @@
+package scope
+
+import (
+	"os"
+
+	apiclient "github.com/sh-miyoshi/hekate/pkg/apiclient/v1"
+	"github.com/sh-miyoshi/hekate/pkg/hctl/config"
+	"github.com/sh-miyoshi/hekate/pkg/hctl/output"
+	"github.com/sh-miyoshi/hekate/pkg/hctl/print"
+	"github.com/spf13/cobra"
+)
+
+var getScopeCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Get Scopes in the project",
+	Long:  "Get client scopes in the project",
+	Run: func(cmd *cobra.Command, args []string) {
+		projectName, _ := cmd.Flags().GetString("project")
+		name, _ := cmd.Flags().GetString("name")
+
+		token, err := config.GetAccessToken()
+		if err != nil {
+			print.Error("Token get failed: %v", err)
+			os.Exit(1)
+		}
+
+		c := config.Get()
+		handler := apiclient.NewHandler(c.ServerAddr, token, c.Insecure, c.RequestTimeout)
+
+		res, err := handler.ScopeGetList(projectName, name)
+		if err != nil {
+			print.Fatal("Failed to get scope: %v", err)
+		}
+
+		if name == "" {
+			format := output.NewScopesInfoFormat(res)
+			output.Print(format)
+		} else {
+			if len(res) == 0 {
+				print.Fatal("Scope %s is not found", name)
+			}
+			format := output.NewScopeInfoFormat(res[0])
+			output.Print(format)
+		}
+	},
+}
+
+func init() {
+	getScopeCmd.Flags().String("project", "", "[Required] name of the project to which the scope belongs")
+	getScopeCmd.Flags().StringP("name", "n", "", "name of the scope")
+	getScopeCmd.MarkFlagRequired("project")
+}
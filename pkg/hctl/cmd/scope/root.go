@@ -0,0 +1,28 @@
+package scope
+
+import (
+	"github.com/sh-miyoshi/hekate/pkg/hctl/print"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	scopeCmd.AddCommand(addScopeCmd)
+	scopeCmd.AddCommand(deleteScopeCmd)
+	scopeCmd.AddCommand(getScopeCmd)
+	scopeCmd.AddCommand(updateScopeCmd)
+}
+
+var scopeCmd = &cobra.Command{
+	Use:   "scope",
+	Short: "Manage client scope in the project",
+	Long:  `Manage client scope in the project`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+		print.Error("scope command requires subcommand")
+	},
+}
+
+// GetCommand ...
+func GetCommand() *cobra.Command {
+	return scopeCmd
+}
@@ -0,0 +1,58 @@
+package scope
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	apiclient "github.com/sh-miyoshi/hekate/pkg/apiclient/v1"
+	clientscopeapi "github.com/sh-miyoshi/hekate/pkg/apihandler/admin/v1/clientscope"
+	"github.com/sh-miyoshi/hekate/pkg/hctl/config"
+	"github.com/sh-miyoshi/hekate/pkg/hctl/print"
+	"github.com/spf13/cobra"
+)
+
+var updateScopeCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update the scope",
+	Long:  "Update the client scope in the project",
+	Run: func(cmd *cobra.Command, args []string) {
+		projectName, _ := cmd.Flags().GetString("project")
+		name, _ := cmd.Flags().GetString("name")
+		file, _ := cmd.Flags().GetString("file")
+
+		req := &clientscopeapi.ClientScopePutRequest{}
+		bytes, err := ioutil.ReadFile(file)
+		if err != nil {
+			print.Error("Failed to read file %s: %v", file, err)
+			os.Exit(1)
+		}
+		if err := json.Unmarshal(bytes, req); err != nil {
+			print.Error("Failed to parse input file to json: %v", err)
+			os.Exit(1)
+		}
+
+		token, err := config.GetAccessToken()
+		if err != nil {
+			print.Error("Token get failed: %v", err)
+			os.Exit(1)
+		}
+
+		c := config.Get()
+		handler := apiclient.NewHandler(c.ServerAddr, token, c.Insecure, c.RequestTimeout)
+		if err := handler.ScopeUpdate(projectName, name, req); err != nil {
+			print.Fatal("Failed to update scope %s: %v", name, err)
+		}
+
+		print.Print("Scope %s successfully updated", name)
+	},
+}
+
+func init() {
+	updateScopeCmd.Flags().String("project", "", "[Required] name of the project to which the scope belongs")
+	updateScopeCmd.Flags().StringP("name", "n", "", "[Required] name of the scope")
+	updateScopeCmd.Flags().StringP("file", "f", "", "[Required] file path for scope info")
+	updateScopeCmd.MarkFlagRequired("project")
+	updateScopeCmd.MarkFlagRequired("name")
+	updateScopeCmd.MarkFlagRequired("file")
+}
@@ -19,6 +19,7 @@ var passwordChangeCmd = &cobra.Command{
 		projectName, _ := cmd.Flags().GetString("project")
 		userName, _ := cmd.Flags().GetString("name")
 		password, _ := cmd.Flags().GetString("password")
+		permanent, _ := cmd.Flags().GetBool("permanent")
 
 		token, err := config.GetAccessToken()
 		if err != nil {
@@ -48,7 +49,7 @@ var passwordChangeCmd = &cobra.Command{
 		c := config.Get()
 		handler := apiclient.NewHandler(c.ServerAddr, token, c.Insecure, c.RequestTimeout)
 
-		if err := handler.UserChangePassword(projectName, userName, password); err != nil {
+		if err := handler.UserChangePassword(projectName, userName, password, permanent); err != nil {
 			print.Fatal("Failed to change user %s password: %v", userName, err)
 		}
 
@@ -60,6 +61,7 @@ func init() {
 	passwordChangeCmd.Flags().StringP("project", "p", "", "[Required] name of project to which the user belongs")
 	passwordChangeCmd.Flags().StringP("name", "n", "", "[Required] name of target user")
 	passwordChangeCmd.Flags().String("password", "", "new password")
+	passwordChangeCmd.Flags().Bool("permanent", false, "the user does not need to change the password at next login")
 	passwordChangeCmd.MarkFlagRequired("project")
 	passwordChangeCmd.MarkFlagRequired("name")
 }
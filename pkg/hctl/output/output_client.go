@@ -37,7 +37,20 @@ func (f *ClientInfoFormat) ToText() (string, error) {
 	res += fmt.Sprintf("Secret:              %s\n", f.client.Secret)
 	res += fmt.Sprintf("AccessType:          %s\n", f.client.AccessType)
 	res += fmt.Sprintf("CreatedAt:           %s\n", f.client.CreatedAt)
-	res += fmt.Sprintf("AllowedCallbackURLs: %v", f.client.AllowedCallbackURLs)
+	res += fmt.Sprintf("AllowedCallbackURLs: %v\n", f.client.AllowedCallbackURLs)
+	res += fmt.Sprintf("DefaultScopes:       %v\n", f.client.DefaultScopes)
+	res += fmt.Sprintf("OptionalScopes:      %v\n", f.client.OptionalScopes)
+	res += fmt.Sprintf("AccessTokenLifeSpan: %d [sec]\n", f.client.AccessTokenLifeSpan)
+	res += fmt.Sprintf("RefreshTokenLifeSpan: %d [sec]\n", f.client.RefreshTokenLifeSpan)
+	res += fmt.Sprintf("IDTokenLifeSpan:     %d [sec]\n", f.client.IDTokenLifeSpan)
+	res += fmt.Sprintf("AllowGrantTypes:     %v\n", f.client.AllowGrantTypes)
+	res += fmt.Sprintf("AllowResponseTypes:  %v\n", f.client.AllowResponseTypes)
+	res += fmt.Sprintf("RequirePKCE:         %v\n", f.client.RequirePKCE)
+	res += fmt.Sprintf("IDTokenEncryption:   %s %s\n", f.client.IDTokenEncryptedResponseAlg, f.client.IDTokenEncryptedResponseEnc)
+	res += fmt.Sprintf("UserinfoSigning:     %s\n", f.client.UserinfoSignedResponseAlg)
+	res += fmt.Sprintf("UserinfoEncryption:  %s %s\n", f.client.UserinfoEncryptedResponseAlg, f.client.UserinfoEncryptedResponseEnc)
+	res += fmt.Sprintf("BackchannelMode:     %s\n", f.client.BackchannelTokenDeliveryMode)
+	res += fmt.Sprintf("BackchannelNotify:   %s", f.client.BackchannelClientNotificationEndpoint)
 	return res, nil
 }
 
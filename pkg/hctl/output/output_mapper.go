@@ -0,0 +1,91 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+
+	clientapi "github.com/sh-miyoshi/hekate/pkg/apihandler/admin/v1/client"
+)
+
+// MapperInfoFormat ...
+type MapperInfoFormat struct {
+	mapper *clientapi.ProtocolMapper
+}
+
+// MappersInfoFormat ...
+type MappersInfoFormat struct {
+	mappers []*clientapi.ProtocolMapper
+}
+
+// NewMapperInfoFormat ...
+func NewMapperInfoFormat(mapper *clientapi.ProtocolMapper) *MapperInfoFormat {
+	return &MapperInfoFormat{
+		mapper: mapper,
+	}
+}
+
+// NewMappersInfoFormat ...
+func NewMappersInfoFormat(mappers []*clientapi.ProtocolMapper) *MappersInfoFormat {
+	return &MappersInfoFormat{
+		mappers: mappers,
+	}
+}
+
+// ToText ...
+func (f *MapperInfoFormat) ToText() (string, error) {
+	targets := []string{}
+	if f.mapper.IDToken {
+		targets = append(targets, "id_token")
+	}
+	if f.mapper.AccessToken {
+		targets = append(targets, "access_token")
+	}
+	if f.mapper.UserInfo {
+		targets = append(targets, "userinfo")
+	}
+
+	res := fmt.Sprintf("ID:             %s\n", f.mapper.ID)
+	res += fmt.Sprintf("Name:           %s\n", f.mapper.Name)
+	res += fmt.Sprintf("Type:           %s\n", f.mapper.Type)
+	res += fmt.Sprintf("Claim Name:     %s\n", f.mapper.ClaimName)
+	res += fmt.Sprintf("User Attribute: %s\n", f.mapper.UserAttribute)
+	res += fmt.Sprintf("Value:          %s\n", f.mapper.Value)
+	res += fmt.Sprintf("Multivalued:    %t\n", f.mapper.Multivalued)
+	res += fmt.Sprintf("Targets:        %v", targets)
+	return res, nil
+}
+
+// ToJSON ...
+func (f *MapperInfoFormat) ToJSON() (string, error) {
+	bytes, err := json.Marshal(f.mapper)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
+
+// ToText ...
+func (f *MappersInfoFormat) ToText() (string, error) {
+	res := ""
+	for i, m := range f.mappers {
+		format := NewMapperInfoFormat(m)
+		msg, err := format.ToText()
+		if err != nil {
+			return "", err
+		}
+		res += msg
+		if i < len(f.mappers)-1 {
+			res += "\n---\n"
+		}
+	}
+	return res, nil
+}
+
+// ToJSON ...
+func (f *MappersInfoFormat) ToJSON() (string, error) {
+	bytes, err := json.Marshal(f.mappers)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
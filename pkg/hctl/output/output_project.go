@@ -37,6 +37,9 @@ func (f *ProjectInfoFormat) ToText() (string, error) {
 	res += fmt.Sprintf("Created Time:            %s\n", f.project.CreatedAt)
 	res += fmt.Sprintf("Access Token Life Span:  %d [sec]\n", f.project.TokenConfig.AccessTokenLifeSpan)
 	res += fmt.Sprintf("Refresh Token Life Span: %d [sec]\n", f.project.TokenConfig.RefreshTokenLifeSpan)
+	res += fmt.Sprintf("Refresh Grace Period:    %d [sec]\n", f.project.TokenConfig.RefreshTokenGracePeriod)
+	res += fmt.Sprintf("Offline Session Life Span:    %d [sec]\n", f.project.TokenConfig.OfflineSessionLifeSpan)
+	res += fmt.Sprintf("Offline Session Idle Timeout: %d [sec]\n", f.project.TokenConfig.OfflineSessionIdleTimeout)
 	res += fmt.Sprintf("Token Signing Algorithm: %s\n", f.project.TokenConfig.SigningAlgorithm)
 	res += fmt.Sprintf("Allow Grant Types:       %v\n", f.project.AllowGrantTypes)
 	res += fmt.Sprintf("Password Policies:\n")
@@ -50,6 +53,7 @@ func (f *ProjectInfoFormat) ToText() (string, error) {
 	res += fmt.Sprintf("Max Login Failure:       %d\n", f.project.UserLock.MaxLoginFailure)
 	res += fmt.Sprintf("Lock Duration:           %d [sec]\n", f.project.UserLock.LockDuration)
 	res += fmt.Sprintf("Failure Reset Time:      %d [sec]\n", f.project.UserLock.FailureResetTime)
+	res += fmt.Sprintf("Default Locale:          %s\n", f.project.DefaultLocale)
 
 	return res, nil
 }
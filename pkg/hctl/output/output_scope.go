@@ -0,0 +1,81 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+
+	clientscopeapi "github.com/sh-miyoshi/hekate/pkg/apihandler/admin/v1/clientscope"
+)
+
+// ScopeInfoFormat ...
+type ScopeInfoFormat struct {
+	scope *clientscopeapi.ClientScopeGetResponse
+}
+
+// ScopesInfoFormat ...
+type ScopesInfoFormat struct {
+	scopes []*clientscopeapi.ClientScopeGetResponse
+}
+
+// NewScopeInfoFormat ...
+func NewScopeInfoFormat(scope *clientscopeapi.ClientScopeGetResponse) *ScopeInfoFormat {
+	return &ScopeInfoFormat{
+		scope: scope,
+	}
+}
+
+// NewScopesInfoFormat ...
+func NewScopesInfoFormat(scopes []*clientscopeapi.ClientScopeGetResponse) *ScopesInfoFormat {
+	return &ScopesInfoFormat{
+		scopes: scopes,
+	}
+}
+
+// ToText ...
+func (f *ScopeInfoFormat) ToText() (string, error) {
+	res := fmt.Sprintf("Name:            %s\n", f.scope.Name)
+	res += fmt.Sprintf("Description:     %s\n", f.scope.Description)
+	res += fmt.Sprintf("CreatedAt:       %s\n", f.scope.CreatedAt)
+	res += fmt.Sprintf("Claims:          %v\n", f.scope.Claims)
+	res += fmt.Sprintf("RequiredRoles:   %v\n", f.scope.RequiredRoles)
+	res += "ProtocolMappers:"
+	for _, m := range f.scope.ProtocolMappers {
+		res += fmt.Sprintf("\n  - %s (%s): %s", m.Name, m.Type, m.ClaimName)
+	}
+	return res, nil
+}
+
+// ToJSON ...
+func (f *ScopeInfoFormat) ToJSON() (string, error) {
+	bytes, err := json.Marshal(f.scope)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
+
+// ToText ...
+func (f *ScopesInfoFormat) ToText() (string, error) {
+	res := ""
+	for i, scope := range f.scopes {
+		format := NewScopeInfoFormat(scope)
+		msg, err := format.ToText()
+		if err != nil {
+			return "", err
+		}
+		res += msg
+		if i < len(f.scopes)-1 {
+			res += "\n---\n"
+		}
+	}
+	return res, nil
+}
+
+// ToJSON ...
+func (f *ScopesInfoFormat) ToJSON() (string, error) {
+	bytes, err := json.Marshal(f.scopes)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
@@ -68,6 +68,33 @@ func ParsePolicies(policies []string) (projectapi.PasswordPolicy, error) {
 				return res, fmt.Errorf("please set string separated by semicolon for blackLists")
 			}
 			res.BlackList = strings.Split(val[1], ";")
+		case "maxAge":
+			if len(val) != 2 {
+				return res, fmt.Errorf("please set unsigned integer for maxAge")
+			}
+			v, err := strconv.ParseUint(val[1], 10, 64)
+			if err != nil {
+				return res, fmt.Errorf("please set unsigned integer for maxAge")
+			}
+			res.MaxAge = uint(v)
+		case "minAge":
+			if len(val) != 2 {
+				return res, fmt.Errorf("please set unsigned integer for minAge")
+			}
+			v, err := strconv.ParseUint(val[1], 10, 64)
+			if err != nil {
+				return res, fmt.Errorf("please set unsigned integer for minAge")
+			}
+			res.MinAge = uint(v)
+		case "historyCount":
+			if len(val) != 2 {
+				return res, fmt.Errorf("please set unsigned integer for historyCount")
+			}
+			v, err := strconv.ParseUint(val[1], 10, 64)
+			if err != nil {
+				return res, fmt.Errorf("please set unsigned integer for historyCount")
+			}
+			res.HistoryCount = uint(v)
 		default:
 			return res, fmt.Errorf("Invalid policy type %s", policy)
 		}
@@ -32,6 +32,15 @@ func TestParsePasswordPolicies(t *testing.T) {
 			policies: []string{"minLen=test"},
 			expectOK: false,
 		},
+		{
+			policies: []string{"maxAge=7776000", "minAge=86400", "historyCount=5"},
+			expect: projectapi.PasswordPolicy{
+				MaxAge:       7776000,
+				MinAge:       86400,
+				HistoryCount: 5,
+			},
+			expectOK: true,
+		},
 	}
 
 	for _, tc := range tt {
@@ -0,0 +1,144 @@
+package login
+
+import (
+	"crypto/subtle"
+	"sync"
+	"time"
+
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/util"
+)
+
+const (
+	otpCodeLength = 6
+
+	// codeResendInterval is the minimum interval to send the code again [sec]
+	codeResendInterval = 30
+	// maxCodeSendTimes is max number of the messages sent for a code
+	maxCodeSendTimes = 5
+	// maxCodeFailure is max number of wrong codes, the code is invalidated after that
+	maxCodeFailure = 5
+)
+
+// Channels to send the code
+const (
+	codeChannelMail = "mail"
+	codeChannelSMS  = "sms"
+)
+
+var (
+	// ErrCodeInvalid ...
+	ErrCodeInvalid = errors.New("Invalid code", "The code is invalid or expired")
+	// ErrCodeSendLimited ...
+	ErrCodeSendLimited = errors.New("Too many codes", "Too many codes are requested")
+)
+
+// codeSendLocks limit the number of messages sent to a user across login sessions
+// It protects the user from flooding, and bounds the guesses of the one-time code.
+// SMS is limited more strictly because it costs per message.
+var codeSendLocks = map[string]model.UserLock{
+	codeChannelMail: {
+		Enabled:          true,
+		MaxLoginFailure:  10,
+		LockDuration:     60 * 60,
+		FailureResetTime: 60 * 60,
+	},
+	codeChannelSMS: {
+		Enabled:          true,
+		MaxLoginFailure:  5,
+		LockDuration:     60 * 60,
+		FailureResetTime: 60 * 60,
+	},
+}
+
+var (
+	codeSendStates   = map[string]*model.LockState{}
+	codeSendStatesMu sync.Mutex
+)
+
+func codeSendLocked(channel, projectName, userID string) bool {
+	codeSendStatesMu.Lock()
+	defer codeSendStatesMu.Unlock()
+
+	state, ok := codeSendStates[channel+"/"+projectName+"/"+userID]
+	if !ok {
+		return false
+	}
+	return isLocked(*state, codeSendLocks[channel])
+}
+
+func codeSendCounted(channel, projectName, userID string) {
+	codeSendStatesMu.Lock()
+	defer codeSendStatesMu.Unlock()
+
+	// remove states which no longer affect the lock
+	old := time.Now().Add(-60 * 60 * time.Second)
+	for k, s := range codeSendStates {
+		if s.VerifyFailedTimes[len(s.VerifyFailedTimes)-1].Before(old) {
+			delete(codeSendStates, k)
+		}
+	}
+
+	key := channel + "/" + projectName + "/" + userID
+	state, ok := codeSendStates[key]
+	if !ok {
+		state = &model.LockState{}
+		codeSendStates[key] = state
+	}
+	inclementFailedNum(state, codeSendLocks[channel])
+}
+
+// prepareCode checks whether the code of the purpose can be sent to the user now
+// It resets c if the code is not for the same purpose and user.
+func prepareCode(c *model.OneTimeCode, channel, projectName, userID, purpose string) *errors.Error {
+	if c.Purpose == purpose && c.UserID == userID && c.SentTimes > 0 {
+		// resend the code in the same session
+		if c.SentTimes >= maxCodeSendTimes || time.Now().Before(c.SentAt.Add(codeResendInterval*time.Second)) {
+			return ErrCodeSendLimited
+		}
+	} else {
+		*c = model.OneTimeCode{
+			Purpose: purpose,
+			UserID:  userID,
+		}
+	}
+	if codeSendLocked(channel, projectName, userID) {
+		return ErrCodeSendLimited
+	}
+	return nil
+}
+
+// codeSent saves the code sent to the user in c
+func codeSent(c *model.OneTimeCode, channel, projectName, code string, lifeSpan uint) {
+	codeSendCounted(channel, projectName, c.UserID)
+
+	now := time.Now()
+	c.CodeHash = util.CreateHash(code)
+	c.ExpiresAt = now.Add(time.Duration(lifeSpan) * time.Second)
+	c.SentAt = now
+	c.SentTimes++
+	c.FailedTimes = 0
+}
+
+// VerifyCode verifies the code sent by email or SMS, and clears it if it is correct because it can be used only once
+// The failure is counted in c, so the caller must save c even if the verification failed.
+func VerifyCode(c *model.OneTimeCode, purpose, code string) *errors.Error {
+	if c.Purpose != purpose || c.CodeHash == "" {
+		return errors.Append(ErrCodeInvalid, "The %s code is not sent", purpose)
+	}
+	if time.Now().After(c.ExpiresAt) {
+		return errors.Append(ErrCodeInvalid, "The %s code is expired", purpose)
+	}
+	if c.FailedTimes >= maxCodeFailure {
+		return errors.Append(ErrCodeInvalid, "Too many failures of the %s code", purpose)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(util.CreateHash(code)), []byte(c.CodeHash)) != 1 {
+		c.FailedTimes++
+		return errors.Append(ErrCodeInvalid, "The %s code does not match", purpose)
+	}
+
+	*c = model.OneTimeCode{}
+	return nil
+}
@@ -0,0 +1,50 @@
+package login
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/util"
+)
+
+func TestVerifyMailCode(t *testing.T) {
+	newCode := func() model.OneTimeCode {
+		return model.OneTimeCode{
+			Purpose:   model.CodePurposeOTP,
+			UserID:    "user",
+			CodeHash:  util.CreateHash("123456"),
+			ExpiresAt: time.Now().Add(time.Minute),
+		}
+	}
+
+	c := newCode()
+	if err := VerifyCode(&c, model.CodePurposeMagicLink, "123456"); err == nil {
+		t.Errorf("VerifyCode with wrong purpose returns nil error")
+	}
+	if err := VerifyCode(&c, model.CodePurposeOTP, "000000"); err == nil {
+		t.Errorf("VerifyCode with wrong code returns nil error")
+	}
+	if c.FailedTimes != 1 {
+		t.Errorf("The failure is not counted: %d", c.FailedTimes)
+	}
+	if err := VerifyCode(&c, model.CodePurposeOTP, "123456"); err != nil {
+		t.Errorf("VerifyCode with correct code returns unexpected error: %v", err)
+	}
+	// the code can be used only once
+	if err := VerifyCode(&c, model.CodePurposeOTP, "123456"); err == nil {
+		t.Errorf("VerifyCode with used code returns nil error")
+	}
+
+	c = newCode()
+	c.ExpiresAt = time.Now().Add(-time.Second)
+	if err := VerifyCode(&c, model.CodePurposeOTP, "123456"); err == nil {
+		t.Errorf("VerifyCode with expired code returns nil error")
+	}
+
+	c = newCode()
+	c.FailedTimes = maxCodeFailure
+	if err := VerifyCode(&c, model.CodePurposeOTP, "123456"); err == nil {
+		t.Errorf("VerifyCode after too many failures returns nil error")
+	}
+}
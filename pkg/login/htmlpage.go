@@ -3,17 +3,20 @@ package login
 import (
 	"html/template"
 	"net/http"
+	neturl "net/url"
 
 	"github.com/sh-miyoshi/hekate/pkg/config"
+	"github.com/sh-miyoshi/hekate/pkg/db"
 	"github.com/sh-miyoshi/hekate/pkg/errors"
 	"github.com/sh-miyoshi/hekate/pkg/logger"
+	"github.com/stretchr/stew/slice"
 )
 
 // WriteUserLoginPage ...
-func WriteUserLoginPage(projectName, sessionID, errMsg, state string, w http.ResponseWriter) {
+func WriteUserLoginPage(projectName, sessionID, errMsg, state, locale string, w http.ResponseWriter) {
 	cfg := config.Get()
 
-	tpl, err := template.ParseFiles(cfg.LoginResource.IndexPage)
+	tpl, err := parseTemplate(cfg.LoginResource.IndexPage, locale)
 	if err != nil {
 		logger.Error("Failed to parse template: %v", err)
 		e := errors.ErrServerError
@@ -27,10 +30,19 @@ func WriteUserLoginPage(projectName, sessionID, errMsg, state string, w http.Res
 		url += "&state=" + state
 	}
 
+	magicLinkURL := ""
+	if prj, err := db.GetInst().ProjectGet(projectName); err == nil && prj.MailConfig.MagicLinkEnabled && MagicLinkAvailable() {
+		magicLinkURL = authnURL(projectName, "/authn/magiclink", sessionID, state)
+	}
+
 	d := map[string]string{
 		"URL":                url,
 		"StaticResourcePath": cfg.LoginStaticResourceURL + "/static",
-		"Error":              errMsg,
+		"Locale":             locale,
+		"Error":              translateError(locale, errMsg),
+		"WebAuthnOptionsURL": authnURL(projectName, "/authn/webauthn/options", sessionID, ""),
+		"WebAuthnURL":        authnURL(projectName, "/authn/webauthn", sessionID, state),
+		"MagicLinkURL":       magicLinkURL,
 	}
 
 	w.Header().Add("Content-Type", "text/html; charset=UTF-8")
@@ -38,10 +50,11 @@ func WriteUserLoginPage(projectName, sessionID, errMsg, state string, w http.Res
 }
 
 // WriteOTPVerifyPage ...
-func WriteOTPVerifyPage(projectName, sessionID, state string, w http.ResponseWriter) {
+// If trustDevice is true, the page has the checkbox to trust the browser and skip the second factor next time.
+func WriteOTPVerifyPage(projectName, sessionID, state, locale string, trustDevice bool, w http.ResponseWriter) {
 	cfg := config.Get()
 
-	tpl, err := template.ParseFiles(cfg.LoginResource.OTPVerifyPage)
+	tpl, err := parseTemplate(cfg.LoginResource.OTPVerifyPage, locale)
 	if err != nil {
 		logger.Error("Failed to parse template: %v", err)
 		e := errors.ErrServerError
@@ -55,9 +68,172 @@ func WriteOTPVerifyPage(projectName, sessionID, state string, w http.ResponseWri
 		url += "&state=" + state
 	}
 
+	d := map[string]string{
+		"StaticResourcePath":  cfg.LoginStaticResourceURL + "/static",
+		"Locale":              locale,
+		"URL":                 url,
+		"RecoveryCodeAllowed": "true",
+	}
+	if trustDevice {
+		d["TrustDeviceAllowed"] = "true"
+	}
+
+	w.Header().Add("Content-Type", "text/html; charset=UTF-8")
+	tpl.Execute(w, d)
+}
+
+// WriteMailOTPVerifyPage writes the OTP verify page for the code sent by email
+func WriteMailOTPVerifyPage(projectName, sessionID, errMsg, state, locale string, w http.ResponseWriter) {
+	cfg := config.Get()
+
+	tpl, err := parseTemplate(cfg.LoginResource.OTPVerifyPage, locale)
+	if err != nil {
+		logger.Error("Failed to parse template: %v", err)
+		e := errors.ErrServerError
+		e.SetDescription("User Login OTP Verify Page maybe broken")
+		errors.WriteToHTTP(w, e, 0, "")
+		return
+	}
+
 	d := map[string]string{
 		"StaticResourcePath": cfg.LoginStaticResourceURL + "/static",
-		"URL":                url,
+		"Locale":             locale,
+		"URL":                authnURL(projectName, "/authn/mailotp", sessionID, state),
+		"ResendURL":          authnURL(projectName, "/authn/mailotp/resend", sessionID, state),
+		"Message":            Translate(locale, "mailotp.sent"),
+		"Error":              translateError(locale, errMsg),
+	}
+
+	w.Header().Add("Content-Type", "text/html; charset=UTF-8")
+	tpl.Execute(w, d)
+}
+
+// WriteSMSOTPVerifyPage writes the page to verify the one-time code sent by SMS
+func WriteSMSOTPVerifyPage(projectName, sessionID, errMsg, state, locale string, w http.ResponseWriter) {
+	cfg := config.Get()
+
+	tpl, err := parseTemplate(cfg.LoginResource.SMSVerifyPage, locale)
+	if err != nil {
+		logger.Error("Failed to parse template: %v", err)
+		e := errors.ErrServerError
+		e.SetDescription("User Login SMS Verify Page maybe broken")
+		errors.WriteToHTTP(w, e, 0, "")
+		return
+	}
+
+	d := map[string]string{
+		"StaticResourcePath": cfg.LoginStaticResourceURL + "/static",
+		"Locale":             locale,
+		"URL":                authnURL(projectName, "/authn/smsotp", sessionID, state),
+		"ResendURL":          authnURL(projectName, "/authn/smsotp/resend", sessionID, state),
+		"Error":              translateError(locale, errMsg),
+	}
+
+	w.Header().Add("Content-Type", "text/html; charset=UTF-8")
+	tpl.Execute(w, d)
+}
+
+// WriteOTPEnrollPage writes the page to enroll TOTP when MFA is required but the user has no second factor
+// If recoveryCodes is not empty, it writes the page to show the issued recovery codes instead.
+func WriteOTPEnrollPage(projectName, sessionID, qrCode, secret string, recoveryCodes []string, errMsg, state, locale string, w http.ResponseWriter) {
+	cfg := config.Get()
+
+	tpl, err := parseTemplate(cfg.LoginResource.OTPEnrollPage, locale)
+	if err != nil {
+		logger.Error("Failed to parse template: %v", err)
+		e := errors.ErrServerError
+		e.SetDescription("User Login OTP Enroll Page maybe broken")
+		errors.WriteToHTTP(w, e, 0, "")
+		return
+	}
+
+	d := map[string]interface{}{
+		"StaticResourcePath": cfg.LoginStaticResourceURL + "/static",
+		"Locale":             locale,
+		"URL":                authnURL(projectName, "/authn/otpenroll", sessionID, state),
+		"ContinueURL":        authnURL(projectName, "/authn/otpenroll/continue", sessionID, state),
+		"QRCode":             template.URL("data:image/png;base64," + qrCode),
+		"Secret":             secret,
+		"RecoveryCodes":      recoveryCodes,
+		"Error":              translateError(locale, errMsg),
+	}
+
+	w.Header().Add("Content-Type", "text/html; charset=UTF-8")
+	tpl.Execute(w, d)
+}
+
+// WriteChangePasswordPage writes the page to change the expired or temporary password before the token is issued
+func WriteChangePasswordPage(projectName, sessionID, errMsg, state, locale string, w http.ResponseWriter) {
+	cfg := config.Get()
+
+	tpl, err := parseTemplate(cfg.LoginResource.ChangePasswordPage, locale)
+	if err != nil {
+		logger.Error("Failed to parse template: %v", err)
+		e := errors.ErrServerError
+		e.SetDescription("User Login Change Password Page maybe broken")
+		errors.WriteToHTTP(w, e, 0, "")
+		return
+	}
+
+	d := map[string]string{
+		"StaticResourcePath": cfg.LoginStaticResourceURL + "/static",
+		"Locale":             locale,
+		"URL":                ChangePasswordURL(projectName, sessionID, state),
+		"Error":              translateError(locale, errMsg),
+	}
+
+	w.Header().Add("Content-Type", "text/html; charset=UTF-8")
+	tpl.Execute(w, d)
+}
+
+// WriteMagicLinkPage writes the page to notify that the login link is sent by email
+// If token is not empty, it writes the page to confirm the login by the link instead.
+// The login is not finished by GET request of the link because mail scanners may open it.
+func WriteMagicLinkPage(projectName, sessionID, token, state, locale string, w http.ResponseWriter) {
+	cfg := config.Get()
+
+	tpl, err := parseTemplate(cfg.LoginResource.MagicLinkPage, locale)
+	if err != nil {
+		logger.Error("Failed to parse template: %v", err)
+		e := errors.ErrServerError
+		e.SetDescription("User Login Magic Link Page maybe broken")
+		errors.WriteToHTTP(w, e, 0, "")
+		return
+	}
+
+	d := map[string]string{
+		"StaticResourcePath": cfg.LoginStaticResourceURL + "/static",
+		"Locale":             locale,
+		"URL":                "",
+		"Token":              token,
+	}
+	if token != "" {
+		d["URL"] = authnURL(projectName, "/authn/magiclink/verify", sessionID, state)
+	}
+
+	w.Header().Add("Content-Type", "text/html; charset=UTF-8")
+	tpl.Execute(w, d)
+}
+
+// WriteWebAuthnPage ...
+func WriteWebAuthnPage(projectName, sessionID, errMsg, state, locale string, w http.ResponseWriter) {
+	cfg := config.Get()
+
+	tpl, err := parseTemplate(cfg.LoginResource.WebAuthnPage, locale)
+	if err != nil {
+		logger.Error("Failed to parse template: %v", err)
+		e := errors.ErrServerError
+		e.SetDescription("User Login WebAuthn Verify Page maybe broken")
+		errors.WriteToHTTP(w, e, 0, "")
+		return
+	}
+
+	d := map[string]string{
+		"StaticResourcePath": cfg.LoginStaticResourceURL + "/static",
+		"Locale":             locale,
+		"Error":              translateError(locale, errMsg),
+		"OptionsURL":         authnURL(projectName, "/authn/webauthn/options", sessionID, ""),
+		"URL":                authnURL(projectName, "/authn/webauthn", sessionID, state),
 	}
 
 	w.Header().Add("Content-Type", "text/html; charset=UTF-8")
@@ -65,10 +241,10 @@ func WriteOTPVerifyPage(projectName, sessionID, state string, w http.ResponseWri
 }
 
 // WriteConsentPage ...
-func WriteConsentPage(projectName, sessionID, state string, w http.ResponseWriter) {
+func WriteConsentPage(projectName, sessionID, state string, scopes []string, locale string, w http.ResponseWriter) {
 	cfg := config.Get()
 
-	tpl, err := template.ParseFiles(cfg.LoginResource.ConsentPage)
+	tpl, err := parseTemplate(cfg.LoginResource.ConsentPage, locale)
 	if err != nil {
 		logger.Error("Failed to parse template: %v", err)
 		e := errors.ErrServerError
@@ -82,9 +258,44 @@ func WriteConsentPage(projectName, sessionID, state string, w http.ResponseWrite
 		url += "&state=" + state
 	}
 
-	d := map[string]string{
+	// show descriptions of the requested scopes
+	all, e := db.GetInst().ClientScopeGetList(projectName, nil)
+	if e != nil {
+		errors.Print(errors.Append(e, "Failed to get client scopes"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, "")
+		return
+	}
+	descriptions := []string{}
+	for _, s := range all {
+		if slice.Contains(scopes, s.Name) && s.Description != "" {
+			descriptions = append(descriptions, s.Description)
+		}
+	}
+
+	// the permissions of the API resources are shown with the resource name
+	resources, e := db.GetInst().APIResourceGetList(projectName, nil)
+	if e != nil {
+		errors.Print(errors.Append(e, "Failed to get API resources"))
+		errors.WriteToHTTP(w, errors.ErrServerError, 0, "")
+		return
+	}
+	for _, r := range resources {
+		name := r.Name
+		if name == "" {
+			name = r.Identifier
+		}
+		for _, s := range r.Scopes {
+			if slice.Contains(scopes, s) {
+				descriptions = append(descriptions, name+": "+s)
+			}
+		}
+	}
+
+	d := map[string]interface{}{
 		"StaticResourcePath": cfg.LoginStaticResourceURL + "/static",
+		"Locale":             locale,
 		"URL":                url,
+		"Scopes":             descriptions,
 	}
 
 	w.Header().Add("Content-Type", "text/html; charset=UTF-8")
@@ -92,10 +303,11 @@ func WriteConsentPage(projectName, sessionID, state string, w http.ResponseWrite
 }
 
 // WriteDeviceLoginPage ...
-func WriteDeviceLoginPage(projectName, errMsg string, w http.ResponseWriter) {
+// userCode is set when the user opens verification_uri_complete, and the page is pre-filled with the code.
+func WriteDeviceLoginPage(projectName, userCode, errMsg, locale string, w http.ResponseWriter) {
 	cfg := config.Get()
 
-	tpl, err := template.ParseFiles(cfg.LoginResource.DeviceLoginPage)
+	tpl, err := parseTemplate(cfg.LoginResource.DeviceLoginPage, locale)
 	if err != nil {
 		logger.Error("Failed to parse template: %v", err)
 		e := errors.ErrServerError
@@ -105,10 +317,17 @@ func WriteDeviceLoginPage(projectName, errMsg string, w http.ResponseWriter) {
 	}
 
 	url := "/resource/project/" + projectName + "/deviceverify"
+	qrCodeURL := ""
+	if userCode != "" {
+		qrCodeURL = "/resource/project/" + projectName + "/deviceqrcode?user_code=" + neturl.QueryEscape(userCode)
+	}
 	d := map[string]string{
 		"StaticResourcePath": cfg.LoginStaticResourceURL + "/static",
-		"Error":              errMsg,
+		"Locale":             locale,
+		"Error":              translateError(locale, errMsg),
 		"URL":                url,
+		"UserCode":           userCode,
+		"QRCodeURL":          qrCodeURL,
 	}
 
 	w.Header().Add("Content-Type", "text/html; charset=UTF-8")
@@ -116,10 +335,10 @@ func WriteDeviceLoginPage(projectName, errMsg string, w http.ResponseWriter) {
 }
 
 // WriteDeviceLoginCompletePage ...
-func WriteDeviceLoginCompletePage(w http.ResponseWriter) {
+func WriteDeviceLoginCompletePage(locale string, w http.ResponseWriter) {
 	cfg := config.Get()
 
-	tpl, err := template.ParseFiles(cfg.LoginResource.DeviceLoginCompletePage)
+	tpl, err := parseTemplate(cfg.LoginResource.DeviceLoginCompletePage, locale)
 	if err != nil {
 		logger.Error("Failed to parse template: %v", err)
 		e := errors.ErrServerError
@@ -130,8 +349,29 @@ func WriteDeviceLoginCompletePage(w http.ResponseWriter) {
 
 	d := map[string]string{
 		"StaticResourcePath": cfg.LoginStaticResourceURL + "/static",
+		"Locale":             locale,
 	}
 
 	w.Header().Add("Content-Type", "text/html; charset=UTF-8")
 	tpl.Execute(w, d)
 }
+
+// ChangePasswordURL returns the URL of the page to change the password in the login session
+func ChangePasswordURL(projectName, sessionID, state string) string {
+	return authnURL(projectName, "/authn/changepassword", sessionID, state)
+}
+
+func authnURL(projectName, path, sessionID, state string) string {
+	url := "/authapi/v1/project/" + projectName + path + "?login_session_id=" + sessionID
+	if state != "" {
+		url += "&state=" + state
+	}
+	return url
+}
+
+func translateError(locale, errMsg string) string {
+	if errMsg == "" {
+		return ""
+	}
+	return Translate(locale, errMsg)
+}
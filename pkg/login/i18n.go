@@ -0,0 +1,298 @@
+package login
+
+import (
+	"html/template"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sh-miyoshi/hekate/pkg/config"
+	"github.com/sh-miyoshi/hekate/pkg/db"
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+)
+
+const (
+	localeCookieName   = "HEKATE_LOCALE"
+	localeCookieMaxAge = 365 * 24 * 60 * 60
+)
+
+// Message keys of server messages shown in login pages
+const (
+	// MsgInvalidUserOrPassword ...
+	MsgInvalidUserOrPassword = "login.invalid_user_or_password"
+	// MsgInvalidDeviceCode ...
+	MsgInvalidDeviceCode = "device.invalid_code"
+	// MsgDeviceVerifyLocked ...
+	MsgDeviceVerifyLocked = "device.too_many_attempts"
+	// MsgWebAuthnFailed ...
+	MsgWebAuthnFailed = "webauthn.failed"
+	// MsgCodeInvalid ...
+	MsgCodeInvalid = "code.invalid"
+	// MsgCodeSendLimited ...
+	MsgCodeSendLimited = "code.too_many_requests"
+	// MsgLoginBlocked ...
+	MsgLoginBlocked = "login.blocked"
+	// MsgTooManyAttempts ...
+	MsgTooManyAttempts = "login.too_many_attempts"
+	// MsgPasswordMismatch ...
+	MsgPasswordMismatch = "password.mismatch"
+	// MsgPasswordPolicyFailed ...
+	MsgPasswordPolicyFailed = "password.policy_failed"
+	// MsgPasswordReused ...
+	MsgPasswordReused = "password.reused"
+)
+
+// catalogs is a map of locale to messages
+var catalogs = map[string]map[string]string{
+	"en": {
+		"page.title":                     "Login",
+		"login.title":                    "LOGIN to Hekate",
+		"login.name":                     "Name",
+		"login.name_placeholder":         "user name(e.g. admin)",
+		"login.password":                 "Password",
+		"login.password_placeholder":     "password",
+		"login.submit":                   "Login",
+		"login.invalid_user_or_password": "invalid user name or password",
+		"login.blocked":                  "This login was blocked for security reasons. Please contact your administrator.",
+		"login.too_many_attempts":        "Too many failed attempts. Please try again later.",
+		"otp.code":                       "Onetime-Code",
+		"otp.recovery_hint":              "If you cannot use your authenticator app, enter one of your recovery codes instead.",
+		"otp.trust_device":               "Trust this browser and skip this step next time",
+		"otpenroll.title":                "Set up two-factor authentication",
+		"otpenroll.message":              "Two-factor authentication is required for your account. Scan the QR code with your authenticator app and enter the code shown in the app.",
+		"otpenroll.secret":               "If you cannot scan the QR code, enter this key manually:",
+		"otpenroll.recovery_codes":       "Two-factor authentication has been enabled. Save these recovery codes in a safe place. Each code can be used once if you lose your authenticator app.",
+		"otpenroll.continue":             "Continue",
+		"password.title":                 "Change password",
+		"password.message":               "Your password has expired or must be changed. Please set a new password to continue.",
+		"password.new":                   "New Password",
+		"password.confirm":               "Confirm Password",
+		"password.submit":                "Change",
+		"password.mismatch":              "The passwords do not match",
+		"password.policy_failed":         "The password does not satisfy the password policy",
+		"password.reused":                "The password was used recently. Please choose another one.",
+		"webauthn.title":                 "Security Key",
+		"webauthn.message":               "Use your security key or passkey to continue.",
+		"webauthn.submit":                "Use Security Key",
+		"webauthn.passkey":               "Login with a passkey",
+		"webauthn.failed":                "Failed to verify the security key",
+		"webauthn.not_supported":         "This browser does not support security keys",
+		"mailotp.sent":                   "A verification code has been sent to your email address.",
+		"mailotp.resend":                 "Resend code",
+		"smsotp.sent":                    "A verification code has been sent to your phone by SMS.",
+		"smsotp.resend":                  "Resend code",
+		"smsotp.message":                 "%s is your verification code for %s. It expires in %d minutes.",
+		"magiclink.request":              "Email me a login link",
+		"magiclink.name_placeholder":     "user name or email address",
+		"magiclink.title":                "Check your email",
+		"magiclink.sent":                 "If the account exists, a login link has been sent to its email address. Please open the link in this browser.",
+		"magiclink.confirm":              "Continue to login with the link sent by email.",
+		"magiclink.submit":               "Continue",
+		"code.invalid":                   "The code is invalid or expired",
+		"code.too_many_requests":         "Too many requests. Please try again later.",
+		"consent.title":                  "Grant Access",
+		"consent.message":                "Do you grant these access privileges?",
+		"consent.yes":                    "Yes",
+		"consent.no":                     "No",
+		"device.title":                   "Device Login",
+		"device.code":                    "Code",
+		"device.code_placeholder":        "Enter your code",
+		"device.continue":                "Continue",
+		"device.invalid_code":            "The code is invalid",
+		"device.too_many_attempts":       "Too many invalid codes. Please try again later.",
+		"device.scan_qrcode":             "Scan the QR code to continue on another device",
+		"device.complete":                "Successfully logged in.",
+		"device.back_to_device":          "Please back to your device.",
+	},
+	"ja": {
+		"page.title":                     "ログイン",
+		"login.title":                    "Hekate にログイン",
+		"login.name":                     "ユーザー名",
+		"login.name_placeholder":         "ユーザー名(例: admin)",
+		"login.password":                 "パスワード",
+		"login.password_placeholder":     "パスワード",
+		"login.submit":                   "ログイン",
+		"login.invalid_user_or_password": "ユーザー名またはパスワードが正しくありません",
+		"login.blocked":                  "セキュリティ上の理由によりログインがブロックされました。管理者にお問い合わせください。",
+		"login.too_many_attempts":        "試行回数が多すぎます。しばらくしてから再度お試しください。",
+		"otp.code":                       "ワンタイムコード",
+		"otp.recovery_hint":              "認証アプリを使用できない場合は、代わりにリカバリーコードを入力してください。",
+		"otp.trust_device":               "このブラウザを信頼し、次回からこの手順を省略する",
+		"otpenroll.title":                "2段階認証の設定",
+		"otpenroll.message":              "このアカウントでは2段階認証が必要です。認証アプリでQRコードを読み取り、アプリに表示されたコードを入力してください。",
+		"otpenroll.secret":               "QRコードを読み取れない場合は、次のキーを手動で入力してください:",
+		"otpenroll.recovery_codes":       "2段階認証が有効になりました。以下のリカバリーコードを安全な場所に保管してください。認証アプリを使用できなくなった場合に、各コードを1回だけ使用できます。",
+		"otpenroll.continue":             "続行",
+		"password.title":                 "パスワードの変更",
+		"password.message":               "パスワードの有効期限が切れているか、変更が必要です。続行するには新しいパスワードを設定してください。",
+		"password.new":                   "新しいパスワード",
+		"password.confirm":               "パスワードの確認",
+		"password.submit":                "変更",
+		"password.mismatch":              "パスワードが一致しません",
+		"password.policy_failed":         "パスワードがパスワードポリシーを満たしていません",
+		"password.reused":                "最近使用したパスワードです。別のパスワードを指定してください。",
+		"webauthn.title":                 "セキュリティキー",
+		"webauthn.message":               "セキュリティキーまたはパスキーを使用して続行してください。",
+		"webauthn.submit":                "セキュリティキーを使用",
+		"webauthn.passkey":               "パスキーでログイン",
+		"webauthn.failed":                "セキュリティキーの確認に失敗しました",
+		"webauthn.not_supported":         "このブラウザはセキュリティキーに対応していません",
+		"mailotp.sent":                   "メールアドレスに確認コードを送信しました。",
+		"mailotp.resend":                 "コードを再送信",
+		"smsotp.sent":                    "SMSで携帯電話に確認コードを送信しました。",
+		"smsotp.resend":                  "コードを再送信",
+		"smsotp.message":                 "%[2]s の確認コードは %[1]s です。有効期限は%[3]d分です。",
+		"magiclink.request":              "ログインリンクをメールで受け取る",
+		"magiclink.name_placeholder":     "ユーザー名またはメールアドレス",
+		"magiclink.title":                "メールを確認してください",
+		"magiclink.sent":                 "アカウントが存在する場合、そのメールアドレスにログインリンクを送信しました。このブラウザでリンクを開いてください。",
+		"magiclink.confirm":              "メールで受け取ったリンクでログインを続行します。",
+		"magiclink.submit":               "続行",
+		"code.invalid":                   "コードが正しくないか、有効期限が切れています",
+		"code.too_many_requests":         "リクエストが多すぎます。しばらくしてから再度お試しください。",
+		"consent.title":                  "アクセスの許可",
+		"consent.message":                "以下のアクセスを許可しますか?",
+		"consent.yes":                    "許可する",
+		"consent.no":                     "許可しない",
+		"device.title":                   "デバイスログイン",
+		"device.code":                    "コード",
+		"device.code_placeholder":        "コードを入力してください",
+		"device.continue":                "次へ",
+		"device.invalid_code":            "コードが正しくありません",
+		"device.too_many_attempts":       "コードの入力に続けて失敗しました。しばらくしてから再度お試しください。",
+		"device.scan_qrcode":             "QR コードを読み取ると別の端末で続行できます",
+		"device.complete":                "ログインに成功しました。",
+		"device.back_to_device":          "デバイスに戻ってください。",
+	},
+}
+
+// SupportedLocales returns a list of locales which have the message catalog
+func SupportedLocales() []string {
+	res := []string{}
+	for l := range catalogs {
+		res = append(res, l)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Translate returns the message of the key in the locale.
+// If the message is not found, it falls back to the default locale and then the key itself.
+func Translate(locale, key string) string {
+	if msg, ok := catalogs[locale][key]; ok {
+		return msg
+	}
+	if msg, ok := catalogs[model.DefaultLocale][key]; ok {
+		return msg
+	}
+	return key
+}
+
+// NegotiateLocale decides the locale of login pages.
+// The priority is ui_locales parameter, locale cookie, Accept-Language header and default locale of the project.
+func NegotiateLocale(r *http.Request, projectName string, uiLocales []string) string {
+	candidates := append([]string{}, uiLocales...)
+	if cookie, err := r.Cookie(localeCookieName); err == nil {
+		candidates = append(candidates, cookie.Value)
+	}
+	candidates = append(candidates, parseAcceptLanguage(r.Header.Get("Accept-Language"))...)
+
+	for _, c := range candidates {
+		if l := matchLocale(c); l != "" {
+			return l
+		}
+	}
+
+	prj, err := db.GetInst().ProjectGet(projectName)
+	if err != nil {
+		errors.PrintAsInfo(errors.Append(err, "Failed to get default locale of project %s", projectName))
+		return model.DefaultLocale
+	}
+	if l := matchLocale(prj.DefaultLocale); l != "" {
+		return l
+	}
+	return model.DefaultLocale
+}
+
+// SetLocaleCookie saves the locale selected by the user
+func SetLocaleCookie(w http.ResponseWriter, locale string) {
+	cookie := &http.Cookie{
+		Name:     localeCookieName,
+		Value:    locale,
+		Path:     "/",
+		MaxAge:   localeCookieMaxAge,
+		Secure:   config.Get().HTTPSConfig.Enabled,
+		HttpOnly: true,
+	}
+	http.SetCookie(w, cookie)
+}
+
+// matchLocale returns a supported locale matched to the language tag.
+// It returns empty string if no locale is matched.
+func matchLocale(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if _, ok := catalogs[tag]; ok {
+		return tag
+	}
+
+	// e.g. ja-JP -> ja
+	base := strings.Split(tag, "-")[0]
+	if _, ok := catalogs[base]; ok {
+		return base
+	}
+	return ""
+}
+
+// parseAcceptLanguage returns language tags in the header ordered by the quality value
+//   ref. https://tools.ietf.org/html/rfc7231#section-5.3.5
+func parseAcceptLanguage(header string) []string {
+	type entry struct {
+		tag string
+		q   float64
+	}
+
+	entries := []entry{}
+	for _, v := range strings.Split(header, ",") {
+		params := strings.Split(strings.TrimSpace(v), ";")
+		tag := strings.TrimSpace(params[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, p := range params[1:] {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "q=") {
+				if f, err := strconv.ParseFloat(strings.TrimPrefix(p, "q="), 64); err == nil {
+					q = f
+				}
+			}
+		}
+		if q > 0 {
+			entries = append(entries, entry{tag: tag, q: q})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].q > entries[j].q
+	})
+
+	res := []string{}
+	for _, e := range entries {
+		res = append(res, e.tag)
+	}
+	return res
+}
+
+// parseTemplate parses the login page with the translate function T
+func parseTemplate(file string, locale string) (*template.Template, error) {
+	funcs := template.FuncMap{
+		"T": func(key string) string {
+			return Translate(locale, key)
+		},
+	}
+	return template.New(filepath.Base(file)).Funcs(funcs).ParseFiles(file)
+}
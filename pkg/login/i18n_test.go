@@ -0,0 +1,45 @@
+package login
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tt := []struct {
+		header string
+		expect []string
+	}{
+		{"", []string{}},
+		{"ja", []string{"ja"}},
+		{"en-US,en;q=0.9,ja;q=0.8", []string{"en-US", "en", "ja"}},
+		{"en;q=0.5, ja-JP", []string{"ja-JP", "en"}},
+		{"*;q=0.1, fr;q=0", []string{}},
+	}
+
+	for _, tc := range tt {
+		res := parseAcceptLanguage(tc.header)
+		if !reflect.DeepEqual(res, tc.expect) {
+			t.Errorf("parseAcceptLanguage(%s) expects %v, but got %v", tc.header, tc.expect, res)
+		}
+	}
+}
+
+func TestMatchLocale(t *testing.T) {
+	tt := []struct {
+		tag    string
+		expect string
+	}{
+		{"en", "en"},
+		{"ja-JP", "ja"},
+		{"JA", "ja"},
+		{"fr", ""},
+	}
+
+	for _, tc := range tt {
+		res := matchLocale(tc.tag)
+		if res != tc.expect {
+			t.Errorf("matchLocale(%s) expects %s, but got %s", tc.tag, tc.expect, res)
+		}
+	}
+}
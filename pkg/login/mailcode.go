@@ -0,0 +1,74 @@
+package login
+
+import (
+	"net/url"
+
+	"github.com/sh-miyoshi/hekate/pkg/config"
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/mail"
+	"github.com/sh-miyoshi/hekate/pkg/util"
+)
+
+const magicLinkLength = 43
+
+var (
+	// ErrNoMailAddress ...
+	ErrNoMailAddress = errors.New("No mail address", "The user does not have mail address")
+)
+
+// MailAvailable returns true if the server can send mail
+func MailAvailable() bool {
+	return mail.GetInst().Enabled()
+}
+
+// MagicLinkAvailable returns true if the server can send the login link by email
+// The link is not created from the request, so HEKATE_SERVER_ADDR is required.
+func MagicLinkAvailable() bool {
+	return MailAvailable() && config.GetExternalServerAddr() != ""
+}
+
+// SendMailCode generates a new code of the purpose, and sends it to the user by email
+// The code is saved to c, so the caller must save the login session or the user which has c.
+// In the magic link, the code is added to linkURL as the token parameter.
+func SendMailCode(prj *model.ProjectInfo, user *model.UserInfo, c *model.OneTimeCode, purpose, linkURL, locale string) *errors.Error {
+	if user.EMail == "" {
+		return ErrNoMailAddress
+	}
+
+	if err := prepareCode(c, codeChannelMail, prj.Name, user.ID, purpose); err != nil {
+		return err
+	}
+
+	lifeSpan := prj.MailConfig.GetCodeLifeSpan()
+	data := &mail.TemplateData{
+		UserName:    user.Name,
+		ProjectName: prj.Name,
+		ExpiresIn:   (lifeSpan + 59) / 60,
+	}
+
+	var code string
+	tplType := model.MailTemplateEMailOTP
+	if purpose == model.CodePurposeMagicLink {
+		code = util.RandomString(magicLinkLength, util.CharTypeDigit|util.CharTypeLower|util.CharTypeUpper)
+		u, err := url.Parse(linkURL)
+		if err != nil {
+			return errors.New("Internal server error", "Failed to parse login link %s: %v", linkURL, err)
+		}
+		q := u.Query()
+		q.Set("token", code)
+		u.RawQuery = q.Encode()
+		data.URL = u.String()
+		tplType = model.MailTemplateMagicLink
+	} else {
+		code = util.RandomString(otpCodeLength, util.CharTypeDigit)
+		data.Code = code
+	}
+
+	if err := mail.GetInst().SendTemplate(prj, tplType, user.EMail, locale, data); err != nil {
+		return errors.Append(err, "Failed to send %s code", purpose)
+	}
+	codeSent(c, codeChannelMail, prj.Name, code, lifeSpan)
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package login
+
+import (
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/oidc/token"
+	"github.com/sh-miyoshi/hekate/pkg/role"
+	"github.com/stretchr/stew/slice"
+)
+
+var (
+	// ErrMFARequired ...
+	ErrMFARequired = errors.New("MFA required", "Multi-factor authentication is required")
+)
+
+// MFARequired returns true if the user must use the second factor by the MFA policy of the project
+// The users who can write the project always require MFA by the compliance rule.
+// The write-cluster role is also included because it allows to write all projects.
+func MFARequired(prj *model.ProjectInfo, usr *model.UserInfo) bool {
+	if role.Authorize(usr.SystemRoles, role.ResProject, role.TypeWrite) || role.Authorize(usr.SystemRoles, role.ResCluster, role.TypeWrite) {
+		return true
+	}
+
+	switch prj.MFAPolicy.Mode {
+	case model.MFAModeRequired:
+		return true
+	case model.MFAModeRole:
+		for _, r := range usr.SystemRoles {
+			if slice.Contains(prj.MFAPolicy.SystemRoles, r) {
+				return true
+			}
+		}
+		for _, r := range usr.CustomRoles {
+			if slice.Contains(prj.MFAPolicy.CustomRoles, r) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SecondFactorEnabled returns true if the second factor of the user is verified at the login
+func SecondFactorEnabled(prj *model.ProjectInfo, usr *model.UserInfo) bool {
+	return prj.MFAPolicy.Mode != model.MFAModeOff || MFARequired(prj, usr)
+}
+
+// MFASatisfied returns true if the authentication methods consist of multiple factors
+// The second factor skipped in the trusted device also satisfies the MFA policy, but not the requested acr.
+func MFASatisfied(authMethods []string) bool {
+	level := token.ACRLevel(authMethods)
+	if level == token.ACRSingleFactor && slice.Contains(authMethods, model.AuthMethodTrustedDevice) {
+		return true
+	}
+	return level == token.ACRMultiFactor
+}
+
+// CheckMFA returns ErrMFARequired if the user must use MFA but the authentication methods are not enough
+func CheckMFA(prj *model.ProjectInfo, usr *model.UserInfo, authMethods []string) *errors.Error {
+	if MFARequired(prj, usr) && !MFASatisfied(authMethods) {
+		return errors.Append(ErrMFARequired, "User %s is authenticated only by %v", usr.ID, authMethods)
+	}
+	return nil
+}
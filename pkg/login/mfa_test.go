@@ -0,0 +1,77 @@
+package login
+
+import (
+	"testing"
+
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+)
+
+func TestMFARequired(t *testing.T) {
+	tt := []struct {
+		Name        string
+		Policy      model.MFAPolicy
+		SystemRoles []string
+		CustomRoles []string
+		Expect      bool
+	}{
+		{Name: "default", Policy: model.MFAPolicy{}, Expect: false},
+		{Name: "optional", Policy: model.MFAPolicy{Mode: model.MFAModeOptional}, Expect: false},
+		{Name: "required", Policy: model.MFAPolicy{Mode: model.MFAModeRequired}, Expect: true},
+		{Name: "off but write-project", Policy: model.MFAPolicy{Mode: model.MFAModeOff}, SystemRoles: []string{"write-project"}, Expect: true},
+		{Name: "off but write-cluster", Policy: model.MFAPolicy{Mode: model.MFAModeOff}, SystemRoles: []string{"read-cluster", "write-cluster"}, Expect: true},
+		{Name: "default but write-project", Policy: model.MFAPolicy{}, SystemRoles: []string{"read-project", "write-project"}, Expect: true},
+		{
+			Name:        "role mode without the role",
+			Policy:      model.MFAPolicy{Mode: model.MFAModeRole, SystemRoles: []string{"read-cluster"}, CustomRoles: []string{"role1"}},
+			SystemRoles: []string{"read-project"},
+			CustomRoles: []string{"role2"},
+			Expect:      false,
+		},
+		{
+			Name:        "role mode with the system role",
+			Policy:      model.MFAPolicy{Mode: model.MFAModeRole, SystemRoles: []string{"read-cluster"}},
+			SystemRoles: []string{"read-cluster"},
+			Expect:      true,
+		},
+		{
+			Name:        "role mode with the custom role",
+			Policy:      model.MFAPolicy{Mode: model.MFAModeRole, CustomRoles: []string{"role1"}},
+			CustomRoles: []string{"role2", "role1"},
+			Expect:      true,
+		},
+		{
+			Name:        "roles are ignored in optional mode",
+			Policy:      model.MFAPolicy{Mode: model.MFAModeOptional, CustomRoles: []string{"role1"}},
+			CustomRoles: []string{"role1"},
+			Expect:      false,
+		},
+	}
+
+	for _, tc := range tt {
+		prj := &model.ProjectInfo{MFAPolicy: tc.Policy}
+		usr := &model.UserInfo{SystemRoles: tc.SystemRoles, CustomRoles: tc.CustomRoles}
+		if res := MFARequired(prj, usr); res != tc.Expect {
+			t.Errorf("Test %s: MFARequired returns %v, but want %v", tc.Name, res, tc.Expect)
+		}
+	}
+}
+
+func TestMFASatisfied(t *testing.T) {
+	tt := []struct {
+		AuthMethods []string
+		Expect      bool
+	}{
+		{AuthMethods: []string{model.AuthMethodPassword}, Expect: false},
+		{AuthMethods: []string{model.AuthMethodEMail}, Expect: false},
+		{AuthMethods: []string{model.AuthMethodPassword, model.AuthMethodOTP}, Expect: true},
+		{AuthMethods: []string{model.AuthMethodWebAuthn, model.AuthMethodMFA}, Expect: true},
+		{AuthMethods: []string{model.AuthMethodPassword, model.AuthMethodTrustedDevice}, Expect: true},
+		{AuthMethods: []string{model.AuthMethodTrustedDevice}, Expect: false},
+	}
+
+	for _, tc := range tt {
+		if res := MFASatisfied(tc.AuthMethods); res != tc.Expect {
+			t.Errorf("MFASatisfied(%v) returns %v, but want %v", tc.AuthMethods, res, tc.Expect)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package login
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sh-miyoshi/hekate/pkg/audit"
+	"github.com/sh-miyoshi/hekate/pkg/db"
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/geoip"
+	"github.com/sh-miyoshi/hekate/pkg/logger"
+	"github.com/sh-miyoshi/hekate/pkg/util"
+)
+
+var (
+	// ErrLoginBlocked ...
+	ErrLoginBlocked = errors.New("Login blocked", "Login is blocked by the risk policy")
+
+	// riskActionLevel is a severity of the risk action
+	riskActionLevel = map[string]int{
+		model.RiskActionNotify: 1,
+		model.RiskActionMFA:    2,
+		model.RiskActionBlock:  3,
+	}
+)
+
+// RiskDecision is a result of the risk evaluation of the login
+type RiskDecision struct {
+	// Action is the most severe action of the matched rules, or empty if no rule matches
+	Action string
+	// Rules are the types of the matched rules
+	Rules []string
+}
+
+// EvaluateRisk compares the login with the recent successful logins in the history
+// The rules of the first-seen values do not match if the history has nothing to compare with, e.g. the first login.
+func EvaluateRisk(policy *model.RiskPolicy, history []model.LoginRecord, current *model.LoginRecord) *RiskDecision {
+	res := &RiskDecision{
+		Rules: []string{},
+	}
+	for _, rule := range policy.Rules {
+		if !matchRiskRule(&rule, history, current) {
+			continue
+		}
+		res.Rules = append(res.Rules, rule.Type)
+		if riskActionLevel[rule.Action] > riskActionLevel[res.Action] {
+			res.Action = rule.Action
+		}
+	}
+	return res
+}
+
+// NewLoginRecord returns the login record of the request
+func NewLoginRecord(r *http.Request, now time.Time) *model.LoginRecord {
+	ip := util.ClientIP(r)
+	return &model.LoginRecord{
+		IP:        ip,
+		UserAgent: r.UserAgent(),
+		Country:   geoip.GetInst().Country(ip),
+		Time:      now,
+	}
+}
+
+// CheckLoginRisk evaluates the risk of the login request by the risk policy of the project
+// The decision is recorded in audit if any rule matches.
+// It returns ErrLoginBlocked if the matched rule blocks the login.
+func CheckLoginRisk(prj *model.ProjectInfo, usr *model.UserInfo, r *http.Request) (*RiskDecision, *errors.Error) {
+	now := time.Now()
+	current := NewLoginRecord(r, now)
+	res := EvaluateRisk(&prj.RiskPolicy, usr.LoginHistory, current)
+	if res.Action == "" {
+		return res, nil
+	}
+
+	msg := fmt.Sprintf("Login of user %s from %s matched risk rules %v, action: %s", usr.ID, current.IP, res.Rules, res.Action)
+	if err := audit.GetInst().Save(prj.Name, now, "LOGIN_RISK", r.Method, r.URL.String(), msg); err != nil {
+		errors.Print(errors.Append(err, "Failed to save audit event"))
+	}
+
+	if res.Action == model.RiskActionBlock {
+		return res, errors.Append(ErrLoginBlocked, msg)
+	}
+	logger.Info(msg)
+	return res, nil
+}
+
+// RecordLogin adds the successful login to the history of the user
+// The failure is only logged because the login itself has already succeeded.
+func RecordLogin(projectName string, userID string, r *http.Request) {
+	if err := db.GetInst().LoginHistoryAdd(projectName, userID, NewLoginRecord(r, time.Now())); err != nil {
+		errors.Print(errors.Append(err, "Failed to record login history of user %s", userID))
+	}
+}
+
+func matchRiskRule(rule *model.RiskRule, history []model.LoginRecord, current *model.LoginRecord) bool {
+	switch rule.Type {
+	case model.RiskRuleNewDevice:
+		return firstSeen(history, current, func(h *model.LoginRecord) string { return h.UserAgent })
+	case model.RiskRuleNewIP:
+		return firstSeen(history, current, func(h *model.LoginRecord) string { return h.IP })
+	case model.RiskRuleNewCountry:
+		return firstSeen(history, current, func(h *model.LoginRecord) string { return h.Country })
+	case model.RiskRuleIPVelocity:
+		since := current.Time.Add(-time.Duration(rule.Window) * time.Second)
+		ips := map[string]bool{
+			current.IP: true,
+		}
+		for _, h := range history {
+			if h.Time.After(since) {
+				ips[h.IP] = true
+			}
+		}
+		return uint(len(ips)) > rule.Threshold
+	}
+	return false
+}
+
+// firstSeen returns true if the value of the current login is not found in the history
+// Empty values are not compared, e.g. the country which GeoIP does not know.
+func firstSeen(history []model.LoginRecord, current *model.LoginRecord, value func(*model.LoginRecord) string) bool {
+	v := value(current)
+	if v == "" {
+		return false
+	}
+
+	compared := false
+	for i := range history {
+		hv := value(&history[i])
+		if hv == "" {
+			continue
+		}
+		if hv == v {
+			return false
+		}
+		compared = true
+	}
+	return compared
+}
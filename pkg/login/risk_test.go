@@ -0,0 +1,114 @@
+package login
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+)
+
+func TestEvaluateRisk(t *testing.T) {
+	now := time.Now()
+	history := []model.LoginRecord{
+		{IP: "192.0.2.1", UserAgent: "browser1", Country: "JP", Time: now.Add(-2 * time.Hour)},
+		{IP: "192.0.2.2", UserAgent: "browser1", Time: now.Add(-30 * time.Minute)},
+		{IP: "192.0.2.3", UserAgent: "browser2", Country: "JP", Time: now.Add(-10 * time.Minute)},
+	}
+	velocity := model.RiskRule{Type: model.RiskRuleIPVelocity, Action: model.RiskActionBlock, Threshold: 3, Window: 60 * 60}
+
+	tt := []struct {
+		Name         string
+		Rules        []model.RiskRule
+		History      []model.LoginRecord
+		Current      model.LoginRecord
+		ExpectAction string
+		ExpectRules  int
+	}{
+		{
+			Name:         "no rules",
+			History:      history,
+			Current:      model.LoginRecord{IP: "198.51.100.1", UserAgent: "browser3", Time: now},
+			ExpectAction: "",
+			ExpectRules:  0,
+		},
+		{
+			Name:         "known device",
+			Rules:        []model.RiskRule{{Type: model.RiskRuleNewDevice, Action: model.RiskActionMFA}},
+			History:      history,
+			Current:      model.LoginRecord{IP: "198.51.100.1", UserAgent: "browser2", Time: now},
+			ExpectAction: "",
+			ExpectRules:  0,
+		},
+		{
+			Name:         "new device",
+			Rules:        []model.RiskRule{{Type: model.RiskRuleNewDevice, Action: model.RiskActionMFA}},
+			History:      history,
+			Current:      model.LoginRecord{IP: "192.0.2.1", UserAgent: "browser3", Time: now},
+			ExpectAction: model.RiskActionMFA,
+			ExpectRules:  1,
+		},
+		{
+			Name:         "first login has nothing to compare",
+			Rules:        []model.RiskRule{{Type: model.RiskRuleNewDevice, Action: model.RiskActionBlock}, {Type: model.RiskRuleNewIP, Action: model.RiskActionBlock}},
+			Current:      model.LoginRecord{IP: "192.0.2.1", UserAgent: "browser3", Time: now},
+			ExpectAction: "",
+			ExpectRules:  0,
+		},
+		{
+			Name:         "most severe action is taken",
+			Rules:        []model.RiskRule{{Type: model.RiskRuleNewIP, Action: model.RiskActionNotify}, {Type: model.RiskRuleNewDevice, Action: model.RiskActionMFA}},
+			History:      history,
+			Current:      model.LoginRecord{IP: "198.51.100.1", UserAgent: "browser3", Time: now},
+			ExpectAction: model.RiskActionMFA,
+			ExpectRules:  2,
+		},
+		{
+			Name:         "new country",
+			Rules:        []model.RiskRule{{Type: model.RiskRuleNewCountry, Action: model.RiskActionNotify}},
+			History:      history,
+			Current:      model.LoginRecord{IP: "198.51.100.1", UserAgent: "browser1", Country: "US", Time: now},
+			ExpectAction: model.RiskActionNotify,
+			ExpectRules:  1,
+		},
+		{
+			Name:         "unknown country is not compared",
+			Rules:        []model.RiskRule{{Type: model.RiskRuleNewCountry, Action: model.RiskActionNotify}},
+			History:      history,
+			Current:      model.LoginRecord{IP: "198.51.100.1", UserAgent: "browser1", Time: now},
+			ExpectAction: "",
+			ExpectRules:  0,
+		},
+		{
+			Name:         "distinct IPs within the threshold",
+			Rules:        []model.RiskRule{velocity},
+			History:      history,
+			Current:      model.LoginRecord{IP: "192.0.2.3", UserAgent: "browser1", Time: now},
+			ExpectAction: "",
+			ExpectRules:  0,
+		},
+		{
+			Name:         "too many distinct IPs in the window",
+			Rules:        []model.RiskRule{{Type: model.RiskRuleIPVelocity, Action: model.RiskActionBlock, Threshold: 2, Window: 60 * 60}},
+			History:      history,
+			Current:      model.LoginRecord{IP: "198.51.100.1", UserAgent: "browser1", Time: now},
+			ExpectAction: model.RiskActionBlock,
+			ExpectRules:  1,
+		},
+		{
+			Name:         "old logins are out of the window",
+			Rules:        []model.RiskRule{{Type: model.RiskRuleIPVelocity, Action: model.RiskActionBlock, Threshold: 2, Window: 20 * 60}},
+			History:      history,
+			Current:      model.LoginRecord{IP: "198.51.100.1", UserAgent: "browser1", Time: now},
+			ExpectAction: "",
+			ExpectRules:  0,
+		},
+	}
+
+	for _, tc := range tt {
+		policy := &model.RiskPolicy{Rules: tc.Rules}
+		res := EvaluateRisk(policy, tc.History, &tc.Current)
+		if res.Action != tc.ExpectAction || len(res.Rules) != tc.ExpectRules {
+			t.Errorf("Test %s: EvaluateRisk returns action %q with rules %v, but want action %q with %d rules", tc.Name, res.Action, res.Rules, tc.ExpectAction, tc.ExpectRules)
+		}
+	}
+}
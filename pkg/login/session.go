@@ -29,6 +29,10 @@ func StartLoginSession(projectName string, req *oidc.AuthRequest) (string, *erro
 		CodeChallenge:       req.CodeChallenge,
 		CodeChallengeMethod: req.CodeChallengeMethod,
 		Scopes:              strings.Split(req.Scope, " "),
+		Claims:              req.Claims,
+		ACRValues:           req.ACRValues,
+		UILocales:           req.UILocales,
+		Resources:           req.Resources,
 	}
 	// *) userID, code will be set in after
 
@@ -51,5 +55,10 @@ func VerifySession(projectName string, sessionID string) (*model.LoginSession, *
 		return nil, errors.ErrSessionExpired
 	}
 
+	// the login session was already finished by the token request
+	if s.CodeRedeemed {
+		return nil, errors.ErrSessionExpired
+	}
+
 	return s, nil
 }
@@ -0,0 +1,44 @@
+package login
+
+import (
+	"fmt"
+
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/sms"
+	"github.com/sh-miyoshi/hekate/pkg/util"
+)
+
+var (
+	// ErrNoPhoneNumber ...
+	ErrNoPhoneNumber = errors.New("No phone number", "The user does not have phone number")
+)
+
+// SMSAvailable returns true if the server can send SMS
+func SMSAvailable() bool {
+	return sms.GetInst().Enabled()
+}
+
+// SendSMSCode generates a new one-time code of the purpose, and sends it to the phone number of the user
+// The code is saved to c, so the caller must save the login session or the user which has c.
+func SendSMSCode(prj *model.ProjectInfo, user *model.UserInfo, c *model.OneTimeCode, purpose, locale string) *errors.Error {
+	if user.PhoneNumber == "" {
+		return ErrNoPhoneNumber
+	}
+
+	if err := prepareCode(c, codeChannelSMS, prj.Name, user.ID, purpose); err != nil {
+		return err
+	}
+
+	lifeSpan := prj.SMSConfig.GetCodeLifeSpan()
+	code := util.RandomString(otpCodeLength, util.CharTypeDigit)
+	// the message is short and plain text because feature phones may not show long or rich messages
+	body := fmt.Sprintf(Translate(locale, "smsotp.message"), code, prj.Name, (lifeSpan+59)/60)
+
+	if err := sms.GetInst().Send(user.PhoneNumber, body); err != nil {
+		return errors.Append(err, "Failed to send %s code", purpose)
+	}
+	codeSent(c, codeChannelSMS, prj.Name, code, lifeSpan)
+
+	return nil
+}
@@ -101,3 +101,16 @@ func UserVerifyByPassword(projectName string, name string, password string) (*mo
 
 	return user, nil
 }
+
+// CheckUserLocked returns ErrUserLocked if the user is locked by the login failures
+// It is used when the user is authenticated without the password.
+func CheckUserLocked(projectName string, user *model.UserInfo) *errors.Error {
+	prj, err := db.GetInst().ProjectGet(projectName)
+	if err != nil {
+		return err
+	}
+	if isLocked(user.LockState, prj.UserLock) {
+		return ErrUserLocked
+	}
+	return nil
+}
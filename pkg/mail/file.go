@@ -0,0 +1,44 @@
+package mail
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sh-miyoshi/hekate/pkg/logger"
+)
+
+// fileSender writes the mail to the file instead of sending it
+// It is used for the development and the test, so the mail is written as plain text.
+// If the file path is empty, the mail is written to the log.
+type fileSender struct {
+	path string
+	mu   sync.Mutex
+}
+
+func newFileSender(path string) *fileSender {
+	return &fileSender{
+		path: path,
+	}
+}
+
+func (s *fileSender) send(from string, to string, msg *Message) error {
+	text := fmt.Sprintf("Date: %s\nFrom: %s\nTo: %s\nSubject: %s\n\n%s\n", time.Now().Format(time.RFC1123Z), from, to, msg.Subject, msg.Body)
+	if s.path == "" {
+		logger.Info("Mail is not sent to the server, but output to the log\n%s", text)
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fp, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+
+	_, err = fp.WriteString(text + "----\n")
+	return err
+}
@@ -0,0 +1,140 @@
+package mail
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	netmail "net/mail"
+	"strings"
+	"time"
+
+	"github.com/sh-miyoshi/hekate/pkg/config"
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/logger"
+	"github.com/sh-miyoshi/hekate/pkg/util"
+)
+
+// Message ...
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type sender interface {
+	send(from string, to string, msg *Message) error
+}
+
+// Manager ...
+type Manager struct {
+	sender sender
+	from   string
+}
+
+var (
+	inst *Manager
+
+	// ErrNotConfigured ...
+	ErrNotConfigured = errors.New("Mail is not available", "Mail sender is not configured")
+)
+
+// Init ...
+func Init(cfg config.MailConfig) *errors.Error {
+	if inst != nil {
+		return errors.New("Internal server error", "MailManager is already initialized")
+	}
+
+	switch cfg.Type {
+	case "":
+		logger.Info("Mail sender is not configured, so the login by email is disabled")
+		inst = &Manager{}
+	case "smtp":
+		logger.Info("Initialize MailManager with smtp server %s:%d", cfg.SMTP.Host, cfg.SMTP.Port)
+		inst = &Manager{
+			sender: newSMTPSender(cfg.SMTP),
+			from:   cfg.From,
+		}
+	case "file":
+		logger.Info("Initialize MailManager with file sender")
+		inst = &Manager{
+			sender: newFileSender(cfg.FilePath),
+			from:   cfg.From,
+		}
+	default:
+		return errors.New("Internal server error", "Mail type %s is not implemented", cfg.Type)
+	}
+	return nil
+}
+
+// GetInst returns an instance of Mail Manager
+func GetInst() *Manager {
+	return inst
+}
+
+// Enabled returns true if the mail can be sent
+func (m *Manager) Enabled() bool {
+	return m != nil && m.sender != nil
+}
+
+// Send ...
+func (m *Manager) Send(msg *Message) *errors.Error {
+	if !m.Enabled() {
+		return ErrNotConfigured
+	}
+
+	to, err := netmail.ParseAddress(msg.To)
+	if err != nil {
+		return errors.New("Invalid mail address", "Failed to parse to address %s: %v", msg.To, err)
+	}
+	if strings.ContainsAny(msg.Subject, "\r\n") {
+		return errors.New("Invalid mail subject", "Subject must not contain line breaks")
+	}
+
+	if err := m.sender.send(m.from, to.Address, msg); err != nil {
+		return errors.New("Failed to send mail", "Failed to send mail to %s: %v", msg.To, err)
+	}
+	logger.Info("Mail %s is sent to %s", msg.Subject, msg.To)
+	return nil
+}
+
+// SendTemplate sends the mail built from the template of the project
+func (m *Manager) SendTemplate(prj *model.ProjectInfo, tplType, to, locale string, data *TemplateData) *errors.Error {
+	msg, err := buildMessage(prj.MailConfig.Templates, tplType, locale, data)
+	if err != nil {
+		return errors.Append(err, "Failed to build mail message")
+	}
+	msg.To = to
+	return m.Send(msg)
+}
+
+// toMIME returns the message in the internet message format
+//   ref. https://tools.ietf.org/html/rfc5322
+func toMIME(from, to string, msg *Message, now time.Time) []byte {
+	domain := "localhost"
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		domain = strings.TrimSuffix(from[i+1:], ">")
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", to)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@%s>\r\n", util.RandomString(32, util.CharTypeDigit|util.CharTypeLower), domain)
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: base64\r\n")
+	buf.WriteString("\r\n")
+
+	// base64 lines must not be longer than 76 characters
+	body := base64.StdEncoding.EncodeToString([]byte(msg.Body))
+	for len(body) > 76 {
+		buf.WriteString(body[:76] + "\r\n")
+		body = body[76:]
+	}
+	buf.WriteString(body + "\r\n")
+
+	return buf.Bytes()
+}
@@ -0,0 +1,92 @@
+package mail
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"time"
+
+	"github.com/sh-miyoshi/hekate/pkg/config"
+)
+
+const smtpTimeout = 30 * time.Second
+
+// smtpSender sends the mail via the smtp server
+type smtpSender struct {
+	host     string
+	addr     string
+	username string
+	password string
+	useTLS   bool
+}
+
+func newSMTPSender(cfg config.SMTPConfig) *smtpSender {
+	return &smtpSender{
+		host:     cfg.Host,
+		addr:     fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		username: cfg.Username,
+		password: cfg.Password,
+		useTLS:   cfg.UseTLS,
+	}
+}
+
+func (s *smtpSender) send(from string, to string, msg *Message) error {
+	tlsConfig := &tls.Config{ServerName: s.host}
+
+	var conn net.Conn
+	var err error
+	if s.useTLS {
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: smtpTimeout}, "tcp", s.addr, tlsConfig)
+	} else {
+		conn, err = net.DialTimeout("tcp", s.addr, smtpTimeout)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %v", s.addr, err)
+	}
+	conn.SetDeadline(time.Now().Add(smtpTimeout))
+
+	c, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if !s.useTLS {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err := c.StartTLS(tlsConfig); err != nil {
+				return fmt.Errorf("failed to start tls: %v", err)
+			}
+		}
+	}
+
+	if s.username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.username, s.password, s.host)); err != nil {
+			return fmt.Errorf("failed to authenticate: %v", err)
+		}
+	}
+
+	envelopeFrom := from
+	if addr, err := netmail.ParseAddress(from); err == nil {
+		envelopeFrom = addr.Address
+	}
+	if err := c.Mail(envelopeFrom); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(toMIME(from, to, msg, time.Now())); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
@@ -0,0 +1,135 @@
+package mail
+
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+)
+
+// TemplateData is a set of fields which can be used in the mail template
+// e.g. "Your code is {{.Code}}. It expires in {{.ExpiresIn}} minutes."
+type TemplateData struct {
+	UserName    string
+	ProjectName string
+	// Code is a one-time code in the email OTP mail
+	Code string
+	// URL is a login link in the magic link mail
+	URL string
+	// ExpiresIn is a life span of the code or the link [minutes]
+	ExpiresIn uint
+}
+
+// defaultTemplates is used when the project does not have the template of the type and the locale
+var defaultTemplates = map[string]map[string]model.MailTemplate{
+	model.MailTemplateEMailOTP: {
+		"en": {
+			Subject: "Your verification code for {{.ProjectName}}",
+			Body: `Hello {{.UserName}},
+
+Your verification code is {{.Code}}
+The code expires in {{.ExpiresIn}} minutes.
+
+If you did not try to login, please ignore this mail.
+`,
+		},
+		"ja": {
+			Subject: "{{.ProjectName}} の確認コード",
+			Body: `{{.UserName}} 様
+
+確認コードは {{.Code}} です。
+このコードの有効期限は{{.ExpiresIn}}分です。
+
+ログインした覚えがない場合は、このメールを破棄してください。
+`,
+		},
+	},
+	model.MailTemplateMagicLink: {
+		"en": {
+			Subject: "Login link for {{.ProjectName}}",
+			Body: `Hello {{.UserName}},
+
+Open the following link to login.
+{{.URL}}
+
+The link expires in {{.ExpiresIn}} minutes and can be used only once.
+If you did not try to login, please ignore this mail.
+`,
+		},
+		"ja": {
+			Subject: "{{.ProjectName}} のログインリンク",
+			Body: `{{.UserName}} 様
+
+以下のリンクを開いてログインしてください。
+{{.URL}}
+
+このリンクの有効期限は{{.ExpiresIn}}分で、一度のみ使用できます。
+ログインした覚えがない場合は、このメールを破棄してください。
+`,
+		},
+	},
+}
+
+// findTemplate returns the project template of the locale, or the project template for all locales
+// If the project does not have them, the default template of the locale or English is used.
+func findTemplate(templates []model.MailTemplate, tplType, locale string) (*model.MailTemplate, *errors.Error) {
+	var common *model.MailTemplate
+	for i, t := range templates {
+		if t.Type != tplType {
+			continue
+		}
+		if t.Locale == locale {
+			return &templates[i], nil
+		}
+		if t.Locale == "" {
+			common = &templates[i]
+		}
+	}
+	if common != nil {
+		return common, nil
+	}
+
+	defaults, ok := defaultTemplates[tplType]
+	if !ok {
+		return nil, errors.New("Internal server error", "No such mail template type %s", tplType)
+	}
+	if t, ok := defaults[locale]; ok {
+		return &t, nil
+	}
+	t := defaults["en"]
+	return &t, nil
+}
+
+func buildMessage(templates []model.MailTemplate, tplType, locale string, data *TemplateData) (*Message, *errors.Error) {
+	t, err := findTemplate(templates, tplType, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	subject, e := execTemplate(t.Subject, data)
+	if e != nil {
+		return nil, errors.New("Internal server error", "Failed to execute subject template: %v", e)
+	}
+	body, e := execTemplate(t.Body, data)
+	if e != nil {
+		return nil, errors.New("Internal server error", "Failed to execute body template: %v", e)
+	}
+
+	return &Message{
+		Subject: subject,
+		Body:    body,
+	}, nil
+}
+
+func execTemplate(text string, data *TemplateData) (string, error) {
+	tpl, err := template.New("mail").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
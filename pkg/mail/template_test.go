@@ -0,0 +1,70 @@
+package mail
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+)
+
+func TestFindTemplate(t *testing.T) {
+	templates := []model.MailTemplate{
+		{Type: model.MailTemplateEMailOTP, Locale: "ja", Subject: "ja subject", Body: "ja body"},
+		{Type: model.MailTemplateEMailOTP, Locale: "", Subject: "common subject", Body: "common body"},
+	}
+
+	tt := []struct {
+		templates []model.MailTemplate
+		tplType   string
+		locale    string
+		expect    string
+	}{
+		{templates, model.MailTemplateEMailOTP, "ja", "ja subject"},
+		{templates, model.MailTemplateEMailOTP, "en", "common subject"},
+		{templates, model.MailTemplateMagicLink, "ja", defaultTemplates[model.MailTemplateMagicLink]["ja"].Subject},
+		{nil, model.MailTemplateMagicLink, "fr", defaultTemplates[model.MailTemplateMagicLink]["en"].Subject},
+	}
+
+	for _, tc := range tt {
+		res, err := findTemplate(tc.templates, tc.tplType, tc.locale)
+		if err != nil {
+			t.Errorf("findTemplate(%s, %s) returns unexpected error: %v", tc.tplType, tc.locale, err)
+			continue
+		}
+		if res.Subject != tc.expect {
+			t.Errorf("findTemplate(%s, %s) returns wrong template. expect %s, but got %s", tc.tplType, tc.locale, tc.expect, res.Subject)
+		}
+	}
+
+	if _, err := findTemplate(nil, "unknown", "en"); err == nil {
+		t.Errorf("findTemplate with unknown type returns nil error")
+	}
+}
+
+func TestBuildMessage(t *testing.T) {
+	data := &TemplateData{
+		UserName:    "admin",
+		ProjectName: "master",
+		Code:        "123456",
+		ExpiresIn:   5,
+	}
+
+	msg, err := buildMessage(nil, model.MailTemplateEMailOTP, "en", data)
+	if err != nil {
+		t.Fatalf("buildMessage returns unexpected error: %v", err)
+	}
+	if msg.Subject != "Your verification code for master" {
+		t.Errorf("Wrong subject: %s", msg.Subject)
+	}
+	if !strings.Contains(msg.Body, "123456") {
+		t.Errorf("The body does not contain the code: %s", msg.Body)
+	}
+
+	// unknown field in the template
+	templates := []model.MailTemplate{
+		{Type: model.MailTemplateEMailOTP, Subject: "{{.Unknown}}", Body: "body"},
+	}
+	if _, err := buildMessage(templates, model.MailTemplateEMailOTP, "en", data); err == nil {
+		t.Errorf("buildMessage with unknown field returns nil error")
+	}
+}
@@ -4,12 +4,12 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
-	"net"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/sh-miyoshi/hekate/pkg/audit"
 	"github.com/sh-miyoshi/hekate/pkg/db"
 	"github.com/sh-miyoshi/hekate/pkg/db/model"
 	"github.com/sh-miyoshi/hekate/pkg/errors"
@@ -17,30 +17,91 @@ import (
 	"github.com/sh-miyoshi/hekate/pkg/login"
 	"github.com/sh-miyoshi/hekate/pkg/oidc"
 	"github.com/sh-miyoshi/hekate/pkg/oidc/token"
+	"github.com/sh-miyoshi/hekate/pkg/ratelimit"
+	ratelimitmodel "github.com/sh-miyoshi/hekate/pkg/ratelimit/model"
+	"github.com/sh-miyoshi/hekate/pkg/secret"
+	"github.com/sh-miyoshi/hekate/pkg/util"
+	"github.com/stretchr/stew/slice"
 )
 
 var (
 	defaultScopes = []string{"openid", "email"}
 )
 
+// slowDownIntervalSec is added to the poll interval when the client polls too frequently
+const slowDownIntervalSec = 5
+
 type option struct {
+	clientID        string
 	audiences       []string
 	genRefreshToken bool
 	genIDToken      bool
 	nonce           string
 	endUserAuthTime time.Time
 	scopes          []string
+	claims          *token.ClaimsRequest
+	authMethods     []string
+	familyID        string
+	loginSessionID  string
+	// previousSessionID is the session of the refresh token which is rotated
+	previousSessionID string
+	// resources are API resources which the access token is issued for
+	resources []*model.APIResource
+	// grantedResources are resource identifiers which the refresh token can request
+	grantedResources []string
+	// offlineExpiresAt is a max life time of the refreshed offline session
+	offlineExpiresAt time.Time
 }
 
 // ReqAuthByPassword ...
 func ReqAuthByPassword(project *model.ProjectInfo, userName string, password string, r *http.Request) (*oidc.TokenResponse, *errors.Error) {
+	limitKeys := []ratelimitmodel.Key{ratelimit.IPKey(project.Name, r), ratelimit.UserKey(project.Name, userName)}
+	if clientID := r.Form.Get("client_id"); clientID != "" {
+		limitKeys = append(limitKeys, ratelimit.ClientKey(project.Name, clientID))
+	}
+	if err := ratelimit.GetInst().Check(limitKeys...); err != nil {
+		return nil, err
+	}
+
 	usr, err := login.UserVerifyByPassword(project.Name, userName, password)
 	if err != nil {
 		if errors.Contains(err, login.ErrAuthFailed) || errors.Contains(err, login.ErrUserLocked) {
+			ratelimit.GetInst().Failed(limitKeys...)
 			return nil, errors.Append(errors.ErrRequestUnauthorized, err.Error())
 		}
 		return nil, err
 	}
+	ratelimit.GetInst().Succeeded(ratelimit.UserKey(project.Name, userName))
+
+	// password grant authenticates the user by only one factor
+	if login.MFARequired(project, usr) {
+		e := errors.ErrInvalidGrant.Copy()
+		e.SetDescription("multi-factor authentication is required, use the authorization code flow")
+		return nil, errors.Append(e, "User %s requires MFA by the project policy", usr.ID)
+	}
+
+	// the second factor required by the risk evaluation cannot be verified in the password grant either
+	decision, err := login.CheckLoginRisk(project, usr, r)
+	if err != nil {
+		if errors.Contains(err, login.ErrLoginBlocked) {
+			e := errors.ErrInvalidGrant.Copy()
+			e.SetDescription("the login is blocked by the risk policy")
+			return nil, errors.Append(e, err.Error())
+		}
+		return nil, errors.Append(err, "Failed to evaluate login risk")
+	}
+	if decision.Action == model.RiskActionMFA {
+		e := errors.ErrInvalidGrant.Copy()
+		e.SetDescription("multi-factor authentication is required, use the authorization code flow")
+		return nil, errors.Append(e, "Login of user %s requires MFA by the risk policy", usr.ID)
+	}
+
+	// the password which must be changed is accepted only in the login page
+	if secret.PasswordChangeRequired(usr, project.PasswordPolicy, time.Now()) {
+		e := errors.ErrInvalidGrant.Copy()
+		e.SetDescription("password change is required, use the authorization code flow")
+		return nil, errors.Append(e, "User %s must change the password", usr.ID)
+	}
 
 	audiences := []string{usr.ID}
 	clientID := r.Form.Get("client_id")
@@ -48,19 +109,49 @@ func ReqAuthByPassword(project *model.ProjectInfo, userName string, password str
 		audiences = append(audiences, clientID)
 	}
 
-	return genTokenRes(usr.ID, project, r, option{
-		audiences:       audiences,
-		genRefreshToken: true,
-		endUserAuthTime: time.Unix(0, 0),
-		scopes:          defaultScopes,
+	scopes := defaultScopes
+	if scope := r.Form.Get("scope"); scope != "" {
+		if err := oidc.ValidateScope(project.Name, clientID, scope); err != nil {
+			return nil, errors.Append(err, "Failed to validate scope")
+		}
+		scopes = strings.Split(scope, " ")
+	}
+	if clientID != "" {
+		scopes, err = oidc.GrantScopeNames(project.Name, clientID, usr.ID, scopes)
+		if err != nil {
+			return nil, errors.Append(err, "Failed to grant scopes")
+		}
+	}
+	// password grant has no consent page, so offline_access is never granted
+	scopes = oidc.RemoveUnconsentedScopes(scopes, false)
+
+	resources, granted, err := requestedResources(project.Name, r, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := genTokenRes(usr.ID, project, r, option{
+		clientID:         clientID,
+		audiences:        audiences,
+		genRefreshToken:  true,
+		endUserAuthTime:  time.Unix(0, 0),
+		scopes:           scopes,
+		authMethods:      []string{model.AuthMethodPassword},
+		resources:        resources,
+		grantedResources: granted,
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	login.RecordLogin(project.Name, usr.ID, r)
+	return res, nil
 }
 
 // ReqAuthByCode ...
 func ReqAuthByCode(project *model.ProjectInfo, clientID string, code string, codeVerifier string, r *http.Request) (*oidc.TokenResponse, *errors.Error) {
 	s, err := db.GetInst().LoginSessionGetByCode(project.Name, code)
 	if err != nil {
-		// TODO(revoke all token in code.UserID) <- SHOULD
 		if errors.Contains(err, model.ErrNoSuchLoginSession) {
 			return nil, errors.Append(errors.ErrInvalidGrant, "no such code")
 		}
@@ -70,6 +161,17 @@ func ReqAuthByCode(project *model.ProjectInfo, clientID string, code string, cod
 		return nil, errors.Append(err, "Failed to get login session info")
 	}
 
+	// the other client cannot revoke the grant by the leaked code
+	if s.ClientID != clientID {
+		return nil, errors.Append(errors.ErrRequestUnauthorized, "missing client id")
+	}
+
+	// the code was already used, so revoke all tokens issued from it
+	//   ref. https://tools.ietf.org/html/rfc6749#section-4.1.2
+	if s.CodeRedeemed {
+		return nil, revokeReplayedCode(project, s, r)
+	}
+
 	// PKCE Code Verify
 	if codeVerifier != "" {
 		challenge := codeVerifier
@@ -93,13 +195,31 @@ func ReqAuthByCode(project *model.ProjectInfo, clientID string, code string, cod
 		return nil, errors.Append(errors.ErrInvalidRequest, "code is already expired")
 	}
 
-	if s.ClientID != clientID {
-		return nil, errors.Append(errors.ErrRequestUnauthorized, "missing client id")
+	cli, err := db.GetInst().ClientGet(project.Name, clientID)
+	if err != nil {
+		return nil, errors.Append(err, "Failed to get client")
+	}
+	if cli.RequirePKCE && s.CodeChallenge == "" {
+		return nil, errors.Append(errors.ErrInvalidGrant, "PKCE is required for the client")
 	}
 
-	// Remove Authorized code
-	if err := db.GetInst().LoginSessionDelete(project.Name, s.SessionID); err != nil {
-		return nil, errors.Append(err, "Failed to delete login session")
+	claims, err := token.ParseClaimsRequest(s.Claims)
+	if err != nil {
+		return nil, errors.Append(err, "Failed to parse claims request")
+	}
+
+	resources, granted, err := requestedResources(project.Name, r, s.Resources)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the redeemed code until the login session expires to detect the replay
+	// Only one of the concurrent requests with the same code can redeem it.
+	if err := db.GetInst().LoginSessionRedeemCode(project.Name, s.SessionID); err != nil {
+		if errors.Contains(err, model.ErrLoginSessionCodeRedeemed) {
+			return nil, revokeReplayedCode(project, s, r)
+		}
+		return nil, errors.Append(err, "Failed to redeem code")
 	}
 
 	audiences := []string{
@@ -108,15 +228,37 @@ func ReqAuthByCode(project *model.ProjectInfo, clientID string, code string, cod
 	}
 
 	return genTokenRes(s.UserID, project, r, option{
-		audiences:       audiences,
-		genRefreshToken: true,
-		genIDToken:      true,
-		nonce:           s.Nonce,
-		endUserAuthTime: s.LoginDate,
-		scopes:          s.Scopes,
+		clientID:         s.ClientID,
+		audiences:        audiences,
+		genRefreshToken:  true,
+		genIDToken:       true,
+		nonce:            s.Nonce,
+		endUserAuthTime:  s.LoginDate,
+		scopes:           oidc.RemoveUnconsentedScopes(s.Scopes, s.Consented),
+		claims:           claims,
+		authMethods:      s.AuthMethods,
+		loginSessionID:   s.SessionID,
+		resources:        resources,
+		grantedResources: granted,
 	})
 }
 
+// revokeReplayedCode revokes all sessions issued from the login session of the replayed code
+func revokeReplayedCode(project *model.ProjectInfo, s *model.LoginSession, r *http.Request) *errors.Error {
+	if err := db.GetInst().SessionLineageDelete(project.Name, s.SessionID); err != nil {
+		return errors.Append(err, "Failed to revoke sessions issued from the code")
+	}
+	if err := db.GetInst().LoginSessionDelete(project.Name, s.SessionID); err != nil {
+		return errors.Append(err, "Failed to delete login session")
+	}
+
+	msg := fmt.Sprintf("Authorization code replay detected, so revoked sessions from login session %s of user %s", s.SessionID, s.UserID)
+	if err := audit.GetInst().Save(project.Name, time.Now(), "SECURITY", r.Method, r.URL.String(), msg); err != nil {
+		errors.Print(errors.Append(err, "Failed to save audit event"))
+	}
+	return errors.Append(errors.ErrInvalidGrant, msg)
+}
+
 // ReqAuthByRefreshToken ...
 func ReqAuthByRefreshToken(project *model.ProjectInfo, clientID string, refreshToken string, r *http.Request) (*oidc.TokenResponse, *errors.Error) {
 	claims := &token.RefreshTokenClaims{}
@@ -125,6 +267,12 @@ func ReqAuthByRefreshToken(project *model.ProjectInfo, clientID string, refreshT
 		return nil, errors.Append(errors.ErrRequestUnauthorized, fmt.Sprintf("Failed to verify token: %v", err))
 	}
 
+	// check the resources before the rotation not to revoke the refresh token by an invalid request
+	resources, grantedResources, err := requestedResources(project.Name, r, claims.Resources)
+	if err != nil {
+		return nil, err
+	}
+
 	ok := false
 	for _, aud := range claims.Audience {
 		if aud == clientID {
@@ -139,22 +287,95 @@ func ReqAuthByRefreshToken(project *model.ProjectInfo, clientID string, refreshT
 
 	s, err := db.GetInst().SessionGet(project.Name, claims.SessionID)
 	if err != nil {
-		return nil, errors.Append(err, "Failed to get previous token")
+		if !errors.Contains(err, model.ErrNoSuchSession) || claims.FamilyID == "" {
+			return nil, errors.Append(err, "Failed to get previous token")
+		}
+
+		// the refresh token was already rotated or revoked
+		s, err = checkRotatedRefreshToken(project, claims, r)
+		if err != nil {
+			return nil, errors.Append(err, "Failed to use rotated refresh token")
+		}
+	} else {
+		// Delete previous token
+		if err := db.GetInst().SessionDelete(project.Name, claims.SessionID); err != nil {
+			return nil, errors.Append(err, "Failed to revoke previous token")
+		}
 	}
 
-	// Delete previous token
-	if err := db.GetInst().SessionDelete(project.Name, claims.SessionID); err != nil {
-		return nil, errors.Append(err, "Failed to revoke previous token")
+	// refreshed tokens never have broader scopes than the previous one
+	prevScopes := strings.Split(claims.Scope, " ")
+	granted, err := oidc.GrantScopeNames(project.Name, clientID, claims.Subject, prevScopes)
+	if err != nil {
+		return nil, errors.Append(err, "Failed to grant scopes")
+	}
+	scopes := []string{}
+	for _, scope := range granted {
+		if slice.Contains(prevScopes, scope) {
+			scopes = append(scopes, scope)
+		}
 	}
 
 	return genTokenRes(claims.Subject, project, r, option{
-		audiences:       claims.Audience,
-		genRefreshToken: true,
-		endUserAuthTime: s.LastAuthTime,
-		scopes:          strings.Split(claims.Scope, " "),
+		clientID:          clientID,
+		audiences:         claims.Audience,
+		genRefreshToken:   true,
+		endUserAuthTime:   s.LastAuthTime,
+		scopes:            scopes,
+		authMethods:       s.AuthMethods,
+		familyID:          s.FamilyID,
+		previousSessionID: claims.SessionID,
+		loginSessionID:    s.LoginSessionID,
+		offlineExpiresAt:  s.OfflineExpiresAt,
+		resources:         resources,
+		grantedResources:  grantedResources,
 	})
 }
 
+// checkRotatedRefreshToken handles a refresh token which was already rotated.
+// If the token is the immediate predecessor of the latest session and it is used within the grace period,
+// the request is regarded as a concurrent refresh and the latest session in the family is returned.
+// Otherwise it is regarded as a reuse by someone, so all sessions in the family are revoked.
+func checkRotatedRefreshToken(project *model.ProjectInfo, claims *token.RefreshTokenClaims, r *http.Request) (*model.Session, *errors.Error) {
+	sessions, err := db.GetInst().SessionGetList(project.Name, &model.SessionFilter{FamilyID: claims.FamilyID})
+	if err != nil {
+		return nil, errors.Append(err, "Failed to get sessions in the family")
+	}
+	if len(sessions) == 0 {
+		// the family is already revoked or expired
+		return nil, errors.Append(model.ErrNoSuchSession, "No sessions in family %s", claims.FamilyID)
+	}
+
+	latest := sessions[0]
+	for _, s := range sessions {
+		if s.CreatedAt.After(latest.CreatedAt) {
+			latest = s
+		}
+	}
+
+	grace := time.Second * time.Duration(project.TokenConfig.RefreshTokenGracePeriod)
+	if inGracePeriod(latest, claims.SessionID, grace, time.Now()) {
+		logger.Info("Rotated refresh token is used within the grace period")
+		return latest, nil
+	}
+
+	if err := db.GetInst().SessionFamilyDelete(project.Name, claims.FamilyID); err != nil {
+		return nil, errors.Append(err, "Failed to revoke session family")
+	}
+
+	msg := fmt.Sprintf("Refresh token reuse detected, so revoked session family %s of user %s", claims.FamilyID, claims.Subject)
+	if err := audit.GetInst().Save(project.Name, time.Now(), "SECURITY", r.Method, r.URL.String(), msg); err != nil {
+		errors.Print(errors.Append(err, "Failed to save audit event"))
+	}
+	return nil, errors.Append(errors.ErrInvalidGrant, msg)
+}
+
+// inGracePeriod returns true if the session was rotated from the refresh token within the grace period
+// The older tokens in the family are never accepted even within the grace period.
+func inGracePeriod(latest *model.Session, sessionID string, grace time.Duration, now time.Time) bool {
+	return latest.PreviousSessionID == sessionID && now.Before(latest.CreatedAt.Add(grace))
+}
+
 // ReqAuthByClientCredentials ...
 func ReqAuthByClientCredentials(project *model.ProjectInfo, clientID string, r *http.Request) (*oidc.TokenResponse, *errors.Error) {
 	cli, err := db.GetInst().ClientGet(project.Name, clientID)
@@ -165,25 +386,57 @@ func ReqAuthByClientCredentials(project *model.ProjectInfo, clientID string, r *
 		return nil, errors.Append(errors.ErrInvalidRequest, "client type is not confidential")
 	}
 
+	scopes := []string{}
+	if scope := r.Form.Get("scope"); scope != "" {
+		if err := oidc.ValidateScope(project.Name, clientID, scope); err != nil {
+			return nil, errors.Append(err, "Failed to validate scope")
+		}
+		scopes = strings.Split(scope, " ")
+	}
+
+	resources, _, err := requestedResources(project.Name, r, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	audiences := []string{
 		clientID,
 	}
 	return genTokenRes("", project, r, option{
+		clientID:  clientID,
 		audiences: audiences,
+		scopes:    scopes,
+		resources: resources,
 	})
 }
 
 // ReqAuthByDeviceCode ...
+//   ref. https://tools.ietf.org/html/rfc8628#section-3.5
 func ReqAuthByDeviceCode(project *model.ProjectInfo, clientID string, deviceCode string, r *http.Request) (*oidc.TokenResponse, *errors.Error) {
+	if deviceCode == "" {
+		return nil, errors.Append(errors.ErrInvalidRequest, "device_code is empty")
+	}
+
 	devices, err := db.GetInst().DeviceGetList(project.Name, &model.DeviceFilter{DeviceCode: deviceCode})
 	if err != nil {
 		return nil, errors.Append(err, "Get device failed")
 	}
 	if len(devices) == 0 {
-		return nil, errors.ErrInvalidRequest
+		return nil, errors.Append(errors.ErrInvalidGrant, "No such device code")
 	}
 
 	device := devices[0]
+	if device.ClientID != clientID {
+		return nil, errors.Append(errors.ErrInvalidGrant, "device code was not issued to client %s", clientID)
+	}
+
+	now := time.Now()
+	if device.Expired(now) {
+		if err := db.GetInst().DeviceDelete(project.Name, device.DeviceCode); err != nil {
+			return nil, errors.Append(err, "Failed to delete expired device")
+		}
+		return nil, errors.Append(errors.ErrExpiredToken, "device code is already expired")
+	}
 
 	// get login session
 	logger.Debug("login session ID of device authentication: %s", device.LoginSessionID)
@@ -192,6 +445,19 @@ func ReqAuthByDeviceCode(project *model.ProjectInfo, clientID string, deviceCode
 		return nil, errors.Append(err, "Failed to get login session info")
 	}
 	if s.LoginDate.IsZero() {
+		polled := device.LastPolledAt
+		device.LastPolledAt = now
+		if !polled.IsZero() && now.Before(polled.Add(time.Second*time.Duration(device.Interval))) {
+			// the client must increase the interval by 5 seconds for all subsequent requests
+			device.Interval += slowDownIntervalSec
+			if err := db.GetInst().DeviceUpdate(project.Name, device); err != nil {
+				return nil, errors.Append(err, "Failed to update device")
+			}
+			return nil, errors.Append(errors.ErrSlowDown, "client %s polls too frequently", clientID)
+		}
+		if err := db.GetInst().DeviceUpdate(project.Name, device); err != nil {
+			return nil, errors.Append(err, "Failed to update device")
+		}
 		logger.Debug("user is not logged in yet")
 		return nil, errors.ErrAuthorizationPending
 	}
@@ -201,78 +467,257 @@ func ReqAuthByDeviceCode(project *model.ProjectInfo, clientID string, deviceCode
 		return nil, errors.Append(err, "Failed to delete device")
 	}
 
-	if time.Now().After(s.ExpiresDate) {
+	if now.After(s.ExpiresDate) {
 		logger.Info("the device session was already expired")
 		return nil, errors.ErrExpiredToken
 	}
 
+	scopes, err := oidc.GrantScopeNames(project.Name, clientID, s.UserID, s.Scopes)
+	if err != nil {
+		return nil, errors.Append(err, "Failed to grant scopes")
+	}
+	scopes = oidc.RemoveUnconsentedScopes(scopes, s.Consented)
+
+	resources, granted, err := requestedResources(project.Name, r, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	audiences := []string{
 		clientID,
 	}
 	return genTokenRes(s.UserID, project, r, option{
-		audiences:       audiences,
-		genRefreshToken: true,
-		endUserAuthTime: s.LoginDate,
-		scopes:          s.Scopes,
+		clientID:         clientID,
+		audiences:        audiences,
+		genRefreshToken:  true,
+		endUserAuthTime:  s.LoginDate,
+		scopes:           scopes,
+		authMethods:      s.AuthMethods,
+		loginSessionID:   s.SessionID,
+		resources:        resources,
+		grantedResources: granted,
 	})
 }
 
+// ReqAuthByCIBA ...
+//   ref. https://openid.net/specs/openid-client-initiated-backchannel-authentication-core-1_0.html#rfc.section.10.1
+func ReqAuthByCIBA(project *model.ProjectInfo, clientID string, authReqID string, r *http.Request) (*oidc.TokenResponse, *errors.Error) {
+	req, err := db.GetInst().BackchannelAuthGet(project.Name, authReqID)
+	if err != nil {
+		if errors.Contains(err, model.ErrNoSuchBackchannelAuth) || errors.Contains(err, model.ErrBackchannelAuthValidateFailed) {
+			return nil, errors.Append(errors.ErrInvalidGrant, "No such auth_req_id %s", authReqID)
+		}
+		return nil, errors.Append(err, "Failed to get backchannel auth request")
+	}
+	if req.ClientID != clientID {
+		return nil, errors.Append(errors.ErrInvalidGrant, "auth_req_id %s was not issued to client %s", authReqID, clientID)
+	}
+
+	now := time.Now()
+	if req.Expired(now) {
+		if err := db.GetInst().BackchannelAuthDelete(project.Name, authReqID); err != nil {
+			return nil, errors.Append(err, "Failed to delete expired backchannel auth request")
+		}
+		return nil, errors.Append(errors.ErrExpiredToken, "auth_req_id %s is already expired", authReqID)
+	}
+
+	switch req.Status {
+	case model.BackchannelAuthStatusPending:
+		polled := req.LastPolledAt
+		req.LastPolledAt = now
+		if !polled.IsZero() && now.Before(polled.Add(time.Second*time.Duration(req.Interval))) {
+			// the client must increase the interval by 5 seconds for all subsequent requests
+			req.Interval += slowDownIntervalSec
+			if err := db.GetInst().BackchannelAuthUpdate(project.Name, req); err != nil {
+				return nil, errors.Append(err, "Failed to update backchannel auth request")
+			}
+			return nil, errors.Append(errors.ErrSlowDown, "client %s polls too frequently", clientID)
+		}
+		if err := db.GetInst().BackchannelAuthUpdate(project.Name, req); err != nil {
+			return nil, errors.Append(err, "Failed to update backchannel auth request")
+		}
+		logger.Debug("user is not authenticated yet")
+		return nil, errors.ErrAuthorizationPending
+	case model.BackchannelAuthStatusDenied:
+		if err := db.GetInst().BackchannelAuthDelete(project.Name, authReqID); err != nil {
+			return nil, errors.Append(err, "Failed to delete denied backchannel auth request")
+		}
+		return nil, errors.Append(errors.ErrAccessDenied, "user denied the request %s", authReqID)
+	}
+
+	// user already approved, so delete the request and return token
+	if err := db.GetInst().BackchannelAuthDelete(project.Name, authReqID); err != nil {
+		return nil, errors.Append(err, "Failed to delete backchannel auth request")
+	}
+
+	// the approval of the user on the authentication device is the consent to the requested scopes
+	scopes, err := oidc.GrantScopeNames(project.Name, clientID, req.UserID, req.Scopes)
+	if err != nil {
+		return nil, errors.Append(err, "Failed to grant scopes")
+	}
+
+	resources, granted, err := requestedResources(project.Name, r, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	audiences := []string{
+		clientID,
+	}
+	return genTokenRes(req.UserID, project, r, option{
+		clientID:         clientID,
+		audiences:        audiences,
+		genRefreshToken:  true,
+		genIDToken:       true,
+		endUserAuthTime:  req.AuthDate,
+		scopes:           scopes,
+		authMethods:      req.AuthMethods,
+		resources:        resources,
+		grantedResources: granted,
+	})
+}
+
+// requestedResources returns the API resources of the resource parameters in the token request.
+// If the resources were granted in the authorization request, the request must be a subset of them
+// and the refresh token keeps all granted resources.
+//   ref. https://tools.ietf.org/html/rfc8707#section-2.2
+func requestedResources(projectName string, r *http.Request, granted []string) ([]*model.APIResource, []string, *errors.Error) {
+	identifiers := r.Form["resource"]
+	if len(identifiers) == 0 {
+		identifiers = granted
+	}
+
+	if len(granted) > 0 {
+		for _, id := range identifiers {
+			if !slice.Contains(granted, id) {
+				return nil, nil, errors.Append(errors.ErrInvalidTarget, "resource %s was not granted", id)
+			}
+		}
+	}
+
+	resources, err := oidc.GetResources(projectName, identifiers)
+	if err != nil {
+		return nil, nil, errors.Append(err, "Failed to get resources")
+	}
+
+	if len(granted) > 0 {
+		return resources, granted, nil
+	}
+	return resources, identifiers, nil
+}
+
 func genTokenRes(userID string, project *model.ProjectInfo, r *http.Request, opt option) (*oidc.TokenResponse, *errors.Error) {
+	lifeSpans := model.TokenLifeSpans{
+		AccessToken:  project.TokenConfig.AccessTokenLifeSpan,
+		RefreshToken: project.TokenConfig.RefreshTokenLifeSpan,
+		IDToken:      project.TokenConfig.AccessTokenLifeSpan,
+	}
+	if opt.clientID != "" {
+		cli, err := db.GetInst().ClientGet(project.Name, opt.clientID)
+		if err != nil {
+			return nil, errors.Append(err, "Failed to get client")
+		}
+		lifeSpans = cli.LifeSpans(project.TokenConfig)
+	}
+
+	audiences := []string{
+		userID,
+	}
+	if len(opt.audiences) > 0 {
+		audiences = opt.audiences
+	}
+
+	// the access token for the API resources is available only in the resources
+	// with the permissions of them
+	accessAudiences := audiences
+	accessScopes := opt.scopes
+	accessLifeSpan := lifeSpans.AccessToken
+	if len(opt.resources) > 0 {
+		accessAudiences = oidc.ResourceIdentifiers(opt.resources)
+		accessScopes = oidc.ResourceScopes(opt.resources, opt.scopes)
+		accessLifeSpan = oidc.ResourceLifeSpan(opt.resources, lifeSpans.AccessToken)
+	}
+
 	// Generate JWT Token
 	res := oidc.TokenResponse{
 		TokenType: "Bearer",
-		ExpiresIn: project.TokenConfig.AccessTokenLifeSpan,
+		ExpiresIn: accessLifeSpan,
+		Scope:     strings.Join(accessScopes, " "),
 	}
 
 	accessTokenReq := token.Request{
 		Issuer:      token.GetFullIssuer(r),
-		ExpiresIn:   int64(project.TokenConfig.AccessTokenLifeSpan),
+		ExpiresIn:   int64(accessLifeSpan),
 		ProjectName: project.Name,
 		UserID:      userID,
-		Scopes:      opt.scopes,
-	}
-
-	audiences := []string{
-		userID,
-	}
-	if len(opt.audiences) > 0 {
-		audiences = opt.audiences
+		ClientID:    opt.clientID,
+		Scopes:      accessScopes,
+		Claims:      opt.claims,
+		AuthMethods: opt.authMethods,
 	}
 
 	var err *errors.Error
-	res.AccessToken, err = token.GenerateAccessToken(audiences, accessTokenReq)
+	res.AccessToken, err = token.GenerateAccessToken(accessAudiences, accessTokenReq)
 	if err != nil {
 		return nil, errors.Append(err, "Failed to generate access token")
 	}
 
 	if opt.genRefreshToken {
-		res.RefreshExpiresIn = project.TokenConfig.RefreshTokenLifeSpan
+		res.RefreshExpiresIn = lifeSpans.RefreshToken
+
+		// offline session survives the SSO logout until the offline life span
+		offline := slice.Contains(opt.scopes, oidc.OfflineAccessScope)
+		offlineExpiresAt := time.Time{}
+		if offline {
+			lifeSpan, idleTimeout := project.TokenConfig.OfflineSessionLifeSpans()
+			offlineExpiresAt = opt.offlineExpiresAt
+			if offlineExpiresAt.IsZero() {
+				offlineExpiresAt = time.Now().Add(time.Second * time.Duration(lifeSpan))
+			}
+
+			remain := time.Until(offlineExpiresAt)
+			if remain <= 0 {
+				return nil, errors.Append(errors.ErrInvalidGrant, "offline session is already expired")
+			}
+			res.RefreshExpiresIn = idleTimeout
+			if remain < time.Second*time.Duration(idleTimeout) {
+				res.RefreshExpiresIn = uint(remain.Seconds())
+			}
+		}
+
 		refreshTokenReq := token.Request{
 			Issuer:      token.GetFullIssuer(r),
 			ExpiresIn:   int64(res.RefreshExpiresIn),
 			ProjectName: project.Name,
 			UserID:      userID,
 			Scopes:      opt.scopes,
+			Resources:   opt.grantedResources,
 		}
 
 		sessionID := uuid.New().String()
-		res.RefreshToken, err = token.GenerateRefreshToken(sessionID, audiences, refreshTokenReq)
+		familyID := opt.familyID
+		if familyID == "" {
+			familyID = uuid.New().String()
+		}
+		res.RefreshToken, err = token.GenerateRefreshToken(sessionID, familyID, audiences, refreshTokenReq)
 		if err != nil {
 			return nil, errors.Append(err, "Failed to generate refresh token")
 		}
 
-		ip, _, err := net.SplitHostPort(r.RemoteAddr)
-		if err != nil {
-			return nil, errors.New("Invalid request", "Failed to get IP: %v", err)
-		}
 		ent := &model.Session{
-			UserID:       userID,
-			ProjectName:  project.Name,
-			SessionID:    sessionID,
-			CreatedAt:    time.Now(),
-			ExpiresIn:    int64(res.RefreshExpiresIn),
-			FromIP:       ip,
-			LastAuthTime: opt.endUserAuthTime,
+			UserID:            userID,
+			ProjectName:       project.Name,
+			SessionID:         sessionID,
+			CreatedAt:         time.Now(),
+			ExpiresIn:         int64(res.RefreshExpiresIn),
+			FromIP:            util.ClientIP(r),
+			LastAuthTime:      opt.endUserAuthTime,
+			AuthMethods:       opt.authMethods,
+			FamilyID:          familyID,
+			PreviousSessionID: opt.previousSessionID,
+			LoginSessionID:    opt.loginSessionID,
+			Offline:           offline,
+			OfflineExpiresAt:  offlineExpiresAt,
 		}
 
 		if err := db.GetInst().SessionAdd(project.Name, ent); err != nil {
@@ -284,12 +729,15 @@ func genTokenRes(userID string, project *model.ProjectInfo, r *http.Request, opt
 	if opt.genIDToken {
 		idTokenReq := token.Request{
 			Issuer:          token.GetFullIssuer(r),
-			ExpiresIn:       int64(project.TokenConfig.AccessTokenLifeSpan),
+			ExpiresIn:       int64(lifeSpans.IDToken),
 			ProjectName:     project.Name,
 			UserID:          userID,
+			ClientID:        opt.clientID,
 			Nonce:           opt.nonce,
 			EndUserAuthTime: opt.endUserAuthTime,
 			Scopes:          opt.scopes,
+			Claims:          opt.claims,
+			AuthMethods:     opt.authMethods,
 		}
 		res.IDToken, err = token.GenerateIDToken(audiences, idTokenReq)
 		if err != nil {
@@ -0,0 +1,35 @@
+package authn
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+)
+
+func TestInGracePeriod(t *testing.T) {
+	now := time.Now()
+	latest := &model.Session{
+		SessionID:         "session3",
+		CreatedAt:         now.Add(-5 * time.Second),
+		PreviousSessionID: "session2",
+	}
+
+	tt := []struct {
+		name      string
+		sessionID string
+		grace     time.Duration
+		expect    bool
+	}{
+		{"predecessor within grace period", "session2", 10 * time.Second, true},
+		{"predecessor after grace period", "session2", 3 * time.Second, false},
+		{"older token within grace period", "session1", 10 * time.Second, false},
+		{"no grace period", "session2", 0, false},
+	}
+
+	for _, tc := range tt {
+		if res := inGracePeriod(latest, tc.sessionID, tc.grace, now); res != tc.expect {
+			t.Errorf("Test %s: inGracePeriod returns %v, but expect %v", tc.name, res, tc.expect)
+		}
+	}
+}
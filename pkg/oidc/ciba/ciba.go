@@ -0,0 +1,117 @@
+package ciba
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/sh-miyoshi/hekate/pkg/db"
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/logger"
+)
+
+// Channel is an authentication device channel which asks the user to approve the backchannel authentication request
+type Channel interface {
+	Notify(req *model.BackchannelAuth) *errors.Error
+}
+
+// userAPIChannel is the default channel
+// It does not push anything, and the user's logged-in app gets the pending requests by the user API.
+type userAPIChannel struct{}
+
+const notifyTimeoutSec = 5
+
+var (
+	channel Channel = &userAPIChannel{}
+
+	// ErrRequestNotPending ...
+	ErrRequestNotPending = errors.New("Request is not pending", "Request is not pending")
+)
+
+// Notify ...
+func (c *userAPIChannel) Notify(req *model.BackchannelAuth) *errors.Error {
+	logger.Debug("Backchannel auth request %s waits for approval of user %s via user API", req.AuthReqID, req.UserID)
+	return nil
+}
+
+// SetChannel replaces the authentication device channel
+func SetChannel(c Channel) {
+	channel = c
+}
+
+// NotifyUser asks the user to approve the request through the authentication device channel
+func NotifyUser(req *model.BackchannelAuth) *errors.Error {
+	return channel.Notify(req)
+}
+
+// Decide sets the result of the user authentication to the request.
+// If the client uses ping mode, the client is notified of the completion.
+func Decide(projectName string, userID string, authReqID string, approve bool, authMethods []string) *errors.Error {
+	req, err := db.GetInst().BackchannelAuthGet(projectName, authReqID)
+	if err != nil {
+		return errors.Append(err, "Failed to get backchannel auth request")
+	}
+	if req.UserID != userID {
+		return errors.Append(model.ErrNoSuchBackchannelAuth, "The request %s is not for user %s", authReqID, userID)
+	}
+
+	now := time.Now()
+	if req.Status != model.BackchannelAuthStatusPending || req.Expired(now) {
+		return errors.Append(ErrRequestNotPending, "The request %s is already %s or expired", authReqID, req.Status)
+	}
+
+	if approve {
+		req.Status = model.BackchannelAuthStatusApproved
+		req.AuthDate = now
+		req.AuthMethods = authMethods
+	} else {
+		req.Status = model.BackchannelAuthStatusDenied
+	}
+
+	if err := db.GetInst().BackchannelAuthUpdate(projectName, req); err != nil {
+		return errors.Append(err, "Failed to update backchannel auth request")
+	}
+
+	cli, err := db.GetInst().ClientGet(projectName, req.ClientID)
+	if err != nil {
+		return errors.Append(err, "Failed to get client")
+	}
+	if cli.BackchannelPingMode() {
+		// the result is already saved, so the client can get it by polling even if the ping is failed
+		if err := notifyClient(cli.BackchannelClientNotificationEndpoint, req.ClientNotificationToken, req.AuthReqID); err != nil {
+			errors.Print(errors.Append(err, "Failed to notify client %s", cli.ID))
+		}
+	}
+
+	return nil
+}
+
+// notifyClient sends the ping callback to the client notification endpoint
+//   ref. https://openid.net/specs/openid-client-initiated-backchannel-authentication-core-1_0.html#rfc.section.10.2
+func notifyClient(endpoint string, notificationToken string, authReqID string) *errors.Error {
+	body, e := json.Marshal(map[string]string{"auth_req_id": authReqID})
+	if e != nil {
+		return errors.New("Internal Error", "Failed to marshal ping callback: %v", e)
+	}
+
+	req, e := http.NewRequest("POST", endpoint, bytes.NewReader(body))
+	if e != nil {
+		return errors.New("Internal Error", "Failed to create ping callback request: %v", e)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+notificationToken)
+
+	client := &http.Client{Timeout: notifyTimeoutSec * time.Second}
+	res, e := client.Do(req)
+	if e != nil {
+		return errors.New("Ping failed", "Failed to send ping callback to %s: %v", endpoint, e)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return errors.New("Ping failed", "Client notification endpoint %s returned status %d", endpoint, res.StatusCode)
+	}
+	return nil
+}
@@ -45,3 +45,22 @@ func ClientAuth(projectName string, clientID string, clientSecret string) *error
 
 	return nil
 }
+
+// ValidateClientRequest checks the auth request is allowed by the client settings
+func ValidateClientRequest(projectName string, req *AuthRequest) *errors.Error {
+	cli, err := db.GetInst().ClientGet(projectName, req.ClientID)
+	if err != nil {
+		return errors.Append(err, "Failed to get client")
+	}
+
+	if !cli.ResponseTypeAllowed(req.ResponseType) {
+		return errors.Append(errors.ErrUnauthorizedClient, "response type %v is not allowed for the client", req.ResponseType)
+	}
+
+	// ref. https://tools.ietf.org/html/rfc7636#section-4.4.1
+	if cli.RequirePKCE && slice.Contains(req.ResponseType, "code") && req.CodeChallenge == "" {
+		return errors.Append(errors.ErrInvalidRequest, "code_challenge is required for the client")
+	}
+
+	return nil
+}
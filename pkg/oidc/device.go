@@ -0,0 +1,66 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+)
+
+const (
+	// UserCodeCharset is a set of characters used in the user code of device authorization grant
+	// It has no vowels to avoid generating real words, and no similar looking characters
+	//   ref. https://tools.ietf.org/html/rfc8628#section-6.1
+	UserCodeCharset = "BCDFGHJKLMNPQRSTVWXZ"
+
+	userCodeLength    = 8
+	userCodeGroupSize = 4
+	userCodeSeparator = "-"
+)
+
+// GenerateUserCode returns a new user code in normalized form, e.g. "WDJBMJHT"
+func GenerateUserCode() string {
+	b := make([]byte, userCodeLength)
+	max := big.NewInt(int64(len(UserCodeCharset)))
+	for i := range b {
+		n, _ := rand.Int(rand.Reader, max)
+		b[i] = UserCodeCharset[n.Int64()]
+	}
+	return string(b)
+}
+
+// FormatUserCode returns the user code in the display form, e.g. "WDJB-MJHT"
+func FormatUserCode(code string) string {
+	groups := []string{}
+	for len(code) > userCodeGroupSize {
+		groups = append(groups, code[:userCodeGroupSize])
+		code = code[userCodeGroupSize:]
+	}
+	groups = append(groups, code)
+	return strings.Join(groups, userCodeSeparator)
+}
+
+// NormalizeUserCode converts the user input into the normalized form
+// It ignores case, separators and spaces which the user may type.
+func NormalizeUserCode(input string) string {
+	res := []rune{}
+	for _, c := range strings.ToUpper(input) {
+		if c == '-' || c == ' ' || c == '\t' {
+			continue
+		}
+		res = append(res, c)
+	}
+	return string(res)
+}
+
+// ValidUserCodeFormat returns true if the normalized user code is in the format which GenerateUserCode returns
+func ValidUserCodeFormat(code string) bool {
+	if len(code) != userCodeLength {
+		return false
+	}
+	for _, c := range code {
+		if !strings.ContainsRune(UserCodeCharset, c) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,70 @@
+package oidc
+
+import (
+	"testing"
+)
+
+func TestGenerateUserCode(t *testing.T) {
+	code := GenerateUserCode()
+	if !ValidUserCodeFormat(code) {
+		t.Errorf("GenerateUserCode returns %s, but it is invalid format", code)
+	}
+}
+
+func TestValidUserCodeFormat(t *testing.T) {
+	tt := []struct {
+		code   string
+		expect bool
+	}{
+		{"WDJBMJHT", true},
+		{"WDJB-MJHT", false},
+		{"WDJBMJH", false},
+		{"WDJBMJHA", false},
+		{"WDJBMJH1", false},
+		{"", false},
+	}
+
+	for _, tc := range tt {
+		res := ValidUserCodeFormat(tc.code)
+		if res != tc.expect {
+			t.Errorf("ValidUserCodeFormat(%s) returns %v, but expect %v", tc.code, res, tc.expect)
+		}
+	}
+}
+
+func TestUserCodeFormat(t *testing.T) {
+	tt := []struct {
+		input     string
+		formatted string
+	}{
+		{"WDJBMJHT", "WDJB-MJHT"},
+		{"WDJB", "WDJB"},
+		{"", ""},
+	}
+
+	for _, tc := range tt {
+		res := FormatUserCode(tc.input)
+		if res != tc.formatted {
+			t.Errorf("FormatUserCode(%s) returns %s, but expect %s", tc.input, res, tc.formatted)
+		}
+		if NormalizeUserCode(res) != tc.input {
+			t.Errorf("NormalizeUserCode(%s) returns %s, but expect %s", res, NormalizeUserCode(res), tc.input)
+		}
+	}
+
+	tt2 := []struct {
+		input  string
+		expect string
+	}{
+		{"wdjb-mjht", "WDJBMJHT"},
+		{" WDJB MJHT ", "WDJBMJHT"},
+		{"WDJB--MJHT", "WDJBMJHT"},
+	}
+
+	for _, tc := range tt2 {
+		res := NormalizeUserCode(tc.input)
+		if res != tc.expect {
+			t.Errorf("NormalizeUserCode(%s) returns %s, but expect %s", tc.input, res, tc.expect)
+		}
+	}
+}
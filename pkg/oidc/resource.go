@@ -0,0 +1,128 @@
+package oidc
+
+import (
+	"github.com/sh-miyoshi/hekate/pkg/db"
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/stretchr/stew/slice"
+)
+
+// GetResources returns the API resources of the resource indicators
+// It returns invalid_target error if the resource is not registered in the project.
+//   ref. https://tools.ietf.org/html/rfc8707#section-2
+func GetResources(projectName string, identifiers []string) ([]*model.APIResource, *errors.Error) {
+	res := []*model.APIResource{}
+	for _, id := range identifiers {
+		if !model.ValidateAPIResourceIdentifier(id) {
+			return nil, errors.Append(errors.ErrInvalidTarget, "resource %s is not an absolute URI", id)
+		}
+
+		resources, err := db.GetInst().APIResourceGetList(projectName, &model.APIResourceFilter{Identifier: id})
+		if err != nil {
+			return nil, errors.Append(err, "Failed to get API resource")
+		}
+		if len(resources) == 0 {
+			return nil, errors.Append(errors.ErrInvalidTarget, "resource %s is not registered", id)
+		}
+
+		found := false
+		for _, r := range res {
+			if r.ID == resources[0].ID {
+				found = true
+			}
+		}
+		if !found {
+			res = append(res, resources[0])
+		}
+	}
+	return res, nil
+}
+
+// ResourceScopes returns the scopes which are permissions of the resources
+func ResourceScopes(resources []*model.APIResource, scopes []string) []string {
+	res := []string{}
+	for _, s := range scopes {
+		if slice.Contains(res, s) {
+			continue
+		}
+		for _, r := range resources {
+			if slice.Contains(r.Scopes, s) {
+				res = append(res, s)
+				break
+			}
+		}
+	}
+	return res
+}
+
+// ResourceLifeSpan returns the access token life span for the resources
+// If the resources have different life spans, the shortest one is used.
+func ResourceLifeSpan(resources []*model.APIResource, defaultLifeSpan uint) uint {
+	res := uint(0)
+	for _, r := range resources {
+		if l := r.LifeSpan(defaultLifeSpan); res == 0 || l < res {
+			res = l
+		}
+	}
+	if res == 0 {
+		return defaultLifeSpan
+	}
+	return res
+}
+
+// ResourceIdentifiers returns the identifiers of the resources
+func ResourceIdentifiers(resources []*model.APIResource) []string {
+	res := []string{}
+	for _, r := range resources {
+		res = append(res, r.Identifier)
+	}
+	return res
+}
+
+// clientResourceScopes returns the API resources in the project and the requested permissions of them
+// which the client can request.
+func clientResourceScopes(projectName string, clientID string, requested []string) ([]*model.APIResource, []string, *errors.Error) {
+	cli, err := db.GetInst().ClientGet(projectName, clientID)
+	if err != nil {
+		return nil, nil, errors.Append(err, "Failed to get client")
+	}
+
+	resources, err := db.GetInst().APIResourceGetList(projectName, nil)
+	if err != nil {
+		return nil, nil, errors.Append(err, "Failed to get API resource list")
+	}
+
+	allowed := append(append([]string{}, cli.DefaultScopes...), cli.OptionalScopes...)
+	return resources, allowedResourceScopes(resources, requested, allowed), nil
+}
+
+// allowedResourceScopes returns the requested permissions of the resources which are in allowed
+// Unlike the client scopes, the permissions must be listed in the client even if the client allows all client scopes.
+func allowedResourceScopes(resources []*model.APIResource, requested []string, allowed []string) []string {
+	res := []string{}
+	for _, s := range ResourceScopes(resources, requested) {
+		if slice.Contains(allowed, s) {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// grantResourceScopes drops the permissions whose required roles the user does not have
+// If some resources have the same permission, the user must have the roles of all of them.
+func grantResourceScopes(resources []*model.APIResource, scopes []string, roles []string) []string {
+	res := []string{}
+	for _, s := range scopes {
+		ok := true
+		for _, r := range resources {
+			if slice.Contains(r.Scopes, s) && !hasAnyRole(roles, r.RequiredRoles[s]) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			res = append(res, s)
+		}
+	}
+	return res
+}
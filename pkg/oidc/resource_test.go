@@ -0,0 +1,121 @@
+package oidc
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+)
+
+func TestResourceScopes(t *testing.T) {
+	resources := []*model.APIResource{
+		{Identifier: "https://api.example.com", Scopes: []string{"read", "write"}},
+		{Identifier: "https://other.example.com", Scopes: []string{"admin"}},
+	}
+
+	tt := []struct {
+		scopes []string
+		expect []string
+	}{
+		{[]string{"openid", "read"}, []string{"read"}},
+		{[]string{"read", "admin", "read"}, []string{"read", "admin"}},
+		{[]string{"openid", "email"}, []string{}},
+		{[]string{}, []string{}},
+	}
+
+	for _, tc := range tt {
+		res := ResourceScopes(resources, tc.scopes)
+		if len(res) != len(tc.expect) {
+			t.Errorf("ResourceScopes(%v) returns %v, but expect %v", tc.scopes, res, tc.expect)
+			continue
+		}
+		for i := range res {
+			if res[i] != tc.expect[i] {
+				t.Errorf("ResourceScopes(%v) returns %v, but expect %v", tc.scopes, res, tc.expect)
+				break
+			}
+		}
+	}
+}
+
+func TestResourceLifeSpan(t *testing.T) {
+	tt := []struct {
+		lifeSpans []uint
+		expect    uint
+	}{
+		{[]uint{0}, 3600},
+		{[]uint{600}, 600},
+		{[]uint{7200}, 7200},
+		{[]uint{600, 300, 0}, 300},
+		{[]uint{}, 3600},
+	}
+
+	for _, tc := range tt {
+		resources := []*model.APIResource{}
+		for _, l := range tc.lifeSpans {
+			resources = append(resources, &model.APIResource{AccessTokenLifeSpan: l})
+		}
+		res := ResourceLifeSpan(resources, 3600)
+		if res != tc.expect {
+			t.Errorf("ResourceLifeSpan(%v) returns %d, but expect %d", tc.lifeSpans, res, tc.expect)
+		}
+	}
+}
+
+func TestAllowedResourceScopes(t *testing.T) {
+	resources := []*model.APIResource{
+		{Identifier: "https://api.example.com", Scopes: []string{"read", "write"}},
+	}
+
+	tt := []struct {
+		name      string
+		requested []string
+		allowed   []string
+		expect    []string
+	}{
+		{"allowed permission", []string{"openid", "read"}, []string{"openid", "read"}, []string{"read"}},
+		{"not allowed permission", []string{"read", "write"}, []string{"read"}, []string{"read"}},
+		{"client without scopes", []string{"read", "write"}, []string{}, []string{}},
+		{"allowed but not requested", []string{"openid"}, []string{"read", "write"}, []string{}},
+	}
+
+	for _, tc := range tt {
+		res := allowedResourceScopes(resources, tc.requested, tc.allowed)
+		if !reflect.DeepEqual(res, tc.expect) {
+			t.Errorf("Test %s: allowedResourceScopes returns %v, but expect %v", tc.name, res, tc.expect)
+		}
+	}
+}
+
+func TestGrantResourceScopes(t *testing.T) {
+	resources := []*model.APIResource{
+		{
+			Identifier:    "https://api.example.com",
+			Scopes:        []string{"read", "write", "admin"},
+			RequiredRoles: map[string][]string{"write": {"editor", "owner"}, "admin": {"owner"}},
+		},
+		{
+			Identifier:    "https://other.example.com",
+			Scopes:        []string{"read"},
+			RequiredRoles: map[string][]string{"read": {"viewer"}},
+		},
+	}
+
+	tt := []struct {
+		name   string
+		roles  []string
+		expect []string
+	}{
+		{"no roles", []string{}, []string{}},
+		{"viewer", []string{"viewer"}, []string{"read"}},
+		{"editor without viewer", []string{"editor"}, []string{"write"}},
+		{"owner and viewer", []string{"owner", "viewer"}, []string{"read", "write", "admin"}},
+	}
+
+	for _, tc := range tt {
+		res := grantResourceScopes(resources, []string{"read", "write", "admin"}, tc.roles)
+		if !reflect.DeepEqual(res, tc.expect) {
+			t.Errorf("Test %s: grantResourceScopes returns %v, but expect %v", tc.name, res, tc.expect)
+		}
+	}
+}
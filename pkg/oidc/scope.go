@@ -0,0 +1,167 @@
+package oidc
+
+import (
+	"strings"
+
+	"github.com/sh-miyoshi/hekate/pkg/db"
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/stretchr/stew/slice"
+)
+
+// OfflineAccessScope is a scope to request an offline refresh token
+//   ref. https://openid.net/specs/openid-connect-core-1_0.html#OfflineAccess
+const OfflineAccessScope = "offline_access"
+
+func validateScope(scope string, supportedScope []string) *errors.Error {
+	scopes := strings.Split(scope, " ")
+	for _, s := range scopes {
+		if !slice.Contains(supportedScope, s) {
+			return errors.Append(errors.ErrInvalidScope, "scope %s is not supported", s)
+		}
+	}
+
+	return nil
+}
+
+// SupportedScopes returns a list of scope names the client can request.
+// If clientID is empty, it returns all scope names in the project.
+func SupportedScopes(projectName string, clientID string) ([]string, *errors.Error) {
+	scopes, err := db.GetInst().ClientScopeGetList(projectName, nil)
+	if err != nil {
+		return nil, errors.Append(err, "Failed to get client scope list")
+	}
+
+	var allowed []string
+	if clientID != "" {
+		cli, err := db.GetInst().ClientGet(projectName, clientID)
+		if err != nil {
+			return nil, errors.Append(err, "Failed to get client")
+		}
+		allowed = cli.AllowedScopes()
+	}
+
+	res := []string{}
+	for _, s := range scopes {
+		if allowed == nil || slice.Contains(allowed, s.Name) {
+			res = append(res, s.Name)
+		}
+	}
+	return res, nil
+}
+
+// ValidateScope checks all requested scopes are defined in the project and allowed for the client.
+// Permissions of the API resources in the project are also valid scopes if the client allows them.
+func ValidateScope(projectName string, clientID string, scope string) *errors.Error {
+	supported, err := SupportedScopes(projectName, clientID)
+	if err != nil {
+		return err
+	}
+	_, resourceScopes, err := clientResourceScopes(projectName, clientID, strings.Split(scope, " "))
+	if err != nil {
+		return err
+	}
+	return validateScope(scope, append(supported, resourceScopes...))
+}
+
+// GrantScopes returns client scopes which are actually granted to the user.
+// It contains the requested scopes allowed for the client and the client default scopes,
+// and drops scopes whose required roles the user does not have.
+func GrantScopes(projectName string, clientID string, userID string, requested []string) ([]*model.ClientScope, *errors.Error) {
+	cli, err := db.GetInst().ClientGet(projectName, clientID)
+	if err != nil {
+		return nil, errors.Append(err, "Failed to get client")
+	}
+
+	user, err := db.GetInst().UserGet(projectName, userID)
+	if err != nil {
+		return nil, errors.Append(err, "Failed to get user")
+	}
+
+	scopes, err := db.GetInst().ClientScopeGetList(projectName, nil)
+	if err != nil {
+		return nil, errors.Append(err, "Failed to get client scope list")
+	}
+
+	allowed := cli.AllowedScopes()
+	res := []*model.ClientScope{}
+	for _, s := range scopes {
+		if !slice.Contains(cli.DefaultScopes, s.Name) {
+			if !slice.Contains(requested, s.Name) {
+				continue
+			}
+			if allowed != nil && !slice.Contains(allowed, s.Name) {
+				continue
+			}
+		}
+
+		if !hasAnyRole(user.CustomRoles, s.RequiredRoles) {
+			continue
+		}
+		res = append(res, s)
+	}
+
+	return res, nil
+}
+
+// GrantScopeNames returns names of the scopes granted by GrantScopes
+// and the requested permissions of the API resources which the client allows and the user's roles cover.
+// The permissions are restricted to the resource which the access token is issued for in the token generation.
+func GrantScopeNames(projectName string, clientID string, userID string, requested []string) ([]string, *errors.Error) {
+	scopes, err := GrantScopes(projectName, clientID, userID, requested)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []string{}
+	for _, s := range scopes {
+		res = append(res, s.Name)
+	}
+
+	resources, resourceScopes, err := clientResourceScopes(projectName, clientID, requested)
+	if err != nil {
+		return nil, err
+	}
+	user, err := db.GetInst().UserGet(projectName, userID)
+	if err != nil {
+		return nil, errors.Append(err, "Failed to get user")
+	}
+	for _, s := range grantResourceScopes(resources, resourceScopes, user.CustomRoles) {
+		if !slice.Contains(res, s) {
+			res = append(res, s)
+		}
+	}
+	return res, nil
+}
+
+// RequireConsent returns true if the scopes must be agreed by the user in the consent page
+func RequireConsent(scopes []string) bool {
+	return slice.Contains(scopes, OfflineAccessScope)
+}
+
+// RemoveUnconsentedScopes drops the scopes which require the consent if the user did not agree
+func RemoveUnconsentedScopes(scopes []string, consented bool) []string {
+	if consented {
+		return scopes
+	}
+
+	res := []string{}
+	for _, s := range scopes {
+		if s != OfflineAccessScope {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+func hasAnyRole(roles []string, required []string) bool {
+	if len(required) == 0 {
+		return true
+	}
+	for _, r := range required {
+		if slice.Contains(roles, r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package oidc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateScope(t *testing.T) {
+	supported := []string{"openid", "profile", "email"}
+	tt := []struct {
+		scope string
+		isErr bool
+	}{
+		{"openid", false},
+		{"openid profile email", false},
+		{"openid phone", true},
+		{"unknown", true},
+	}
+
+	for _, tc := range tt {
+		err := validateScope(tc.scope, supported)
+		if tc.isErr != (err != nil) {
+			t.Errorf("validateScope returns wrong result for %s. expect error: %v, but got %v", tc.scope, tc.isErr, err)
+		}
+	}
+}
+
+func TestRemoveUnconsentedScopes(t *testing.T) {
+	tt := []struct {
+		scopes    []string
+		consented bool
+		expect    []string
+	}{
+		{[]string{"openid", "offline_access"}, true, []string{"openid", "offline_access"}},
+		{[]string{"openid", "offline_access"}, false, []string{"openid"}},
+		{[]string{"openid", "email"}, false, []string{"openid", "email"}},
+	}
+
+	for _, tc := range tt {
+		res := RemoveUnconsentedScopes(tc.scopes, tc.consented)
+		if !reflect.DeepEqual(res, tc.expect) {
+			t.Errorf("RemoveUnconsentedScopes(%v, %v) expects %v, but got %v", tc.scopes, tc.consented, tc.expect, res)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package oidc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strings"
+
+	"github.com/sh-miyoshi/hekate/pkg/util"
+)
+
+const sessionStateSaltLength = 16
+
+// SessionState returns session_state parameter of the authentication response
+// The RP's check_session_iframe calculates it again with the browser state in the cookie to detect the change of the session.
+//   ref. https://openid.net/specs/openid-connect-session-1_0.html#CreatingUpdatingSessions
+func SessionState(clientID string, origin string, browserState string, salt string) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{clientID, origin, browserState, salt}, " ")))
+	return hex.EncodeToString(sum[:]) + "." + salt
+}
+
+// NewSessionState returns session_state with a new salt
+func NewSessionState(clientID string, redirectURI string, browserState string) string {
+	salt := util.RandomString(sessionStateSaltLength, util.CharTypeDigit|util.CharTypeLower)
+	return SessionState(clientID, Origin(redirectURI), browserState, salt)
+}
+
+// Origin returns the origin of the URL, e.g. "https://rp.example.com:8080"
+func Origin(rawurl string) string {
+	u, err := url.Parse(rawurl)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return ""
+	}
+	return strings.ToLower(u.Scheme) + "://" + strings.ToLower(u.Host)
+}
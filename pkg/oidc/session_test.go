@@ -0,0 +1,48 @@
+package oidc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOrigin(t *testing.T) {
+	tt := []struct {
+		url    string
+		expect string
+	}{
+		{"https://rp.example.com/callback?x=1", "https://rp.example.com"},
+		{"http://localhost:3000/callback", "http://localhost:3000"},
+		{"HTTPS://RP.example.com", "https://rp.example.com"},
+		{"/callback", ""},
+		{"", ""},
+	}
+
+	for _, tc := range tt {
+		res := Origin(tc.url)
+		if res != tc.expect {
+			t.Errorf("Origin(%s) returns %s, but expect %s", tc.url, res, tc.expect)
+		}
+	}
+}
+
+func TestSessionState(t *testing.T) {
+	s := NewSessionState("client", "https://rp.example.com/callback", "state")
+	i := strings.LastIndex(s, ".")
+	if i < 0 {
+		t.Fatalf("NewSessionState returns %s, but it has no salt", s)
+	}
+	salt := s[i+1:]
+	if len(salt) != sessionStateSaltLength {
+		t.Errorf("Expect salt length %d, but got %d", sessionStateSaltLength, len(salt))
+	}
+
+	if SessionState("client", "https://rp.example.com", "state", salt) != s {
+		t.Errorf("SessionState with the same parameters returns a different value")
+	}
+	if SessionState("client", "https://rp.example.com", "changed", salt) == s {
+		t.Errorf("SessionState returns the same value for the changed browser state")
+	}
+	if SessionState("client", "https://other.example.com", "state", salt) == s {
+		t.Errorf("SessionState returns the same value for the other origin")
+	}
+}
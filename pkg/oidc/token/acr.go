@@ -0,0 +1,82 @@
+package token
+
+import (
+	"strconv"
+
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+)
+
+// Authentication context class reference values issued by the server
+const (
+	// ACRSingleFactor means that the user was authenticated by one factor such as password
+	ACRSingleFactor = "1"
+	// ACRMultiFactor means that the user was authenticated by multiple factors
+	ACRMultiFactor = "2"
+)
+
+// SupportedACRValues returns a list of acr values which the server can satisfy
+func SupportedACRValues() []string {
+	return []string{ACRSingleFactor, ACRMultiFactor}
+}
+
+// ACRLevel returns the acr value mapped from the authentication methods(amr)
+// The trusted device is not counted as a factor because it only skips the second factor of the MFA policy.
+func ACRLevel(authMethods []string) string {
+	factors := 0
+	for _, m := range authMethods {
+		switch m {
+		case model.AuthMethodPassword, model.AuthMethodOTP, model.AuthMethodWebAuthn, model.AuthMethodEMail, model.AuthMethodSMS:
+			factors++
+		case model.AuthMethodMFA:
+			// the authenticator verified the user in addition to the possession of the key
+			factors += 2
+		}
+	}
+
+	switch {
+	case factors == 0:
+		return ""
+	case factors == 1:
+		return ACRSingleFactor
+	}
+	return ACRMultiFactor
+}
+
+// ACRSatisfied returns true if the acr value meets at least one of the requested values.
+// Unknown requested values are ignored, so it returns true if no known value is requested.
+func ACRSatisfied(acr string, requested []string) bool {
+	// acr values are ordered by the strength, and empty acr means no authentication
+	level, err := strconv.Atoi(acr)
+	if err != nil {
+		level = 0
+	}
+
+	known := false
+	for _, r := range requested {
+		switch r {
+		case ACRSingleFactor, ACRMultiFactor:
+			known = true
+			n, _ := strconv.Atoi(r)
+			if level >= n {
+				return true
+			}
+		}
+	}
+	return !known
+}
+
+// EssentialACRValues returns acr values which are requested as an essential claim of the id token
+func (c *ClaimsRequest) EssentialACRValues() []string {
+	if c == nil {
+		return nil
+	}
+
+	e, ok := c.IDToken["acr"]
+	if !ok || e == nil || !e.Essential {
+		return nil
+	}
+	if e.Value != "" {
+		return []string{e.Value}
+	}
+	return e.Values
+}
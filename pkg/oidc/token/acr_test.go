@@ -0,0 +1,126 @@
+package token
+
+import (
+	"testing"
+
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+)
+
+func TestACRSatisfied(t *testing.T) {
+	tt := []struct {
+		authMethods []string
+		requested   []string
+		expect      bool
+	}{
+		{
+			[]string{model.AuthMethodPassword},
+			[]string{},
+			true,
+		},
+		{
+			[]string{model.AuthMethodPassword},
+			[]string{ACRSingleFactor},
+			true,
+		},
+		{
+			[]string{model.AuthMethodPassword},
+			[]string{ACRMultiFactor},
+			false,
+		},
+		{
+			[]string{model.AuthMethodPassword, model.AuthMethodOTP},
+			[]string{ACRMultiFactor},
+			true,
+		},
+		{
+			[]string{model.AuthMethodPassword, model.AuthMethodOTP},
+			[]string{ACRSingleFactor},
+			true,
+		},
+		{
+			[]string{model.AuthMethodPassword, model.AuthMethodWebAuthn},
+			[]string{ACRMultiFactor},
+			true,
+		},
+		{
+			[]string{model.AuthMethodWebAuthn},
+			[]string{ACRMultiFactor},
+			false,
+		},
+		{
+			[]string{model.AuthMethodWebAuthn, model.AuthMethodMFA},
+			[]string{ACRMultiFactor},
+			true,
+		},
+		{
+			[]string{model.AuthMethodPassword, model.AuthMethodEMail},
+			[]string{ACRMultiFactor},
+			true,
+		},
+		{
+			[]string{model.AuthMethodPassword, model.AuthMethodTrustedDevice},
+			[]string{ACRMultiFactor},
+			false,
+		},
+		{
+			[]string{model.AuthMethodPassword, model.AuthMethodTrustedDevice},
+			[]string{ACRSingleFactor},
+			true,
+		},
+		{
+			[]string{model.AuthMethodTrustedDevice},
+			[]string{ACRSingleFactor},
+			false,
+		},
+		{
+			[]string{model.AuthMethodEMail},
+			[]string{ACRMultiFactor},
+			false,
+		},
+		{
+			[]string{model.AuthMethodPassword, model.AuthMethodSMS},
+			[]string{ACRMultiFactor},
+			true,
+		},
+		{
+			[]string{},
+			[]string{ACRSingleFactor},
+			false,
+		},
+		{
+			[]string{model.AuthMethodPassword},
+			[]string{"urn:unknown:acr"},
+			true,
+		},
+	}
+
+	for _, tc := range tt {
+		res := ACRSatisfied(ACRLevel(tc.authMethods), tc.requested)
+		if res != tc.expect {
+			t.Errorf("ACRSatisfied with amr %v, requested %v expects %v, but got %v", tc.authMethods, tc.requested, tc.expect, res)
+		}
+	}
+}
+
+func TestACRSatisfiedLevel(t *testing.T) {
+	tt := []struct {
+		acr       string
+		requested []string
+		expect    bool
+	}{
+		{"", []string{ACRSingleFactor}, false},
+		{ACRSingleFactor, []string{ACRMultiFactor}, false},
+		{ACRMultiFactor, []string{ACRSingleFactor}, true},
+		{ACRSingleFactor, []string{ACRMultiFactor, ACRSingleFactor}, true},
+		// "10" is lower than "2" if the levels are compared as strings
+		{"10", []string{ACRMultiFactor}, true},
+		{"broken", []string{ACRSingleFactor}, false},
+	}
+
+	for _, tc := range tt {
+		res := ACRSatisfied(tc.acr, tc.requested)
+		if res != tc.expect {
+			t.Errorf("ACRSatisfied with acr %q, requested %v expects %v, but got %v", tc.acr, tc.requested, tc.expect, res)
+		}
+	}
+}
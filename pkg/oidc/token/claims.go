@@ -0,0 +1,206 @@
+package token
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/sh-miyoshi/hekate/pkg/db"
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/stretchr/stew/slice"
+)
+
+// ClaimRequestEntry is a member of the claims request parameter
+//   ref. https://openid.net/specs/openid-connect-core-1_0.html#IndividualClaimsRequests
+type ClaimRequestEntry struct {
+	Essential bool     `json:"essential,omitempty"`
+	Value     string   `json:"value,omitempty"`
+	Values    []string `json:"values,omitempty"`
+}
+
+// ClaimsRequest is a parsed value of the claims request parameter
+type ClaimsRequest struct {
+	UserInfo map[string]*ClaimRequestEntry `json:"userinfo"`
+	IDToken  map[string]*ClaimRequestEntry `json:"id_token"`
+}
+
+type addressClaim struct {
+	Formatted     string `json:"formatted,omitempty"`
+	StreetAddress string `json:"street_address,omitempty"`
+	Locality      string `json:"locality,omitempty"`
+	Region        string `json:"region,omitempty"`
+	PostalCode    string `json:"postal_code,omitempty"`
+	Country       string `json:"country,omitempty"`
+}
+
+// ParseClaimsRequest parses the claims request parameter
+func ParseClaimsRequest(claims string) (*ClaimsRequest, *errors.Error) {
+	res := &ClaimsRequest{}
+	if claims == "" {
+		return res, nil
+	}
+
+	if err := json.Unmarshal([]byte(claims), res); err != nil {
+		return nil, errors.Append(errors.ErrInvalidRequest, "Failed to parse claims parameter: %v", err)
+	}
+	return res, nil
+}
+
+// UserInfoClaims returns a list of claim names requested for the userinfo endpoint
+func (c *ClaimsRequest) UserInfoClaims() []string {
+	if c == nil {
+		return nil
+	}
+	return claimNames(c.UserInfo)
+}
+
+// IDTokenClaims returns a list of claim names requested for the id token
+func (c *ClaimsRequest) IDTokenClaims() []string {
+	if c == nil {
+		return nil
+	}
+	return claimNames(c.IDToken)
+}
+
+func claimNames(entries map[string]*ClaimRequestEntry) []string {
+	res := []string{}
+	for name := range entries {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// SupportedUserClaims returns a list of user claims which the server can release
+func SupportedUserClaims() []string {
+	res := append([]string{}, model.StandardUserClaims...)
+	sort.Strings(res)
+	return res
+}
+
+// UserClaims returns user claims released by client scopes and individually requested claims
+func UserClaims(user *model.UserInfo, scopes []*model.ClientScope, requested []string) map[string]interface{} {
+	names := []string{}
+	for _, s := range scopes {
+		names = append(names, s.Claims...)
+	}
+	names = append(names, requested...)
+
+	res := map[string]interface{}{}
+	for _, name := range names {
+		if v := userClaimValue(user, name); v != nil {
+			res[name] = v
+		}
+	}
+	return res
+}
+
+// getClientScopes returns client scopes of the names in the project.
+// Unknown scope names are ignored.
+func getClientScopes(projectName string, names []string) ([]*model.ClientScope, *errors.Error) {
+	res := []*model.ClientScope{}
+	if len(names) == 0 {
+		return res, nil
+	}
+
+	scopes, err := db.GetInst().ClientScopeGetList(projectName, nil)
+	if err != nil {
+		return nil, errors.Append(err, "Failed to get client scope list")
+	}
+	for _, s := range scopes {
+		if slice.Contains(names, s.Name) {
+			res = append(res, s)
+		}
+	}
+	return res, nil
+}
+
+func userClaimValue(user *model.UserInfo, name string) interface{} {
+	str := func(v string) interface{} {
+		if v == "" {
+			return nil
+		}
+		return v
+	}
+
+	switch name {
+	case "name":
+		return str(strings.TrimSpace(user.GivenName + " " + user.FamilyName))
+	case "family_name":
+		return str(user.FamilyName)
+	case "given_name":
+		return str(user.GivenName)
+	case "preferred_username":
+		return str(user.Name)
+	case "locale":
+		return str(user.Locale)
+	case "zoneinfo":
+		return str(user.Zoneinfo)
+	case "updated_at":
+		if user.UpdatedAt.IsZero() {
+			return nil
+		}
+		return user.UpdatedAt.Unix()
+	case "email":
+		return str(user.EMail)
+	case "email_verified":
+		if user.EMail == "" {
+			return nil
+		}
+		return user.EMailVerified
+	case "address":
+		addr := addressClaim(user.Address)
+		if addr == (addressClaim{}) {
+			return nil
+		}
+		return addr
+	case "phone_number":
+		return str(user.PhoneNumber)
+	case "phone_number_verified":
+		if user.PhoneNumber == "" {
+			return nil
+		}
+		return user.PhoneNumberVerified
+	}
+	return nil
+}
+
+// marshalWithClaims marshals v and appends extra claims which are not defined in v
+func marshalWithClaims(v interface{}, extra map[string]interface{}) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return b, err
+	}
+
+	res := map[string]interface{}{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&res); err != nil {
+		return nil, err
+	}
+	for k, val := range extra {
+		if _, exists := res[k]; !exists {
+			res[k] = val
+		}
+	}
+	return json.Marshal(res)
+}
+
+func scopeString(scopes []string) string {
+	res := []string{}
+	for _, s := range scopes {
+		if s != "" && !slice.Contains(res, s) {
+			res = append(res, s)
+		}
+	}
+	return strings.Join(res, " ")
+}
+
+func splitScope(scope string) []string {
+	if scope == "" {
+		return []string{}
+	}
+	return strings.Split(scope, " ")
+}
@@ -0,0 +1,108 @@
+package token
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/stretchr/stew/slice"
+)
+
+func TestUserClaims(t *testing.T) {
+	user := &model.UserInfo{
+		Name:       "user",
+		EMail:      "user@example.com",
+		GivenName:  "Taro",
+		FamilyName: "Yamada",
+		UpdatedAt:  time.Unix(100, 0),
+		Address: model.Address{
+			Country: "JP",
+		},
+	}
+
+	tt := []struct {
+		scopes    []string
+		requested []string
+		expect    map[string]interface{}
+	}{
+		{
+			[]string{"openid"},
+			nil,
+			map[string]interface{}{},
+		},
+		{
+			[]string{"openid", "email"},
+			nil,
+			map[string]interface{}{
+				"email":          "user@example.com",
+				"email_verified": false,
+			},
+		},
+		{
+			[]string{"openid", "profile"},
+			nil,
+			map[string]interface{}{
+				"name":               "Taro Yamada",
+				"given_name":         "Taro",
+				"family_name":        "Yamada",
+				"preferred_username": "user",
+				"updated_at":         int64(100),
+			},
+		},
+		{
+			[]string{"openid", "phone"},
+			[]string{"address", "unknown"},
+			map[string]interface{}{
+				"address": addressClaim{Country: "JP"},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		scopes := []*model.ClientScope{}
+		for _, s := range model.DefaultClientScopes("test", time.Now()) {
+			if slice.Contains(tc.scopes, s.Name) {
+				scopes = append(scopes, s)
+			}
+		}
+
+		res := UserClaims(user, scopes, tc.requested)
+		if !reflect.DeepEqual(res, tc.expect) {
+			t.Errorf("UserClaims returns wrong claims by scopes %v. got %v, want %v", tc.scopes, res, tc.expect)
+		}
+	}
+}
+
+func TestParseClaimsRequest(t *testing.T) {
+	tt := []struct {
+		claims   string
+		userInfo []string
+		idToken  []string
+		isErr    bool
+	}{
+		{"", []string{}, []string{}, false},
+		{`{"userinfo":{"given_name":{"essential":true},"email":null},"id_token":{"auth_time":null}}`, []string{"email", "given_name"}, []string{"auth_time"}, false},
+		{`invalid`, nil, nil, true},
+	}
+
+	for _, tc := range tt {
+		res, err := ParseClaimsRequest(tc.claims)
+		if tc.isErr {
+			if err == nil {
+				t.Errorf("ParseClaimsRequest(%s) expects error, but got nil", tc.claims)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseClaimsRequest(%s) returns unexpected error: %v", tc.claims, err)
+			continue
+		}
+		if !reflect.DeepEqual(res.UserInfoClaims(), tc.userInfo) {
+			t.Errorf("Wrong userinfo claims. got %v, want %v", res.UserInfoClaims(), tc.userInfo)
+		}
+		if !reflect.DeepEqual(res.IDTokenClaims(), tc.idToken) {
+			t.Errorf("Wrong id token claims. got %v, want %v", res.IDTokenClaims(), tc.idToken)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package token
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/json"
+	"math/big"
+	"strings"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	jose "github.com/dvsekhvalnov/jose2go"
+	"github.com/dvsekhvalnov/jose2go/base64url"
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+)
+
+// defaultJWEEncryption is used when the client sets only the key management algorithm
+//   ref. https://openid.net/specs/openid-connect-registration-1_0.html#ClientMetadata
+const defaultJWEEncryption = jose.A128CBC_HS256
+
+type clientJWK struct {
+	KeyType      string `json:"kty"`
+	KeyID        string `json:"kid"`
+	PublicKeyUse string `json:"use"`
+	N            string `json:"n"`
+	E            string `json:"e"`
+}
+
+type clientJWKSet struct {
+	Keys []clientJWK `json:"keys"`
+}
+
+// contentKeySize is a key size in bytes used in "dir" algorithm
+var contentKeySize = map[string]int{
+	jose.A128CBC_HS256: 32,
+	jose.A192CBC_HS384: 48,
+	jose.A256CBC_HS512: 64,
+	jose.A128GCM:       16,
+	jose.A192GCM:       24,
+	jose.A256GCM:       32,
+}
+
+// wrapKeySize is a key size in bytes used in AES key wrap algorithms
+var wrapKeySize = map[string]int{
+	jose.A128KW: 16,
+	jose.A192KW: 24,
+	jose.A256KW: 32,
+}
+
+// EncryptToken wraps the payload in JWE with the client key.
+// cty is set to the content type header, e.g. "JWT" for the nested signed token.
+func EncryptToken(payload string, cty string, alg string, enc string, client *model.ClientInfo) (string, *errors.Error) {
+	if enc == "" {
+		enc = defaultJWEEncryption
+	}
+
+	key, kid, err := encryptionKey(alg, enc, client)
+	if err != nil {
+		return "", errors.Append(err, "Failed to get encryption key")
+	}
+
+	headers := map[string]interface{}{}
+	if cty != "" {
+		headers["cty"] = cty
+	}
+	if kid != "" {
+		headers["kid"] = kid
+	}
+
+	res, e := jose.Encrypt(payload, alg, enc, key, jose.Headers(headers))
+	if e != nil {
+		return "", errors.New("Failed to encrypt token", "Failed to encrypt token with %s and %s: %v", alg, enc, e)
+	}
+	return res, nil
+}
+
+// GenerateUserInfoResponse returns the userinfo response as JWT in the format required by the client.
+// The response is signed and/or encrypted, so the content type is application/jwt.
+func GenerateUserInfoResponse(projectName string, issuer string, userInfo map[string]interface{}, client *model.ClientInfo) (string, *errors.Error) {
+	payload := ""
+	cty := ""
+	if client.UserinfoSignedResponseAlg != "" {
+		// signed userinfo response should contain iss and aud claims
+		//   ref. https://openid.net/specs/openid-connect-core-1_0.html#UserInfoResponse
+		claims := jwt.MapClaims{}
+		for k, v := range userInfo {
+			claims[k] = v
+		}
+		claims["iss"] = issuer
+		claims["aud"] = client.ID
+
+		var err *errors.Error
+		payload, err = signToken(projectName, claims)
+		if err != nil {
+			return "", errors.Append(err, "Failed to sign userinfo")
+		}
+		cty = "JWT"
+	} else {
+		bytes, e := json.Marshal(userInfo)
+		if e != nil {
+			return "", errors.New("Failed to marshal userinfo", "Failed to marshal userinfo: %v", e)
+		}
+		payload = string(bytes)
+	}
+
+	if client.UserinfoEncryptedResponseAlg == "" {
+		return payload, nil
+	}
+	return EncryptToken(payload, cty, client.UserinfoEncryptedResponseAlg, client.UserinfoEncryptedResponseEnc, client)
+}
+
+// UserInfoJWTRequired returns true if the client requires the userinfo response as JWT
+func UserInfoJWTRequired(client *model.ClientInfo) bool {
+	return client.UserinfoSignedResponseAlg != "" || client.UserinfoEncryptedResponseAlg != ""
+}
+
+func encryptionKey(alg string, enc string, client *model.ClientInfo) (interface{}, string, *errors.Error) {
+	if strings.HasPrefix(alg, "RSA") {
+		return clientPublicKey(client.JWKS)
+	}
+
+	// symmetric key is derived from the client secret
+	//   ref. https://openid.net/specs/openid-connect-core-1_0.html#Encryption
+	if client.Secret == "" {
+		return nil, "", errors.New("Invalid client", "Client secret is required for algorithm %s", alg)
+	}
+
+	size := 0
+	if alg == jose.DIR {
+		size = contentKeySize[enc]
+	} else {
+		size = wrapKeySize[alg]
+	}
+	if size == 0 {
+		return nil, "", errors.New("Invalid client", "Unsupported encryption algorithm %s with %s", alg, enc)
+	}
+	return deriveSymmetricKey(client.Secret, size), "", nil
+}
+
+// deriveSymmetricKey returns the left-most bytes of SHA-2 hash of the secret
+func deriveSymmetricKey(secret string, size int) []byte {
+	var sum []byte
+	switch {
+	case size <= 32:
+		s := sha256.Sum256([]byte(secret))
+		sum = s[:]
+	case size <= 48:
+		s := sha512.Sum384([]byte(secret))
+		sum = s[:]
+	default:
+		s := sha512.Sum512([]byte(secret))
+		sum = s[:]
+	}
+	return sum[:size]
+}
+
+// clientPublicKey returns the RSA public key for encryption in the client JWKS
+func clientPublicKey(jwks string) (*rsa.PublicKey, string, *errors.Error) {
+	if jwks == "" {
+		return nil, "", errors.New("Invalid client", "Client JWKS is not registered")
+	}
+
+	var set clientJWKSet
+	if e := json.Unmarshal([]byte(jwks), &set); e != nil {
+		return nil, "", errors.New("Invalid client", "Failed to parse client JWKS: %v", e)
+	}
+
+	for _, k := range set.Keys {
+		if k.KeyType != "RSA" || (k.PublicKeyUse != "" && k.PublicKeyUse != "enc") {
+			continue
+		}
+
+		n, e := base64url.Decode(k.N)
+		if e != nil {
+			return nil, "", errors.New("Invalid client", "Failed to decode modulus of key %s: %v", k.KeyID, e)
+		}
+		exp, e := base64url.Decode(k.E)
+		if e != nil {
+			return nil, "", errors.New("Invalid client", "Failed to decode exponent of key %s: %v", k.KeyID, e)
+		}
+
+		key := &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(exp).Int64()),
+		}
+		return key, k.KeyID, nil
+	}
+
+	return nil, "", errors.New("Invalid client", "No RSA encryption key in client JWKS")
+}
@@ -0,0 +1,57 @@
+package token
+
+import (
+	"testing"
+
+	jose "github.com/dvsekhvalnov/jose2go"
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+)
+
+func TestEncryptTokenWithSecret(t *testing.T) {
+	tt := []struct {
+		alg    string
+		enc    string
+		secret string
+		expErr bool
+	}{
+		{jose.DIR, jose.A128GCM, "secret", false},
+		{jose.DIR, jose.A256CBC_HS512, "secret", false},
+		{jose.DIR, "", "secret", false},
+		{jose.A128KW, jose.A128CBC_HS256, "secret", false},
+		{jose.A256KW, jose.A256GCM, "secret", false},
+		{jose.A128KW, jose.A128CBC_HS256, "", true},
+		{jose.RSA_OAEP, jose.A128CBC_HS256, "secret", true},
+	}
+
+	for _, tc := range tt {
+		cli := &model.ClientInfo{ID: "client", Secret: tc.secret}
+		res, err := EncryptToken("payload", "", tc.alg, tc.enc, cli)
+		if tc.expErr {
+			if err == nil {
+				t.Errorf("EncryptToken with %s %s should return error, but got nil", tc.alg, tc.enc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("EncryptToken with %s %s returned unexpected error: %v", tc.alg, tc.enc, err)
+			continue
+		}
+
+		enc := tc.enc
+		if enc == "" {
+			enc = defaultJWEEncryption
+		}
+		size := contentKeySize[enc]
+		if tc.alg != jose.DIR {
+			size = wrapKeySize[tc.alg]
+		}
+		payload, _, e := jose.Decode(res, deriveSymmetricKey(tc.secret, size))
+		if e != nil {
+			t.Errorf("Failed to decrypt token with %s %s: %v", tc.alg, tc.enc, e)
+			continue
+		}
+		if payload != "payload" {
+			t.Errorf("Decrypted payload is wrong. expect: payload, but got %s", payload)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package token
+
+import (
+	"github.com/sh-miyoshi/hekate/pkg/db"
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/stretchr/stew/slice"
+)
+
+type mapperTarget int
+
+const (
+	targetIDToken mapperTarget = iota
+	targetAccessToken
+	targetUserInfo
+)
+
+func (t mapperTarget) match(m *model.ProtocolMapper) bool {
+	switch t {
+	case targetIDToken:
+		return m.IDToken
+	case targetAccessToken:
+		return m.AccessToken
+	case targetUserInfo:
+		return m.UserInfo
+	}
+	return false
+}
+
+// getMappers returns protocol mappers of the client and the granted client scopes
+func getMappers(projectName string, clientID string, scopes []*model.ClientScope) ([]model.ProtocolMapper, *errors.Error) {
+	res := []model.ProtocolMapper{}
+	if clientID != "" {
+		cli, err := db.GetInst().ClientGet(projectName, clientID)
+		if err != nil {
+			return nil, errors.Append(err, "Failed to get client %s", clientID)
+		}
+		res = append(res, cli.ProtocolMappers...)
+	}
+
+	for _, s := range scopes {
+		res = append(res, s.ProtocolMappers...)
+	}
+	return res, nil
+}
+
+// applyMappers sets custom claims into claims and returns audiences appended by the mappers
+func applyMappers(target mapperTarget, request Request, scopes []*model.ClientScope, user *model.UserInfo, claims map[string]interface{}, audiences []string) ([]string, *errors.Error) {
+	mappers, err := getMappers(request.ProjectName, request.ClientID, scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	audiences = append([]string{}, audiences...)
+
+	for _, m := range mappers {
+		if !target.match(&m) {
+			continue
+		}
+		if user == nil && m.Type != model.MapperTypeHardcoded && m.Type != model.MapperTypeAudience {
+			// the token is issued to the client itself, so there are no user claims
+			continue
+		}
+
+		switch m.Type {
+		case model.MapperTypeUserAttribute:
+			values := user.Attributes[m.UserAttribute]
+			if len(values) == 0 {
+				continue
+			}
+			if m.Multivalued {
+				claims[m.ClaimName] = values
+			} else {
+				claims[m.ClaimName] = values[0]
+			}
+		case model.MapperTypeHardcoded:
+			claims[m.ClaimName] = m.Value
+		case model.MapperTypeRoleList:
+			roles := []string{}
+			for _, rid := range user.CustomRoles {
+				role, err := db.GetInst().CustomRoleGet(request.ProjectName, rid)
+				if err != nil {
+					return nil, errors.Append(err, "Failed to get custom role name")
+				}
+				roles = append(roles, role.Name)
+			}
+			claims[m.ClaimName] = roles
+		case model.MapperTypeGroups:
+			attr := m.UserAttribute
+			if attr == "" {
+				attr = "groups"
+			}
+			groups := user.Attributes[attr]
+			if groups == nil {
+				groups = []string{}
+			}
+			claims[m.ClaimName] = groups
+		case model.MapperTypeAudience:
+			if !slice.Contains(audiences, m.Value) {
+				audiences = append(audiences, m.Value)
+			}
+		}
+	}
+
+	return audiences, nil
+}
+
+// GetUserInfo returns claims released by the userinfo endpoint
+func GetUserInfo(user *model.UserInfo, claims *AccessTokenClaims) (map[string]interface{}, *errors.Error) {
+	scopes, err := getClientScopes(claims.Project, splitScope(claims.Scope))
+	if err != nil {
+		return nil, err
+	}
+
+	res := UserClaims(user, scopes, claims.RequestedClaims)
+	req := Request{
+		ProjectName: claims.Project,
+		ClientID:    claims.ClientID,
+	}
+	if _, err := applyMappers(targetUserInfo, req, scopes, user, res, nil); err != nil {
+		return nil, errors.Append(err, "Failed to apply protocol mappers")
+	}
+	res["sub"] = claims.Subject
+	return res, nil
+}
@@ -12,12 +12,20 @@ import (
 	"github.com/google/uuid"
 	"github.com/sh-miyoshi/hekate/pkg/config"
 	"github.com/sh-miyoshi/hekate/pkg/db"
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
 	"github.com/sh-miyoshi/hekate/pkg/errors"
 	"github.com/sh-miyoshi/hekate/pkg/logger"
-	"github.com/stretchr/stew/slice"
 )
 
+// accessTokenType is a media type of the JWT access token
+//   ref. https://tools.ietf.org/html/rfc9068#section-2.1
+const accessTokenType = "at+jwt"
+
 func signToken(projectName string, claims jwt.Claims) (string, *errors.Error) {
+	return signTokenWithType(projectName, "JWT", claims)
+}
+
+func signTokenWithType(projectName string, typ string, claims jwt.Claims) (string, *errors.Error) {
 	project, err := db.GetInst().ProjectGet(projectName)
 	if err != nil {
 		return "", errors.Append(err, "Failed to get project")
@@ -25,6 +33,7 @@ func signToken(projectName string, claims jwt.Claims) (string, *errors.Error) {
 	switch project.TokenConfig.SigningAlgorithm {
 	case "RS256":
 		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["typ"] = typ
 		key, err := x509.ParsePKCS1PrivateKey(project.TokenConfig.SignSecretKey)
 		if err != nil {
 			return "", errors.New("Invalid request", "Failed to parse private key: %v", err)
@@ -40,18 +49,33 @@ func signToken(projectName string, claims jwt.Claims) (string, *errors.Error) {
 }
 
 // GenerateAccessToken ...
+// If the request has no user, e.g. client credentials grant, the subject is the client itself.
 func GenerateAccessToken(audiences []string, request Request) (string, *errors.Error) {
-	user, err := db.GetInst().UserGet(request.ProjectName, request.UserID)
+	var user *model.UserInfo
+	subject := request.ClientID
+	userName := ""
+	if request.UserID != "" {
+		var err *errors.Error
+		user, err = db.GetInst().UserGet(request.ProjectName, request.UserID)
+		if err != nil {
+			return "", errors.Append(err, "Failed to get user")
+		}
+		subject = user.ID
+		userName = user.Name
+	}
+
+	scopes, err := getClientScopes(request.ProjectName, request.Scopes)
 	if err != nil {
-		return "", errors.Append(err, "Failed to get user")
+		return "", err
+	}
+
+	userClaims := map[string]interface{}{}
+	if user != nil {
+		userClaims = UserClaims(user, scopes, nil)
 	}
 
 	now := time.Now()
 	expires := time.Second * time.Duration(request.ExpiresIn)
-	var email *string
-	if slice.Contains(request.Scopes, "email") {
-		email = &user.EMail
-	}
 
 	claims := AccessTokenClaims{
 		jwt.StandardClaims{
@@ -60,7 +84,7 @@ func GenerateAccessToken(audiences []string, request Request) (string, *errors.E
 			IssuedAt:  now.Unix(),
 			ExpiresAt: now.Add(expires).Unix(),
 			NotBefore: 0,
-			Subject:   request.UserID,
+			Subject:   subject,
 		},
 		request.ProjectName,
 		audiences,
@@ -69,33 +93,39 @@ func GenerateAccessToken(audiences []string, request Request) (string, *errors.E
 				Roles: []string{},
 			},
 		},
-		user.Name,
+		userName,
 		"access",
-		email,
+		scopeString(request.Scopes),
+		request.ClientID,
+		ACRLevel(request.AuthMethods),
+		request.AuthMethods,
+		request.Claims.UserInfoClaims(),
+		userClaims,
 	}
 
-	claims.ResourceAccess.SystemManagement.Roles = append(claims.ResourceAccess.SystemManagement.Roles, user.SystemRoles...)
-	for _, rid := range user.CustomRoles {
-		role, err := db.GetInst().CustomRoleGet(request.ProjectName, rid)
-		if err != nil {
-			return "", errors.Append(err, "Failed to get custom role name")
+	claims.Audience, err = applyMappers(targetAccessToken, request, scopes, user, claims.UserClaims, audiences)
+	if err != nil {
+		return "", errors.Append(err, "Failed to apply protocol mappers")
+	}
+
+	if user != nil {
+		claims.ResourceAccess.SystemManagement.Roles = append(claims.ResourceAccess.SystemManagement.Roles, user.SystemRoles...)
+		for _, rid := range user.CustomRoles {
+			role, err := db.GetInst().CustomRoleGet(request.ProjectName, rid)
+			if err != nil {
+				return "", errors.Append(err, "Failed to get custom role name")
+			}
+			claims.ResourceAccess.User.Roles = append(claims.ResourceAccess.User.Roles, role.Name)
 		}
-		claims.ResourceAccess.User.Roles = append(claims.ResourceAccess.User.Roles, role.Name)
 	}
 
-	return signToken(request.ProjectName, claims)
+	return signTokenWithType(request.ProjectName, accessTokenType, claims)
 }
 
 // GenerateRefreshToken ...
-func GenerateRefreshToken(sessionID string, audiences []string, request Request) (string, *errors.Error) {
+func GenerateRefreshToken(sessionID string, familyID string, audiences []string, request Request) (string, *errors.Error) {
 	now := time.Now()
 	expires := time.Second * time.Duration(request.ExpiresIn)
-	scope := ""
-	for _, s := range request.Scopes {
-		scope += s + " "
-	}
-	scope = strings.TrimSuffix(scope, " ")
-
 	claims := &RefreshTokenClaims{
 		jwt.StandardClaims{
 			Id:        uuid.New().String(),
@@ -109,7 +139,9 @@ func GenerateRefreshToken(sessionID string, audiences []string, request Request)
 		sessionID,
 		audiences,
 		"refresh",
-		scope,
+		scopeString(request.Scopes),
+		familyID,
+		request.Resources,
 	}
 
 	return signToken(request.ProjectName, claims)
@@ -117,6 +149,16 @@ func GenerateRefreshToken(sessionID string, audiences []string, request Request)
 
 // GenerateIDToken ...
 func GenerateIDToken(audiences []string, request Request) (string, *errors.Error) {
+	user, err := db.GetInst().UserGet(request.ProjectName, request.UserID)
+	if err != nil {
+		return "", errors.Append(err, "Failed to get user")
+	}
+
+	scopes, err := getClientScopes(request.ProjectName, request.Scopes)
+	if err != nil {
+		return "", err
+	}
+
 	now := time.Now()
 	expires := time.Second * time.Duration(request.ExpiresIn)
 	claims := &IDTokenClaims{
@@ -132,9 +174,33 @@ func GenerateIDToken(audiences []string, request Request) (string, *errors.Error
 		request.Nonce,
 		request.EndUserAuthTime.Unix(),
 		"id",
+		ACRLevel(request.AuthMethods),
+		request.AuthMethods,
+		request.ClientID,
+		UserClaims(user, scopes, request.Claims.IDTokenClaims()),
 	}
 
-	return signToken(request.ProjectName, claims)
+	claims.Audience, err = applyMappers(targetIDToken, request, scopes, user, claims.UserClaims, audiences)
+	if err != nil {
+		return "", errors.Append(err, "Failed to apply protocol mappers")
+	}
+
+	tkn, err := signToken(request.ProjectName, claims)
+	if err != nil {
+		return "", err
+	}
+
+	client, err := db.GetInst().ClientGet(request.ProjectName, request.ClientID)
+	if err != nil {
+		return "", errors.Append(err, "Failed to get client")
+	}
+	if client.IDTokenEncryptedResponseAlg == "" {
+		return tkn, nil
+	}
+
+	// the id token is signed and then encrypted as a nested JWT
+	//   ref. https://openid.net/specs/openid-connect-core-1_0.html#SigningOrder
+	return EncryptToken(tkn, "JWT", client.IDTokenEncryptedResponseAlg, client.IDTokenEncryptedResponseEnc, client)
 }
 
 // GenerateSSOToken ...
@@ -152,6 +218,26 @@ func GenerateSSOToken(request Request) (string, *errors.Error) {
 	return signToken(request.ProjectName, claims)
 }
 
+// GenerateTrustedDeviceToken returns the token which identifies the trusted device of the user
+// The id of the token is the device id, so the device can be revoked in the server side.
+func GenerateTrustedDeviceToken(request Request, deviceID string) (string, *errors.Error) {
+	now := time.Now()
+	expires := time.Second * time.Duration(request.ExpiresIn)
+	claims := TrustedDeviceClaims{
+		StandardClaims: jwt.StandardClaims{
+			Id:        deviceID,
+			Issuer:    request.Issuer,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(expires).Unix(),
+			NotBefore: 0,
+			Subject:   request.UserID,
+		},
+		Project: request.ProjectName,
+		Format:  "trusted_device",
+	}
+	return signToken(request.ProjectName, claims)
+}
+
 // ValidateAccessToken ...
 func ValidateAccessToken(claims *AccessTokenClaims, tokenString string, expectIssuer string) *errors.Error {
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
@@ -255,6 +341,54 @@ func ValidateRefreshToken(claims *RefreshTokenClaims, tokenString string, expect
 	return nil
 }
 
+// ValidateTrustedDeviceToken ...
+func ValidateTrustedDeviceToken(claims *TrustedDeviceClaims, tokenString string, expectIssuer string) *errors.Error {
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		project, err := db.GetInst().ProjectGet(claims.Project)
+		if err != nil {
+			return nil, errors.Append(err, "Failed to get project")
+		}
+
+		if claims.Format != "trusted_device" {
+			return nil, errors.New("Invalid request", "Invalid token format: %s", claims.Format)
+		}
+
+		if claims.Issuer != expectIssuer {
+			logger.Debug("Unexpected token issuer: want %s, got %s", expectIssuer, claims.Issuer)
+			return nil, errors.New("Invalid request", "Unexpected token issuer")
+		}
+		now := time.Now().Unix()
+		if now > claims.ExpiresAt {
+			return nil, errors.New("Invalid request", "Token is expired")
+		}
+
+		switch token.Method {
+		case jwt.SigningMethodRS256:
+			key, err := x509.ParsePKCS1PublicKey(project.TokenConfig.SignPublicKey)
+			if err != nil {
+				return nil, errors.New("Invalid request", "Failed to parse public key: %v", err)
+			}
+			return key, nil
+		}
+
+		return nil, errors.New("Invalid request", "unknown token sigining method")
+	})
+
+	if err != nil {
+		e, ok := err.(*errors.Error)
+		if !ok {
+			return errors.New("Invalid request", err.Error())
+		}
+		return errors.Append(e, "Failed to parse token")
+	}
+
+	if !token.Valid {
+		return errors.New("Invalid request", "Invalid token is specified")
+	}
+
+	return nil
+}
+
 // ValidateIDToken ...
 func ValidateIDToken(claims *IDTokenClaims, tokenString string, projectName string, expectIssuer string) *errors.Error {
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
@@ -320,6 +454,11 @@ func GetFullIssuer(r *http.Request) string {
 	return strings.TrimSuffix(res, "/")
 }
 
+// GetProjectIssuer returns the issuer of the project for the request which is not under the project path
+func GetProjectIssuer(r *http.Request, projectName string) string {
+	return fmt.Sprintf("%s/authapi/v1/project/%s", GetExpectIssuer(r), projectName)
+}
+
 // GetExpectIssuer ...
 func GetExpectIssuer(r *http.Request) string {
 	proto := "http"
@@ -12,9 +12,13 @@ type Request struct {
 	ExpiresIn       int64
 	ProjectName     string
 	UserID          string
+	ClientID        string
 	Nonce           string
 	EndUserAuthTime time.Time
 	Scopes          []string
+	Claims          *ClaimsRequest
+	AuthMethods     []string
+	Resources       []string
 }
 
 // RoleValue ...
@@ -37,7 +41,22 @@ type AccessTokenClaims struct {
 	ResourceAccess RoleSet  `json:"resource_access"`
 	UserName       string   `json:"preferred_username"`
 	Format         string   `json:"format"`
-	EMail          *string  `json:"email"`
+	Scope          string   `json:"scope,omitempty"`
+	ClientID       string   `json:"client_id,omitempty"`
+	ACR            string   `json:"acr,omitempty"`
+	AMR            []string `json:"amr,omitempty"`
+
+	// RequestedClaims is a list of claims individually requested for the userinfo endpoint
+	RequestedClaims []string `json:"requested_claims,omitempty"`
+
+	// UserClaims are user claims released by the granted scopes and the protocol mappers
+	UserClaims map[string]interface{} `json:"-"`
+}
+
+// MarshalJSON ...
+func (c AccessTokenClaims) MarshalJSON() ([]byte, error) {
+	type claims AccessTokenClaims
+	return marshalWithClaims(claims(c), c.UserClaims)
 }
 
 // RefreshTokenClaims ...
@@ -49,16 +68,36 @@ type RefreshTokenClaims struct {
 	Audience  []string `json:"aud"`
 	Format    string   `json:"format"`
 	Scope     string   `json:"scope"`
+	FamilyID  string   `json:"familyID,omitempty"`
+	Resources []string `json:"resources,omitempty"`
+}
+
+// TrustedDeviceClaims ...
+type TrustedDeviceClaims struct {
+	jwt.StandardClaims
+
+	Project string `json:"project"`
+	Format  string `json:"format"`
 }
 
 // IDTokenClaims ...
 type IDTokenClaims struct {
 	jwt.StandardClaims
 
-	Audience []string `json:"aud"`
-	Nonce    string   `json:"nonce"`
-	AuthTime int64    `json:"auth_time"`
-	Format   string   `json:"format"`
-	// TODO(acr, amr, azp)
-	// ref. https://openid-foundation-japan.github.io/openid-connect-core-1_0.ja.html#IDToken
+	Audience        []string `json:"aud"`
+	Nonce           string   `json:"nonce"`
+	AuthTime        int64    `json:"auth_time"`
+	Format          string   `json:"format"`
+	ACR             string   `json:"acr,omitempty"`
+	AMR             []string `json:"amr,omitempty"`
+	AuthorizedParty string   `json:"azp,omitempty"`
+
+	// UserClaims are user claims released by the granted scopes, the claims request parameter and the protocol mappers
+	UserClaims map[string]interface{} `json:"-"`
+}
+
+// MarshalJSON ...
+func (c IDTokenClaims) MarshalJSON() ([]byte, error) {
+	type claims IDTokenClaims
+	return marshalWithClaims(claims(c), c.UserClaims)
 }
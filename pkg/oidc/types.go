@@ -7,9 +7,13 @@ import (
 	validator "github.com/go-playground/validator/v10"
 	"github.com/sh-miyoshi/hekate/pkg/config"
 	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/oidc/token"
 	"github.com/stretchr/stew/slice"
 )
 
+// CodeChallengeMethods is a list of supported PKCE code challenge methods
+var CodeChallengeMethods = []string{"plain", "S256"}
+
 // AuthRequest ...
 type AuthRequest struct {
 	// Required
@@ -29,13 +33,15 @@ type AuthRequest struct {
 	IDTokenHint         string
 	CodeChallenge       string
 	CodeChallengeMethod string
+	Claims              string
+	ACRValues           []string
+	UILocales           []string
+	Resources           []string
 
 	Request string
 
 	// TODO(implement this)
 	// Display string // display(OPTIONAL)
-	// UILocales string // ui_locales(OPTIONAL)
-	// ACRValues string // acr_values(OPTIONAL)
 }
 
 func validatePrompt(prompts []string) *errors.Error {
@@ -84,25 +90,13 @@ func validateResponseMode(mode string) *errors.Error {
 	return nil
 }
 
-func validateScope(scope string, supportedScope []string) *errors.Error {
-	scopes := strings.Split(scope, " ")
-	for _, s := range scopes {
-		if !slice.Contains(scopes, s) {
-			return errors.ErrInvalidScope
-		}
-	}
-
-	return nil
-}
-
 func validateCodeChallenge(challenge string, method string) *errors.Error {
 	if challenge == "" {
 		if method != "" {
 			return errors.ErrInvalidRequest
 		}
 	} else {
-		methods := []string{"plain", "S256"}
-		if !slice.Contains(methods, method) {
+		if !slice.Contains(CodeChallengeMethods, method) {
 			return errors.ErrInvalidRequest
 		}
 	}
@@ -110,6 +104,7 @@ func validateCodeChallenge(challenge string, method string) *errors.Error {
 }
 
 // Validate ...
+// Scope is validated separately by ValidateScope because it depends on the project and the client
 func (r *AuthRequest) Validate() *errors.Error {
 	if err := validator.New().Struct(r); err != nil {
 		return errors.Append(errors.ErrInvalidRequest, err.Error())
@@ -122,11 +117,6 @@ func (r *AuthRequest) Validate() *errors.Error {
 
 	cfg := config.Get()
 
-	// Check Scope
-	if err := validateScope(r.Scope, cfg.SupportedScope); err != nil {
-		return errors.Append(err, "Failed to validate scope %v", r.Scope)
-	}
-
 	// Check Response Type
 	if err := validateResponseType(r.ResponseType, cfg.SupportedResponseType); err != nil {
 		return errors.Append(err, "Failed to validate response type %v", r.ResponseType)
@@ -142,6 +132,11 @@ func (r *AuthRequest) Validate() *errors.Error {
 		return errors.Append(err, "Failed to validate response mode %s", r.ResponseMode)
 	}
 
+	// Check claims request
+	if _, err := token.ParseClaimsRequest(r.Claims); err != nil {
+		return errors.Append(err, "Failed to validate claims %s", r.Claims)
+	}
+
 	// Check CodeChallengeMethod
 	if err := validateCodeChallenge(r.CodeChallenge, r.CodeChallengeMethod); err != nil {
 		return errors.Append(err, "Failed to validate code challenge %s with method %s", r.CodeChallenge, r.CodeChallengeMethod)
@@ -175,4 +170,5 @@ type TokenResponse struct {
 	RefreshToken     string
 	RefreshExpiresIn uint
 	IDToken          string
+	Scope            string
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/sh-miyoshi/hekate/pkg/db"
 	"github.com/sh-miyoshi/hekate/pkg/db/model"
 	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/logger"
 	"github.com/sh-miyoshi/hekate/pkg/oidc/token"
 )
 
@@ -24,6 +25,14 @@ func NewAuthRequest(values url.Values) *AuthRequest {
 		prompt = strings.Split(values.Get("prompt"), " ")
 	}
 	responseTypes := strings.Split(values.Get("response_type"), " ")
+	acrValues := []string{}
+	if values.Get("acr_values") != "" {
+		acrValues = strings.Split(values.Get("acr_values"), " ")
+	}
+	uiLocales := []string{}
+	if values.Get("ui_locales") != "" {
+		uiLocales = strings.Split(values.Get("ui_locales"), " ")
+	}
 
 	resMode := values.Get("response_mode")
 	if resMode == "" {
@@ -49,16 +58,42 @@ func NewAuthRequest(values url.Values) *AuthRequest {
 		CodeChallengeMethod: values.Get("code_challenge_method"),
 		Request:             request,
 		IDTokenHint:         values.Get("id_token_hint"),
+		Claims:              values.Get("claims"),
+		ACRValues:           acrValues,
+		UILocales:           uiLocales,
+		Resources:           values["resource"],
 	}
 }
 
 // CreateLoggedInResponse ...
-func CreateLoggedInResponse(session *model.LoginSession, state, tokenIssuer string) (*http.Request, *errors.Error) {
+// If browserState is not empty, session_state is also returned for OpenID Connect Session Management.
+func CreateLoggedInResponse(session *model.LoginSession, state, tokenIssuer, browserState string) (*http.Request, *errors.Error) {
+	claims, err := token.ParseClaimsRequest(session.Claims)
+	if err != nil {
+		return nil, errors.Append(err, "Failed to parse claims request")
+	}
+
+	session.Scopes, err = GrantScopeNames(session.ProjectName, session.ClientID, session.UserID, session.Scopes)
+	if err != nil {
+		return nil, errors.Append(err, "Failed to grant scopes")
+	}
+	// offline_access is granted only if the user agreed it
+	//   ref. https://openid.net/specs/openid-connect-core-1_0.html#OfflineAccess
+	session.Scopes = RemoveUnconsentedScopes(session.Scopes, session.Consented)
+
 	values := url.Values{}
 	if state != "" {
 		values.Set("state", state)
 	}
 
+	// acr requested as an essential claim must be satisfied
+	//   ref. https://openid.net/specs/openid-connect-unmet-authentication-requirements-1_0.html
+	if acrs := claims.EssentialACRValues(); !token.ACRSatisfied(token.ACRLevel(session.AuthMethods), acrs) {
+		logger.Info("The login does not satisfy the essential acr values %v", acrs)
+		values.Set("error", errors.ErrUnmetAuthenticationRequirements.Error())
+		return newLoggedInRequest(session, values)
+	}
+
 	for _, typ := range session.ResponseType {
 		switch typ {
 		case "code":
@@ -66,20 +101,23 @@ func CreateLoggedInResponse(session *model.LoginSession, state, tokenIssuer stri
 			session.Code = code
 			values.Set("code", code)
 		case "id_token":
-			prj, err := db.GetInst().ProjectGet(session.ProjectName)
+			lifeSpans, err := clientLifeSpans(session.ProjectName, session.ClientID)
 			if err != nil {
-				return nil, errors.Append(err, "Failed to get token lifespan in project")
+				return nil, err
 			}
 
 			audiences := []string{session.UserID, session.ClientID}
 			tokenReq := token.Request{
 				Issuer:          tokenIssuer,
-				ExpiresIn:       int64(prj.TokenConfig.AccessTokenLifeSpan),
+				ExpiresIn:       int64(lifeSpans.IDToken),
 				ProjectName:     session.ProjectName,
 				UserID:          session.UserID,
+				ClientID:        session.ClientID,
 				Nonce:           session.Nonce,
 				EndUserAuthTime: session.LoginDate,
 				Scopes:          session.Scopes,
+				Claims:          claims,
+				AuthMethods:     session.AuthMethods,
 			}
 			tkn, err := token.GenerateIDToken(audiences, tokenReq)
 			if err != nil {
@@ -87,32 +125,70 @@ func CreateLoggedInResponse(session *model.LoginSession, state, tokenIssuer stri
 			}
 			values.Set("id_token", tkn)
 		case "token":
-			prj, err := db.GetInst().ProjectGet(session.ProjectName)
+			lifeSpans, err := clientLifeSpans(session.ProjectName, session.ClientID)
 			if err != nil {
-				return nil, errors.Append(err, "Failed to get token lifespan in project")
+				return nil, err
 			}
 
 			audiences := []string{session.UserID, session.ClientID}
+			scopes := session.Scopes
+			lifeSpan := lifeSpans.AccessToken
+			if len(session.Resources) > 0 {
+				resources, err := GetResources(session.ProjectName, session.Resources)
+				if err != nil {
+					return nil, errors.Append(err, "Failed to get resources")
+				}
+				audiences = ResourceIdentifiers(resources)
+				scopes = ResourceScopes(resources, scopes)
+				lifeSpan = ResourceLifeSpan(resources, lifeSpan)
+			}
+
 			tokenReq := token.Request{
 				Issuer:      tokenIssuer,
-				ExpiresIn:   int64(prj.TokenConfig.AccessTokenLifeSpan),
+				ExpiresIn:   int64(lifeSpan),
 				ProjectName: session.ProjectName,
 				UserID:      session.UserID,
-				Scopes:      session.Scopes,
+				ClientID:    session.ClientID,
+				Scopes:      scopes,
+				Claims:      claims,
+				AuthMethods: session.AuthMethods,
 			}
 			tkn, err := token.GenerateAccessToken(audiences, tokenReq)
 			if err != nil {
 				return nil, errors.Append(err, "Failed to generate access token")
 			}
 			values.Set("access_token", tkn)
+			values.Set("scope", strings.Join(scopes, " "))
 		default:
 			return nil, errors.New("Unknown response type", "Unknown response type %s", typ)
 		}
 	}
 
-	req, err := http.NewRequest("GET", session.RedirectURI, nil)
+	if browserState != "" {
+		values.Set("session_state", NewSessionState(session.ClientID, session.RedirectURI, browserState))
+	}
+
+	return newLoggedInRequest(session, values)
+}
+
+func clientLifeSpans(projectName string, clientID string) (*model.TokenLifeSpans, *errors.Error) {
+	prj, err := db.GetInst().ProjectGet(projectName)
 	if err != nil {
-		return nil, errors.New("Internal server error", "Failed to create response: %v", err)
+		return nil, errors.Append(err, "Failed to get token lifespan in project")
+	}
+	cli, err := db.GetInst().ClientGet(projectName, clientID)
+	if err != nil {
+		return nil, errors.Append(err, "Failed to get token lifespan in client")
+	}
+
+	res := cli.LifeSpans(prj.TokenConfig)
+	return &res, nil
+}
+
+func newLoggedInRequest(session *model.LoginSession, values url.Values) (*http.Request, *errors.Error) {
+	req, e := http.NewRequest("GET", session.RedirectURI, nil)
+	if e != nil {
+		return nil, errors.New("Internal server error", "Failed to create response: %v", e)
 	}
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
@@ -0,0 +1,76 @@
+package oidc
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/sh-miyoshi/hekate/pkg/config"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+)
+
+// IssuerRelation is a link relation type of OpenID Connect issuer in WebFinger
+//   ref. https://openid.net/specs/openid-connect-discovery-1_0.html#IssuerDiscovery
+const IssuerRelation = "http://openid.net/specs/connect/1.0/issuer"
+
+var (
+	// ErrNoSuchWebFingerResource ...
+	ErrNoSuchWebFingerResource = errors.New("No such resource", "No such resource")
+)
+
+var issuerPathRe = regexp.MustCompile(`^/authapi/v1/project/([^/]+)`)
+
+// WebFingerProject returns the project name which the WebFinger resource belongs to
+// The resource is acct: URI or URL, and normalized by the rule of OpenID Connect Discovery
+//   ref. https://openid.net/specs/openid-connect-discovery-1_0.html#NormalizationSteps
+func WebFingerProject(resource string, cfg *config.WebFingerConfig) (string, *errors.Error) {
+	if resource == "" {
+		return "", errors.New("Invalid resource", "resource is empty")
+	}
+
+	if !strings.Contains(resource, ":") || strings.HasPrefix(resource, "//") {
+		if strings.Contains(resource, "@") && !strings.Contains(resource, "/") {
+			resource = "acct:" + resource
+		} else {
+			resource = "https://" + strings.TrimPrefix(resource, "//")
+		}
+	}
+
+	u, err := url.Parse(resource)
+	if err != nil {
+		return "", errors.New("Invalid resource", "Failed to parse resource %s: %v", resource, err)
+	}
+
+	domain := ""
+	switch u.Scheme {
+	case "acct":
+		i := strings.LastIndex(u.Opaque, "@")
+		if i < 0 {
+			return "", errors.New("Invalid resource", "acct resource %s does not have host", resource)
+		}
+		domain = u.Opaque[i+1:]
+	case "http", "https":
+		// URL of the issuer itself has the project name in the path
+		if m := issuerPathRe.FindStringSubmatch(u.Path); len(m) == 2 {
+			return m[1], nil
+		}
+		domain = u.Hostname()
+	default:
+		return "", errors.New("Invalid resource", "Unsupported resource scheme %s", u.Scheme)
+	}
+
+	if domain == "" {
+		return "", errors.New("Invalid resource", "resource %s does not have host", resource)
+	}
+
+	for _, m := range cfg.Mappings {
+		if strings.EqualFold(m.Domain, domain) {
+			return m.Project, nil
+		}
+	}
+
+	if cfg.DefaultProject != "" {
+		return cfg.DefaultProject, nil
+	}
+	return "", ErrNoSuchWebFingerResource
+}
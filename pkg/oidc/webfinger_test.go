@@ -0,0 +1,54 @@
+package oidc
+
+import (
+	"testing"
+
+	"github.com/sh-miyoshi/hekate/pkg/config"
+)
+
+func TestWebFingerProject(t *testing.T) {
+	cfg := &config.WebFingerConfig{
+		Mappings: []config.WebFingerMapping{
+			{Domain: "example.com", Project: "prj1"},
+			{Domain: "sub.example.com", Project: "prj2"},
+		},
+	}
+	defaultCfg := &config.WebFingerConfig{
+		DefaultProject: "master",
+		Mappings:       cfg.Mappings,
+	}
+
+	tt := []struct {
+		resource string
+		cfg      *config.WebFingerConfig
+		expect   string
+		expectOK bool
+	}{
+		{"acct:alice@example.com", cfg, "prj1", true},
+		{"alice@Example.COM", cfg, "prj1", true},
+		{"acct:bob@sub.example.com", cfg, "prj2", true},
+		{"https://example.com/alice", cfg, "prj1", true},
+		{"example.com", cfg, "prj1", true},
+		{"http://localhost:18443/authapi/v1/project/prj3", cfg, "prj3", true},
+		{"acct:alice@other.com", cfg, "", false},
+		{"acct:alice@other.com", defaultCfg, "master", true},
+		{"acct:alice", cfg, "", false},
+		{"mailto:alice@example.com", cfg, "", false},
+		{"", cfg, "", false},
+	}
+
+	for _, tc := range tt {
+		res, err := WebFingerProject(tc.resource, tc.cfg)
+		if tc.expectOK && err != nil {
+			t.Errorf("WebFingerProject returned unexpected error for %s: %v", tc.resource, err)
+			continue
+		}
+		if !tc.expectOK && err == nil {
+			t.Errorf("WebFingerProject for %s should return error, but got project %s", tc.resource, res)
+			continue
+		}
+		if res != tc.expect {
+			t.Errorf("WebFingerProject for %s returned wrong project. expect: %s, but got %s", tc.resource, tc.expect, res)
+		}
+	}
+}
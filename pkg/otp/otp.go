@@ -4,11 +4,16 @@ import (
 	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
 	"encoding/base32"
 	"encoding/base64"
-	"encoding/hex"
-	"fmt"
+	"encoding/binary"
+	"hash"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,11 +24,6 @@ import (
 	qrcode "github.com/skip2/go-qrcode"
 )
 
-const (
-	period   = 30
-	digitLen = 6
-)
-
 var (
 	// ErrNotEnabled ...
 	ErrNotEnabled = errors.New("Authenticator Application is not set", "User OTP Info is not enabled")
@@ -32,26 +32,34 @@ var (
 )
 
 // Register ...
-func Register(projectName string, userID, userName string) (string, *errors.Error) {
+func Register(prj *model.ProjectInfo, userID, userName string) (string, *errors.Error) {
 	// private key is 20 bytes and base32 encoded
 	privateKey := make([]byte, 20)
 	rand.Read(privateKey)
 
+	// save the parameters to verify the code by the same settings as the QR code
+	cfg := prj.TOTPConfig
 	data := model.OTPInfo{
 		ID:         uuid.New().String(),
 		PrivateKey: base32.StdEncoding.EncodeToString(privateKey),
 		Enabled:    false,
+		Algorithm:  cfg.GetAlgorithm(),
+		Digits:     cfg.GetDigits(),
+		Period:     cfg.GetPeriod(),
 	}
 	logger.Debug("set OTP data: %v", data)
 
 	// enter to db
-	if err := db.GetInst().OTPAdd(projectName, userID, &data); err != nil {
+	if err := db.GetInst().OTPAdd(prj.Name, userID, &data); err != nil {
 		return "", errors.Append(err, "Failed to register OTP data")
 	}
 
-	// return qr code
-	content := fmt.Sprintf("otpauth://totp/hekate:%s?secret=%s&digits=%d&issuer=hekate&period=%d", userName, data.PrivateKey, digitLen, period)
-	var png []byte
+	return QRCode(prj, userName, &data)
+}
+
+// QRCode returns the base64 encoded png image of the key URI
+func QRCode(prj *model.ProjectInfo, userName string, info *model.OTPInfo) (string, *errors.Error) {
+	content := keyURI(prj.TOTPConfig.GetIssuer(), userName, info)
 	png, err := qrcode.Encode(content, qrcode.Medium, 256)
 	if err != nil {
 		return "", errors.New("QR Code encoding failed", "Failed to QR encode: %v", err)
@@ -60,35 +68,97 @@ func Register(projectName string, userID, userName string) (string, *errors.Erro
 	return base64.StdEncoding.EncodeToString(png), nil
 }
 
-// Verify ...
-func Verify(now time.Time, user *model.UserInfo, userCode string) *errors.Error {
+// Verify verifies the user code in the window of the clock skew, and returns the time step of the code
+// The code of the step which is not after the last used step is rejected to prevent the replay,
+// so the caller must save the returned step by Use.
+func Verify(now time.Time, cfg *model.TOTPConfig, user *model.UserInfo, userCode string) (int64, *errors.Error) {
 	if !user.OTPInfo.Enabled {
-		return ErrNotEnabled
+		return 0, ErrNotEnabled
 	}
 
 	key, e := base32.StdEncoding.DecodeString(user.OTPInfo.PrivateKey)
 	if e != nil {
-		return errors.New("Internal Server Error", "Failed to decode private key %v", e)
+		return 0, errors.New("Internal Server Error", "Failed to decode private key %v", e)
 	}
 
-	tSrc := zeroPadding(strconv.FormatInt(now.Unix()/period, 16), 16)
-	t := make([]byte, hex.DecodedLen(len(tSrc)))
-	hex.Decode(t, []byte(tSrc))
+	newHash, err := hashFunc(user.OTPInfo.Algorithm)
+	if err != nil {
+		return 0, err
+	}
 
-	hs := getMAC(t, key)
-	if len(hs) != 20 {
-		return errors.New("Internal Server Error", "Invalid HMAC-SHA-1 size %d", len(hs))
+	period := int64(user.OTPInfo.Period)
+	if period == 0 {
+		period = model.DefaultTOTPPeriod
+	}
+	digits := user.OTPInfo.Digits
+	if digits == 0 {
+		digits = model.DefaultTOTPDigits
 	}
 
-	expect := zeroPadding(strconv.Itoa(truncate(hs)), digitLen)
-	if expect != userCode {
-		logger.Debug("Failed to verify user code: expect %s but got %s", expect, userCode)
-		return ErrVerifyFailed
+	current := now.Unix() / period
+	skew := int64(cfg.SkewSteps)
+	for step := current - skew; step <= current+skew; step++ {
+		expect := generate(newHash, key, step, digits)
+		if subtle.ConstantTimeCompare([]byte(expect), []byte(userCode)) != 1 {
+			continue
+		}
+
+		if step <= user.OTPInfo.LastUsedStep {
+			return 0, errors.Append(ErrVerifyFailed, "The code of step %d is already used", step)
+		}
+		return step, nil
 	}
 
+	logger.Debug("Failed to verify user code %s at step %d", userCode, current)
+	return 0, ErrVerifyFailed
+}
+
+// Use records the time step of the verified code, and the code can not be used again
+func Use(projectName string, userID string, step int64) *errors.Error {
+	if err := db.GetInst().OTPStepUse(projectName, userID, step); err != nil {
+		if errors.Contains(err, model.ErrOTPCodeAlreadyUsed) {
+			return errors.Append(ErrVerifyFailed, "The code of step %d is already used", step)
+		}
+		return errors.Append(err, "Failed to save OTP used step")
+	}
 	return nil
 }
 
+// keyURI returns the otpauth URI of the key
+//   ref. https://github.com/google/google-authenticator/wiki/Key-Uri-Format
+func keyURI(issuer, userName string, info *model.OTPInfo) string {
+	q := url.Values{}
+	q.Set("secret", strings.TrimRight(info.PrivateKey, "="))
+	q.Set("issuer", issuer)
+	q.Set("algorithm", info.Algorithm)
+	q.Set("digits", strconv.FormatUint(uint64(info.Digits), 10))
+	q.Set("period", strconv.FormatUint(uint64(info.Period), 10))
+
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(userName)
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
+
+func hashFunc(algorithm string) (func() hash.Hash, *errors.Error) {
+	switch algorithm {
+	case "", model.TOTPAlgorithmSHA1:
+		return sha1.New, nil
+	case model.TOTPAlgorithmSHA256:
+		return sha256.New, nil
+	case model.TOTPAlgorithmSHA512:
+		return sha512.New, nil
+	}
+	return nil, errors.New("Internal Server Error", "Unknown TOTP algorithm %s", algorithm)
+}
+
+func generate(newHash func() hash.Hash, key []byte, step int64, digits uint) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(step))
+
+	mac := hmac.New(newHash, key)
+	mac.Write(msg)
+	return zeroPadding(strconv.Itoa(truncate(mac.Sum(nil), digits)), int(digits))
+}
+
 func zeroPadding(d string, length int) string {
 	for i := len(d); i < length; i++ {
 		d = "0" + d
@@ -96,14 +166,12 @@ func zeroPadding(d string, length int) string {
 	return d
 }
 
-func getMAC(message, key []byte) []byte {
-	mac := hmac.New(sha1.New, key)
-	mac.Write(message)
-	return mac.Sum(nil)
-}
-
-func truncate(hs []byte) int {
-	offset := hs[19] & 0xf
+func truncate(hs []byte, digits uint) int {
+	offset := hs[len(hs)-1] & 0xf
 	binCode := (int(hs[offset])&0x7f)<<24 | (int(hs[offset+1])&0xff)<<16 | (int(hs[offset+2])&0xff)<<8 | (int(hs[offset+3]) & 0xff)
-	return binCode % 1000000
+	mod := 1
+	for i := uint(0); i < digits; i++ {
+		mod *= 10
+	}
+	return binCode % mod
 }
@@ -2,42 +2,103 @@ package otp
 
 import (
 	"encoding/base32"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
 )
 
 func TestVerify(t *testing.T) {
-	privateKey := []byte("12345678901234567890")
+	// test vectors in RFC 6238 Appendix B
+	keys := map[string]string{
+		model.TOTPAlgorithmSHA1:   "12345678901234567890",
+		model.TOTPAlgorithmSHA256: "12345678901234567890123456789012",
+		model.TOTPAlgorithmSHA512: "1234567890123456789012345678901234567890123456789012345678901234",
+	}
+
+	tt := []struct {
+		Algorithm string
+		Digits    uint
+		TimeSec   int
+		Expect    string
+	}{
+		{Algorithm: "", Digits: 0, TimeSec: 59, Expect: "287082"},
+		{Algorithm: "", Digits: 0, TimeSec: 1111111109, Expect: "081804"},
+		{Algorithm: "", Digits: 0, TimeSec: 1111111111, Expect: "050471"},
+		{Algorithm: "", Digits: 0, TimeSec: 1234567890, Expect: "005924"},
+		{Algorithm: "", Digits: 0, TimeSec: 2000000000, Expect: "279037"},
+		{Algorithm: "", Digits: 0, TimeSec: 20000000000, Expect: "353130"},
+		{Algorithm: model.TOTPAlgorithmSHA1, Digits: 8, TimeSec: 59, Expect: "94287082"},
+		{Algorithm: model.TOTPAlgorithmSHA256, Digits: 8, TimeSec: 59, Expect: "46119246"},
+		{Algorithm: model.TOTPAlgorithmSHA512, Digits: 8, TimeSec: 59, Expect: "90693936"},
+		{Algorithm: model.TOTPAlgorithmSHA256, Digits: 8, TimeSec: 1111111109, Expect: "68084774"},
+		{Algorithm: model.TOTPAlgorithmSHA512, Digits: 8, TimeSec: 1111111109, Expect: "25091201"},
+	}
+
+	for _, tc := range tt {
+		key := keys[tc.Algorithm]
+		if key == "" {
+			key = keys[model.TOTPAlgorithmSHA1]
+		}
+		user := &model.UserInfo{
+			ID:          uuid.New().String(),
+			ProjectName: "master",
+			Name:        "admin",
+			OTPInfo: model.OTPInfo{
+				ID:         uuid.New().String(),
+				PrivateKey: base32.StdEncoding.EncodeToString([]byte(key)),
+				Enabled:    true,
+				Algorithm:  tc.Algorithm,
+				Digits:     tc.Digits,
+			},
+		}
+
+		step, err := Verify(time.Unix(int64(tc.TimeSec), 0), &model.TOTPConfig{}, user, tc.Expect)
+		if err != nil {
+			t.Errorf("Failed to verify user code %s: %v", tc.Expect, err)
+		} else if step != int64(tc.TimeSec/30) {
+			t.Errorf("Verify returns step %d, but want %d", step, tc.TimeSec/30)
+		}
+	}
+}
 
+func TestVerifyWindow(t *testing.T) {
 	user := &model.UserInfo{
-		ID:          uuid.New().String(),
-		ProjectName: "master",
-		Name:        "admin",
 		OTPInfo: model.OTPInfo{
-			ID:         uuid.New().String(),
-			PrivateKey: base32.StdEncoding.EncodeToString(privateKey),
+			PrivateKey: base32.StdEncoding.EncodeToString([]byte("12345678901234567890")),
 			Enabled:    true,
 		},
 	}
+	// "081804" is the code of step 37037036
+	code := "081804"
 
 	tt := []struct {
-		TimeSec int
-		Expect  string
+		Name         string
+		TimeSec      int
+		SkewSteps    uint
+		LastUsedStep int64
+		ExpectOK     bool
 	}{
-		{TimeSec: 59, Expect: "287082"},
-		{TimeSec: 1111111109, Expect: "081804"},
-		{TimeSec: 1111111111, Expect: "050471"},
-		{TimeSec: 1234567890, Expect: "005924"},
-		{TimeSec: 2000000000, Expect: "279037"},
-		{TimeSec: 20000000000, Expect: "353130"},
+		{Name: "current step", TimeSec: 1111111109, SkewSteps: 0, LastUsedStep: 0, ExpectOK: true},
+		{Name: "code of previous step without skew", TimeSec: 1111111111, SkewSteps: 0, LastUsedStep: 0, ExpectOK: false},
+		{Name: "code of previous step with skew", TimeSec: 1111111111, SkewSteps: 1, LastUsedStep: 0, ExpectOK: true},
+		{Name: "code of next step with skew", TimeSec: 1111111079, SkewSteps: 1, LastUsedStep: 0, ExpectOK: true},
+		{Name: "out of skew", TimeSec: 1111111171, SkewSteps: 1, LastUsedStep: 0, ExpectOK: false},
+		{Name: "replay", TimeSec: 1111111109, SkewSteps: 1, LastUsedStep: 37037036, ExpectOK: false},
+		{Name: "after newer code", TimeSec: 1111111109, SkewSteps: 1, LastUsedStep: 37037037, ExpectOK: false},
 	}
 
 	for _, tc := range tt {
-		if err := Verify(time.Unix(int64(tc.TimeSec), 0), user, tc.Expect); err != nil {
-			t.Errorf("Failed to verify user code: %v", err)
+		user.OTPInfo.LastUsedStep = tc.LastUsedStep
+		_, err := Verify(time.Unix(int64(tc.TimeSec), 0), &model.TOTPConfig{SkewSteps: tc.SkewSteps}, user, code)
+		if tc.ExpectOK && err != nil {
+			t.Errorf("Test %s: Failed to verify user code: %v", tc.Name, err)
+		}
+		if !tc.ExpectOK && !errors.Contains(err, ErrVerifyFailed) {
+			t.Errorf("Test %s: Verify returns %v, but want ErrVerifyFailed", tc.Name, err)
 		}
 	}
 }
@@ -46,8 +107,50 @@ func TestTruncate(t *testing.T) {
 	input := []byte{0x1f, 0x86, 0x98, 0x69, 0x0e, 0x02, 0xca, 0x16, 0x61, 0x85, 0x50, 0xef, 0x7f, 0x19, 0xda, 0x8e, 0x94, 0x5b, 0x55, 0x5a}
 	expect := 872921
 
-	res := truncate(input)
+	res := truncate(input, 6)
 	if res != expect {
 		t.Errorf("truncate method return %d, but want %d", res, expect)
 	}
 }
+
+func TestKeyURI(t *testing.T) {
+	info := &model.OTPInfo{
+		PrivateKey: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+		Algorithm:  model.TOTPAlgorithmSHA256,
+		Digits:     8,
+		Period:     60,
+	}
+	expect := "otpauth://totp/My%20Company:alice?algorithm=SHA256&digits=8&issuer=My+Company&period=60&secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+	res := keyURI("My Company", "alice", info)
+	if res != expect {
+		t.Errorf("keyURI returns %s, but want %s", res, expect)
+	}
+}
+
+func TestRecoveryCode(t *testing.T) {
+	codes, hashes := GenerateRecoveryCodes()
+	if len(codes) != RecoveryCodeNum || len(hashes) != RecoveryCodeNum {
+		t.Fatalf("Wrong number of recovery codes: %d, %d", len(codes), len(hashes))
+	}
+
+	used := map[string]bool{}
+	for i, c := range codes {
+		if !IsRecoveryCode(c) {
+			t.Errorf("IsRecoveryCode(%s) returns false", c)
+		}
+		if used[c] {
+			t.Errorf("Recovery code %s is duplicated", c)
+		}
+		used[c] = true
+
+		// the separator and the case are ignored
+		if HashRecoveryCode(strings.ToUpper(strings.Replace(c, "-", "", 1))) != hashes[i] {
+			t.Errorf("Hash of normalized code %s does not match", c)
+		}
+	}
+
+	if IsRecoveryCode("123456") {
+		t.Errorf("IsRecoveryCode returns true for TOTP code")
+	}
+}
@@ -0,0 +1,42 @@
+package otp
+
+import (
+	"strings"
+
+	"github.com/sh-miyoshi/hekate/pkg/util"
+)
+
+const (
+	// RecoveryCodeNum is the number of recovery codes issued at once
+	RecoveryCodeNum = 10
+
+	recoveryCodeLength = 10
+)
+
+// GenerateRecoveryCodes returns new recovery codes and their hashes
+// The codes are shown to the user only once, and only the hashes are saved.
+func GenerateRecoveryCodes() ([]string, []string) {
+	codes := []string{}
+	hashes := []string{}
+	for i := 0; i < RecoveryCodeNum; i++ {
+		c := util.RandomString(recoveryCodeLength, util.CharTypeDigit|util.CharTypeLower)
+		// split the code to read easily, e.g. abcde-12345
+		codes = append(codes, c[:recoveryCodeLength/2]+"-"+c[recoveryCodeLength/2:])
+		hashes = append(hashes, HashRecoveryCode(c))
+	}
+	return codes, hashes
+}
+
+// HashRecoveryCode returns the hash of the recovery code
+// The code is normalized, so the separator and the case are ignored.
+func HashRecoveryCode(code string) string {
+	c := strings.ToLower(code)
+	c = strings.NewReplacer("-", "", " ", "").Replace(c)
+	return util.CreateHash(c)
+}
+
+// IsRecoveryCode returns true if the code entered by the user looks like a recovery code, not a TOTP code
+func IsRecoveryCode(code string) bool {
+	c := strings.NewReplacer("-", "", " ", "").Replace(code)
+	return len(c) == recoveryCodeLength
+}
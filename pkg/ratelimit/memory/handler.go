@@ -0,0 +1,140 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/ratelimit/model"
+)
+
+// Handler ...
+type Handler struct {
+	failures map[model.Key][]time.Time
+	blocks   map[model.Key]model.Block
+	mu       sync.Mutex
+}
+
+// NewHandler ...
+func NewHandler() *Handler {
+	return &Handler{
+		failures: map[model.Key][]time.Time{},
+		blocks:   map[model.Key]model.Block{},
+	}
+}
+
+// Ping ...
+func (h *Handler) Ping() *errors.Error {
+	return nil
+}
+
+// AddFailure ...
+func (h *Handler) AddFailure(key model.Key, tm time.Time) *errors.Error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.failures[key] = append(h.failures[key], tm)
+	return nil
+}
+
+// GetFailureNum ...
+func (h *Handler) GetFailureNum(key model.Key, since time.Time) (uint, *errors.Error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	res := uint(0)
+	for _, tm := range h.failures[key] {
+		if !tm.Before(since) {
+			res++
+		}
+	}
+	return res, nil
+}
+
+// DeleteFailures ...
+func (h *Handler) DeleteFailures(key model.Key) *errors.Error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.failures, key)
+	return nil
+}
+
+// AddBlock ...
+func (h *Handler) AddBlock(ent *model.Block) *errors.Error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.blocks[ent.Key()] = *ent
+	return nil
+}
+
+// GetBlock ...
+func (h *Handler) GetBlock(key model.Key, now time.Time) (*model.Block, *errors.Error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	b, ok := h.blocks[key]
+	if !ok || !now.Before(b.ExpiresAt) {
+		return nil, model.ErrNoSuchBlock
+	}
+	return &b, nil
+}
+
+// GetBlockList ...
+func (h *Handler) GetBlockList(projectName string, now time.Time) ([]*model.Block, *errors.Error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	res := []*model.Block{}
+	for _, b := range h.blocks {
+		if b.ProjectName == projectName && now.Before(b.ExpiresAt) {
+			v := b
+			res = append(res, &v)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].CreatedAt.Before(res[j].CreatedAt)
+	})
+	return res, nil
+}
+
+// DeleteBlock ...
+func (h *Handler) DeleteBlock(key model.Key) *errors.Error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.blocks[key]; !ok {
+		return model.ErrNoSuchBlock
+	}
+	delete(h.blocks, key)
+	return nil
+}
+
+// Cleanup ...
+func (h *Handler) Cleanup(failedBefore, now time.Time) *errors.Error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for k, times := range h.failures {
+		valid := []time.Time{}
+		for _, tm := range times {
+			if !tm.Before(failedBefore) {
+				valid = append(valid, tm)
+			}
+		}
+		if len(valid) == 0 {
+			delete(h.failures, k)
+		} else {
+			h.failures[k] = valid
+		}
+	}
+
+	for k, b := range h.blocks {
+		if !now.Before(b.ExpiresAt) {
+			delete(h.blocks, k)
+		}
+	}
+	return nil
+}
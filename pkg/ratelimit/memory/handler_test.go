@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/ratelimit/model"
+)
+
+func TestFailures(t *testing.T) {
+	handler := NewHandler()
+	key := model.Key{ProjectName: "project1", Type: model.KeyTypeIP, Value: "192.0.2.1"}
+	other := model.Key{ProjectName: "project1", Type: model.KeyTypeUser, Value: "192.0.2.1"}
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		handler.AddFailure(key, now.Add(-time.Duration(i)*time.Minute))
+	}
+
+	if n, _ := handler.GetFailureNum(key, now.Add(-150*time.Second)); n != 3 {
+		t.Errorf("Failed to count failures in the window. expect: 3, but got: %d", n)
+	}
+	if n, _ := handler.GetFailureNum(other, now.Add(-time.Hour)); n != 0 {
+		t.Errorf("Failures of the other key are counted. got: %d", n)
+	}
+
+	handler.Cleanup(now.Add(-90*time.Second), now)
+	if n, _ := handler.GetFailureNum(key, now.Add(-time.Hour)); n != 2 {
+		t.Errorf("Failed to cleanup old failures. expect: 2, but got: %d", n)
+	}
+
+	handler.DeleteFailures(key)
+	if n, _ := handler.GetFailureNum(key, now.Add(-time.Hour)); n != 0 {
+		t.Errorf("Failed to delete failures. got: %d", n)
+	}
+}
+
+func TestBlocks(t *testing.T) {
+	handler := NewHandler()
+	now := time.Now()
+	active := &model.Block{ProjectName: "project1", Type: model.KeyTypeIP, Value: "192.0.2.1", CreatedAt: now, ExpiresAt: now.Add(time.Minute)}
+	expired := &model.Block{ProjectName: "project1", Type: model.KeyTypeIP, Value: "192.0.2.2", CreatedAt: now.Add(-time.Hour), ExpiresAt: now.Add(-time.Minute)}
+	handler.AddBlock(active)
+	handler.AddBlock(expired)
+
+	if _, err := handler.GetBlock(active.Key(), now); err != nil {
+		t.Errorf("Failed to get the active block: %v", err)
+	}
+	if _, err := handler.GetBlock(expired.Key(), now); !errors.Contains(err, model.ErrNoSuchBlock) {
+		t.Errorf("The expired block should not be returned, but got: %v", err)
+	}
+	if res, _ := handler.GetBlockList("project1", now); len(res) != 1 {
+		t.Errorf("Failed to get active blocks. expect: 1, but got: %d", len(res))
+	}
+
+	if err := handler.DeleteBlock(active.Key()); err != nil {
+		t.Errorf("Failed to delete the block: %v", err)
+	}
+	if _, err := handler.GetBlock(active.Key(), now); !errors.Contains(err, model.ErrNoSuchBlock) {
+		t.Errorf("The deleted block should not be returned, but got: %v", err)
+	}
+}
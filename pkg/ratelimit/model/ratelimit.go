@@ -0,0 +1,53 @@
+package model
+
+import (
+	"time"
+
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+)
+
+const (
+	// KeyTypeIP is a key type for the client IP address
+	KeyTypeIP = "ip"
+	// KeyTypeUser is a key type for the user name
+	KeyTypeUser = "user"
+	// KeyTypeClient is a key type for the OAuth client id
+	KeyTypeClient = "client"
+	// KeyTypeUserCode is a key type for the client IP address which enters the user code of the device flow
+	KeyTypeUserCode = "user_code"
+)
+
+// Key ...
+type Key struct {
+	ProjectName string
+	Type        string
+	Value       string
+}
+
+// Block ...
+type Block struct {
+	ProjectName string
+	Type        string
+	Value       string
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+var (
+	// ErrNoSuchBlock ...
+	ErrNoSuchBlock = errors.New("No such block", "No such block")
+)
+
+// String ...
+func (k Key) String() string {
+	return k.ProjectName + "/" + k.Type + "/" + k.Value
+}
+
+// Key returns the key of the block
+func (b *Block) Key() Key {
+	return Key{
+		ProjectName: b.ProjectName,
+		Type:        b.Type,
+		Value:       b.Value,
+	}
+}
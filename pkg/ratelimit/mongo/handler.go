@@ -0,0 +1,246 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/ratelimit/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	failureCollectionName = "ratelimit_failure"
+	blockCollectionName   = "ratelimit_block"
+	timeoutSecond         = 5
+)
+
+var (
+	databaseName = "hekate"
+)
+
+// Handler ...
+type Handler struct {
+	dbClient *mongo.Client
+}
+
+// NewClient ...
+func NewClient(connStr string) (*mongo.Client, *errors.Error) {
+	cli, err := mongo.NewClient(options.Client().ApplyURI(connStr))
+	if err != nil {
+		return nil, errors.New("DB failed", "Failed to create mongo client: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
+	defer cancel()
+
+	if err := cli.Connect(ctx); err != nil {
+		return nil, errors.New("DB failed", "Failed to connect to mongo: %v", err)
+	}
+
+	if err := cli.Ping(ctx, nil); err != nil {
+		return nil, errors.New("DB failed", "Failed to ping to mongo: %v", err)
+	}
+
+	return cli, nil
+}
+
+// NewHandler ...
+func NewHandler(dbClient *mongo.Client) *Handler {
+	return &Handler{
+		dbClient: dbClient,
+	}
+}
+
+// ChangeDatabase changes the database of store data
+// this method should call at first
+func ChangeDatabase(name string) {
+	if len(name) > 0 {
+		databaseName = name
+	}
+}
+
+func keyFilter(key model.Key) bson.D {
+	return bson.D{
+		{Key: "project_name", Value: key.ProjectName},
+		{Key: "type", Value: key.Type},
+		{Key: "value", Value: key.Value},
+	}
+}
+
+// Ping ...
+func (h *Handler) Ping() *errors.Error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
+	defer cancel()
+
+	if err := h.dbClient.Ping(ctx, nil); err != nil {
+		return errors.New("DB failed", "DB Ping failed: %v", err)
+	}
+	return nil
+}
+
+// AddFailure ...
+func (h *Handler) AddFailure(key model.Key, tm time.Time) *errors.Error {
+	v := &failure{
+		ProjectName: key.ProjectName,
+		Type:        key.Type,
+		Value:       key.Value,
+		Time:        tm,
+	}
+
+	col := h.dbClient.Database(databaseName).Collection(failureCollectionName)
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
+	defer cancel()
+
+	if _, err := col.InsertOne(ctx, v); err != nil {
+		return errors.New("DB failed", "Failed to insert rate limit failure to mongodb: %v", err)
+	}
+	return nil
+}
+
+// GetFailureNum ...
+func (h *Handler) GetFailureNum(key model.Key, since time.Time) (uint, *errors.Error) {
+	filter := keyFilter(key)
+	filter = append(filter, bson.E{Key: "time", Value: bson.D{{Key: "$gte", Value: since}}})
+
+	col := h.dbClient.Database(databaseName).Collection(failureCollectionName)
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
+	defer cancel()
+
+	n, err := col.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, errors.New("DB failed", "Failed to count rate limit failures in mongodb: %v", err)
+	}
+	return uint(n), nil
+}
+
+// DeleteFailures ...
+func (h *Handler) DeleteFailures(key model.Key) *errors.Error {
+	col := h.dbClient.Database(databaseName).Collection(failureCollectionName)
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
+	defer cancel()
+
+	if _, err := col.DeleteMany(ctx, keyFilter(key)); err != nil {
+		return errors.New("DB failed", "Failed to delete rate limit failures in mongodb: %v", err)
+	}
+	return nil
+}
+
+// AddBlock ...
+func (h *Handler) AddBlock(ent *model.Block) *errors.Error {
+	v := &block{
+		ProjectName: ent.ProjectName,
+		Type:        ent.Type,
+		Value:       ent.Value,
+		CreatedAt:   ent.CreatedAt,
+		ExpiresAt:   ent.ExpiresAt,
+	}
+
+	col := h.dbClient.Database(databaseName).Collection(blockCollectionName)
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
+	defer cancel()
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := col.ReplaceOne(ctx, keyFilter(ent.Key()), v, opts); err != nil {
+		return errors.New("DB failed", "Failed to upsert rate limit block to mongodb: %v", err)
+	}
+	return nil
+}
+
+// GetBlock ...
+func (h *Handler) GetBlock(key model.Key, now time.Time) (*model.Block, *errors.Error) {
+	filter := keyFilter(key)
+	filter = append(filter, bson.E{Key: "expires_at", Value: bson.D{{Key: "$gt", Value: now}}})
+
+	col := h.dbClient.Database(databaseName).Collection(blockCollectionName)
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
+	defer cancel()
+
+	res := &block{}
+	if err := col.FindOne(ctx, filter).Decode(res); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, model.ErrNoSuchBlock
+		}
+		return nil, errors.New("DB failed", "Failed to get rate limit block from mongodb: %v", err)
+	}
+
+	return &model.Block{
+		ProjectName: res.ProjectName,
+		Type:        res.Type,
+		Value:       res.Value,
+		CreatedAt:   res.CreatedAt,
+		ExpiresAt:   res.ExpiresAt,
+	}, nil
+}
+
+// GetBlockList ...
+func (h *Handler) GetBlockList(projectName string, now time.Time) ([]*model.Block, *errors.Error) {
+	filter := bson.D{
+		{Key: "project_name", Value: projectName},
+		{Key: "expires_at", Value: bson.D{{Key: "$gt", Value: now}}},
+	}
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	col := h.dbClient.Database(databaseName).Collection(blockCollectionName)
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
+	defer cancel()
+
+	cursor, err := col.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, errors.New("DB failed", "Failed to get rate limit block list from mongodb: %v", err)
+	}
+
+	blocks := []block{}
+	if err := cursor.All(ctx, &blocks); err != nil {
+		return nil, errors.New("DB failed", "Failed to get rate limit block list from mongodb: %v", err)
+	}
+
+	res := []*model.Block{}
+	for _, b := range blocks {
+		res = append(res, &model.Block{
+			ProjectName: b.ProjectName,
+			Type:        b.Type,
+			Value:       b.Value,
+			CreatedAt:   b.CreatedAt,
+			ExpiresAt:   b.ExpiresAt,
+		})
+	}
+	return res, nil
+}
+
+// DeleteBlock ...
+func (h *Handler) DeleteBlock(key model.Key) *errors.Error {
+	col := h.dbClient.Database(databaseName).Collection(blockCollectionName)
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
+	defer cancel()
+
+	res, err := col.DeleteOne(ctx, keyFilter(key))
+	if err != nil {
+		return errors.New("DB failed", "Failed to delete rate limit block from mongodb: %v", err)
+	}
+	if res.DeletedCount == 0 {
+		return model.ErrNoSuchBlock
+	}
+	return nil
+}
+
+// Cleanup ...
+func (h *Handler) Cleanup(failedBefore, now time.Time) *errors.Error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutSecond*time.Second)
+	defer cancel()
+
+	col := h.dbClient.Database(databaseName).Collection(failureCollectionName)
+	filter := bson.D{{Key: "time", Value: bson.D{{Key: "$lt", Value: failedBefore}}}}
+	if _, err := col.DeleteMany(ctx, filter); err != nil {
+		return errors.New("DB failed", "Failed to delete old rate limit failures in mongodb: %v", err)
+	}
+
+	col = h.dbClient.Database(databaseName).Collection(blockCollectionName)
+	filter = bson.D{{Key: "expires_at", Value: bson.D{{Key: "$lte", Value: now}}}}
+	if _, err := col.DeleteMany(ctx, filter); err != nil {
+		return errors.New("DB failed", "Failed to delete expired rate limit blocks in mongodb: %v", err)
+	}
+	return nil
+}
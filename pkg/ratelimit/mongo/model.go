@@ -0,0 +1,18 @@
+package mongo
+
+import "time"
+
+type failure struct {
+	ProjectName string    `bson:"project_name"`
+	Type        string    `bson:"type"`
+	Value       string    `bson:"value"`
+	Time        time.Time `bson:"time"`
+}
+
+type block struct {
+	ProjectName string    `bson:"project_name"`
+	Type        string    `bson:"type"`
+	Value       string    `bson:"value"`
+	CreatedAt   time.Time `bson:"created_at"`
+	ExpiresAt   time.Time `bson:"expires_at"`
+}
@@ -0,0 +1,226 @@
+package ratelimit
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/sh-miyoshi/hekate/pkg/config"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/logger"
+	"github.com/sh-miyoshi/hekate/pkg/ratelimit/memory"
+	"github.com/sh-miyoshi/hekate/pkg/ratelimit/model"
+	"github.com/sh-miyoshi/hekate/pkg/ratelimit/mongo"
+	"github.com/sh-miyoshi/hekate/pkg/util"
+)
+
+// Manager limits the failures of the login and the token request per IP address, user and client
+type Manager struct {
+	handler    Handler
+	rules      map[string]config.RateLimitRule
+	delayAfter uint
+	maxDelay   uint
+}
+
+var inst *Manager
+
+// userCodeRule is a rule for the user code entry in the device login page
+// The user code has low entropy, so the guess is limited more strictly than the other requests.
+//   ref. https://tools.ietf.org/html/rfc8628#section-5.1
+var userCodeRule = config.RateLimitRule{
+	MaxFailures:   5,
+	Window:        10 * 60,
+	BlockDuration: 10 * 60,
+}
+
+// Init ...
+func Init(cfg config.RateLimitConfig, dbType string, connStr string) *errors.Error {
+	if inst != nil {
+		return errors.New("Internal server error", "RateLimitManager is already initialized")
+	}
+
+	res := &Manager{
+		rules: map[string]config.RateLimitRule{
+			model.KeyTypeIP:       cfg.IP,
+			model.KeyTypeUser:     cfg.User,
+			model.KeyTypeClient:   cfg.Client,
+			model.KeyTypeUserCode: userCodeRule,
+		},
+		delayAfter: cfg.DelayAfter,
+		maxDelay:   cfg.MaxDelay,
+	}
+
+	switch dbType {
+	case "memory":
+		logger.Info("Initialize RateLimitManager with local memory DB")
+		res.handler = memory.NewHandler()
+	case "mongo":
+		logger.Info("Initialize RateLimitManager with mongo DB")
+		dbClient, err := mongo.NewClient(connStr)
+		if err != nil {
+			return errors.Append(err, "Failed to create mongo handler")
+		}
+		res.handler = mongo.NewHandler(dbClient)
+	default:
+		return errors.New("Internal server error", "Database Type %s is not implemented for rate limit", dbType)
+	}
+
+	inst = res
+	return nil
+}
+
+// GetInst returns an instance of RateLimit Manager
+func GetInst() *Manager {
+	return inst
+}
+
+// IPKey returns the key of the client IP address
+func IPKey(projectName string, r *http.Request) model.Key {
+	return model.Key{ProjectName: projectName, Type: model.KeyTypeIP, Value: util.ClientIP(r)}
+}
+
+// UserCodeKey returns the key of the client IP address for the user code entry
+func UserCodeKey(projectName string, r *http.Request) model.Key {
+	return model.Key{ProjectName: projectName, Type: model.KeyTypeUserCode, Value: util.ClientIP(r)}
+}
+
+// UserKey returns the key of the user
+func UserKey(projectName string, userName string) model.Key {
+	return model.Key{ProjectName: projectName, Type: model.KeyTypeUser, Value: userName}
+}
+
+// ClientKey returns the key of the client
+func ClientKey(projectName string, clientID string) model.Key {
+	return model.Key{ProjectName: projectName, Type: model.KeyTypeClient, Value: clientID}
+}
+
+// Check returns ErrTooManyRequests if any key is blocked
+// Otherwise it delays the response progressively by the recent failures of the keys.
+func (m *Manager) Check(keys ...model.Key) *errors.Error {
+	now := time.Now()
+	failures := uint(0)
+	for _, key := range keys {
+		b, err := m.handler.GetBlock(key, now)
+		if err == nil {
+			return errors.Append(errors.ErrTooManyRequests, "%s is blocked until %s", key.String(), b.ExpiresAt.Format(time.RFC3339))
+		}
+		if !errors.Contains(err, model.ErrNoSuchBlock) {
+			return errors.Append(err, "Failed to get block")
+		}
+
+		rule, ok := m.rules[key.Type]
+		if !ok {
+			continue
+		}
+		n, err := m.handler.GetFailureNum(key, now.Add(-time.Duration(rule.Window)*time.Second))
+		if err != nil {
+			return errors.Append(err, "Failed to get failure num")
+		}
+		if n > failures {
+			failures = n
+		}
+	}
+
+	if d := delay(failures, m.delayAfter, m.maxDelay); d > 0 {
+		logger.Debug("Delay the response %v by %d recent failures", d, failures)
+		time.Sleep(d)
+	}
+	return nil
+}
+
+// Failed records the failure of the keys, and blocks the key if it reaches the limit
+func (m *Manager) Failed(keys ...model.Key) {
+	now := time.Now()
+	for _, key := range keys {
+		rule, ok := m.rules[key.Type]
+		if !ok {
+			continue
+		}
+
+		if err := m.handler.AddFailure(key, now); err != nil {
+			errors.Print(errors.Append(err, "Failed to add failure of %s", key.String()))
+			continue
+		}
+		n, err := m.handler.GetFailureNum(key, now.Add(-time.Duration(rule.Window)*time.Second))
+		if err != nil {
+			errors.Print(errors.Append(err, "Failed to get failure num of %s", key.String()))
+			continue
+		}
+		if n < rule.MaxFailures {
+			continue
+		}
+
+		ent := &model.Block{
+			ProjectName: key.ProjectName,
+			Type:        key.Type,
+			Value:       key.Value,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(time.Duration(rule.BlockDuration) * time.Second),
+		}
+		if err := m.handler.AddBlock(ent); err != nil {
+			errors.Print(errors.Append(err, "Failed to block %s", key.String()))
+			continue
+		}
+		// the block is a fresh start of the window
+		if err := m.handler.DeleteFailures(key); err != nil {
+			errors.Print(errors.Append(err, "Failed to delete failures of %s", key.String()))
+		}
+		logger.Info("%s is blocked until %s by %d failures", key.String(), ent.ExpiresAt.Format(time.RFC3339), n)
+	}
+
+	if err := m.handler.Cleanup(now.Add(-m.maxWindow()), now); err != nil {
+		errors.Print(errors.Append(err, "Failed to cleanup rate limit"))
+	}
+}
+
+// Succeeded clears the failures of the keys
+// Only the user key should be passed because a valid account must not reset the limit of the IP address or the client.
+func (m *Manager) Succeeded(keys ...model.Key) {
+	for _, key := range keys {
+		if err := m.handler.DeleteFailures(key); err != nil {
+			errors.Print(errors.Append(err, "Failed to delete failures of %s", key.String()))
+		}
+	}
+}
+
+// GetBlockList returns the active blocks in the project
+func (m *Manager) GetBlockList(projectName string) ([]*model.Block, *errors.Error) {
+	return m.handler.GetBlockList(projectName, time.Now())
+}
+
+// Unblock removes the block of the key with its failures
+func (m *Manager) Unblock(key model.Key) *errors.Error {
+	if err := m.handler.DeleteBlock(key); err != nil {
+		return errors.Append(err, "Failed to delete block")
+	}
+	if err := m.handler.DeleteFailures(key); err != nil {
+		return errors.Append(err, "Failed to delete failures")
+	}
+	return nil
+}
+
+func (m *Manager) maxWindow() time.Duration {
+	res := uint(0)
+	for _, rule := range m.rules {
+		if rule.Window > res {
+			res = rule.Window
+		}
+	}
+	return time.Duration(res) * time.Second
+}
+
+// delay returns the delay of the response
+// It doubles from 1 second per failure after delayAfter failures, up to maxDelay seconds.
+func delay(failures, delayAfter, maxDelay uint) time.Duration {
+	if failures <= delayAfter || maxDelay == 0 {
+		return 0
+	}
+
+	res := uint(1)
+	for i := delayAfter + 1; i < failures && res < maxDelay; i++ {
+		res *= 2
+	}
+	if res > maxDelay {
+		res = maxDelay
+	}
+	return time.Duration(res) * time.Second
+}
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sh-miyoshi/hekate/pkg/config"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/ratelimit/memory"
+	"github.com/sh-miyoshi/hekate/pkg/ratelimit/model"
+)
+
+func TestDelay(t *testing.T) {
+	tt := []struct {
+		Name       string
+		Failures   uint
+		DelayAfter uint
+		MaxDelay   uint
+		Expect     time.Duration
+	}{
+		{Name: "no failure", Failures: 0, DelayAfter: 3, MaxDelay: 4, Expect: 0},
+		{Name: "before delay", Failures: 3, DelayAfter: 3, MaxDelay: 4, Expect: 0},
+		{Name: "first delay", Failures: 4, DelayAfter: 3, MaxDelay: 4, Expect: time.Second},
+		{Name: "doubled", Failures: 5, DelayAfter: 3, MaxDelay: 4, Expect: 2 * time.Second},
+		{Name: "max delay", Failures: 7, DelayAfter: 3, MaxDelay: 4, Expect: 4 * time.Second},
+		{Name: "max delay not power of two", Failures: 10, DelayAfter: 0, MaxDelay: 5, Expect: 5 * time.Second},
+		{Name: "disabled", Failures: 10, DelayAfter: 3, MaxDelay: 0, Expect: 0},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			res := delay(tc.Failures, tc.DelayAfter, tc.MaxDelay)
+			if res != tc.Expect {
+				t.Errorf("Wrong delay. expect: %v, but got: %v", tc.Expect, res)
+			}
+		})
+	}
+}
+
+func TestUserCodeLimit(t *testing.T) {
+	m := &Manager{
+		handler: memory.NewHandler(),
+		rules: map[string]config.RateLimitRule{
+			model.KeyTypeIP:       {MaxFailures: 100, Window: 600, BlockDuration: 600},
+			model.KeyTypeUserCode: userCodeRule,
+		},
+	}
+	r := &http.Request{RemoteAddr: "192.0.2.1:12345"}
+	other := &http.Request{RemoteAddr: "192.0.2.2:12345"}
+
+	for i := 0; i < int(userCodeRule.MaxFailures); i++ {
+		if err := m.Check(UserCodeKey("master", r), IPKey("master", r)); err != nil {
+			t.Fatalf("User code entry is blocked after %d failures: %v", i, err)
+		}
+		m.Failed(UserCodeKey("master", r), IPKey("master", r))
+	}
+
+	if err := m.Check(UserCodeKey("master", r), IPKey("master", r)); !errors.Contains(err, errors.ErrTooManyRequests) {
+		t.Errorf("User code entry is not blocked after max failures: %v", err)
+	}
+	if err := m.Check(IPKey("master", r)); err != nil {
+		t.Errorf("The login is blocked by the failures of the user code: %v", err)
+	}
+	if err := m.Check(UserCodeKey("master", other)); err != nil {
+		t.Errorf("User code entry is blocked for other client: %v", err)
+	}
+	if err := m.Check(UserCodeKey("other-project", r)); err != nil {
+		t.Errorf("User code entry is blocked for other project: %v", err)
+	}
+
+	if err := m.Unblock(UserCodeKey("master", r)); err != nil {
+		t.Fatalf("Failed to unblock: %v", err)
+	}
+	if err := m.Check(UserCodeKey("master", r)); err != nil {
+		t.Errorf("User code entry is blocked after unblock: %v", err)
+	}
+}
@@ -0,0 +1,22 @@
+package ratelimit
+
+import (
+	"time"
+
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/ratelimit/model"
+)
+
+// Handler is a store of the failure history and the blocks
+// The DB-backed store shares the limits between the replicas.
+type Handler interface {
+	Ping() *errors.Error
+	AddFailure(key model.Key, tm time.Time) *errors.Error
+	GetFailureNum(key model.Key, since time.Time) (uint, *errors.Error)
+	DeleteFailures(key model.Key) *errors.Error
+	AddBlock(ent *model.Block) *errors.Error
+	GetBlock(key model.Key, now time.Time) (*model.Block, *errors.Error)
+	GetBlockList(projectName string, now time.Time) ([]*model.Block, *errors.Error)
+	DeleteBlock(key model.Key) *errors.Error
+	Cleanup(failedBefore, now time.Time) *errors.Error
+}
@@ -0,0 +1,90 @@
+package secret
+
+import (
+	"time"
+
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/util"
+)
+
+var (
+	// ErrPasswordReused ...
+	ErrPasswordReused = errors.New("Password reused", "Password is same as the recent one")
+	// ErrPasswordChangeTooEarly ...
+	ErrPasswordChangeTooEarly = errors.New("Password change too early", "Password was changed recently")
+)
+
+// CheckPasswordHistory returns ErrPasswordReused if the password is same as the current or the recent ones
+// The number of the checked passwords is HistoryCount in the policy.
+func CheckPasswordHistory(password string, user *model.UserInfo, policy model.PasswordPolicy) *errors.Error {
+	if policy.HistoryCount == 0 {
+		return nil
+	}
+
+	hash := util.CreateHash(password)
+	if user.PasswordHash == hash {
+		return ErrPasswordReused
+	}
+
+	// the current password is one of the history count
+	start := len(user.PasswordHistory) - int(policy.HistoryCount-1)
+	if start < 0 {
+		start = 0
+	}
+	for _, h := range user.PasswordHistory[start:] {
+		if h == hash {
+			return ErrPasswordReused
+		}
+	}
+	return nil
+}
+
+// NextPasswordHistory returns the password history which contains the current password
+// It keeps up to MaxPasswordHistoryCount passwords so that the policy can be strengthened later.
+func NextPasswordHistory(user *model.UserInfo) []string {
+	res := append([]string{}, user.PasswordHistory...)
+	if user.PasswordHash != "" {
+		res = append(res, user.PasswordHash)
+	}
+	if len(res) > model.MaxPasswordHistoryCount-1 {
+		res = res[len(res)-(model.MaxPasswordHistoryCount-1):]
+	}
+	return res
+}
+
+// PasswordExpired returns true if the password is older than MaxAge in the policy
+func PasswordExpired(user *model.UserInfo, policy model.PasswordPolicy, now time.Time) bool {
+	if policy.MaxAge == 0 {
+		return false
+	}
+	return now.After(passwordUpdatedAt(user).Add(time.Duration(policy.MaxAge) * time.Second))
+}
+
+// PasswordChangeRequired returns true if the user must change the temporary or expired password at the login
+func PasswordChangeRequired(user *model.UserInfo, policy model.PasswordPolicy, now time.Time) bool {
+	return user.PasswordChangeRequired || PasswordExpired(user, policy, now)
+}
+
+// CheckPasswordMinAge returns ErrPasswordChangeTooEarly if the password is younger than MinAge in the policy
+// The password which must be changed can be changed anytime.
+func CheckPasswordMinAge(user *model.UserInfo, policy model.PasswordPolicy, now time.Time) *errors.Error {
+	if policy.MinAge == 0 || user.PasswordChangeRequired || PasswordExpired(user, policy, now) {
+		return nil
+	}
+
+	changeableAt := passwordUpdatedAt(user).Add(time.Duration(policy.MinAge) * time.Second)
+	if now.Before(changeableAt) {
+		err := ErrPasswordChangeTooEarly.Copy()
+		err.SetDescription("password can be changed after %s", changeableAt.Format(time.RFC3339))
+		return err
+	}
+	return nil
+}
+
+func passwordUpdatedAt(user *model.UserInfo) time.Time {
+	if user.PasswordUpdatedAt.IsZero() {
+		return user.CreatedAt
+	}
+	return user.PasswordUpdatedAt
+}
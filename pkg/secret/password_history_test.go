@@ -0,0 +1,87 @@
+package secret
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/util"
+)
+
+func TestCheckPasswordHistory(t *testing.T) {
+	user := &model.UserInfo{
+		PasswordHash:    util.CreateHash("current"),
+		PasswordHistory: []string{util.CreateHash("oldest"), util.CreateHash("old")},
+	}
+
+	tt := []struct {
+		password string
+		count    uint
+		expect   *errors.Error
+	}{
+		{password: "current", count: 0, expect: nil},
+		{password: "current", count: 1, expect: ErrPasswordReused},
+		{password: "old", count: 1, expect: nil},
+		{password: "old", count: 2, expect: ErrPasswordReused},
+		{password: "oldest", count: 2, expect: nil},
+		{password: "oldest", count: 5, expect: ErrPasswordReused},
+		{password: "new", count: 5, expect: nil},
+	}
+
+	for _, tc := range tt {
+		err := CheckPasswordHistory(tc.password, user, model.PasswordPolicy{HistoryCount: tc.count})
+		if !errors.Contains(err, tc.expect) {
+			t.Errorf("CheckPasswordHistory(%s, %d) expects %v, but got %v", tc.password, tc.count, tc.expect, err)
+		}
+	}
+}
+
+func TestNextPasswordHistory(t *testing.T) {
+	user := &model.UserInfo{PasswordHash: "current"}
+	for i := 0; i < model.MaxPasswordHistoryCount+3; i++ {
+		user.PasswordHistory = append(user.PasswordHistory, "old")
+	}
+
+	res := NextPasswordHistory(user)
+	if len(res) != model.MaxPasswordHistoryCount-1 {
+		t.Errorf("History should be trimmed to %d, but got %d", model.MaxPasswordHistoryCount-1, len(res))
+	}
+	if res[len(res)-1] != "current" {
+		t.Errorf("The current password should be the last of the history, but got %s", res[len(res)-1])
+	}
+}
+
+func TestPasswordAge(t *testing.T) {
+	now := time.Now()
+	policy := model.PasswordPolicy{MaxAge: 3600, MinAge: 600}
+
+	tt := []struct {
+		name           string
+		user           *model.UserInfo
+		expectExpired  bool
+		expectTooEarly bool
+		expectChange   bool
+	}{
+		{name: "young", user: &model.UserInfo{PasswordUpdatedAt: now.Add(-time.Minute)}, expectExpired: false, expectTooEarly: true},
+		{name: "middle", user: &model.UserInfo{PasswordUpdatedAt: now.Add(-30 * time.Minute)}, expectExpired: false, expectTooEarly: false},
+		{name: "expired", user: &model.UserInfo{PasswordUpdatedAt: now.Add(-2 * time.Hour)}, expectExpired: true, expectTooEarly: false, expectChange: true},
+		{name: "created at is used", user: &model.UserInfo{CreatedAt: now.Add(-2 * time.Hour)}, expectExpired: true, expectTooEarly: false, expectChange: true},
+		{name: "change required", user: &model.UserInfo{PasswordUpdatedAt: now, PasswordChangeRequired: true}, expectExpired: false, expectTooEarly: false, expectChange: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if res := PasswordExpired(tc.user, policy, now); res != tc.expectExpired {
+				t.Errorf("PasswordExpired expects %v, but got %v", tc.expectExpired, res)
+			}
+			if res := PasswordChangeRequired(tc.user, policy, now); res != tc.expectChange {
+				t.Errorf("PasswordChangeRequired expects %v, but got %v", tc.expectChange, res)
+			}
+			err := CheckPasswordMinAge(tc.user, policy, now)
+			if tooEarly := errors.Contains(err, ErrPasswordChangeTooEarly); tooEarly != tc.expectTooEarly {
+				t.Errorf("CheckPasswordMinAge expects too early %v, but got %v", tc.expectTooEarly, err)
+			}
+		})
+	}
+}
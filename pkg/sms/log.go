@@ -0,0 +1,15 @@
+package sms
+
+import (
+	"github.com/sh-miyoshi/hekate/pkg/logger"
+)
+
+// LogSender writes the message to the log instead of sending it
+// It is used for test, so never use it in production because the message contains the code.
+type LogSender struct{}
+
+// Send ...
+func (s *LogSender) Send(to string, body string) error {
+	logger.Info("[SMS] To: %s, Body: %s", to, body)
+	return nil
+}
@@ -0,0 +1,88 @@
+package sms
+
+import (
+	"regexp"
+
+	"github.com/sh-miyoshi/hekate/pkg/config"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/logger"
+)
+
+// Sender is an interface of the SMS provider
+type Sender interface {
+	// Send sends the message to the phone number in E.164 format
+	Send(to string, body string) error
+}
+
+// Manager ...
+type Manager struct {
+	sender Sender
+}
+
+var (
+	inst *Manager
+
+	// ErrNotConfigured ...
+	ErrNotConfigured = errors.New("SMS is not available", "SMS sender is not configured")
+
+	// phoneNumberRegExp is a phone number in E.164 format
+	phoneNumberRegExp = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+)
+
+// Init ...
+func Init(cfg config.SMSConfig) *errors.Error {
+	if inst != nil {
+		return errors.New("Internal server error", "SMSManager is already initialized")
+	}
+
+	switch cfg.Type {
+	case "":
+		logger.Info("SMS sender is not configured, so the login by SMS is disabled")
+		inst = &Manager{}
+	case "webhook":
+		logger.Info("Initialize SMSManager with webhook %s", cfg.Webhook.URL)
+		inst = &Manager{
+			sender: NewWebhookSender(cfg.Webhook),
+		}
+	case "log":
+		logger.Info("Initialize SMSManager with log sender")
+		inst = &Manager{
+			sender: &LogSender{},
+		}
+	default:
+		return errors.New("Internal server error", "SMS type %s is not implemented", cfg.Type)
+	}
+	return nil
+}
+
+// GetInst returns an instance of SMS Manager
+func GetInst() *Manager {
+	return inst
+}
+
+// Enabled returns true if the SMS can be sent
+func (m *Manager) Enabled() bool {
+	return m != nil && m.sender != nil
+}
+
+// Send ...
+func (m *Manager) Send(to string, body string) *errors.Error {
+	if !m.Enabled() {
+		return ErrNotConfigured
+	}
+
+	if !ValidPhoneNumber(to) {
+		return errors.New("Invalid phone number", "Phone number %s is not E.164 format", to)
+	}
+
+	if err := m.sender.Send(to, body); err != nil {
+		return errors.New("Failed to send SMS", "Failed to send SMS to %s: %v", to, err)
+	}
+	logger.Info("SMS is sent to %s", to)
+	return nil
+}
+
+// ValidPhoneNumber returns true if the number is E.164 format such as +819012345678
+func ValidPhoneNumber(number string) bool {
+	return phoneNumberRegExp.MatchString(number)
+}
@@ -0,0 +1,63 @@
+package sms
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sh-miyoshi/hekate/pkg/config"
+)
+
+const webhookTimeout = 10 * time.Second
+
+// WebhookSender sends the message to the SMS gateway by HTTP POST request
+// The request body is JSON {"to": "+819012345678", "body": "message"}.
+type WebhookSender struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+type webhookRequest struct {
+	To   string `json:"to"`
+	Body string `json:"body"`
+}
+
+// NewWebhookSender ...
+func NewWebhookSender(cfg config.SMSWebhookConfig) *WebhookSender {
+	return &WebhookSender{
+		url:     cfg.URL,
+		headers: cfg.Headers,
+		client:  &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+// Send ...
+func (s *WebhookSender) Send(to string, body string) error {
+	data, err := json.Marshal(&webhookRequest{To: to, Body: body})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", s.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("webhook returns unexpected status %d", res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package sms
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sh-miyoshi/hekate/pkg/config"
+)
+
+func TestWebhookSend(t *testing.T) {
+	var got webhookRequest
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&got)
+		if got.To == "+819000000000" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	s := NewWebhookSender(config.SMSWebhookConfig{
+		URL:     srv.URL,
+		Headers: map[string]string{"Authorization": "Bearer token"},
+	})
+
+	if err := s.Send("+819012345678", "code 123456"); err != nil {
+		t.Errorf("Send returns unexpected error: %v", err)
+	}
+	if got.To != "+819012345678" || got.Body != "code 123456" {
+		t.Errorf("Wrong request body: %+v", got)
+	}
+	if auth != "Bearer token" {
+		t.Errorf("Header is not set: %s", auth)
+	}
+
+	if err := s.Send("+819000000000", "code 123456"); err == nil {
+		t.Errorf("Send returns nil error for error status")
+	}
+}
+
+func TestValidPhoneNumber(t *testing.T) {
+	tt := []struct {
+		number string
+		expect bool
+	}{
+		{"+819012345678", true},
+		{"+15555550100", true},
+		{"09012345678", false},
+		{"+0123456789", false},
+		{"+81 90 1234 5678", false},
+		{"", false},
+	}
+
+	for _, tc := range tt {
+		if res := ValidPhoneNumber(tc.number); res != tc.expect {
+			t.Errorf("ValidPhoneNumber(%s) returns %v, but expect %v", tc.number, res, tc.expect)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package sso
 import (
 	"crypto/x509"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
@@ -13,12 +14,46 @@ import (
 	"github.com/sh-miyoshi/hekate/pkg/db/model"
 	"github.com/sh-miyoshi/hekate/pkg/errors"
 	"github.com/sh-miyoshi/hekate/pkg/logger"
+	"github.com/sh-miyoshi/hekate/pkg/login"
 	"github.com/sh-miyoshi/hekate/pkg/oidc"
 	"github.com/sh-miyoshi/hekate/pkg/oidc/token"
+	"github.com/sh-miyoshi/hekate/pkg/secret"
 )
 
+const (
+	ssoSessionCookieName = "HEKATE_LOGIN_SESSION"
+
+	// BrowserStateCookieName is a name of the cookie which has the browser state for OpenID Connect Session Management
+	// It is not HttpOnly because the check_session_iframe reads it by JavaScript.
+	BrowserStateCookieName = "HEKATE_SESSION_STATE"
+)
+
+// NewBrowserState returns a new browser state for the SSO session
+func NewBrowserState() string {
+	return uuid.New().String()
+}
+
+// GetBrowserState returns the browser state in the cookie, or empty string if not found
+func GetBrowserState(r *http.Request) string {
+	cookie, err := r.Cookie(BrowserStateCookieName)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+// cookiePath returns the path of the issuer, so the cookies are sent only to the project
+func cookiePath(issuer string) string {
+	u, err := url.Parse(issuer)
+	if err != nil || u.Path == "" {
+		return "/"
+	}
+	return u.Path
+}
+
 // SetSSOSessionToCookie ...
-func SetSSOSessionToCookie(w http.ResponseWriter, projectName, userID, issuer string) *errors.Error {
+// The browser state is updated with the SSO session.
+func SetSSOSessionToCookie(w http.ResponseWriter, projectName, userID, issuer, browserState string) *errors.Error {
 	cfg := config.Get()
 
 	req := token.Request{
@@ -33,20 +68,63 @@ func SetSSOSessionToCookie(w http.ResponseWriter, projectName, userID, issuer st
 	}
 
 	cookie := &http.Cookie{
-		Name:     "HEKATE_LOGIN_SESSION",
+		Name:     ssoSessionCookieName,
 		Value:    tkn,
+		Path:     cookiePath(issuer),
 		MaxAge:   int(req.ExpiresIn),
 		Secure:   cfg.HTTPSConfig.Enabled,
 		HttpOnly: true,
 	}
-
 	http.SetCookie(w, cookie)
+
+	http.SetCookie(w, browserStateCookie(browserState, issuer, int(req.ExpiresIn)))
 	return nil
 }
 
+// browserStateCookie returns the cookie of the browser state
+// check_session_iframe reads the browser state in the third-party context of the RP,
+// and browsers send SameSite=None cookie only if it is secure, so it falls back to Lax without https.
+//   ref. https://openid.net/specs/openid-connect-session-1_0.html#OPiframe
+func browserStateCookie(browserState, issuer string, maxAge int) *http.Cookie {
+	res := &http.Cookie{
+		Name:     BrowserStateCookieName,
+		Value:    browserState,
+		Path:     cookiePath(issuer),
+		MaxAge:   maxAge,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if config.Get().HTTPSConfig.Enabled {
+		res.Secure = true
+		res.SameSite = http.SameSiteNoneMode
+	}
+	return res
+}
+
+// DeleteSSOSessionCookie removes the SSO session and the browser state from the cookie
+func DeleteSSOSessionCookie(w http.ResponseWriter, issuer string) {
+	secure := config.Get().HTTPSConfig.Enabled
+	http.SetCookie(w, &http.Cookie{
+		Name:     ssoSessionCookieName,
+		Path:     cookiePath(issuer),
+		MaxAge:   -1,
+		Secure:   secure,
+		HttpOnly: true,
+	})
+	http.SetCookie(w, browserStateCookie("", issuer, -1))
+}
+
+// GetSSOSessionCookie returns the cookie of the SSO session
+func GetSSOSessionCookie(r *http.Request) (*http.Cookie, error) {
+	return r.Cookie(ssoSessionCookieName)
+}
+
 // GetLoginUserIDFromSSOSessionCookie ...
 func GetLoginUserIDFromSSOSessionCookie(cookie *http.Cookie, projectName string) (string, *errors.Error) {
-	var claims jwt.StandardClaims
+	// other tokens signed by the same key, e.g. the trusted device token, have the format
+	var claims struct {
+		jwt.StandardClaims
+		Format string `json:"format"`
+	}
 	tkn, err := jwt.ParseWithClaims(cookie.Value, &claims, func(token *jwt.Token) (interface{}, error) {
 		project, err := db.GetInst().ProjectGet(projectName)
 		if err != nil {
@@ -68,12 +146,15 @@ func GetLoginUserIDFromSSOSessionCookie(cookie *http.Cookie, projectName string)
 	if err != nil || !tkn.Valid {
 		return "", errors.New("Invalid request", "Token in cookie is not valid: %v", err)
 	}
+	if claims.Format != "" {
+		return "", errors.New("Invalid request", "Token in cookie is not SSO token: %s", claims.Format)
+	}
 
 	return claims.Subject, nil
 }
 
 // Handle method return redirect page after logged in when found valid session
-func Handle(method string, projectName string, userID string, tokenIssuer string, authReq *oidc.AuthRequest) (*http.Request, *errors.Error) {
+func Handle(method string, projectName string, userID string, tokenIssuer string, browserState string, authReq *oidc.AuthRequest) (*http.Request, *errors.Error) {
 	sessions, err := db.GetInst().SessionGetList(projectName, &model.SessionFilter{UserID: userID})
 	if err != nil {
 		return nil, errors.Append(err, "Failed to get session list")
@@ -82,10 +163,47 @@ func Handle(method string, projectName string, userID string, tokenIssuer string
 		return nil, errors.Append(errors.ErrLoginRequired, "No sessions, so return login_required")
 	}
 
+	claims, err := token.ParseClaimsRequest(authReq.Claims)
+	if err != nil {
+		return nil, errors.Append(err, "Failed to parse claims request")
+	}
+	acrValues := append(append([]string{}, authReq.ACRValues...), claims.EssentialACRValues()...)
+
+	prj, err := db.GetInst().ProjectGet(projectName)
+	if err != nil {
+		return nil, errors.Append(err, "Failed to get project")
+	}
+	usr, err := db.GetInst().UserGet(projectName, userID)
+	if err != nil {
+		return nil, errors.Append(err, "Failed to get login user")
+	}
+
+	// the temporary or expired password must be changed in the login page
+	if secret.PasswordChangeRequired(usr, prj.PasswordPolicy, time.Now()) {
+		return nil, errors.Append(errors.ErrLoginRequired, "User %s must change the password", usr.ID)
+	}
+
 	// check max_age
 	// if now > auth_time + max_age return login_required
+	// check acr_values
+	// if the session does not satisfy the requested acr, return login_required for step-up authentication
+	// check MFA policy
+	// if the session is authenticated by one factor but the user requires MFA, return login_required
 	now := time.Now()
 	for _, s := range sessions {
+		// offline sessions are not the SSO session
+		if s.Offline {
+			continue
+		}
+		if !token.ACRSatisfied(token.ACRLevel(s.AuthMethods), acrValues) {
+			logger.Debug("Session %s does not satisfy acr values %v", s.SessionID, acrValues)
+			continue
+		}
+		if err := login.CheckMFA(prj, usr, s.AuthMethods); err != nil {
+			logger.Debug("Session %s does not satisfy MFA policy: %v", s.SessionID, err)
+			continue
+		}
+
 		lifeSpan := s.ExpiresIn
 		if authReq.MaxAge > 0 {
 			lifeSpan = authReq.MaxAge
@@ -111,8 +229,13 @@ func Handle(method string, projectName string, userID string, tokenIssuer string
 				ExpiresDate:         time.Now().Add(expires),
 				CodeChallenge:       authReq.CodeChallenge,
 				CodeChallengeMethod: authReq.CodeChallengeMethod,
+				Claims:              authReq.Claims,
+				AuthMethods:         s.AuthMethods,
+				ACRValues:           authReq.ACRValues,
+				UILocales:           authReq.UILocales,
+				Resources:           authReq.Resources,
 			}
-			req, err := oidc.CreateLoggedInResponse(ls, authReq.State, tokenIssuer)
+			req, err := oidc.CreateLoggedInResponse(ls, authReq.State, tokenIssuer, browserState)
 			if err != nil {
 				return nil, errors.Append(err, "Failed to create login redirect info")
 			}
@@ -0,0 +1,24 @@
+package sso
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/sh-miyoshi/hekate/pkg/config"
+)
+
+func TestBrowserStateCookie(t *testing.T) {
+	defer func(enabled bool) { config.Get().HTTPSConfig.Enabled = enabled }(config.Get().HTTPSConfig.Enabled)
+
+	config.Get().HTTPSConfig.Enabled = false
+	c := browserStateCookie("state", "http://localhost/authapi/v1/project/master", 60)
+	if c.Secure || c.SameSite != http.SameSiteLaxMode {
+		t.Errorf("Browser state cookie without https expects Lax and not secure, but got secure %v, SameSite %v", c.Secure, c.SameSite)
+	}
+
+	config.Get().HTTPSConfig.Enabled = true
+	c = browserStateCookie("state", "https://localhost/authapi/v1/project/master", 60)
+	if !c.Secure || c.SameSite != http.SameSiteNoneMode {
+		t.Errorf("Browser state cookie with https expects None and secure, but got secure %v, SameSite %v", c.Secure, c.SameSite)
+	}
+}
@@ -0,0 +1,77 @@
+package sso
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sh-miyoshi/hekate/pkg/config"
+	"github.com/sh-miyoshi/hekate/pkg/db"
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/oidc/token"
+)
+
+// trustedDeviceCookiePrefix is a prefix of the cookie name, and the user id follows it
+// The cookie is per user, so several users can trust the same browser.
+const trustedDeviceCookiePrefix = "HEKATE_TRUSTED_DEVICE_"
+
+// SetTrustedDeviceCookie registers the browser as the trusted device of the user, and sets the cookie
+func SetTrustedDeviceCookie(w http.ResponseWriter, prj *model.ProjectInfo, userID, issuer, userAgent string) *errors.Error {
+	now := time.Now()
+	lifeSpan := time.Second * time.Duration(prj.MFAPolicy.TrustedDeviceLifeSpan)
+	dev := &model.TrustedDevice{
+		ID:         uuid.New().String(),
+		UserAgent:  userAgent,
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(lifeSpan),
+		LastUsedAt: now,
+	}
+
+	req := token.Request{
+		Issuer:      issuer,
+		ExpiresIn:   int64(prj.MFAPolicy.TrustedDeviceLifeSpan),
+		ProjectName: prj.Name,
+		UserID:      userID,
+	}
+	tkn, err := token.GenerateTrustedDeviceToken(req, dev.ID)
+	if err != nil {
+		return errors.Append(err, "Failed to generate trusted device token")
+	}
+
+	if err := db.GetInst().TrustedDeviceAdd(prj.Name, userID, dev); err != nil {
+		return errors.Append(err, "Failed to add trusted device")
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     trustedDeviceCookiePrefix + userID,
+		Value:    tkn,
+		Path:     cookiePath(issuer),
+		MaxAge:   int(prj.MFAPolicy.TrustedDeviceLifeSpan),
+		Secure:   config.Get().HTTPSConfig.Enabled,
+		HttpOnly: true,
+	})
+	return nil
+}
+
+// VerifyTrustedDevice returns nil if the browser is the trusted device of the user
+// The device is not trusted if it is revoked in the server side even if the cookie is valid.
+func VerifyTrustedDevice(r *http.Request, projectName, userID, issuer string) *errors.Error {
+	cookie, e := r.Cookie(trustedDeviceCookiePrefix + userID)
+	if e != nil {
+		return errors.New("Invalid request", "No trusted device cookie: %v", e)
+	}
+
+	var claims token.TrustedDeviceClaims
+	if err := token.ValidateTrustedDeviceToken(&claims, cookie.Value, issuer); err != nil {
+		return errors.Append(err, "Failed to validate trusted device token")
+	}
+	if claims.Project != projectName || claims.Subject != userID {
+		return errors.New("Invalid request", "Trusted device token is for user %s in project %s", claims.Subject, claims.Project)
+	}
+
+	if err := db.GetInst().TrustedDeviceUse(projectName, userID, claims.Id, time.Now()); err != nil {
+		return errors.Append(err, "Failed to use trusted device")
+	}
+	return nil
+}
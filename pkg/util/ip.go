@@ -0,0 +1,15 @@
+package util
+
+import (
+	"net"
+	"net/http"
+)
+
+// ClientIP returns the IP address of the client which sends the request
+func ClientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
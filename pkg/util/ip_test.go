@@ -0,0 +1,24 @@
+package util
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	tt := []struct {
+		remoteAddr string
+		expect     string
+	}{
+		{"192.0.2.1:12345", "192.0.2.1"},
+		{"[2001:db8::1]:12345", "2001:db8::1"},
+		{"192.0.2.1", "192.0.2.1"},
+	}
+
+	for _, tc := range tt {
+		r := &http.Request{RemoteAddr: tc.remoteAddr}
+		if res := ClientIP(r); res != tc.expect {
+			t.Errorf("ClientIP returns wrong result. input: %s, got %s, want %s", tc.remoteAddr, res, tc.expect)
+		}
+	}
+}
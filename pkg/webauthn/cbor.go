@@ -0,0 +1,222 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// maxCBORDepth limits the nesting of the decoded item to reject a malicious input
+const maxCBORDepth = 16
+
+// cborDecoder is a minimal CBOR decoder for the data which the authenticator returns
+// Integers are decoded as int64, byte strings as []byte, text strings as string, arrays as []interface{},
+// maps as map[interface{}]interface{}, booleans as bool, null and undefined as nil, and floats as float64.
+// Indefinite length items are not supported because authenticators must use the canonical form.
+//   ref. https://www.w3.org/TR/webauthn-2/#sctn-conforming-all-classes
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+// decodeCBOR decodes the first item in the data, and returns it with the number of bytes read
+func decodeCBOR(data []byte) (interface{}, int, error) {
+	d := &cborDecoder{data: data}
+	v, err := d.decode(0)
+	if err != nil {
+		return nil, 0, err
+	}
+	return v, d.pos, nil
+}
+
+func (d *cborDecoder) readByte() (byte, error) {
+	if d.pos >= len(d.data) {
+		return 0, fmt.Errorf("unexpected end of data")
+	}
+	b := d.data[d.pos]
+	d.pos++
+	return b, nil
+}
+
+func (d *cborDecoder) readBytes(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, fmt.Errorf("unexpected end of data")
+	}
+	b := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return b, nil
+}
+
+// readArgument returns the argument of the head which has additional information info
+func (d *cborDecoder) readArgument(info byte) (uint64, error) {
+	switch {
+	case info < 24:
+		return uint64(info), nil
+	case info == 24:
+		b, err := d.readBytes(1)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(b[0]), nil
+	case info == 25:
+		b, err := d.readBytes(2)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case info == 26:
+		b, err := d.readBytes(4)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	case info == 27:
+		b, err := d.readBytes(8)
+		if err != nil {
+			return 0, err
+		}
+		return binary.BigEndian.Uint64(b), nil
+	}
+	return 0, fmt.Errorf("unsupported additional information %d", info)
+}
+
+func (d *cborDecoder) decode(depth int) (interface{}, error) {
+	if depth > maxCBORDepth {
+		return nil, fmt.Errorf("too deep nesting")
+	}
+
+	head, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+	major := head >> 5
+	info := head & 0x1f
+
+	if major == 7 {
+		return d.decodeSimple(info)
+	}
+
+	arg, err := d.readArgument(info)
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case 0:
+		if arg > math.MaxInt64 {
+			return nil, fmt.Errorf("integer overflow")
+		}
+		return int64(arg), nil
+	case 1:
+		if arg > math.MaxInt64 {
+			return nil, fmt.Errorf("integer overflow")
+		}
+		return -1 - int64(arg), nil
+	case 2:
+		b, err := d.readBytes(arg)
+		if err != nil {
+			return nil, err
+		}
+		res := make([]byte, len(b))
+		copy(res, b)
+		return res, nil
+	case 3:
+		b, err := d.readBytes(arg)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	case 4:
+		if arg > uint64(len(d.data)-d.pos) {
+			return nil, fmt.Errorf("too large array length %d", arg)
+		}
+		res := []interface{}{}
+		for i := uint64(0); i < arg; i++ {
+			v, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, v)
+		}
+		return res, nil
+	case 5:
+		if arg > uint64(len(d.data)-d.pos) {
+			return nil, fmt.Errorf("too large map length %d", arg)
+		}
+		res := map[interface{}]interface{}{}
+		for i := uint64(0); i < arg; i++ {
+			k, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			switch k.(type) {
+			case int64, string:
+			default:
+				return nil, fmt.Errorf("unsupported map key type %T", k)
+			}
+			v, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := res[k]; ok {
+				return nil, fmt.Errorf("duplicate map key %v", k)
+			}
+			res[k] = v
+		}
+		return res, nil
+	case 6:
+		// tags are not used in the WebAuthn data, so return the content only
+		return d.decode(depth + 1)
+	}
+
+	return nil, fmt.Errorf("unknown major type %d", major)
+}
+
+func (d *cborDecoder) decodeSimple(info byte) (interface{}, error) {
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 25:
+		b, err := d.readBytes(2)
+		if err != nil {
+			return nil, err
+		}
+		return float64(halfToFloat(binary.BigEndian.Uint16(b))), nil
+	case 26:
+		b, err := d.readBytes(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), nil
+	case 27:
+		b, err := d.readBytes(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+	}
+	return nil, fmt.Errorf("unsupported simple value %d", info)
+}
+
+func halfToFloat(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exp := (h >> 10) & 0x1f
+	frac := uint32(h & 0x3ff)
+
+	switch exp {
+	case 0:
+		// subnormal
+		v := float32(frac) / 1024 / 16384
+		if sign != 0 {
+			return -v
+		}
+		return v
+	case 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | frac<<13)
+	}
+	return math.Float32frombits(sign | uint32(exp+112)<<23 | frac<<13)
+}
@@ -0,0 +1,167 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"fmt"
+	"math/big"
+)
+
+// COSE algorithm identifiers which hekate accepts
+//   ref. https://www.iana.org/assignments/cose/cose.xhtml#algorithms
+const (
+	AlgES256 = -7
+	AlgEdDSA = -8
+	AlgRS256 = -257
+)
+
+// SupportedAlgorithms is a list of algorithms in the order of preference
+var SupportedAlgorithms = []int{AlgES256, AlgEdDSA, AlgRS256}
+
+// COSE key parameters
+//   ref. https://tools.ietf.org/html/rfc8152#section-13
+const (
+	coseKeyKty = 1
+	coseKeyAlg = 3
+
+	coseKeyCrv = -1
+	coseKeyX   = -2
+	coseKeyY   = -3
+	coseKeyN   = -1
+	coseKeyE   = -2
+
+	coseKtyOKP = 1
+	coseKtyEC2 = 2
+	coseKtyRSA = 3
+
+	coseCrvP256    = 1
+	coseCrvEd25519 = 6
+)
+
+// publicKey is a credential public key decoded from the COSE_Key format
+type publicKey struct {
+	alg int64
+	key crypto.PublicKey
+}
+
+func parseCOSEKey(data []byte) (*publicKey, error) {
+	v, n, err := decodeCBOR(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode COSE key: %v", err)
+	}
+	if n != len(data) {
+		return nil, fmt.Errorf("COSE key has trailing data")
+	}
+	m, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("COSE key is not a map")
+	}
+
+	kty, _ := m[int64(coseKeyKty)].(int64)
+	alg, ok := m[int64(coseKeyAlg)].(int64)
+	if !ok {
+		return nil, fmt.Errorf("COSE key has no algorithm")
+	}
+
+	switch alg {
+	case AlgES256:
+		crv, _ := m[int64(coseKeyCrv)].(int64)
+		x, _ := m[int64(coseKeyX)].([]byte)
+		y, _ := m[int64(coseKeyY)].([]byte)
+		if kty != coseKtyEC2 || crv != coseCrvP256 || len(x) != 32 || len(y) != 32 {
+			return nil, fmt.Errorf("invalid ES256 key")
+		}
+		key := &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, fmt.Errorf("ES256 key is not on the curve")
+		}
+		return &publicKey{alg: alg, key: key}, nil
+	case AlgEdDSA:
+		crv, _ := m[int64(coseKeyCrv)].(int64)
+		x, _ := m[int64(coseKeyX)].([]byte)
+		if kty != coseKtyOKP || crv != coseCrvEd25519 || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid EdDSA key")
+		}
+		return &publicKey{alg: alg, key: ed25519.PublicKey(x)}, nil
+	case AlgRS256:
+		n, _ := m[int64(coseKeyN)].([]byte)
+		e, _ := m[int64(coseKeyE)].([]byte)
+		if kty != coseKtyRSA || len(n) == 0 || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("invalid RS256 key")
+		}
+		key := &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+		if key.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("too short RSA key")
+		}
+		return &publicKey{alg: alg, key: key}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported algorithm %d", alg)
+}
+
+// verify checks the signature of the data by the key
+func (k *publicKey) verify(data, sig []byte) error {
+	return verifySignature(k.alg, k.key, data, sig)
+}
+
+func verifySignature(alg int64, key crypto.PublicKey, data, sig []byte) error {
+	switch alg {
+	case AlgES256:
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type %T does not match to ES256", key)
+		}
+		h := sha256.Sum256(data)
+		if !ecdsa.VerifyASN1(pub, h[:], sig) {
+			return fmt.Errorf("invalid ES256 signature")
+		}
+		return nil
+	case AlgEdDSA:
+		pub, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type %T does not match to EdDSA", key)
+		}
+		if !ed25519.Verify(pub, data, sig) {
+			return fmt.Errorf("invalid EdDSA signature")
+		}
+		return nil
+	case AlgRS256:
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type %T does not match to RS256", key)
+		}
+		h := sha256.Sum256(data)
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, h[:], sig); err != nil {
+			return fmt.Errorf("invalid RS256 signature: %v", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported algorithm %d", alg)
+}
+
+// verifyCertificateSignature checks the signature by the public key in the attestation certificate
+func verifyCertificateSignature(alg int64, cert *x509.Certificate, data, sig []byte) error {
+	return verifySignature(alg, cert.PublicKey, data, sig)
+}
+
+// ecdsaPoint returns the 32 bytes coordinates of the ES256 key
+func (k *publicKey) ecdsaPoint() ([]byte, []byte) {
+	pub := k.key.(*ecdsa.PublicKey)
+	x := make([]byte, 32)
+	y := make([]byte, 32)
+	pub.X.FillBytes(x)
+	pub.Y.FillBytes(y)
+	return x, y
+}
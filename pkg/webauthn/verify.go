@@ -0,0 +1,420 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+)
+
+// Attestation format identifiers
+//   ref. https://www.w3.org/TR/webauthn-2/#sctn-defined-attestation-formats
+const (
+	FormatNone    = "none"
+	FormatPacked  = "packed"
+	FormatFIDOU2F = "fido-u2f"
+)
+
+// authenticator data flags
+//   ref. https://www.w3.org/TR/webauthn-2/#flags
+const (
+	flagUserPresent  = 0x01
+	flagUserVerified = 0x04
+	flagAttested     = 0x40
+	flagExtension    = 0x80
+)
+
+const (
+	clientDataTypeCreate = "webauthn.create"
+	clientDataTypeGet    = "webauthn.get"
+)
+
+// RegistrationResponse is a JSON form of PublicKeyCredential which navigator.credentials.create returns
+//   ref. https://www.w3.org/TR/webauthn-3/#dictdef-registrationresponsejson
+type RegistrationResponse struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    string   `json:"clientDataJSON"`
+		AttestationObject string   `json:"attestationObject"`
+		Transports        []string `json:"transports"`
+	} `json:"response"`
+}
+
+// AssertionResponse is a JSON form of PublicKeyCredential which navigator.credentials.get returns
+//   ref. https://www.w3.org/TR/webauthn-3/#dictdef-authenticationresponsejson
+type AssertionResponse struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    string `json:"clientDataJSON"`
+		AuthenticatorData string `json:"authenticatorData"`
+		Signature         string `json:"signature"`
+		UserHandle        string `json:"userHandle"`
+	} `json:"response"`
+}
+
+// RegisteredCredential is a result of the registration ceremony
+type RegisteredCredential struct {
+	ID                string
+	PublicKey         []byte
+	SignCount         uint32
+	AAGUID            []byte
+	AttestationFormat string
+	UserVerified      bool
+}
+
+// AssertionResult is a result of the authentication ceremony
+type AssertionResult struct {
+	SignCount    uint32
+	UserVerified bool
+}
+
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+type authenticatorData struct {
+	rpIDHash     []byte
+	flags        byte
+	signCount    uint32
+	aaguid       []byte
+	credentialID []byte
+	publicKey    []byte
+}
+
+// decodeBase64URL decodes the value which the browser encodes by base64url
+// Some libraries add the padding, so both forms are accepted.
+func decodeBase64URL(s string) ([]byte, error) {
+	if b, err := base64.RawURLEncoding.DecodeString(s); err == nil {
+		return b, nil
+	}
+	return base64.URLEncoding.DecodeString(s)
+}
+
+func verifyClientData(raw []byte, typ, challenge, origin string) error {
+	var c clientData
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return fmt.Errorf("failed to parse client data: %v", err)
+	}
+	if c.Type != typ {
+		return fmt.Errorf("client data type %s is not %s", c.Type, typ)
+	}
+	if challenge == "" || subtle.ConstantTimeCompare([]byte(c.Challenge), []byte(challenge)) != 1 {
+		return fmt.Errorf("challenge does not match")
+	}
+	if c.Origin != origin {
+		return fmt.Errorf("origin %s does not match to %s", c.Origin, origin)
+	}
+	return nil
+}
+
+// parseAuthenticatorData parses the binary authenticator data
+//   ref. https://www.w3.org/TR/webauthn-2/#sctn-authenticator-data
+func parseAuthenticatorData(data []byte) (*authenticatorData, error) {
+	if len(data) < 37 {
+		return nil, fmt.Errorf("too short authenticator data")
+	}
+	res := &authenticatorData{
+		rpIDHash:  data[:32],
+		flags:     data[32],
+		signCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	rest := data[37:]
+
+	if res.flags&flagAttested != 0 {
+		if len(rest) < 18 {
+			return nil, fmt.Errorf("too short attested credential data")
+		}
+		res.aaguid = rest[:16]
+		l := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if len(rest) < l {
+			return nil, fmt.Errorf("too short credential id")
+		}
+		res.credentialID = rest[:l]
+		rest = rest[l:]
+
+		_, n, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode credential public key: %v", err)
+		}
+		res.publicKey = rest[:n]
+		rest = rest[n:]
+	}
+
+	if res.flags&flagExtension != 0 {
+		_, n, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode extensions: %v", err)
+		}
+		rest = rest[n:]
+	}
+
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("authenticator data has trailing data")
+	}
+	return res, nil
+}
+
+func (a *authenticatorData) verify(rpID string, requireUV bool) error {
+	h := sha256.Sum256([]byte(rpID))
+	if !bytes.Equal(a.rpIDHash, h[:]) {
+		return fmt.Errorf("rp id hash does not match")
+	}
+	if a.flags&flagUserPresent == 0 {
+		return fmt.Errorf("user is not present")
+	}
+	if requireUV && a.flags&flagUserVerified == 0 {
+		return fmt.Errorf("user is not verified")
+	}
+	return nil
+}
+
+// VerifyRegistration verifies the response of navigator.credentials.create
+// The attestation statement is verified with its own certificate, but the certificate chain is not
+// checked against trust anchors because hekate does not use the FIDO metadata service.
+// So the project can require the attestation statement, but it does not prove the authenticator model.
+//   ref. https://www.w3.org/TR/webauthn-2/#sctn-registering-a-new-credential
+func VerifyRegistration(resp *RegistrationResponse, challenge, origin, rpID string, requireAttestation, requireUV bool) (*RegisteredCredential, error) {
+	if resp.Type != "public-key" {
+		return nil, fmt.Errorf("invalid credential type %s", resp.Type)
+	}
+
+	rawClientData, err := decodeBase64URL(resp.Response.ClientDataJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode client data: %v", err)
+	}
+	if err := verifyClientData(rawClientData, clientDataTypeCreate, challenge, origin); err != nil {
+		return nil, err
+	}
+
+	rawAttObj, err := decodeBase64URL(resp.Response.AttestationObject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode attestation object: %v", err)
+	}
+	v, n, err := decodeCBOR(rawAttObj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode attestation object: %v", err)
+	}
+	if n != len(rawAttObj) {
+		return nil, fmt.Errorf("attestation object has trailing data")
+	}
+	attObj, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("attestation object is not a map")
+	}
+	format, _ := attObj["fmt"].(string)
+	attStmt, _ := attObj["attStmt"].(map[interface{}]interface{})
+	rawAuthData, _ := attObj["authData"].([]byte)
+	if format == "" || attStmt == nil || rawAuthData == nil {
+		return nil, fmt.Errorf("attestation object lacks required fields")
+	}
+
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := authData.verify(rpID, requireUV); err != nil {
+		return nil, err
+	}
+	if authData.flags&flagAttested == 0 {
+		return nil, fmt.Errorf("authenticator data has no attested credential")
+	}
+
+	credID := base64.RawURLEncoding.EncodeToString(authData.credentialID)
+	if id, err := decodeBase64URL(resp.ID); err != nil || !bytes.Equal(id, authData.credentialID) {
+		return nil, fmt.Errorf("credential id does not match")
+	}
+
+	key, err := parseCOSEKey(authData.publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	clientDataHash := sha256.Sum256(rawClientData)
+	switch format {
+	case FormatNone:
+		if requireAttestation {
+			return nil, fmt.Errorf("attestation statement is required")
+		}
+		if len(attStmt) != 0 {
+			return nil, fmt.Errorf("none attestation has statement")
+		}
+	case FormatPacked:
+		if err := verifyPacked(attStmt, key, rawAuthData, clientDataHash[:]); err != nil {
+			return nil, err
+		}
+	case FormatFIDOU2F:
+		if err := verifyFIDOU2F(attStmt, key, authData, clientDataHash[:]); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported attestation format %s", format)
+	}
+
+	return &RegisteredCredential{
+		ID:                credID,
+		PublicKey:         authData.publicKey,
+		SignCount:         authData.signCount,
+		AAGUID:            authData.aaguid,
+		AttestationFormat: format,
+		UserVerified:      authData.flags&flagUserVerified != 0,
+	}, nil
+}
+
+// verifyPacked verifies the attestation statement of packed format
+//   ref. https://www.w3.org/TR/webauthn-2/#sctn-packed-attestation
+func verifyPacked(attStmt map[interface{}]interface{}, key *publicKey, authData, clientDataHash []byte) error {
+	alg, ok := attStmt["alg"].(int64)
+	if !ok {
+		return fmt.Errorf("packed attestation has no alg")
+	}
+	sig, ok := attStmt["sig"].([]byte)
+	if !ok {
+		return fmt.Errorf("packed attestation has no sig")
+	}
+	signed := append(append([]byte{}, authData...), clientDataHash...)
+
+	x5c, ok := attStmt["x5c"].([]interface{})
+	if !ok {
+		// self attestation
+		if alg != key.alg {
+			return fmt.Errorf("self attestation alg %d does not match to the credential key", alg)
+		}
+		if err := key.verify(signed, sig); err != nil {
+			return fmt.Errorf("failed to verify self attestation: %v", err)
+		}
+		return nil
+	}
+
+	cert, err := attestationCertificate(x5c)
+	if err != nil {
+		return err
+	}
+	if err := verifyCertificateSignature(alg, cert, signed, sig); err != nil {
+		return fmt.Errorf("failed to verify packed attestation: %v", err)
+	}
+	if cert.Version != 3 || cert.IsCA {
+		return fmt.Errorf("invalid attestation certificate")
+	}
+	return nil
+}
+
+// verifyFIDOU2F verifies the attestation statement of fido-u2f format
+//   ref. https://www.w3.org/TR/webauthn-2/#sctn-fido-u2f-attestation
+func verifyFIDOU2F(attStmt map[interface{}]interface{}, key *publicKey, authData *authenticatorData, clientDataHash []byte) error {
+	sig, ok := attStmt["sig"].([]byte)
+	if !ok {
+		return fmt.Errorf("fido-u2f attestation has no sig")
+	}
+	x5c, ok := attStmt["x5c"].([]interface{})
+	if !ok || len(x5c) != 1 {
+		return fmt.Errorf("fido-u2f attestation must have one certificate")
+	}
+	cert, err := attestationCertificate(x5c)
+	if err != nil {
+		return err
+	}
+
+	if key.alg != AlgES256 {
+		return fmt.Errorf("fido-u2f credential must be ES256")
+	}
+	// the COSE key is already validated as P-256 point
+	x, y := key.ecdsaPoint()
+	signed := []byte{0x00}
+	signed = append(signed, authData.rpIDHash...)
+	signed = append(signed, clientDataHash...)
+	signed = append(signed, authData.credentialID...)
+	signed = append(signed, 0x04)
+	signed = append(signed, x...)
+	signed = append(signed, y...)
+
+	if err := verifyCertificateSignature(AlgES256, cert, signed, sig); err != nil {
+		return fmt.Errorf("failed to verify fido-u2f attestation: %v", err)
+	}
+	return nil
+}
+
+func attestationCertificate(x5c []interface{}) (*x509.Certificate, error) {
+	if len(x5c) == 0 {
+		return nil, fmt.Errorf("no attestation certificate")
+	}
+	der, ok := x5c[0].([]byte)
+	if !ok {
+		return nil, fmt.Errorf("invalid attestation certificate")
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse attestation certificate: %v", err)
+	}
+	return cert, nil
+}
+
+// VerifyAssertion verifies the response of navigator.credentials.get by the registered credential public key
+// It returns an error if the signature counter does not increase, because it means the authenticator may be cloned.
+//   ref. https://www.w3.org/TR/webauthn-2/#sctn-verifying-assertion
+func VerifyAssertion(resp *AssertionResponse, challenge, origin, rpID string, credentialPublicKey []byte, storedSignCount uint32, requireUV bool) (*AssertionResult, error) {
+	if resp.Type != "public-key" {
+		return nil, fmt.Errorf("invalid credential type %s", resp.Type)
+	}
+
+	rawClientData, err := decodeBase64URL(resp.Response.ClientDataJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode client data: %v", err)
+	}
+	if err := verifyClientData(rawClientData, clientDataTypeGet, challenge, origin); err != nil {
+		return nil, err
+	}
+
+	rawAuthData, err := decodeBase64URL(resp.Response.AuthenticatorData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode authenticator data: %v", err)
+	}
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := authData.verify(rpID, requireUV); err != nil {
+		return nil, err
+	}
+
+	sig, err := decodeBase64URL(resp.Response.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode signature: %v", err)
+	}
+	key, err := parseCOSEKey(credentialPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	clientDataHash := sha256.Sum256(rawClientData)
+	signed := append(append([]byte{}, rawAuthData...), clientDataHash[:]...)
+	if err := key.verify(signed, sig); err != nil {
+		return nil, err
+	}
+
+	// authenticators which do not support the counter always return 0
+	if (authData.signCount != 0 || storedSignCount != 0) && authData.signCount <= storedSignCount {
+		return nil, fmt.Errorf("signature counter %d is not greater than the stored value %d", authData.signCount, storedSignCount)
+	}
+
+	return &AssertionResult{
+		SignCount:    authData.signCount,
+		UserVerified: authData.flags&flagUserVerified != 0,
+	}, nil
+}
+
+// UserHandle returns the decoded user handle in the assertion response
+func (r *AssertionResponse) UserHandle() (string, error) {
+	b, err := decodeBase64URL(r.Response.UserHandle)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode user handle: %v", err)
+	}
+	return string(b), nil
+}
@@ -0,0 +1,396 @@
+package webauthn
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+)
+
+const (
+	testRPID   = "localhost"
+	testOrigin = "http://localhost:18443"
+)
+
+// cborPair is a map entry for the test encoder to keep the order of keys
+type cborPair struct {
+	key   interface{}
+	value interface{}
+}
+
+func encodeHead(major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return []byte{major<<5 | byte(n)}
+	case n < 1<<8:
+		return []byte{major<<5 | 24, byte(n)}
+	case n < 1<<16:
+		b := []byte{major<<5 | 25, 0, 0}
+		binary.BigEndian.PutUint16(b[1:], uint16(n))
+		return b
+	}
+	b := []byte{major<<5 | 26, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(b[1:], uint32(n))
+	return b
+}
+
+func encodeCBOR(v interface{}) []byte {
+	switch t := v.(type) {
+	case int:
+		if t >= 0 {
+			return encodeHead(0, uint64(t))
+		}
+		return encodeHead(1, uint64(-1-t))
+	case []byte:
+		return append(encodeHead(2, uint64(len(t))), t...)
+	case string:
+		return append(encodeHead(3, uint64(len(t))), t...)
+	case []interface{}:
+		res := encodeHead(4, uint64(len(t)))
+		for _, e := range t {
+			res = append(res, encodeCBOR(e)...)
+		}
+		return res
+	case []cborPair:
+		res := encodeHead(5, uint64(len(t)))
+		for _, p := range t {
+			res = append(res, encodeCBOR(p.key)...)
+			res = append(res, encodeCBOR(p.value)...)
+		}
+		return res
+	}
+	panic("unsupported type")
+}
+
+// softAuthenticator is a software implementation of the authenticator for the test
+type softAuthenticator struct {
+	key       *ecdsa.PrivateKey
+	credID    []byte
+	signCount uint32
+	flags     byte
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	id := make([]byte, 16)
+	rand.Read(id)
+	return &softAuthenticator{
+		key:    key,
+		credID: id,
+		flags:  flagUserPresent | flagUserVerified,
+	}
+}
+
+func (a *softAuthenticator) coseKey() []byte {
+	x := make([]byte, 32)
+	y := make([]byte, 32)
+	a.key.X.FillBytes(x)
+	a.key.Y.FillBytes(y)
+	return encodeCBOR([]cborPair{
+		{coseKeyKty, coseKtyEC2},
+		{coseKeyAlg, AlgES256},
+		{coseKeyCrv, coseCrvP256},
+		{coseKeyX, x},
+		{coseKeyY, y},
+	})
+}
+
+func (a *softAuthenticator) authData(rpID string, attested bool) []byte {
+	h := sha256.Sum256([]byte(rpID))
+	res := append([]byte{}, h[:]...)
+	flags := a.flags
+	if attested {
+		flags |= flagAttested
+	}
+	res = append(res, flags)
+	cnt := make([]byte, 4)
+	binary.BigEndian.PutUint32(cnt, a.signCount)
+	res = append(res, cnt...)
+	if attested {
+		res = append(res, make([]byte, 16)...) // aaguid
+		l := make([]byte, 2)
+		binary.BigEndian.PutUint16(l, uint16(len(a.credID)))
+		res = append(res, l...)
+		res = append(res, a.credID...)
+		res = append(res, a.coseKey()...)
+	}
+	return res
+}
+
+func (a *softAuthenticator) sign(t *testing.T, key *ecdsa.PrivateKey, data []byte) []byte {
+	h := sha256.Sum256(data)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, h[:])
+	if err != nil {
+		t.Fatalf("Failed to sign: %v", err)
+	}
+	return sig
+}
+
+func clientDataJSON(typ, challenge, origin string) []byte {
+	b, _ := json.Marshal(clientData{Type: typ, Challenge: challenge, Origin: origin})
+	return b
+}
+
+func selfSignedCert(t *testing.T, key *ecdsa.PrivateKey) []byte {
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test authenticator"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	return der
+}
+
+// create returns the response of navigator.credentials.create with the attestation format
+func (a *softAuthenticator) create(t *testing.T, format, challenge, origin, rpID string) *RegistrationResponse {
+	cd := clientDataJSON(clientDataTypeCreate, challenge, origin)
+	authData := a.authData(rpID, true)
+	cdHash := sha256.Sum256(cd)
+	signed := append(append([]byte{}, authData...), cdHash[:]...)
+
+	attStmt := []cborPair{}
+	switch format {
+	case FormatPacked:
+		// self attestation
+		attStmt = []cborPair{
+			{"alg", AlgES256},
+			{"sig", a.sign(t, a.key, signed)},
+		}
+	case "packed-x5c":
+		format = FormatPacked
+		attKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		attStmt = []cborPair{
+			{"alg", AlgES256},
+			{"sig", a.sign(t, attKey, signed)},
+			{"x5c", []interface{}{selfSignedCert(t, attKey)}},
+		}
+	case FormatFIDOU2F:
+		attKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		x := make([]byte, 32)
+		y := make([]byte, 32)
+		a.key.X.FillBytes(x)
+		a.key.Y.FillBytes(y)
+		h := sha256.Sum256([]byte(rpID))
+		u2f := []byte{0x00}
+		u2f = append(u2f, h[:]...)
+		u2f = append(u2f, cdHash[:]...)
+		u2f = append(u2f, a.credID...)
+		u2f = append(u2f, 0x04)
+		u2f = append(u2f, x...)
+		u2f = append(u2f, y...)
+		attStmt = []cborPair{
+			{"sig", a.sign(t, attKey, u2f)},
+			{"x5c", []interface{}{selfSignedCert(t, attKey)}},
+		}
+	}
+
+	attObj := encodeCBOR([]cborPair{
+		{"fmt", format},
+		{"attStmt", attStmt},
+		{"authData", authData},
+	})
+
+	res := &RegistrationResponse{
+		ID:   base64.RawURLEncoding.EncodeToString(a.credID),
+		Type: "public-key",
+	}
+	res.Response.ClientDataJSON = base64.RawURLEncoding.EncodeToString(cd)
+	res.Response.AttestationObject = base64.RawURLEncoding.EncodeToString(attObj)
+	return res
+}
+
+// get returns the response of navigator.credentials.get
+func (a *softAuthenticator) get(t *testing.T, challenge, origin, rpID string) *AssertionResponse {
+	a.signCount++
+	cd := clientDataJSON(clientDataTypeGet, challenge, origin)
+	authData := a.authData(rpID, false)
+	cdHash := sha256.Sum256(cd)
+
+	res := &AssertionResponse{
+		ID:   base64.RawURLEncoding.EncodeToString(a.credID),
+		Type: "public-key",
+	}
+	res.Response.ClientDataJSON = base64.RawURLEncoding.EncodeToString(cd)
+	res.Response.AuthenticatorData = base64.RawURLEncoding.EncodeToString(authData)
+	res.Response.Signature = base64.RawURLEncoding.EncodeToString(a.sign(t, a.key, append(authData, cdHash[:]...)))
+	res.Response.UserHandle = base64.RawURLEncoding.EncodeToString([]byte("user-id"))
+	return res
+}
+
+func TestDecodeCBOR(t *testing.T) {
+	// test vectors in RFC 7049 Appendix A
+	tt := []struct {
+		input  []byte
+		expect interface{}
+	}{
+		{[]byte{0x00}, int64(0)},
+		{[]byte{0x18, 0x64}, int64(100)},
+		{[]byte{0x39, 0x01, 0x00}, int64(-257)},
+		{[]byte{0x43, 0x01, 0x02, 0x03}, "\x01\x02\x03"},
+		{[]byte{0x64, 0x49, 0x45, 0x54, 0x46}, "IETF"},
+		{[]byte{0xf5}, true},
+		{[]byte{0xf6}, nil},
+		{[]byte{0xf9, 0x3c, 0x00}, float64(1)},
+	}
+
+	for _, tc := range tt {
+		res, n, err := decodeCBOR(tc.input)
+		if err != nil {
+			t.Errorf("decodeCBOR(%x) returns error: %v", tc.input, err)
+			continue
+		}
+		if b, ok := res.([]byte); ok {
+			res = string(b)
+		}
+		if res != tc.expect || n != len(tc.input) {
+			t.Errorf("decodeCBOR(%x) returns %v, %d, but expect %v, %d", tc.input, res, n, tc.expect, len(tc.input))
+		}
+	}
+
+	invalid := [][]byte{
+		{},
+		{0x18},
+		{0x43, 0x01},
+		{0x9f, 0x01, 0xff},       // indefinite length array
+		{0xa2, 0x01, 0x02, 0x01}, // duplicate key and no value
+		{0xa1, 0x41, 0x00, 0x01}, // byte string key
+	}
+	for _, tc := range invalid {
+		if _, _, err := decodeCBOR(tc); err == nil {
+			t.Errorf("decodeCBOR(%x) expects error, but got nil", tc)
+		}
+	}
+}
+
+func TestVerifyRegistration(t *testing.T) {
+	const challenge = "dGVzdC1jaGFsbGVuZ2U"
+
+	tt := []struct {
+		name               string
+		format             string
+		challenge          string
+		origin             string
+		rpID               string
+		requireAttestation bool
+		expectErr          bool
+	}{
+		{"none", FormatNone, challenge, testOrigin, testRPID, false, false},
+		{"none with attestation required", FormatNone, challenge, testOrigin, testRPID, true, true},
+		{"packed self", FormatPacked, challenge, testOrigin, testRPID, true, false},
+		{"packed x5c", "packed-x5c", challenge, testOrigin, testRPID, true, false},
+		{"fido-u2f", FormatFIDOU2F, challenge, testOrigin, testRPID, true, false},
+		{"wrong challenge", FormatNone, "other", testOrigin, testRPID, false, true},
+		{"wrong origin", FormatNone, challenge, "https://evil.example.com", testRPID, false, true},
+		{"wrong rp id", FormatNone, challenge, testOrigin, "evil.example.com", false, true},
+	}
+
+	for _, tc := range tt {
+		a := newSoftAuthenticator(t)
+		resp := a.create(t, tc.format, tc.challenge, tc.origin, tc.rpID)
+		res, err := VerifyRegistration(resp, challenge, testOrigin, testRPID, tc.requireAttestation, false)
+		if tc.expectErr {
+			if err == nil {
+				t.Errorf("VerifyRegistration %s expects error, but got nil", tc.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("VerifyRegistration %s returns error: %v", tc.name, err)
+			continue
+		}
+		if res.ID != resp.ID {
+			t.Errorf("VerifyRegistration %s returns credential id %s, but expect %s", tc.name, res.ID, resp.ID)
+		}
+		if _, err := parseCOSEKey(res.PublicKey); err != nil {
+			t.Errorf("VerifyRegistration %s returns invalid public key: %v", tc.name, err)
+		}
+	}
+
+	// the attestation signature does not cover the modified client data
+	a := newSoftAuthenticator(t)
+	resp := a.create(t, FormatPacked, challenge, testOrigin, testRPID)
+	cd, _ := json.Marshal(map[string]interface{}{
+		"type":        clientDataTypeCreate,
+		"challenge":   challenge,
+		"origin":      testOrigin,
+		"crossOrigin": true,
+	})
+	resp.Response.ClientDataJSON = base64.RawURLEncoding.EncodeToString(cd)
+	if _, err := VerifyRegistration(resp, challenge, testOrigin, testRPID, false, false); err == nil {
+		t.Errorf("VerifyRegistration with modified client data expects error, but got nil")
+	}
+}
+
+func TestVerifyAssertion(t *testing.T) {
+	const challenge = "dGVzdC1jaGFsbGVuZ2U"
+
+	a := newSoftAuthenticator(t)
+	reg, err := VerifyRegistration(a.create(t, FormatNone, challenge, testOrigin, testRPID), challenge, testOrigin, testRPID, false, false)
+	if err != nil {
+		t.Fatalf("Failed to register credential: %v", err)
+	}
+
+	// success
+	signCount := reg.SignCount
+	res, err := VerifyAssertion(a.get(t, challenge, testOrigin, testRPID), challenge, testOrigin, testRPID, reg.PublicKey, signCount, true)
+	if err != nil {
+		t.Fatalf("VerifyAssertion returns error: %v", err)
+	}
+	if res.SignCount != a.signCount || !res.UserVerified {
+		t.Errorf("VerifyAssertion returns %v, but expect sign count %d with user verified", res, a.signCount)
+	}
+	signCount = res.SignCount
+
+	// replayed sign counter means the cloned authenticator
+	a.signCount--
+	if _, err := VerifyAssertion(a.get(t, challenge, testOrigin, testRPID), challenge, testOrigin, testRPID, reg.PublicKey, signCount, false); err == nil {
+		t.Errorf("VerifyAssertion with old sign count expects error, but got nil")
+	}
+
+	// wrong challenge
+	if _, err := VerifyAssertion(a.get(t, "other", testOrigin, testRPID), challenge, testOrigin, testRPID, reg.PublicKey, signCount, false); err == nil {
+		t.Errorf("VerifyAssertion with wrong challenge expects error, but got nil")
+	}
+
+	// registration response is not an assertion
+	resp := a.get(t, challenge, testOrigin, testRPID)
+	resp.Response.ClientDataJSON = base64.RawURLEncoding.EncodeToString(clientDataJSON(clientDataTypeCreate, challenge, testOrigin))
+	if _, err := VerifyAssertion(resp, challenge, testOrigin, testRPID, reg.PublicKey, signCount, false); err == nil {
+		t.Errorf("VerifyAssertion with create type expects error, but got nil")
+	}
+
+	// signed by other key
+	other := newSoftAuthenticator(t)
+	other.credID = a.credID
+	other.signCount = a.signCount
+	if _, err := VerifyAssertion(other.get(t, challenge, testOrigin, testRPID), challenge, testOrigin, testRPID, reg.PublicKey, signCount, false); err == nil {
+		t.Errorf("VerifyAssertion with other key expects error, but got nil")
+	}
+
+	// user verification is required
+	a.flags = flagUserPresent
+	if _, err := VerifyAssertion(a.get(t, challenge, testOrigin, testRPID), challenge, testOrigin, testRPID, reg.PublicKey, signCount, true); err == nil {
+		t.Errorf("VerifyAssertion without user verification expects error, but got nil")
+	}
+	res, err = VerifyAssertion(a.get(t, challenge, testOrigin, testRPID), challenge, testOrigin, testRPID, reg.PublicKey, signCount, false)
+	if err != nil {
+		t.Errorf("VerifyAssertion without user verification returns error: %v", err)
+	} else if res.UserVerified {
+		t.Errorf("VerifyAssertion returns user verified, but the authenticator does not verify the user")
+	}
+}
@@ -0,0 +1,286 @@
+package webauthn
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sh-miyoshi/hekate/pkg/db"
+	"github.com/sh-miyoshi/hekate/pkg/db/model"
+	"github.com/sh-miyoshi/hekate/pkg/errors"
+	"github.com/sh-miyoshi/hekate/pkg/logger"
+	"github.com/sh-miyoshi/hekate/pkg/oidc/token"
+)
+
+const (
+	challengeLength = 32
+	// ceremonyTimeoutSec is a time limit of the registration and authentication ceremony
+	ceremonyTimeoutSec = 5 * 60
+	rpName             = "hekate"
+
+	// UserVerificationPreferred ...
+	UserVerificationPreferred = "preferred"
+	// UserVerificationRequired ...
+	UserVerificationRequired = "required"
+)
+
+var (
+	// ErrRegistrationNotStarted ...
+	ErrRegistrationNotStarted = errors.New("Registration is not started", "WebAuthn registration is not started or expired")
+	// ErrVerifyFailed ...
+	ErrVerifyFailed = errors.New("Failed to verify the authenticator", "Failed to verify WebAuthn response")
+)
+
+// RelyingParty is an entity of the relying party
+type RelyingParty struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+// User is an entity of the user account
+type User struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// CredentialParameter ...
+type CredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int    `json:"alg"`
+}
+
+// CredentialDescriptor ...
+type CredentialDescriptor struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+// AuthenticatorSelection ...
+type AuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+// CreationOptions is a JSON form of PublicKeyCredentialCreationOptions
+// Binary values are base64url encoded, so the client decodes them before calling navigator.credentials.create.
+//   ref. https://www.w3.org/TR/webauthn-2/#dictdef-publickeycredentialcreationoptions
+type CreationOptions struct {
+	RP                     RelyingParty           `json:"rp"`
+	User                   User                   `json:"user"`
+	Challenge              string                 `json:"challenge"`
+	PubKeyCredParams       []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                uint                   `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// RequestOptions is a JSON form of PublicKeyCredentialRequestOptions
+//   ref. https://www.w3.org/TR/webauthn-2/#dictdef-publickeycredentialrequestoptions
+type RequestOptions struct {
+	Challenge        string                 `json:"challenge"`
+	Timeout          uint                   `json:"timeout"`
+	RPID             string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+// RPID returns the relying party id of the request, it is the host name of the server
+func RPID(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host = r.Host
+	}
+	return host
+}
+
+// ExpectedOrigin returns the origin which the client data must have
+// The app which registers the credential by the user API may be served from other origin in the relying party domain,
+// so the Origin header is used if it is in the domain of rpID. Otherwise the origin of the server is expected.
+func ExpectedOrigin(r *http.Request, rpID string) string {
+	o := r.Header.Get("Origin")
+	if o == "" {
+		return token.GetExpectIssuer(r)
+	}
+	u, err := url.Parse(o)
+	if err != nil || u.Path != "" {
+		return token.GetExpectIssuer(r)
+	}
+	host := u.Hostname()
+	if host != rpID && !strings.HasSuffix(host, "."+rpID) {
+		return token.GetExpectIssuer(r)
+	}
+	// browsers allow http only for localhost
+	if u.Scheme != "https" && !(u.Scheme == "http" && host == "localhost") {
+		return token.GetExpectIssuer(r)
+	}
+	return o
+}
+
+// NewChallenge returns a new random challenge in the base64url encoding
+func NewChallenge() string {
+	b := make([]byte, challengeLength)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func userHandle(user *model.UserInfo) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(user.ID))
+}
+
+func credentialDescriptors(user *model.UserInfo) []CredentialDescriptor {
+	res := []CredentialDescriptor{}
+	if user == nil {
+		return res
+	}
+	for _, c := range user.WebAuthnInfo.Credentials {
+		res = append(res, CredentialDescriptor{
+			Type:       "public-key",
+			ID:         c.ID,
+			Transports: c.Transports,
+		})
+	}
+	return res
+}
+
+// BeginRegistration returns options of navigator.credentials.create, and saves the challenge to the user
+func BeginRegistration(project *model.ProjectInfo, user *model.UserInfo, rpID string) (*CreationOptions, *errors.Error) {
+	challenge := NewChallenge()
+	expires := time.Now().Add(ceremonyTimeoutSec * time.Second)
+	if err := db.GetInst().WebAuthnRegistrationStart(project.Name, user.ID, challenge, expires); err != nil {
+		return nil, errors.Append(err, "Failed to save registration challenge")
+	}
+
+	attestation := project.WebAuthnConfig.AttestationConveyance
+	if attestation == "" {
+		attestation = model.AttestationConveyanceNone
+	}
+
+	res := &CreationOptions{
+		RP: RelyingParty{
+			ID:   rpID,
+			Name: rpName,
+		},
+		User: User{
+			ID:          userHandle(user),
+			Name:        user.Name,
+			DisplayName: user.Name,
+		},
+		Challenge:          challenge,
+		Timeout:            ceremonyTimeoutSec * 1000,
+		ExcludeCredentials: credentialDescriptors(user),
+		AuthenticatorSelection: AuthenticatorSelection{
+			// discoverable credentials are preferred to use it for passwordless login
+			ResidentKey:      "preferred",
+			UserVerification: UserVerificationPreferred,
+		},
+		Attestation: attestation,
+	}
+	for _, alg := range SupportedAlgorithms {
+		res.PubKeyCredParams = append(res.PubKeyCredParams, CredentialParameter{Type: "public-key", Alg: alg})
+	}
+
+	return res, nil
+}
+
+// FinishRegistration verifies the response of navigator.credentials.create, and adds the credential to the user
+func FinishRegistration(project *model.ProjectInfo, user *model.UserInfo, name string, resp *RegistrationResponse, origin, rpID string) (*model.WebAuthnCredential, *errors.Error) {
+	info := user.WebAuthnInfo
+	if info.RegistrationChallenge == "" || time.Now().After(info.RegistrationExpiresAt) {
+		return nil, ErrRegistrationNotStarted
+	}
+
+	requireAttestation := project.WebAuthnConfig.AttestationConveyance == model.AttestationConveyanceDirect
+	c, err := VerifyRegistration(resp, info.RegistrationChallenge, origin, rpID, requireAttestation, false)
+	if err != nil {
+		return nil, errors.Append(ErrVerifyFailed, "Failed to verify registration: %v", err)
+	}
+
+	if name == "" {
+		name = "Security Key"
+	}
+	now := time.Now()
+	cred := &model.WebAuthnCredential{
+		ID:                c.ID,
+		Name:              name,
+		PublicKey:         c.PublicKey,
+		SignCount:         c.SignCount,
+		Transports:        resp.Response.Transports,
+		AAGUID:            hex.EncodeToString(c.AAGUID),
+		AttestationFormat: c.AttestationFormat,
+		CreatedAt:         now,
+		LastUsedAt:        now,
+	}
+	if err := db.GetInst().WebAuthnCredentialAdd(project.Name, user.ID, cred); err != nil {
+		return nil, errors.Append(err, "Failed to add WebAuthn credential")
+	}
+	logger.Info("WebAuthn credential %s is registered to user %s", cred.ID, user.ID)
+
+	return cred, nil
+}
+
+// NewRequestOptions returns options of navigator.credentials.get with the challenge
+// If the user is nil, allowCredentials is empty to use the discoverable credentials for passwordless login.
+func NewRequestOptions(user *model.UserInfo, rpID string, userVerification string) *RequestOptions {
+	return &RequestOptions{
+		Challenge:        NewChallenge(),
+		Timeout:          ceremonyTimeoutSec * 1000,
+		RPID:             rpID,
+		AllowCredentials: credentialDescriptors(user),
+		UserVerification: userVerification,
+	}
+}
+
+// FindUser returns the user who owns the credential in the assertion response
+// It is used in the passwordless login which the user is not identified before the authentication.
+func FindUser(projectName string, resp *AssertionResponse) (*model.UserInfo, *errors.Error) {
+	userID, e := resp.UserHandle()
+	if e != nil || userID == "" {
+		return nil, errors.Append(ErrVerifyFailed, "Failed to get user handle: %v", e)
+	}
+
+	user, err := db.GetInst().UserGet(projectName, userID)
+	if err != nil {
+		if errors.Contains(err, model.ErrNoSuchUser) {
+			return nil, errors.Append(ErrVerifyFailed, "User %s in the user handle is not found", userID)
+		}
+		return nil, errors.Append(err, "Failed to get user")
+	}
+	return user, nil
+}
+
+// Verify verifies the response of navigator.credentials.get by the user's credential
+// It updates the signature counter of the credential, and returns true if the authenticator verified the user.
+func Verify(projectName string, user *model.UserInfo, challenge string, resp *AssertionResponse, origin, rpID string, requireUV bool) (bool, *errors.Error) {
+	cred := user.GetWebAuthnCredential(resp.ID)
+	if cred == nil {
+		return false, errors.Append(ErrVerifyFailed, "User %s does not have credential %s", user.ID, resp.ID)
+	}
+
+	if resp.Response.UserHandle != "" {
+		if id, e := resp.UserHandle(); e != nil || id != user.ID {
+			return false, errors.Append(ErrVerifyFailed, "User handle does not match to user %s", user.ID)
+		}
+	}
+
+	res, e := VerifyAssertion(resp, challenge, origin, rpID, cred.PublicKey, cred.SignCount, requireUV)
+	if e != nil {
+		return false, errors.Append(ErrVerifyFailed, "Failed to verify assertion: %v", e)
+	}
+
+	upd := *cred
+	upd.SignCount = res.SignCount
+	upd.LastUsedAt = time.Now()
+	if err := db.GetInst().WebAuthnCredentialUpdate(projectName, user.ID, &upd); err != nil {
+		return false, errors.Append(err, "Failed to update WebAuthn credential")
+	}
+
+	return res.UserVerified, nil
+}
@@ -12,15 +12,13 @@ SERVER_ADDR="http://localhost:18443"
 URL="$SERVER_ADDR/adminapi/v1"
 PROJECT_NAME="master"
 
-function test() {
-  token_info=`curl --insecure -s -X POST $SERVER_ADDR/authapi/v1/project/master/openid-connect/token \
-    -H "Content-Type: application/x-www-form-urlencoded" \
-    -d "username=admin" \
-    -d "password=password" \
-    -d "client_id=portal" \
-    -d 'grant_type=password'`
-  token=`echo $token_info | jq -r .access_token`
+source mfa_login.sh
+
+# the admin logs in only once because the same one-time code can not be used in parallel
+token_info=`mfa_login $SERVER_ADDR master admin password`
+token=`echo $token_info | jq -r .access_token`
 
+function test() {
   # user create
   result=`curl -s -X POST -H "Authorization: Bearer $token" \
     "$URL/project/master/user" \
@@ -0,0 +1,64 @@
+#!/bin/bash
+
+# Helper to get the token of the user who must use MFA, e.g. the user who has write-project role
+#   The password grant is rejected for such users, so the user logs in by the device flow of the portal client.
+#   The authenticator application is registered at the first login, and the secret is kept in TOTP_SECRET_DIR.
+#
+# Usage:
+#   source mfa_login.sh
+#   token_info=`mfa_login $SERVER_ADDR <project> <user name> <password>`
+
+TOTP_SECRET_DIR=${TOTP_SECRET_DIR:-"/tmp/hekate-test-totp"}
+
+function totp_code() {
+  python3 -c "
+import base64, hashlib, hmac, struct, sys, time
+key = base64.b32decode(sys.argv[1])
+msg = struct.pack('>Q', int(time.time()) // 30)
+h = hmac.new(key, msg, hashlib.sha1).digest()
+o = h[-1] & 15
+print('%06d' % ((struct.unpack('>I', h[o:o+4])[0] & 0x7fffffff) % 1000000))" $1
+}
+
+function mfa_login() {
+  server=$1
+  project=$2
+  name=$3
+  passwd=$4
+  base="$server/authapi/v1/project/$project"
+  secret_file="$TOTP_SECRET_DIR/$project.$name"
+
+  device=`curl --insecure -s -X POST $base/oauth/device -d "client_id=portal" -d "scope=openid"`
+  device_code=`echo $device | jq -r .device_code`
+  user_code=`echo $device | jq -r .user_code`
+
+  page=`curl --insecure -s -X POST $server/resource/project/$project/deviceverify -d "code=$user_code"`
+  session_id=`echo "$page" | grep -o 'login_session_id=[^&"]*' | head -1 | cut -d= -f2`
+  page=`curl --insecure -s -X POST "$base/authn/login?login_session_id=$session_id" -d "username=$name" -d "password=$passwd"`
+
+  if echo "$page" | grep -q 'otpenroll'; then
+    # the first login, register the authenticator application
+    secret=`echo "$page" | grep -o '<code>[A-Z2-7=]*</code>' | sed 's/<[^>]*>//g'`
+    mkdir -p $TOTP_SECRET_DIR
+    echo $secret > $secret_file
+    curl --insecure -s -o /dev/null -X POST "$base/authn/otpenroll?login_session_id=$session_id" -d "code=`totp_code $secret`"
+    curl --insecure -s -o /dev/null -X POST "$base/authn/otpenroll/continue?login_session_id=$session_id"
+  else
+    # the same code can not be used twice, so wait for the next period if it was used just before
+    secret=`cat $secret_file`
+    last_used=`cat $secret_file.used 2>/dev/null`
+    code=`totp_code $secret`
+    while [ "$code" = "$last_used" ]; do
+      sleep 1
+      code=`totp_code $secret`
+    done
+    curl --insecure -s -o /dev/null -X POST "$base/authn/otpverify?login_session_id=$session_id" -d "code=$code"
+  fi
+  totp_code `cat $secret_file` > $secret_file.used
+
+  curl --insecure -s -X POST $base/openid-connect/token \
+    -H "Content-Type: application/x-www-form-urlencoded" \
+    -d "device_code=$device_code" \
+    -d "client_id=portal" \
+    -d 'grant_type=urn:ietf:params:oauth:grant-type:device_code'
+}
@@ -85,7 +85,7 @@ func main() {
 	req, _ = http.NewRequest("POST", url, nil)
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 	values = neturl.Values{
-		"username":         []string{"admin"},
+		"username":         []string{"tester"},
 		"password":         []string{"password"},
 		"login_session_id": []string{code},
 	}
@@ -1,12 +1,9 @@
 #!/bin/bash
 
+source ../mfa_login.sh
+
 # Token get
-token_info=`curl --insecure -s -X POST http://localhost:18443/authapi/v1/project/master/openid-connect/token \
-  -H "Content-Type: application/x-www-form-urlencoded" \
-  -d "username=admin" \
-  -d "password=password" \
-  -d "client_id=portal" \
-  -d 'grant_type=password'`
+token_info=`mfa_login http://localhost:18443 master admin password`
 master_access_token=`echo $token_info | jq -r .access_token`
 
 # Register callback URL
@@ -14,3 +11,10 @@ curl --insecure -s -X PUT \
   -d "{\"id\":\"portal\",\"access_type\":\"public\",\"allowed_callback_urls\":[\"http://localhost:3000/callback\"]}" \
   -H "Authorization: Bearer $master_access_token" \
   http://localhost:18443/adminapi/v1/project/master/client/portal
+
+# Add user for the login tests
+#   the admin can not be used because the login requires the second factor
+curl --insecure -s -X POST \
+  -d "{\"name\":\"tester\",\"password\":\"password\"}" \
+  -H "Authorization: Bearer $master_access_token" \
+  http://localhost:18443/adminapi/v1/project/master/user
@@ -3,6 +3,8 @@
 SERVER_ADDR="http://localhost:18443"
 URL="$SERVER_ADDR/adminapi/v1"
 
+source mfa_login.sh
+
 curl $SERVER_ADDR/healthz -s -o /dev/null
 if [ $? != 0 ]; then
   echo "Before test, please run a server"
@@ -10,12 +12,7 @@ if [ $? != 0 ]; then
 fi
 
 # Get Master Token
-token_info=`curl --insecure -s -X POST $SERVER_ADDR/authapi/v1/project/master/openid-connect/token \
-  -H "Content-Type: application/x-www-form-urlencoded" \
-  -d "username=admin" \
-  -d "password=password" \
-  -d "client_id=portal" \
-  -d 'grant_type=password'`
+token_info=`mfa_login $SERVER_ADDR master admin password`
 master_access_token=`echo $token_info | jq -r .access_token`
 
 # Get Public Key Info
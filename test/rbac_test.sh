@@ -3,6 +3,8 @@
 CLI_DIR="../cmd/hctl"
 SERVER_ADDR="http://localhost:18443"
 
+source mfa_login.sh
+
 curl $SERVER_ADDR/healthz -s -o /dev/null
 if [ $? != 0 ]; then
   echo "Before test, please run a server"
@@ -30,12 +32,17 @@ function login() {
   name=$2
   passwd=$3
 
-  rawToken=`curl --insecure -s -X POST $SERVER_ADDR/authapi/v1/project/$project/openid-connect/token \
-    -H "Content-Type: application/x-www-form-urlencoded" \
-    -d "username=$name" \
-    -d "password=$passwd" \
-    -d "client_id=portal" \
-    -d 'grant_type=password'`
+  if [ "$4" = "mfa" ]; then
+    # the user who must use the second factor can not use the password grant
+    rawToken=`mfa_login $SERVER_ADDR $project $name $passwd`
+  else
+    rawToken=`curl --insecure -s -X POST $SERVER_ADDR/authapi/v1/project/$project/openid-connect/token \
+      -H "Content-Type: application/x-www-form-urlencoded" \
+      -d "username=$name" \
+      -d "password=$passwd" \
+      -d "client_id=portal" \
+      -d 'grant_type=password'`
+  fi
 
   # set expires time to tomorrow
   d=`date --rfc-3339=ns -d tomorrow`
@@ -51,8 +58,8 @@ function login() {
 cd $CLI_DIR
 go build
 
-login "master" "admin" "password"
-./hctl project add --name rbac-test --grantTypes password
+login "master" "admin" "password" mfa
+./hctl project add --name rbac-test --grantTypes password --grantTypes urn:ietf:params:oauth:grant-type:device_code
 ./hctl user add --project rbac-test --name viewer --password password --systemRoles "read-project"
 ./hctl user add --project rbac-test --name editor --password password --systemRoles "read-project,write-project"
 ./hctl project add --name rbac-test-2 --grantTypes password
@@ -62,7 +69,7 @@ login "master" "admin" "password"
 # Test read/write role
 #----------------------------------
 
-login "rbac-test" "editor" "password"
+login "rbac-test" "editor" "password" mfa
 test_command client add --project rbac-test --id test-client --accessType public
 test_command client get --project rbac-test
 
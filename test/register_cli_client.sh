@@ -2,12 +2,9 @@
 
 SERVER_ADDR=$1
 
-token=`curl --insecure -s -X POST $SERVER_ADDR/authapi/v1/project/master/openid-connect/token \
-  -H "Content-Type: application/x-www-form-urlencoded" \
-  -d "username=admin" \
-  -d "password=password" \
-  -d "client_id=portal" \
-  -d 'grant_type=password' | jq -r .access_token`
+source mfa_login.sh
+
+token=`mfa_login $SERVER_ADDR master admin password | jq -r .access_token`
 
 status=`curl --insecure -s -X POST -H "Authorization: Bearer $token" \
   "$SERVER_ADDR/adminapi/v1/project/master/client" \
@@ -1,14 +1,12 @@
 #!/bin/bash
 
-URL="http://localhost:18443/authapi/v1"
+SERVER_ADDR="http://localhost:18443"
+URL="$SERVER_ADDR/authapi/v1"
 PROJECT_NAME="master"
 
-token_info=`curl --insecure -s -X POST $URL/project/$PROJECT_NAME/openid-connect/token \
-  -H "Content-Type: application/x-www-form-urlencoded" \
-  -d "username=admin" \
-  -d "password=password" \
-  -d "client_id=portal" \
-  -d 'grant_type=password'`
+source mfa_login.sh
+
+token_info=`mfa_login $SERVER_ADDR $PROJECT_NAME admin password`
 access_token=`echo $token_info | jq -r .access_token`
 refresh_token=`echo $token_info | jq -r .refresh_token`
 
@@ -3,6 +3,8 @@
 CLI_DIR="../cmd/hctl"
 SERVER_ADDR="http://localhost:18443"
 
+source mfa_login.sh
+
 curl $SERVER_ADDR/healthz -s -o /dev/null
 if [ $? != 0 ]; then
   echo "Before test, please run a server"
@@ -30,12 +32,17 @@ function test_login() {
   name=$2
   passwd=$3
 
-  rawToken=`curl --insecure -s -X POST $SERVER_ADDR/authapi/v1/project/$project/openid-connect/token \
-    -H "Content-Type: application/x-www-form-urlencoded" \
-    -d "username=$name" \
-    -d "password=$passwd" \
-    -d "client_id=portal" \
-    -d 'grant_type=password'`
+  if [ "$4" = "mfa" ]; then
+    # the user who must use the second factor can not use the password grant
+    rawToken=`mfa_login $SERVER_ADDR $project $name $passwd`
+  else
+    rawToken=`curl --insecure -s -X POST $SERVER_ADDR/authapi/v1/project/$project/openid-connect/token \
+      -H "Content-Type: application/x-www-form-urlencoded" \
+      -d "username=$name" \
+      -d "password=$passwd" \
+      -d "client_id=portal" \
+      -d 'grant_type=password'`
+  fi
 
   ok=`echo $rawToken | jq .access_token`
   if [ $ok = "null" ]; then
@@ -80,7 +87,7 @@ cd $CLI_DIR
 go build
 echo "start test"
 
-test_login "master" "admin" "password"
+test_login "master" "admin" "password" mfa
 ./hctl project add --name lock-test --grantTypes password --maxLoginFailure 3 --lockDuration 5 --failureResetTime 10 --userLockEnabled
 ./hctl user add --project lock-test --name tester --password password
 
@@ -108,12 +115,12 @@ test_login "lock-test" "tester" "password"
 test_login_failed "lock-test" "tester" "invalid_password"
 test_login_failed "lock-test" "tester" "invalid_password"
 test_login_failed "lock-test" "tester" "invalid_password"
-test_login "master" "admin" "password"
+test_login "master" "admin" "password" mfa
 test_command user update unlock --project lock-test --name tester
 test_login "lock-test" "tester" "password"
 
 #----------------------------------
 # Post-processing
 #----------------------------------
-test_login "master" "admin" "password"
+test_login "master" "admin" "password" mfa
 ./hctl project delete --name lock-test
\ No newline at end of file